@@ -0,0 +1,246 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/store"
+)
+
+// Roles of an account in a transaction
+const (
+	AccountTxRoleInput       = "input"
+	AccountTxRoleOutput      = "output"
+	AccountTxRoleProposer    = "proposer"
+	AccountTxRoleSlashed     = "slashed"
+	AccountTxRoleSource      = "source"
+	AccountTxRoleTarget      = "target"
+	AccountTxRoleInitiator   = "initiator"
+	AccountTxRoleSplit       = "split"
+	AccountTxRoleFrom        = "from"
+	AccountTxRoleTo          = "to"
+	AccountTxRoleHolder      = "holder"
+	AccountTxRoleBeneficiary = "beneficiary"
+	AccountTxRoleOffender    = "offender"
+)
+
+// The entries of an account are stored in a sequence, i.e. "atx/e/<address>/<seq>", in the order
+// of the block finalization. Hence the heights of the entries are non-decreasing in the sequence.
+func accountTxEntryKey(address common.Address, seq uint64) common.Bytes {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, seq)
+	key := append(common.Bytes("atx/e/"), address[:]...)
+	key = append(key, '/')
+	return append(key, buf...)
+}
+
+// accountTxCountKey constructs the DB key for the number of indexed entries of the account.
+func accountTxCountKey(address common.Address) common.Bytes {
+	return append(common.Bytes("atx/c/"), address[:]...)
+}
+
+// accountTxBlockKey constructs the DB key which marks the block as indexed.
+func accountTxBlockKey(blockHash common.Hash) common.Bytes {
+	return append(common.Bytes("atx/b/"), blockHash[:]...)
+}
+
+// AccountTxEntry records a transaction which involves the account.
+type AccountTxEntry struct {
+	BlockHash common.Hash
+	Height    uint64
+	TxHash    common.Hash
+	Role      string
+}
+
+// AccountTxCountEntry records the number of indexed entries of an account.
+type AccountTxCountEntry struct {
+	Count uint64
+}
+
+// AccountTxIndexEnabled returns whether the transactions are indexed by the accounts involved.
+func (ch *Chain) AccountTxIndexEnabled() bool {
+	return ch.accountTxIndexEnabled
+}
+
+// addTxsToAccountIndex indexes the transactions of a finalized block by the accounts involved.
+func (ch *Chain) addTxsToAccountIndex(block *core.ExtendedBlock) {
+	if !ch.accountTxIndexEnabled || !block.Status.IsFinalized() {
+		return
+	}
+
+	ch.accountTxIndexMu.Lock()
+	defer ch.accountTxIndexMu.Unlock()
+
+	blockHash := block.Hash()
+	blockKey := accountTxBlockKey(blockHash)
+	if err := ch.store.Get(blockKey, &AccountTxCountEntry{}); err != store.ErrKeyNotFound {
+		return // already indexed
+	}
+
+	for _, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			continue
+		}
+		txHash := crypto.Keccak256Hash(rawTx)
+		for _, ar := range ch.accountRolesOfTx(blockHash, txHash, tx) {
+			ch.appendAccountTxEntry(ar.address, &AccountTxEntry{
+				BlockHash: blockHash,
+				Height:    block.Height,
+				TxHash:    txHash,
+				Role:      ar.role,
+			})
+		}
+	}
+
+	err := ch.store.Put(blockKey, AccountTxCountEntry{Count: uint64(len(block.Txs))})
+	if err != nil {
+		logger.Panic(err)
+	}
+}
+
+func (ch *Chain) appendAccountTxEntry(address common.Address, entry *AccountTxEntry) {
+	count := ch.getAccountTxCount(address)
+	err := ch.store.Put(accountTxEntryKey(address, count), *entry)
+	if err != nil {
+		logger.Panic(err)
+	}
+	err = ch.store.Put(accountTxCountKey(address), AccountTxCountEntry{Count: count + 1})
+	if err != nil {
+		logger.Panic(err)
+	}
+}
+
+func (ch *Chain) getAccountTxCount(address common.Address) uint64 {
+	entry := &AccountTxCountEntry{}
+	err := ch.store.Get(accountTxCountKey(address), entry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Panic(err)
+		}
+		return 0
+	}
+	return entry.Count
+}
+
+func (ch *Chain) getAccountTxEntry(address common.Address, seq uint64) *AccountTxEntry {
+	entry := &AccountTxEntry{}
+	err := ch.store.Get(accountTxEntryKey(address, seq), entry)
+	if err != nil {
+		logger.Panic(err)
+	}
+	return entry
+}
+
+// FindAccountTxs returns the indexed transactions of the account within the height range [fromHeight,
+// toHeight], from the newest to the oldest. The cursor returned by the previous call continues the
+// listing, and zero starts from the newest. A returned cursor of zero means there are no more
+// transactions in the range.
+func (ch *Chain) FindAccountTxs(address common.Address, fromHeight, toHeight uint64, cursor uint64, limit int) ([]*AccountTxEntry, uint64) {
+	ch.accountTxIndexMu.Lock()
+	defer ch.accountTxIndexMu.Unlock()
+
+	count := ch.getAccountTxCount(address)
+	end := count
+	if cursor > 0 && cursor < end {
+		end = cursor
+	}
+
+	// Skip the entries above toHeight
+	end = uint64(sort.Search(int(end), func(i int) bool {
+		return ch.getAccountTxEntry(address, uint64(i)).Height > toHeight
+	}))
+
+	entries := []*AccountTxEntry{}
+	seq := end
+	for seq > 0 && len(entries) < limit {
+		entry := ch.getAccountTxEntry(address, seq-1)
+		if entry.Height < fromHeight {
+			return entries, 0
+		}
+		entries = append(entries, entry)
+		seq--
+	}
+	if seq == 0 || ch.getAccountTxEntry(address, seq-1).Height < fromHeight {
+		return entries, 0
+	}
+	return entries, seq
+}
+
+type accountRole struct {
+	address common.Address
+	role    string
+}
+
+// accountRolesOfTx returns the accounts involved in the transaction, and their roles
+func (ch *Chain) accountRolesOfTx(blockHash common.Hash, txHash common.Hash, tx types.Tx) []accountRole {
+	roles := []accountRole{}
+	seen := make(map[accountRole]bool)
+	add := func(address common.Address, role string) {
+		ar := accountRole{address: address, role: role}
+		if (address == common.Address{}) || seen[ar] {
+			return
+		}
+		seen[ar] = true
+		roles = append(roles, ar)
+	}
+
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		add(tx.Proposer.Address, AccountTxRoleProposer)
+		for _, output := range tx.Outputs {
+			add(output.Address, AccountTxRoleOutput)
+		}
+	case *types.SlashTx:
+		add(tx.Proposer.Address, AccountTxRoleProposer)
+		add(tx.SlashedAddress, AccountTxRoleSlashed)
+	case *types.SendTx:
+		for _, input := range tx.Inputs {
+			add(input.Address, AccountTxRoleInput)
+		}
+		for _, output := range tx.Outputs {
+			add(output.Address, AccountTxRoleOutput)
+		}
+	case *types.ReserveFundTx:
+		add(tx.Source.Address, AccountTxRoleSource)
+	case *types.ReleaseFundTx:
+		add(tx.Source.Address, AccountTxRoleSource)
+	case *types.ServicePaymentTx:
+		add(tx.Source.Address, AccountTxRoleSource)
+		add(tx.Target.Address, AccountTxRoleTarget)
+	case *types.SplitRuleTx:
+		add(tx.Initiator.Address, AccountTxRoleInitiator)
+		for _, split := range tx.Splits {
+			add(split.Address, AccountTxRoleSplit)
+		}
+	case *types.SmartContractTx:
+		add(tx.From.Address, AccountTxRoleFrom)
+		to := tx.To.Address
+		if (to == common.Address{}) { // contract deployment
+			if receipt, found := ch.FindTxReceiptByHash(blockHash, txHash); found {
+				to = receipt.ContractAddress
+			}
+		}
+		add(to, AccountTxRoleTo)
+	case *types.DepositStakeTx:
+		add(tx.Source.Address, AccountTxRoleSource)
+		add(tx.Holder.Address, AccountTxRoleHolder)
+	case *types.DepositStakeTxV1:
+		add(tx.Source.Address, AccountTxRoleSource)
+		add(tx.Holder.Address, AccountTxRoleHolder)
+	case *types.WithdrawStakeTx:
+		add(tx.Source.Address, AccountTxRoleSource)
+		add(tx.Holder.Address, AccountTxRoleHolder)
+	case *types.StakeRewardDistributionTx:
+		add(tx.Holder.Address, AccountTxRoleHolder)
+		add(tx.Beneficiary.Address, AccountTxRoleBeneficiary)
+	case *types.EquivocationSlashTx:
+		add(tx.Proposer.Address, AccountTxRoleProposer)
+		add(tx.Evidence.Offender(), AccountTxRoleOffender)
+	}
+	return roles
+}
@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/types"
+)
+
+func TestAccountTxIndex(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	viper.Set(common.CfgStorageIndexAccountTxs, true)
+	defer viper.Set(common.CfgStorageIndexAccountTxs, false)
+
+	chain := CreateTestChain()
+	require.True(chain.AccountTxIndexEnabled())
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	parent := chain.Root().Block
+	txHashes := []common.Hash{}
+	var blocks []*core.Block
+	for i := 0; i < 5; i++ {
+		var rawTx common.Bytes
+		if i%2 == 0 {
+			rawTx = createTestSendTx(alice, bob, uint64(i+1))
+		} else {
+			rawTx = createTestSendTx(bob, carol, uint64(i+1))
+		}
+		block := createTestBlockWithTxs(parent, []common.Bytes{rawTx})
+		_, err := chain.AddBlock(block)
+		require.Nil(err)
+		blocks = append(blocks, block)
+		txHashes = append(txHashes, crypto.Keccak256Hash(rawTx))
+		parent = block
+	}
+
+	// Only the finalized blocks are indexed
+	entries, cursor := chain.FindAccountTxs(bob, 0, ^uint64(0), 0, 10)
+	assert.Equal(0, len(entries))
+	assert.Equal(uint64(0), cursor)
+
+	// Finalizing the last block finalizes all the blocks in the branch, from the lowest block
+	require.Nil(chain.FinalizePreviousBlocks(blocks[4].Hash()))
+	chain.AddTxsToIndex(&core.ExtendedBlock{Block: blocks[4], Status: core.BlockStatusDirectlyFinalized}, true)
+
+	entries, cursor = chain.FindAccountTxs(bob, 0, ^uint64(0), 0, 10)
+	require.Equal(5, len(entries))
+	assert.Equal(uint64(0), cursor)
+	for i, entry := range entries {
+		// From the newest to the oldest
+		assert.Equal(txHashes[4-i], entry.TxHash)
+		assert.Equal(blocks[4-i].Height, entry.Height)
+		assert.Equal(blocks[4-i].Hash(), entry.BlockHash)
+		if (4-i)%2 == 0 {
+			assert.Equal(AccountTxRoleOutput, entry.Role)
+		} else {
+			assert.Equal(AccountTxRoleInput, entry.Role)
+		}
+	}
+
+	entries, _ = chain.FindAccountTxs(carol, 0, ^uint64(0), 0, 10)
+	require.Equal(2, len(entries))
+	assert.Equal(txHashes[3], entries[0].TxHash)
+	assert.Equal(txHashes[1], entries[1].TxHash)
+
+	// Pagination
+	entries, cursor = chain.FindAccountTxs(alice, 0, ^uint64(0), 0, 2)
+	require.Equal(2, len(entries))
+	assert.Equal(txHashes[4], entries[0].TxHash)
+	assert.Equal(txHashes[2], entries[1].TxHash)
+	assert.NotEqual(uint64(0), cursor)
+
+	entries, cursor = chain.FindAccountTxs(alice, 0, ^uint64(0), cursor, 2)
+	require.Equal(1, len(entries))
+	assert.Equal(txHashes[0], entries[0].TxHash)
+	assert.Equal(uint64(0), cursor)
+
+	// Height range
+	entries, cursor = chain.FindAccountTxs(bob, blocks[1].Height, blocks[3].Height, 0, 2)
+	require.Equal(2, len(entries))
+	assert.Equal(txHashes[3], entries[0].TxHash)
+	assert.Equal(txHashes[2], entries[1].TxHash)
+	assert.NotEqual(uint64(0), cursor)
+
+	entries, cursor = chain.FindAccountTxs(bob, blocks[1].Height, blocks[3].Height, cursor, 2)
+	require.Equal(1, len(entries))
+	assert.Equal(txHashes[1], entries[0].TxHash)
+	assert.Equal(uint64(0), cursor)
+}
+
+func TestAccountTxIndexDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	chain := CreateTestChain()
+	assert.False(chain.AccountTxIndexEnabled())
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	block := createTestBlockWithTxs(chain.Root().Block, []common.Bytes{createTestSendTx(alice, bob, 1)})
+	chain.AddBlock(block)
+	chain.FinalizePreviousBlocks(block.Hash())
+
+	entries, _ := chain.FindAccountTxs(alice, 0, ^uint64(0), 0, 10)
+	assert.Equal(0, len(entries))
+}
+
+func createTestSendTx(from, to common.Address, sequence uint64) common.Bytes {
+	tx := &types.SendTx{
+		Fee: types.NewCoins(0, 1000000000000),
+		Inputs: []types.TxInput{{
+			Address:  from,
+			Coins:    types.NewCoins(0, 1000000000010),
+			Sequence: sequence,
+		}},
+		Outputs: []types.TxOutput{{
+			Address: to,
+			Coins:   types.NewCoins(0, 10),
+		}},
+	}
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func createTestBlockWithTxs(parent *core.Block, txs []common.Bytes) *core.Block {
+	block := core.NewBlock()
+	block.ChainID = parent.ChainID
+	block.Parent = parent.Hash()
+	block.Height = parent.Height + 1
+	block.Epoch = parent.Epoch + 1
+	block.Txs = txs
+	block.TxHash = core.CalculateRootHash(txs)
+	return block
+}
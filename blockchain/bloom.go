@@ -0,0 +1,187 @@
+package blockchain
+
+import (
+	"encoding/binary"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/store"
+)
+
+// BloomSectionSize is the number of consecutive heights covered by a section bloom.
+const BloomSectionSize uint64 = 1024
+
+// blockBloomKey constructs the DB key for the log bloom of the given block.
+func blockBloomKey(blockHash common.Hash) common.Bytes {
+	return append(common.Bytes("bloom/b/"), blockHash[:]...)
+}
+
+// sectionBloomKey constructs the DB key for the log bloom of the given section.
+func sectionBloomKey(section uint64) common.Bytes {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, section)
+	return append(common.Bytes("bloom/s/"), buf...)
+}
+
+// SectionBloomEntry is the union of the log blooms of the finalized blocks in a section.
+type SectionBloomEntry struct {
+	Bloom     core.Bloom
+	NumBlocks uint64 // number of blocks merged into the bloom
+}
+
+// BloomSection returns the index of the section the given height belongs to.
+func BloomSection(height uint64) uint64 {
+	return height / BloomSectionSize
+}
+
+// AddBlockToBloomIndex computes the log bloom of a finalized block from its tx receipts, and
+// merges it into the bloom of the section the block belongs to.
+func (ch *Chain) AddBlockToBloomIndex(block *core.ExtendedBlock) {
+	key := blockBloomKey(block.Hash())
+	if err := ch.store.Get(key, &core.Bloom{}); err != store.ErrKeyNotFound {
+		return // already indexed
+	}
+
+	bloom := ch.calculateBlockBloom(block)
+	err := ch.store.Put(key, bloom)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	section := BloomSection(block.Height)
+	sectionKey := sectionBloomKey(section)
+	entry := &SectionBloomEntry{}
+	err = ch.store.Get(sectionKey, entry)
+	if err != nil && err != store.ErrKeyNotFound {
+		logger.Panic(err)
+	}
+	entry.Bloom.Or(bloom)
+	entry.NumBlocks++
+	err = ch.store.Put(sectionKey, *entry)
+	if err != nil {
+		logger.Panic(err)
+	}
+}
+
+// GetBlockBloom returns the log bloom of the given block. For blocks finalized before the bloom
+// index was introduced, the bloom is calculated from the tx receipts on the fly.
+func (ch *Chain) GetBlockBloom(block *core.ExtendedBlock) core.Bloom {
+	bloom := core.Bloom{}
+	err := ch.store.Get(blockBloomKey(block.Hash()), &bloom)
+	if err == nil {
+		return bloom
+	}
+	if err != store.ErrKeyNotFound {
+		logger.Error(err)
+	}
+	return ch.calculateBlockBloom(block)
+}
+
+// GetSectionBloom returns the log bloom of the given section. It returns false if some blocks in the
+// section are not indexed yet, in which case the section bloom can NOT be used to skip the section.
+func (ch *Chain) GetSectionBloom(section uint64) (core.Bloom, bool) {
+	entry := &SectionBloomEntry{}
+	err := ch.store.Get(sectionBloomKey(section), entry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return core.Bloom{}, false
+	}
+	return entry.Bloom, entry.NumBlocks >= BloomSectionSize
+}
+
+func (ch *Chain) calculateBlockBloom(block *core.ExtendedBlock) core.Bloom {
+	bloom := core.Bloom{}
+	blockHash := block.Hash()
+	for _, rawTx := range block.Txs {
+		txHash := crypto.Keccak256Hash(rawTx)
+		receipt, found := ch.FindTxReceiptByHash(blockHash, txHash)
+		if !found {
+			continue
+		}
+		bloom.Or(types.LogsBloom(receipt.Logs))
+	}
+	return bloom
+}
+
+// ---------------- Log Filter ---------------
+
+// LogFilter specifies the contract addresses and topics to match. An empty address list matches
+// any address. Topics are matched by position, each position matches any of the listed topics,
+// and an empty list at a position matches any topic.
+type LogFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
+// MatchBloom returns false if the bloom guarantees that no log matches the filter.
+func (f *LogFilter) MatchBloom(bloom core.Bloom) bool {
+	if len(f.Addresses) > 0 {
+		matched := false
+		for _, addr := range f.Addresses {
+			if core.BloomLookup(bloom, addr) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		matched := false
+		for _, topic := range topics {
+			if core.BloomLookup(bloom, topic) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchLog returns whether the log matches the filter.
+func (f *LogFilter) MatchLog(log *types.Log) bool {
+	if len(f.Addresses) > 0 {
+		matched := false
+		for _, addr := range f.Addresses {
+			if log.Address == addr {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		matched := false
+		for _, topic := range topics {
+			if log.Topics[i] == topic {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/types"
+)
+
+func TestBloomIndex(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	privKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	contractAddr := common.HexToAddress("0x00000000000000000000000000000000000000ab")
+	otherAddr := privKey.PublicKey().Address()
+	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	otherTopic := common.HexToHash("0x01")
+
+	sctx := &types.SmartContractTx{
+		From:     types.TxInput{Address: otherAddr, Sequence: 1},
+		To:       types.TxOutput{Address: contractAddr},
+		GasLimit: 100000,
+		GasPrice: big.NewInt(1),
+	}
+	sctx.From.Signature, err = privKey.Sign(sctx.SignBytes("testchain"))
+	require.Nil(err)
+	rawTx, err := types.TxToBytes(sctx)
+	require.Nil(err)
+
+	block := core.CreateTestBlock("bloom1", "")
+	block.Height = 10
+	block.Txs = []common.Bytes{rawTx}
+	block.UpdateHash()
+
+	chain := CreateTestChain()
+	eb, err := chain.AddBlock(block)
+	require.Nil(err)
+
+	logs := []*types.Log{{Address: contractAddr, Topics: []common.Hash{transferTopic}}}
+	chain.AddTxReceipt(block, sctx, logs, nil, nil, common.Address{}, 21000, nil)
+
+	// not indexed yet, calculated from the receipts
+	bloom := chain.GetBlockBloom(eb)
+	assert.True(core.BloomLookup(bloom, contractAddr))
+	assert.True(core.BloomLookup(bloom, transferTopic))
+	_, complete := chain.GetSectionBloom(BloomSection(block.Height))
+	assert.False(complete)
+
+	chain.AddBlockToBloomIndex(eb)
+	chain.AddBlockToBloomIndex(eb) // should not be counted twice
+	assert.Equal(bloom, chain.GetBlockBloom(eb))
+
+	entry := &SectionBloomEntry{}
+	require.Nil(chain.store.Get(sectionBloomKey(BloomSection(block.Height)), entry))
+	assert.Equal(uint64(1), entry.NumBlocks)
+	assert.Equal(bloom, entry.Bloom)
+
+	filter := &LogFilter{Addresses: []common.Address{contractAddr}, Topics: [][]common.Hash{{transferTopic}}}
+	assert.True(filter.MatchBloom(bloom))
+	assert.True(filter.MatchLog(logs[0]))
+
+	filter = &LogFilter{Addresses: []common.Address{otherAddr, contractAddr}}
+	assert.True(filter.MatchBloom(bloom))
+	assert.True(filter.MatchLog(logs[0]))
+
+	filter = &LogFilter{Topics: [][]common.Hash{{otherTopic}}}
+	assert.False(filter.MatchBloom(bloom))
+	assert.False(filter.MatchLog(logs[0]))
+
+	filter = &LogFilter{Topics: [][]common.Hash{{}, {transferTopic}}}
+	assert.False(filter.MatchLog(logs[0]))
+}
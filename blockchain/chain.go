@@ -7,6 +7,7 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/core"
@@ -25,6 +26,9 @@ type Chain struct {
 	root    common.Hash
 
 	mu *sync.RWMutex
+
+	accountTxIndexEnabled bool
+	accountTxIndexMu      *sync.Mutex
 }
 
 // NewChain creates a new Chain instance.
@@ -33,6 +37,9 @@ func NewChain(chainID string, store store.Store, root *core.Block) *Chain {
 		ChainID: chainID,
 		store:   store,
 		mu:      &sync.RWMutex{},
+
+		accountTxIndexEnabled: viper.GetBool(common.CfgStorageIndexAccountTxs),
+		accountTxIndexMu:      &sync.Mutex{},
 	}
 	rootBlock, err := chain.FindBlock(root.Hash())
 	if err != nil {
@@ -274,18 +281,30 @@ func (ch *Chain) FinalizePreviousBlocks(hash common.Hash) error {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
-	status := core.BlockStatusDirectlyFinalized
+	var ret error
+	blocks := []*core.ExtendedBlock{}
 	for !hash.IsEmpty() {
 		block, err := ch.findBlock(hash)
 		if err != nil || block.Status.IsFinalized() {
-			return nil
+			break
 		}
 		if block.Status == core.BlockStatusDisposed {
-			return errors.New("Cannot finalize disposed branch")
+			ret = errors.New("Cannot finalize disposed branch")
+			break
+		}
+		blocks = append(blocks, block)
+		hash = block.Parent
+	}
+
+	// Finalize from the lowest block, so that the blocks are indexed in the order of height
+	for i := len(blocks) - 1; i >= 0; i-- {
+		block := blocks[i]
+		if i == 0 {
+			block.Status = core.BlockStatusDirectlyFinalized // Only the first block is marked as directly finalized
+		} else {
+			block.Status = core.BlockStatusIndirectlyFinalized
 		}
-		block.Status = status
-		status = core.BlockStatusIndirectlyFinalized // Only the first block is marked as directly finalized
-		err = ch.saveBlock(block)
+		err := ch.saveBlock(block)
 		if err != nil {
 			logger.Panic(err)
 		}
@@ -293,10 +312,8 @@ func (ch *Chain) FinalizePreviousBlocks(hash common.Hash) error {
 		// Force update TX index on block finalization so that the index doesn't point to
 		// duplicate TX in fork.
 		ch.AddTxsToIndex(block, true)
-
-		hash = block.Parent
 	}
-	return nil
+	return ret
 }
 
 func (ch *Chain) IsOrphan(block *core.Block) bool {
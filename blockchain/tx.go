@@ -49,6 +49,8 @@ func (ch *Chain) AddTxsToIndex(block *core.ExtendedBlock, force bool) {
 
 		ch.insertEthTxHash(block, tx, &txIndexEntry)
 	}
+
+	ch.addTxsToAccountIndex(block)
 }
 
 // Index the ETH smart contract transactions, using the ETH tx hash as the key
@@ -17,7 +17,14 @@ var initCmd = &cobra.Command{
 	Run:   runInit,
 }
 
+var (
+	devnet        bool
+	devnetChainID string
+)
+
 func init() {
+	initCmd.Flags().BoolVar(&devnet, "devnet", false, "initialize a devnet where all the features are live from block #1")
+	initCmd.Flags().StringVar(&devnetChainID, "chain_id", "privatenet", "chain ID of the devnet")
 	RootCmd.AddCommand(initCmd)
 }
 
@@ -30,6 +37,13 @@ func runInit(cmd *cobra.Command, args []string) {
 		log.WithFields(log.Fields{"err": err, "path": cfgPath}).Fatal("Failed to create config folder")
 	}
 
+	if devnet {
+		if err := common.WriteInitialDevnetConfig(cfgPath, devnetChainID); err != nil {
+			log.WithFields(log.Fields{"err": err, "path": cfgPath}).Fatal("Failed to write devnet config")
+		}
+		return
+	}
+
 	if err := common.WriteInitialConfig(path.Join(cfgPath, "config.yaml")); err != nil {
 		log.WithFields(log.Fields{"err": err, "path": cfgPath}).Fatal("Failed to write config")
 	}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	dp "github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/lightclient"
+	"github.com/dnerochain/dnero/netsync"
+	msg "github.com/dnerochain/dnero/p2p/messenger"
+	"github.com/dnerochain/dnero/p2p/scoring"
+	msgl "github.com/dnerochain/dnero/p2pl/messenger"
+	"github.com/dnerochain/dnero/store/database"
+	"github.com/dnerochain/dnero/store/kvstore"
+)
+
+// runLightClient runs the node in the header-only light client mode. It syncs the headers and votes
+// from the peers, tracks the finalized headers starting from the snapshot checkpoint, and serves the
+// queries verified with the proofs fetched from the configured full node RPC endpoints.
+func runLightClient(ctx context.Context, cancel context.CancelFunc, root *core.Block,
+	networkOld *msg.Messenger, network *msgl.Messenger, db database.Database, peerScorer *scoring.Scorer) {
+	f := func(c rune) bool {
+		return c == ','
+	}
+	endpoints := strings.FieldsFunc(viper.GetString(common.CfgLightClientRPCEndpoints), f)
+	if len(endpoints) == 0 {
+		log.Fatalf("No full node RPC endpoint is specified for the light client, please set %v", common.CfgLightClientRPCEndpoints)
+	}
+
+	fetcher := lightclient.NewRPCProofFetcher(endpoints)
+	lc := lightclient.NewLightClient(root.BlockHeader, kvstore.NewKVStore(db), fetcher)
+
+	dispatcher := dp.NewDispatcher(networkOld, network)
+	dispatcher.SetPeerScorer(peerScorer)
+	syncMgr := netsync.NewLightSyncManager(networkOld, network, dispatcher, lc)
+
+	var rpcServer *lightclient.LightRPCServer
+	if viper.GetBool(common.CfgRPCEnabled) {
+		rpcServer = lightclient.NewLightRPCServer(lc)
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		<-c
+		signal.Stop(c)
+		cancel()
+		if network != nil {
+			network.Stop()
+		}
+		// Wait at most 5 seconds before forcefully shutting down.
+		<-time.After(time.Duration(5) * time.Second)
+		close(done)
+	}()
+
+	syncMgr.Start(ctx)
+	if err := dispatcher.Start(ctx); err != nil {
+		log.Fatalf("Failed to start the p2p network: %v", err)
+	}
+	if rpcServer != nil {
+		rpcServer.Start(ctx)
+	}
+
+	go func() {
+		syncMgr.Wait()
+		if rpcServer != nil {
+			rpcServer.Wait()
+		}
+		close(done)
+	}()
+
+	<-done
+	log.Infof("")
+	log.Infof("Graceful exit.")
+	printExitBanner()
+}
@@ -17,14 +17,21 @@ import (
 	"github.com/spf13/viper"
 	"github.com/dnerochain/dnero/cmd/dnerocli/cmd/utils"
 	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/metrics"
 	"github.com/dnerochain/dnero/common/util"
 	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/crypto"
+	dp "github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/ledger"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/netsync"
 	"github.com/dnerochain/dnero/node"
 	msg "github.com/dnerochain/dnero/p2p/messenger"
+	"github.com/dnerochain/dnero/p2p/scoring"
 	msgl "github.com/dnerochain/dnero/p2pl/messenger"
 	"github.com/dnerochain/dnero/rlp"
 	"github.com/dnerochain/dnero/snapshot"
+	"github.com/dnerochain/dnero/store/database"
 	"github.com/dnerochain/dnero/store/database/backend"
 	"github.com/dnerochain/dnero/store/rollingdb"
 	"github.com/dnerochain/dnero/version"
@@ -52,6 +59,12 @@ func runStart(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to load or create key: %v", err)
 	}
 
+	// Metrics need to be enabled before the components register them
+	if viper.GetBool(common.CfgMetricsEnabled) {
+		metrics.Enabled = true
+		go metrics.CollectProcessMetrics(3 * time.Second)
+	}
+
 	// Open database
 	dbPath := viper.GetString(common.CfgDataPath)
 	if dbPath == "" {
@@ -70,6 +83,21 @@ func runStart(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to connect to the db. main: %v, ref: %v, err: %v",
 			mainDBPath, refDBPath, err)
 	}
+	if metrics.Enabled {
+		db.Meter("db/")
+	}
+
+	// An archive node guarantees the full state of every finalized block, which a pruned DB can't provide
+	if viper.GetBool(common.CfgStorageArchiveMode) {
+		if prunedHeight, pruned := ledger.PrunedHeight(rdb); pruned {
+			log.Fatalf("Can't run in the archive mode, the state below height %v has been pruned from the db at %v. "+
+				"Please start from an unpruned db", prunedHeight, dbPath)
+		}
+		log.Infof("Running in the archive mode, the state pruning and the db rolling are disabled")
+	}
+
+	// load the fork schedule specified in the config, which is used only for a chain without a stored schedule
+	chainParamsFromConfig := loadChainParamsFromConfig()
 
 	// load snapshot
 	if len(snapshotPath) == 0 {
@@ -83,6 +111,7 @@ func runStart(cmd *cobra.Command, args []string) {
 
 	// Read last verified snapshot header from db and compare with current snapshot
 	raw, err := db.Get([]byte("/snapshot_blockheader"))
+	snapshotLoaded := err == nil
 	if err == nil {
 		err = rlp.DecodeBytes(raw, dbSnapshotHeader)
 		if err == nil {
@@ -93,7 +122,25 @@ func runStart(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
-	if skipLoadSnapshot && !viper.GetBool(common.CfgForceValidateSnapshot) {
+	// A new node syncs the state from the peers instead of loading the snapshot, if enabled
+	stateSync := viper.GetBool(common.CfgSyncStateSyncEnabled) && !viper.GetBool(common.CfgLightClientEnabled) && !snapshotLoaded
+	stateSynced := false
+
+	if viper.GetBool(common.CfgLightClientEnabled) {
+		// the light client only trusts the checkpoint header, and does not load the snapshot state
+		snapshotBlockHeader = snapshot.LoadSnapshotCheckpointHeader(snapshotPath)
+		if snapshotBlockHeader == nil {
+			log.Fatalf("Failed to load the checkpoint header from snapshot: %v", snapshotPath)
+		}
+	} else if stateSync {
+		snapshotBlockHeader = loadStateSyncHeader(db)
+		if snapshotBlockHeader != nil {
+			log.Println("Skip state sync, the state has been synced from the peers")
+			stateSynced = true
+		} else if viper.GetString(common.CfgGenesisChainID) == "" {
+			log.Fatalf("Chain ID must be specified for the state sync, please set %v", common.CfgGenesisChainID)
+		}
+	} else if skipLoadSnapshot && !viper.GetBool(common.CfgForceValidateSnapshot) {
 		log.Println("Skip validating snapshot")
 	} else {
 		snapshotBlockHeader, err = snapshot.ValidateSnapshot(snapshotPath, chainImportDirPath, chainCorrectionPath)
@@ -110,9 +157,11 @@ func runStart(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	root = &core.Block{BlockHeader: snapshotBlockHeader}
-
-	viper.Set(common.CfgGenesisChainID, root.ChainID)
+	if snapshotBlockHeader != nil {
+		// otherwise the root is determined by the state sync after the p2p network starts
+		root = &core.Block{BlockHeader: snapshotBlockHeader}
+		setupChain(root, chainParamsFromConfig, db)
+	}
 
 	// Parse seeds and filter out empty item.
 	f := func(c rune) bool {
@@ -135,6 +184,28 @@ func runStart(cmd *cobra.Command, args []string) {
 		networkOld = newMessengerOld(privKey, peerSeedsOld, portOld, ctx)
 	}
 
+	peerScorer := newPeerScorer()
+	if network != nil {
+		network.SetPeerScorer(peerScorer)
+	}
+	if networkOld != nil {
+		networkOld.SetPeerScorer(peerScorer)
+	}
+
+	if viper.GetBool(common.CfgLightClientEnabled) {
+		runLightClient(ctx, cancel, root, networkOld, network, db, peerScorer)
+		return
+	}
+
+	var dispatcher *dp.Dispatcher
+	var stateSyncMgr *netsync.StateSyncManager
+	if root == nil {
+		snapshotBlockHeader, dispatcher, stateSyncMgr = runStateSync(ctx, networkOld, network, db)
+		root = &core.Block{BlockHeader: snapshotBlockHeader}
+		setupChain(root, chainParamsFromConfig, db)
+		stateSynced = true
+	}
+
 	params := &node.Params{
 		ChainID:             root.ChainID,
 		PrivateKey:          privKey,
@@ -146,6 +217,10 @@ func runStart(cmd *cobra.Command, args []string) {
 		SnapshotPath:        snapshotPath,
 		ChainImportDirPath:  chainImportDirPath,
 		ChainCorrectionPath: chainCorrectionPath,
+		StateSynced:         stateSynced,
+		Dispatcher:          dispatcher,
+		StateSyncManager:    stateSyncMgr,
+		PeerScorer:          peerScorer,
 	}
 
 	n := node.NewNode(params)
@@ -281,6 +356,70 @@ func loadOrCreateKey() (*crypto.PrivateKey, error) {
 	return nodePrivKey, nil
 }
 
+// setupChain sets the chain ID and registers the fork schedule for the chain of the root block. The schedule
+// stored in the snapshot state is authoritative, and the node refuses to start if the config specifies a
+// different one. The schedule in the config is used only for a chain without a stored schedule.
+func setupChain(root *core.Block, chainParamsFromConfig *common.ChainParams, db database.Database) {
+	viper.Set(common.CfgGenesisChainID, root.ChainID)
+
+	chainParams := loadChainParamsFromSnapshot(root, db)
+	if chainParams != nil {
+		if chainParamsFromConfig != nil && *chainParamsFromConfig != *chainParams {
+			log.Fatalf("The chain params specified by %v disagree with the ones stored for chain %v, "+
+				"please remove %v from the config", common.CfgGenesisChainParams, root.ChainID, common.CfgGenesisChainParams)
+		}
+		log.Infof("Loaded chain params from the snapshot")
+	} else if chainParamsFromConfig != nil {
+		if chainParamsFromConfig.ChainID != root.ChainID {
+			log.Fatalf("Chain ID mismatch: chain params are specified for %v, while the snapshot is for %v",
+				chainParamsFromConfig.ChainID, root.ChainID)
+		}
+		chainParams = chainParamsFromConfig
+		log.Infof("Loaded chain params from the config")
+	} else {
+		return
+	}
+
+	if err := common.RegisterChainParams(chainParams); err != nil {
+		log.Fatalf("Failed to register chain params: %v", err)
+	}
+}
+
+// loadChainParamsFromConfig loads the fork schedule specified by the config file, if any
+func loadChainParamsFromConfig() *common.ChainParams {
+	chainParamsPath := viper.GetString(common.CfgGenesisChainParams)
+	if chainParamsPath == "" {
+		return nil
+	}
+	if !path.IsAbs(chainParamsPath) {
+		chainParamsPath = path.Join(cfgPath, chainParamsPath)
+	}
+
+	chainParams, err := common.LoadChainParams(chainParamsPath)
+	if err != nil {
+		log.Fatalf("Failed to load chain params: %v", err)
+	}
+	return chainParams
+}
+
+// loadChainParamsFromSnapshot returns the fork schedule stored in the snapshot state, if any. Chains
+// without a stored schedule (e.g. the mainnet) follow the default schedule.
+func loadChainParamsFromSnapshot(root *core.Block, db database.Database) *common.ChainParams {
+	storeView := state.NewStoreView(root.Height, root.StateHash, db)
+	if storeView == nil {
+		return nil
+	}
+	chainParams := storeView.GetChainParams()
+	if chainParams == nil {
+		return nil
+	}
+	if chainParams.ChainID != root.ChainID {
+		log.Fatalf("Chain ID mismatch: chain params are stored for %v, while the snapshot is for %v",
+			chainParams.ChainID, root.ChainID)
+	}
+	return chainParams
+}
+
 func newMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string, port int, seedPeerOnly bool, ctx context.Context) *msgl.Messenger {
 	log.WithFields(log.Fields{
 		"pubKey":  fmt.Sprintf("%v", privKey.PublicKey().ToBytes()),
@@ -308,6 +447,13 @@ func newMessengerOld(privKey *crypto.PrivateKey, seedPeerNetAddresses []string,
 	return messenger
 }
 
+// newPeerScorer creates the peer scorer shared by the networks. The bans are persisted in the address book.
+func newPeerScorer() *scoring.Scorer {
+	addrBook := msg.NewAddrBook(path.Join(cfgPath, "addrbook.json"), false)
+	addrBook.OnStart()
+	return scoring.NewScorer(addrBook, scoring.GetDefaultScorerConfig())
+}
+
 func printCountdown() {
 	for i := 10; i >= 0; i-- {
 		fmt.Printf("\rLaunching Dnero Blockchain: %d...", i)
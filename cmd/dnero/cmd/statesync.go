@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/dnerochain/dnero/core"
+	dp "github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/netsync"
+	msg "github.com/dnerochain/dnero/p2p/messenger"
+	msgl "github.com/dnerochain/dnero/p2pl/messenger"
+	"github.com/dnerochain/dnero/rlp"
+	"github.com/dnerochain/dnero/store/database"
+)
+
+const stateSyncHeaderKey = "/statesync_blockheader"
+
+// runStateSync syncs the state of a recent finalized block from the peers, and returns its header to be
+// used as the root of the chain. The returned dispatcher is already started, and is reused by the node
+// together with the state sync manager.
+func runStateSync(ctx context.Context, networkOld *msg.Messenger, network *msgl.Messenger,
+	db database.Database) (*core.BlockHeader, *dp.Dispatcher, *netsync.StateSyncManager) {
+	dispatcher := dp.NewDispatcher(networkOld, network)
+	stateSyncMgr := netsync.NewStateSyncManager(networkOld, network, dispatcher, db)
+
+	stateSyncMgr.Start(ctx)
+	if err := dispatcher.Start(ctx); err != nil {
+		log.Fatalf("Failed to start the p2p network: %v", err)
+	}
+
+	log.Infof("Syncing the state from the peers")
+	header, err := stateSyncMgr.SyncState()
+	if err != nil {
+		log.Fatalf("State sync failed: %v", err)
+	}
+	log.Infof("State synced, height: %v, block: %v", header.Height, header.Hash().Hex())
+
+	// The node restarts the state sync manager to serve the peers
+	stateSyncMgr.Stop()
+	stateSyncMgr.Wait()
+
+	raw, err := rlp.EncodeToBytes(header)
+	if err == nil {
+		err = db.Put([]byte(stateSyncHeaderKey), raw)
+	}
+	if err != nil {
+		log.Fatalf("Failed to save state sync result: %v", err)
+	}
+
+	return header, dispatcher, stateSyncMgr
+}
+
+// loadStateSyncHeader returns the header of the block whose state has been synced from the peers, if any.
+func loadStateSyncHeader(db database.Database) *core.BlockHeader {
+	raw, err := db.Get([]byte(stateSyncHeaderKey))
+	if err != nil {
+		return nil
+	}
+	header := &core.BlockHeader{}
+	if err := rlp.DecodeBytes(raw, header); err != nil {
+		return nil
+	}
+	return header
+}
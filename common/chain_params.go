@@ -0,0 +1,140 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// ChainParams specifies the fork schedule of a network, i.e. the block heights
+// at which the protocol features are activated. A node looks up the schedule
+// by the chain ID, so a private net or a testnet can activate the features much
+// earlier than the mainnet without patching the binary.
+type ChainParams struct {
+	ChainID string `json:"chain_id"`
+
+	HeightEnableDneroV1                    uint64 `json:"height_enable_dnero_v1"`
+	HeightEnableValidatorReward            uint64 `json:"height_enable_validator_reward"`
+	HeightEnableSmartContract              uint64 `json:"height_enable_smart_contract"`
+	HeightRPCCompatibility                 uint64 `json:"height_rpc_compatibility"`
+	HeightNewFeeAdjustment                 uint64 `json:"height_new_fee_adjustment"`
+	HeightSampleStakingReward              uint64 `json:"height_sample_staking_reward"`
+	HeightEnableDneroV2                    uint64 `json:"height_enable_dnero_v2"`
+	HeightTxWrapperExtension               uint64 `json:"height_tx_wrapper_extension"`
+	HeightSupportDneroTokenInSmartContract uint64 `json:"height_support_dnero_token_in_smart_contract"`
+	HeightSupportWrappedDnero              uint64 `json:"height_support_wrapped_dnero"`
+	HeightEnableEquivocationSlashing       uint64 `json:"height_enable_equivocation_slashing"`
+}
+
+// DefaultChainParams returns the mainnet fork schedule (as defined in heights.go) for the given chain.
+func DefaultChainParams(chainID string) *ChainParams {
+	return &ChainParams{
+		ChainID:                                chainID,
+		HeightEnableDneroV1:                    HeightEnableDneroV1,
+		HeightEnableValidatorReward:            HeightEnableValidatorReward,
+		HeightEnableSmartContract:              HeightEnableSmartContract,
+		HeightRPCCompatibility:                 HeightRPCCompatibility,
+		HeightNewFeeAdjustment:                 HeightNewFeeAdjustment,
+		HeightSampleStakingReward:              HeightSampleStakingReward,
+		HeightEnableDneroV2:                    HeightEnableDneroV2,
+		HeightTxWrapperExtension:               HeightTxWrapperExtension,
+		HeightSupportDneroTokenInSmartContract: HeightSupportDneroTokenInSmartContract,
+		HeightSupportWrappedDnero:              HeightSupportWrappedDnero,
+		HeightEnableEquivocationSlashing:       HeightEnableEquivocationSlashing,
+	}
+}
+
+// DevnetChainParams returns a fork schedule where all the features are live from block #1.
+func DevnetChainParams(chainID string) *ChainParams {
+	return &ChainParams{
+		ChainID:                                chainID,
+		HeightEnableDneroV1:                    1,
+		HeightEnableValidatorReward:            1,
+		HeightEnableSmartContract:              1,
+		HeightRPCCompatibility:                 1,
+		HeightNewFeeAdjustment:                 1,
+		HeightSampleStakingReward:              1,
+		HeightEnableDneroV2:                    1,
+		HeightTxWrapperExtension:               1,
+		HeightSupportDneroTokenInSmartContract: 1,
+		HeightSupportWrappedDnero:              1,
+		HeightEnableEquivocationSlashing:       1,
+	}
+}
+
+// Validate checks whether the fork schedule is well-formed.
+func (p *ChainParams) Validate() error {
+	if p.ChainID == "" {
+		return errors.New("Chain ID is not specified")
+	}
+	if p.HeightEnableDneroV1 == 0 || p.HeightEnableDneroV2 == 0 {
+		return errors.New("DneroV1 and DneroV2 cannot be enabled at the genesis block")
+	}
+	if p.HeightEnableDneroV1 > p.HeightEnableDneroV2 {
+		return fmt.Errorf("DneroV2 (height %v) cannot be enabled before DneroV1 (height %v)",
+			p.HeightEnableDneroV2, p.HeightEnableDneroV1)
+	}
+	if p.HeightSupportDneroTokenInSmartContract > p.HeightSupportWrappedDnero {
+		return fmt.Errorf("Wrapped Dnero (height %v) cannot be supported before Dnero in smart contracts (height %v)",
+			p.HeightSupportWrappedDnero, p.HeightSupportDneroTokenInSmartContract)
+	}
+	return nil
+}
+
+// LoadChainParams reads the fork schedule from the given JSON file.
+func LoadChainParams(filePath string) (*ChainParams, error) {
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	params := &ChainParams{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, fmt.Errorf("Failed to parse chain params %v: %v", filePath, err)
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// WriteChainParams writes the fork schedule to the given JSON file.
+func WriteChainParams(filePath string, params *ChainParams) error {
+	raw, err := json.MarshalIndent(params, "", "    ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filePath, raw, 0600)
+}
+
+var (
+	chainParamsLock sync.RWMutex
+	chainParamsMap  = make(map[string]*ChainParams)
+)
+
+// RegisterChainParams registers the fork schedule for the chain specified by params.ChainID.
+// It should be called before the node starts processing blocks of that chain.
+func RegisterChainParams(params *ChainParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	chainParamsLock.Lock()
+	defer chainParamsLock.Unlock()
+	chainParamsMap[params.ChainID] = params
+	return nil
+}
+
+// GetChainParams returns the fork schedule of the given chain. Chains without a
+// registered schedule (e.g. the mainnet) follow the default schedule. The default
+// schedule is not stored, so looking up arbitrary chain IDs (e.g. from the peers)
+// does not grow the registry.
+func GetChainParams(chainID string) *ChainParams {
+	chainParamsLock.RLock()
+	defer chainParamsLock.RUnlock()
+	if params, ok := chainParamsMap[chainID]; ok {
+		return params
+	}
+	return DefaultChainParams(chainID)
+}
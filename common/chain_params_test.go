@@ -0,0 +1,79 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetChainParams(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mainnet := GetChainParams("mainnet")
+	assert.Equal("mainnet", mainnet.ChainID)
+	assert.Equal(HeightEnableDneroV2, mainnet.HeightEnableDneroV2)
+	assert.Equal(HeightSupportWrappedDnero, mainnet.HeightSupportWrappedDnero)
+
+	require.Nil(RegisterChainParams(DevnetChainParams("test_devnet")))
+	devnet := GetChainParams("test_devnet")
+	assert.Equal(uint64(1), devnet.HeightEnableSmartContract)
+	assert.Equal(uint64(1), devnet.HeightSupportWrappedDnero)
+
+	// looking up an unknown chain does not register it
+	assert.Equal(HeightEnableSmartContract, GetChainParams("test_custom").HeightEnableSmartContract)
+	chainParamsLock.RLock()
+	_, ok := chainParamsMap["test_custom"]
+	chainParamsLock.RUnlock()
+	assert.False(ok)
+
+	// registering overrides the default schedule
+	custom := DefaultChainParams("test_custom")
+	custom.HeightEnableSmartContract = 10
+	require.Nil(RegisterChainParams(custom))
+	assert.Equal(uint64(10), GetChainParams("test_custom").HeightEnableSmartContract)
+	assert.Equal(HeightEnableSmartContract, GetChainParams("mainnet").HeightEnableSmartContract)
+}
+
+func TestChainParamsValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(DevnetChainParams("test_devnet").Validate())
+	assert.NotNil(DevnetChainParams("").Validate())
+
+	params := DevnetChainParams("test_devnet")
+	params.HeightEnableDneroV1 = 0
+	assert.NotNil(params.Validate())
+
+	params = DevnetChainParams("test_devnet")
+	params.HeightEnableDneroV1 = 100
+	assert.NotNil(params.Validate())
+
+	params = DevnetChainParams("test_devnet")
+	params.HeightSupportDneroTokenInSmartContract = 100
+	assert.NotNil(params.Validate())
+}
+
+func TestLoadChainParams(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "chain_params")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	filePath := path.Join(dir, "chain_params.json")
+	require.Nil(WriteChainParams(filePath, DevnetChainParams("test_devnet")))
+
+	params, err := LoadChainParams(filePath)
+	require.Nil(err)
+	assert.Equal(DevnetChainParams("test_devnet"), params)
+
+	require.Nil(ioutil.WriteFile(filePath, []byte(`{"chain_id": "test_devnet"}`), 0600))
+	_, err = LoadChainParams(filePath)
+	assert.NotNil(err)
+}
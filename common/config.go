@@ -1,6 +1,8 @@
 package common
 
 import (
+	"path"
+
 	"github.com/spf13/viper"
 )
 
@@ -23,6 +25,9 @@ const (
 	CfgGenesisHash = "genesis.hash"
 	// CfgGenesisChainID defines the chainID.
 	CfgGenesisChainID = "genesis.chainID"
+	// CfgGenesisChainParams defines the path of the JSON file specifying the fork schedule of the chain.
+	// It is used only for a chain without a fork schedule stored in the snapshot.
+	CfgGenesisChainParams = "genesis.chainParams"
 
 	// CfgConsensusMaxEpochLength defines the maxium length of an epoch.
 	CfgConsensusMaxEpochLength = "consensus.maxEpochLength"
@@ -51,6 +56,19 @@ const (
 	CfgStorageLevelDBHandles = "storage.levelDBHandles"
 	// CfgStorageRollingInterval is the block interval that we start new db layer
 	CfgStorageRollingInterval = "storage.rollingInterval"
+	// CfgStorageArchiveMode indicates whether to retain the full state of every finalized block, which disables
+	// the state pruning and the DB rolling
+	CfgStorageArchiveMode = "storage.archiveMode"
+	// CfgStorageIndexAccountTxs indicates whether to index the finalized transactions by the accounts involved
+	CfgStorageIndexAccountTxs = "storage.indexAccountTransactions"
+
+	// CfgMempoolMaxNumTxs defines the maximum number of transactions the mempool can hold.
+	CfgMempoolMaxNumTxs = "mempool.maxNumTxs"
+	// CfgMempoolMaxNumTxsPerAccount defines the maximum number of pending transactions per account.
+	CfgMempoolMaxNumTxsPerAccount = "mempool.maxNumTxsPerAccount"
+	// CfgMempoolReplaceTxMinGasPriceBump defines the minimal gas price bump (in percent) required to replace
+	// a pending transaction with the same sequence.
+	CfgMempoolReplaceTxMinGasPriceBump = "mempool.replaceTxMinGasPriceBump"
 
 	// CfgSyncMessageQueueSize defines the capacity of Sync Manager message queue.
 	CfgSyncMessageQueueSize = "sync.messageQueueSize"
@@ -58,6 +76,11 @@ const (
 	CfgSyncDownloadByHash = "sync.downloadByHash"
 	// CfgSyncDownloadByHeader indicates whether should download blocks using header.
 	CfgSyncDownloadByHeader = "sync.downloadByHeader"
+	// CfgSyncStateSyncEnabled indicates whether a new node should sync the state from the peers instead of importing the snapshot file.
+	CfgSyncStateSyncEnabled = "sync.stateSyncEnabled"
+	// CfgSyncCompactBlockEnabled indicates whether to download the new blocks from the supporting peers as
+	// compact blocks, which are reconstructed from the transactions in the mempool.
+	CfgSyncCompactBlockEnabled = "sync.compactBlockEnabled"
 
 	// CfgP2POpt sets which P2P network to use: p2p, libp2p, or both.
 	CfgP2POpt = "p2p.opt"
@@ -107,6 +130,22 @@ const (
 	CfgP2PNatMapping = "p2p.natMapping"
 	// CfgP2PMaxConnections specifies the number of max connections a node can accept
 	CfgP2PMaxConnections = "p2p.maxConnections"
+	// CfgP2PBanThreshold specifies the misbehavior score at which a peer is banned, zero disables the automatic banning
+	CfgP2PBanThreshold = "p2p.banThreshold"
+	// CfgP2PBanDuration specifies how long (in seconds) a peer stays banned
+	CfgP2PBanDuration = "p2p.banDuration"
+	// CfgP2PScoreHalfLife specifies the time (in seconds) for the misbehavior score of a peer to decay by half
+	CfgP2PScoreHalfLife = "p2p.scoreHalfLife"
+	// CfgP2PSendRate specifies the max number of bytes per second sent to a peer, zero for no limit
+	CfgP2PSendRate = "p2p.sendRate"
+	// CfgP2PChannelPriorities specifies the send priority of each channel by name, e.g. p2p.channelPriorities.vote.
+	// A channel with priority N sends up to N packets in each round over the channels with pending messages.
+	CfgP2PChannelPriorities = "p2p.channelPriorities"
+	// CfgP2PChannelSendRates specifies the max number of bytes per second sent to a peer on each channel by name, zero for no limit
+	CfgP2PChannelSendRates = "p2p.channelSendRates"
+	// CfgP2PChannelRecvRates specifies the max number of bytes per second received from a peer on each channel by name,
+	// zero for no limit. The messages received beyond the limit are dropped.
+	CfgP2PChannelRecvRates = "p2p.channelRecvRates"
 
 	// CfgSyncInboundResponseWhitelist filters inbound messages based on peer ID.
 	CfgSyncInboundResponseWhitelist = "sync.inboundResponseWhitelist"
@@ -121,6 +160,28 @@ const (
 	CfgRPCMaxConnections = "rpc.maxConnections"
 	// CfgRPCTimeoutSecs set a timeout for RPC.
 	CfgRPCTimeoutSecs = "rpc.timeoutSecs"
+	// CfgRPCAdminMethods lists the methods in the "admin" namespace, separated by comma. The other methods are in the "public" namespace.
+	CfgRPCAdminMethods = "rpc.adminMethods"
+	// CfgRPCAnonymousNamespaces lists the namespaces accessible without an API key, separated by comma.
+	// Only the "public" namespace is open by default, add "admin" to open the admin methods to anyone.
+	CfgRPCAnonymousNamespaces = "rpc.anonymousNamespaces"
+	// CfgRPCAPIKeys lists the API keys and the namespaces they grant, as "<key>:<namespace>|<namespace>" separated by comma.
+	CfgRPCAPIKeys = "rpc.apiKeys"
+	// CfgRPCRateLimitPerIP limits the calls per second from each IP address, zero means unlimited.
+	CfgRPCRateLimitPerIP = "rpc.rateLimit.perIP"
+	// CfgRPCRateLimitPerKey limits the calls per second made with each API key, zero means unlimited.
+	CfgRPCRateLimitPerKey = "rpc.rateLimit.perKey"
+	// CfgRPCRateLimitBurst sets the maximum number of calls allowed in a burst by the rate limits.
+	CfgRPCRateLimitBurst = "rpc.rateLimit.burst"
+	// CfgRPCMaxRequestBytes limits the size of an HTTP request body or a WebSocket message.
+	CfgRPCMaxRequestBytes = "rpc.maxRequestBytes"
+	// CfgRPCAuditLogPath sets the file the admin calls are recorded to. The calls are logged by the RPC logger if empty.
+	CfgRPCAuditLogPath = "rpc.auditLogPath"
+
+	// CfgLightClientEnabled sets whether to run the node in the header-only light client mode.
+	CfgLightClientEnabled = "lightClient.enabled"
+	// CfgLightClientRPCEndpoints lists the RPC endpoints of the full nodes to fetch the proofs from, separated by comma.
+	CfgLightClientRPCEndpoints = "lightClient.rpcEndpoints"
 
 	// CfgLogLevels sets the log level.
 	CfgLogLevels = "log.levels"
@@ -133,6 +194,12 @@ const (
 
 	// Graphite Server to collet metrics
 	CfgMetricsServer = "metrics.server"
+	// CfgMetricsEnabled sets whether to collect the metrics and serve them in the Prometheus format.
+	CfgMetricsEnabled = "metrics.enabled"
+	// CfgMetricsAddress sets the binding address of the Prometheus metrics endpoint.
+	CfgMetricsAddress = "metrics.address"
+	// CfgMetricsPort sets the port of the Prometheus metrics endpoint.
+	CfgMetricsPort = "metrics.port"
 
 	// CfgProfEnabled to enable profiling
 	CfgProfEnabled = "prof.enabled"
@@ -166,9 +233,15 @@ func init() {
 	viper.SetDefault(CfgConsensusEdgeNodeVoteQueueSize, 100000)
 	viper.SetDefault(CfgConsensusPassThroughSentryVote, false)
 
+	viper.SetDefault(CfgMempoolMaxNumTxs, 25600)
+	viper.SetDefault(CfgMempoolMaxNumTxsPerAccount, 256)
+	viper.SetDefault(CfgMempoolReplaceTxMinGasPriceBump, 10)
+
 	viper.SetDefault(CfgSyncMessageQueueSize, 512)
 	viper.SetDefault(CfgSyncDownloadByHash, false)
 	viper.SetDefault(CfgSyncDownloadByHeader, true)
+	viper.SetDefault(CfgSyncStateSyncEnabled, false)
+	viper.SetDefault(CfgSyncCompactBlockEnabled, true)
 
 	viper.SetDefault(CfgStorageRollingEnabled, true)
 	viper.SetDefault(CfgStorageStatePruningEnabled, true)
@@ -178,6 +251,8 @@ func init() {
 	viper.SetDefault(CfgStorageLevelDBCacheSize, 256)
 	viper.SetDefault(CfgStorageLevelDBHandles, 16)
 	viper.SetDefault(CfgStorageRollingInterval, 14400) // approximately 1 days by default
+	viper.SetDefault(CfgStorageArchiveMode, false)
+	viper.SetDefault(CfgStorageIndexAccountTxs, false)
 
 	viper.SetDefault(CfgRPCEnabled, false)
 	viper.SetDefault(CfgP2PMessageQueueSize, 512)
@@ -200,11 +275,44 @@ func init() {
 	viper.SetDefault(CfgP2PConnectionFIFO, false)
 	viper.SetDefault(CfgP2PNatMapping, false)
 	viper.SetDefault(CfgP2PMaxConnections, 2048)
+	viper.SetDefault(CfgP2PBanThreshold, 100)
+	viper.SetDefault(CfgP2PBanDuration, 86400) // 24 hours
+	viper.SetDefault(CfgP2PScoreHalfLife, 600) // 10 minutes
+	viper.SetDefault(CfgP2PSendRate, 0)        // no limit
+	viper.SetDefault(CfgP2PChannelPriorities, map[string]interface{}{
+		"proposal":             10,
+		"vote":                 10,
+		"cc":                   10,
+		"een_vote":             10,
+		"aggregated_een_votes": 10,
+		"checkpoint":           5,
+		"header":               5,
+		"block":                5,
+		"compact_block":        5,
+		"block_txs":            5,
+		"evidence":             5,
+		"state_sync":           2,
+		"peer_discovery":       2,
+		"sentry":               2,
+		"nat_mapping":          2,
+		"transaction":          1,
+	})
 
 	viper.SetDefault(CfgRPCAddress, "0.0.0.0")
 	viper.SetDefault(CfgRPCPort, "15511")
 	viper.SetDefault(CfgRPCMaxConnections, 200)
 	viper.SetDefault(CfgRPCTimeoutSecs, 60)
+	viper.SetDefault(CfgRPCAdminMethods, "BackupSnapshot,BackupChain,BackupChainCorrection,ListBannedPeers,BanPeer,UnbanPeer")
+	viper.SetDefault(CfgRPCAnonymousNamespaces, "public")
+	viper.SetDefault(CfgRPCAPIKeys, "")
+	viper.SetDefault(CfgRPCRateLimitPerIP, 0)
+	viper.SetDefault(CfgRPCRateLimitPerKey, 0)
+	viper.SetDefault(CfgRPCRateLimitBurst, 100)
+	viper.SetDefault(CfgRPCMaxRequestBytes, 5*1024*1024)
+	viper.SetDefault(CfgRPCAuditLogPath, "")
+
+	viper.SetDefault(CfgLightClientEnabled, false)
+	viper.SetDefault(CfgLightClientRPCEndpoints, "")
 
 	viper.SetDefault(CfgLogLevels, "*:debug")
 	viper.SetDefault(CfgLogPrintSelfID, false)
@@ -213,6 +321,9 @@ func init() {
 
 	//TODO: Setup Dnero own Metrics Server
 	//viper.SetDefault(CfgMetricsServer, "sentry-metrics.dnerochain.xyz")
+	viper.SetDefault(CfgMetricsEnabled, false)
+	viper.SetDefault(CfgMetricsAddress, "127.0.0.1")
+	viper.SetDefault(CfgMetricsPort, "15599")
 
 	viper.SetDefault(CfgProfEnabled, false)
 	viper.SetDefault(CfgForceGCEnabled, true)
@@ -222,3 +333,20 @@ func init() {
 func WriteInitialConfig(filePath string) error {
 	return WriteFileAtomic(filePath, []byte(InitialConfig), 0600)
 }
+
+// InitialDevnetChainParamsFile is the fork schedule file produced by init command for a devnet.
+const InitialDevnetChainParamsFile = "chain_params.json"
+
+// InitialDevnetConfig is the devnet configuration produced by init command.
+const InitialDevnetConfig = InitialConfig + `genesis:
+  chainParams: ` + InitialDevnetChainParamsFile + `
+`
+
+// WriteInitialDevnetConfig writes initial devnet config file and the fork schedule, where all
+// the features are live from block #1, to the given config folder.
+func WriteInitialDevnetConfig(cfgPath string, chainID string) error {
+	if err := WriteChainParams(path.Join(cfgPath, InitialDevnetChainParamsFile), DevnetChainParams(chainID)); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path.Join(cfgPath, "config.yaml"), []byte(InitialDevnetConfig), 0600)
+}
@@ -1,5 +1,7 @@
 package common
 
+// The activation heights below are the mainnet fork schedule. They also serve as the
+// default schedule for chains without registered ChainParams (see chain_params.go).
 
 // HeightEnableDneroV1 specifies the minimal block height to enable the DneroV1.0 feature.
 const HeightEnableDneroV1 uint64 = 1001 // block #1001
@@ -41,6 +43,10 @@ const HeightSupportDneroTokenInSmartContract uint64 = 120001 // block #120001
 // HeightSupportWrappedDnero specifies the block height to support wrapped Dnero
 const HeightSupportWrappedDnero uint64 = 150001 // block #150001
 
+// HeightEnableEquivocationSlashing specifies the minimal block height to slash the validators that sign conflicting
+// votes or proposals. Not scheduled on the mainnet yet.
+const HeightEnableEquivocationSlashing uint64 = ^uint64(0) // max uint64
+
 // CheckpointInterval defines the interval between checkpoints.
 const CheckpointInterval = int64(100)
 
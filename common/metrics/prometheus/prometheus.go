@@ -0,0 +1,147 @@
+// Hook go-metrics into Prometheus
+// on any /metrics request, write all the metrics in the registry in the Prometheus text exposition format
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dnerochain/dnero/common/metrics"
+)
+
+// Namespace is prepended to the names of all the exported metrics
+const Namespace = "dnero"
+
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// Handler returns a handler that exports the metrics in the given registry to Prometheus.
+// The durations measured by the timers are exported in seconds.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(Export(reg))
+	})
+}
+
+// Export writes the metrics in the given registry in the Prometheus text exposition format,
+// sorted by name.
+func Export(reg metrics.Registry) []byte {
+	metricMap := make(map[string]interface{})
+	reg.Each(func(name string, i interface{}) {
+		metricMap[name] = i
+	})
+	names := make([]string, 0, len(metricMap))
+	for name := range metricMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := newCollector()
+	for _, name := range names {
+		switch m := metricMap[name].(type) {
+		case metrics.Counter:
+			c.addCounter(name, m.Snapshot())
+		case metrics.Gauge:
+			c.addGauge(name, m.Snapshot())
+		case metrics.GaugeFloat64:
+			c.addGaugeFloat64(name, m.Snapshot())
+		case metrics.Histogram:
+			c.addHistogram(name, m.Snapshot())
+		case metrics.Meter:
+			c.addMeter(name, m.Snapshot())
+		case metrics.Timer:
+			c.addTimer(name, m.Snapshot())
+		case metrics.ResettingTimer:
+			c.addResettingTimer(name, m.Snapshot())
+		}
+	}
+	return c.buff.Bytes()
+}
+
+// collector formats the metrics in the Prometheus text exposition format
+type collector struct {
+	buff *bytes.Buffer
+}
+
+func newCollector() *collector {
+	return &collector{
+		buff: &bytes.Buffer{},
+	}
+}
+
+func (c *collector) addCounter(name string, m metrics.Counter) {
+	c.writeSample(mangleName(name)+"_total", "counter", float64(m.Count()))
+}
+
+func (c *collector) addGauge(name string, m metrics.Gauge) {
+	c.writeSample(mangleName(name), "gauge", float64(m.Value()))
+}
+
+func (c *collector) addGaugeFloat64(name string, m metrics.GaugeFloat64) {
+	c.writeSample(mangleName(name), "gauge", m.Value())
+}
+
+func (c *collector) addHistogram(name string, m metrics.Histogram) {
+	c.writeSummary(mangleName(name), m.Percentiles(quantiles), float64(m.Sum()), m.Count(), 1)
+}
+
+func (c *collector) addMeter(name string, m metrics.Meter) {
+	c.writeSample(mangleName(name)+"_total", "counter", float64(m.Count()))
+}
+
+func (c *collector) addTimer(name string, m metrics.Timer) {
+	c.writeSummary(mangleName(name)+"_seconds", m.Percentiles(quantiles), float64(m.Sum()), m.Count(), 1e-9)
+}
+
+func (c *collector) addResettingTimer(name string, m metrics.ResettingTimer) {
+	values := m.Values()
+	if len(values) == 0 {
+		return
+	}
+	ps := m.Percentiles(quantiles)
+	psFloat := make([]float64, len(ps))
+	for i, p := range ps {
+		psFloat[i] = float64(p)
+	}
+	sum := float64(0)
+	for _, v := range values {
+		sum += float64(v)
+	}
+	c.writeSummary(mangleName(name)+"_seconds", psFloat, sum, int64(len(values)), 1e-9)
+}
+
+func (c *collector) writeSample(name string, typ string, value float64) {
+	fmt.Fprintf(c.buff, "# TYPE %s %s\n", name, typ)
+	fmt.Fprintf(c.buff, "%s %s\n", name, formatValue(value))
+}
+
+// writeSummary writes the quantiles, sum and count of the samples. The sample values are multiplied
+// by the given scale, e.g. to convert the nanoseconds to seconds.
+func (c *collector) writeSummary(name string, ps []float64, sum float64, count int64, scale float64) {
+	fmt.Fprintf(c.buff, "# TYPE %s summary\n", name)
+	for i, q := range quantiles {
+		fmt.Fprintf(c.buff, "%s{quantile=\"%s\"} %s\n", name, formatValue(q), formatValue(ps[i]*scale))
+	}
+	fmt.Fprintf(c.buff, "%s_sum %s\n", name, formatValue(sum*scale))
+	fmt.Fprintf(c.buff, "%s_count %d\n", name, count)
+}
+
+// mangleName converts the metric name, e.g. "consensus/finalized/height", into a valid
+// Prometheus metric name, e.g. "dnero_consensus_finalized_height"
+func mangleName(name string) string {
+	mangled := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	return Namespace + "_" + mangled
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
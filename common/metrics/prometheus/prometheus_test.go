@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dnerochain/dnero/common/metrics"
+)
+
+func TestExport(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	reg := metrics.NewRegistry()
+	metrics.NewRegisteredGauge("consensus/finalized/height", reg).Update(123)
+	metrics.NewRegisteredCounter("trie/cachemiss", reg).Inc(7)
+	metrics.NewRegisteredMeter("p2p/peer/0xabc/bytes-sent", reg).Mark(2048)
+	timer := metrics.NewRegisteredTimer("consensus/vote/latency", reg)
+	timer.Update(2 * time.Second)
+	timer.Update(4 * time.Second)
+
+	out := string(Export(reg))
+
+	expected := []string{
+		"# TYPE dnero_consensus_finalized_height gauge\ndnero_consensus_finalized_height 123\n",
+		"# TYPE dnero_trie_cachemiss_total counter\ndnero_trie_cachemiss_total 7\n",
+		"# TYPE dnero_p2p_peer_0xabc_bytes_sent_total counter\ndnero_p2p_peer_0xabc_bytes_sent_total 2048\n",
+		"# TYPE dnero_consensus_vote_latency_seconds summary\n",
+		"dnero_consensus_vote_latency_seconds_sum 6\n",
+		"dnero_consensus_vote_latency_seconds_count 2\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Errorf("expected %q in the output:\n%v", e, out)
+		}
+	}
+
+	// Metrics are sorted by name
+	if strings.Index(out, "dnero_consensus_finalized_height") > strings.Index(out, "dnero_trie_cachemiss_total") {
+		t.Errorf("metrics are not sorted:\n%v", out)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	reg := metrics.NewRegistry()
+	metrics.NewRegisteredFunctionalGauge("mempool/size", reg, func() int64 { return 42 })
+
+	rec := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type: %v", ct)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "dnero_mempool_size 42\n") {
+		t.Errorf("unexpected body: %v", body)
+	}
+}
@@ -409,6 +409,18 @@ const (
 
 	// ChannelIDAggregatedEliteEdgeNodeVotes indicates the channel for Elite Edge Node aggregated vote messages
 	ChannelIDAggregatedEliteEdgeNodeVotes
+
+	// ChannelIDStateSync indicates the channel for the state sync checkpoints and trie nodes
+	ChannelIDStateSync
+
+	// ChannelIDEvidence indicates the channel for the evidence of validator equivocation
+	ChannelIDEvidence
+
+	// ChannelIDCompactBlock indicates the channel for the compact blocks, reconstructed from the mempool
+	ChannelIDCompactBlock
+
+	// ChannelIDBlockTxs indicates the channel for the transactions missing from the reconstructed compact blocks
+	ChannelIDBlockTxs
 )
 
 // P2POptEnum defines the p2p network
@@ -20,6 +20,9 @@ import (
 	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/crypto"
 	"github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/eventbus"
+	"github.com/dnerochain/dnero/evidence"
+	"github.com/dnerochain/dnero/p2p/scoring"
 	"github.com/dnerochain/dnero/rlp"
 	"github.com/dnerochain/dnero/store"
 )
@@ -38,12 +41,13 @@ type ConsensusEngine struct {
 	dispatcher       *dispatcher.Dispatcher
 	validatorManager core.ValidatorManager
 	ledger           core.Ledger
+	evidencePool     *evidence.EvidencePool
+	eventBus         *eventbus.EventBus
 	sentry         *SentryEngine
 	eliteEdgeNode    *EliteEdgeNodeEngine
 
-	incoming        chan interface{}
-	finalizedBlocks chan *core.Block
-	hasSynced       bool
+	incoming  chan interface{}
+	hasSynced bool
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -60,6 +64,8 @@ type ConsensusEngine struct {
 	blockProcessed bool
 
 	state *State
+
+	metrics *engineMetrics
 }
 
 // NewConsensusEngine creates a instance of ConsensusEngine.
@@ -70,8 +76,7 @@ func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *bl
 
 		privateKey: privateKey,
 
-		incoming:        make(chan interface{}, viper.GetInt(common.CfgConsensusMessageQueueSize)),
-		finalizedBlocks: make(chan *core.Block, viper.GetInt(common.CfgConsensusMessageQueueSize)),
+		incoming: make(chan interface{}, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 
 		wg: &sync.WaitGroup{},
 
@@ -82,6 +87,8 @@ func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *bl
 
 		voteTimerReady: false,
 		blockProcessed: false,
+
+		metrics: newEngineMetrics(),
 	}
 
 	logger = util.GetLoggerForModule("consensus")
@@ -103,6 +110,16 @@ func (e *ConsensusEngine) SetLedger(ledger core.Ledger) {
 	e.ledger = ledger
 }
 
+// SetEvidencePool sets the pool that collects the evidence of validator equivocation
+func (e *ConsensusEngine) SetEvidencePool(pool *evidence.EvidencePool) {
+	e.evidencePool = pool
+}
+
+// SetEventBus sets the event bus to which the proposed, committed and finalized blocks are published
+func (e *ConsensusEngine) SetEventBus(bus *eventbus.EventBus) {
+	e.eventBus = bus
+}
+
 // GetLedger returns the ledger instance attached to the consensus engine
 func (e *ConsensusEngine) GetLedger() core.Ledger {
 	return e.ledger
@@ -123,6 +140,11 @@ func (e *ConsensusEngine) Chain() *blockchain.Chain {
 	return e.chain
 }
 
+// ChainParams returns the fork schedule of the chain
+func (e *ConsensusEngine) ChainParams() *common.ChainParams {
+	return common.GetChainParams(e.chain.ChainID)
+}
+
 // GetEpoch returns the current epoch
 func (e *ConsensusEngine) GetEpoch() uint64 {
 	return e.state.GetEpoch()
@@ -151,6 +173,7 @@ func (e *ConsensusEngine) Start(ctx context.Context) {
 	lastCC := e.autoRewind(e.state.GetHighestCCBlock())
 	//e.ledger.ResetState(lastCC.Height, lastCC.StateHash)
 	e.ledger.ResetState(lastCC.Block)
+	e.metrics.finalizeBlock(e.state.GetLastFinalizedBlock())
 
 	e.resetSentryTimer()
 	e.sentry.Start(e.ctx)
@@ -320,6 +343,8 @@ func (e *ConsensusEngine) enterEpoch() {
 
 	e.voteTimerReady = false
 	e.blockProcessed = false
+
+	e.metrics.enterEpoch(e.GetEpoch())
 }
 
 // GetChannelIDs implements the p2p.MessageHandler interface.
@@ -508,7 +533,7 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 
 	// Validate Sentry Votes.
 	// We allow checkpoint blocks to have nil sentry votes.
-	if block.SentryVotes != nil && block.Height >= common.HeightEnableDneroV1 && common.IsCheckPointHeight(block.Height) {
+	if block.SentryVotes != nil && block.Height >= e.ChainParams().HeightEnableDneroV1 && common.IsCheckPointHeight(block.Height) {
 		// Voted block must exist.
 		padding := uint64(20)
 		if e.chain.Root().Height+padding*uint64(common.CheckpointInterval) < block.Height {
@@ -579,7 +604,7 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 
 	// Validate Elite Edge Node Votes.
 	// We allow checkpoint blocks to have nil elite edge node votes.
-	if block.EliteEdgeNodeVotes != nil && block.Height >= common.HeightEnableDneroV2 && common.IsCheckPointHeight(block.Height) {
+	if block.EliteEdgeNodeVotes != nil && block.Height >= e.ChainParams().HeightEnableDneroV2 && common.IsCheckPointHeight(block.Height) {
 		// Voted block must exist.
 		padding := uint64(20)
 		if e.chain.Root().Height+padding*uint64(common.CheckpointInterval) < block.Height {
@@ -737,10 +762,15 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 			"block.Hash": block.Hash().Hex(),
 		}).Warn("Block is invalid")
 		e.chain.MarkBlockInvalid(block.Hash())
+		e.reportMessageSource(block.Hash(), scoring.OffenseInvalidBlock)
 		return
 	}
 	validateBlockTime := time.Since(start1)
 
+	if e.evidencePool != nil {
+		e.evidencePool.CheckBlock(block.BlockHeader)
+	}
+
 	for _, vote := range block.HCC.Votes.Votes() {
 		e.handleVote(vote)
 	}
@@ -789,6 +819,8 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 	}
 
 	e.chain.MarkBlockValid(block.Hash())
+	e.metrics.validateBlock(block)
+	e.eventBus.Publish(eventbus.TopicBlockProposed, &eventbus.BlockProposedEvent{Block: eb})
 
 	// Skip voting for block older than current best known epoch.
 	// Allow block with one epoch behind since votes are processed first and might advance epoch
@@ -874,6 +906,7 @@ func (e *ConsensusEngine) vote() {
 		"vote": vote,
 	}).Debug("Sending vote")
 	e.broadcastVote(vote)
+	e.metrics.vote()
 
 	go func() {
 		e.AddMessage(vote)
@@ -909,17 +942,30 @@ func (e *ConsensusEngine) validateVote(vote core.Vote) bool {
 		e.logger.WithFields(log.Fields{
 			"err": res.String(),
 		}).Warn("Ignoring invalid vote")
+		e.reportMessageSource(vote.Hash(), scoring.OffenseInvalidVote)
 		return false
 	}
 	return true
 }
 
+// reportMessageSource penalizes the peer the invalid block or vote is received from
+func (e *ConsensusEngine) reportMessageSource(hash common.Hash, offense scoring.Offense) {
+	if e.dispatcher != nil {
+		e.dispatcher.ReportMessageSource(hash, offense)
+	}
+}
+
 func (e *ConsensusEngine) handleVote(vote core.Vote) (endEpoch bool) {
 	// Validate vote.
 	if !e.validateVote(vote) {
 		return
 	}
 
+	// Check for the conflicting votes before they are deduplicated by the voter.
+	if e.evidencePool != nil {
+		e.evidencePool.CheckVote(vote)
+	}
+
 	// Save vote.
 	err := e.state.AddVote(&vote)
 	if err != nil {
@@ -1097,11 +1143,6 @@ func (e *ConsensusEngine) GetSummary() *StateStub {
 	return e.state.GetSummary()
 }
 
-// FinalizedBlocks returns a channel that will be published with finalized blocks by the engine.
-func (e *ConsensusEngine) FinalizedBlocks() chan *core.Block {
-	return e.finalizedBlocks
-}
-
 // GetLastFinalizedBlock returns the last finalized block.
 func (e *ConsensusEngine) GetLastFinalizedBlock() *core.ExtendedBlock {
 	return e.state.GetLastFinalizedBlock()
@@ -1129,6 +1170,8 @@ func (e *ConsensusEngine) processCCBlock(ccBlock *core.ExtendedBlock) {
 	e.logger.WithFields(log.Fields{"ccBlock.Hash": ccBlock.Hash().Hex(), "c.epoch": e.state.GetEpoch()}).Debug("Updating highestCCBlock")
 	e.state.SetHighestCCBlock(ccBlock)
 	e.chain.CommitBlock(ccBlock.Hash())
+	e.metrics.commitBlock(ccBlock)
+	e.eventBus.Publish(eventbus.TopicBlockCommitted, &eventbus.BlockCommittedEvent{Block: ccBlock})
 }
 
 func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
@@ -1145,6 +1188,7 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 
 	e.state.SetLastFinalizedBlock(block)
 	e.ledger.FinalizeState(block.Height, block.StateHash)
+	e.metrics.finalizeBlock(block)
 
 	e.checkSyncStatus()
 
@@ -1157,6 +1201,9 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 	// duplicate TX in fork.
 	e.chain.AddTxsToIndex(block, true)
 
+	// Index the event logs of the finalized block for log queries.
+	e.chain.AddBlockToBloomIndex(block)
+
 	// Sentrys and Elite Edge Nodes to vote for checkpoint blocks.
 	if common.IsCheckPointHeight(block.Height) {
 		e.sentry.StartNewBlock(block.Hash())
@@ -1164,12 +1211,7 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 		e.resetSentryTimer()
 	}
 
-	select {
-	case e.finalizedBlocks <- block.Block:
-		e.logger.Infof("Notified finalized block, height=%v", block.Height)
-	default:
-		e.logger.Warnf("Failed to notify finalized block, height=%v", block.Height)
-	}
+	e.eventBus.Publish(eventbus.TopicBlockFinalized, &eventbus.BlockFinalizedEvent{Block: block})
 	return nil
 }
 
@@ -1261,12 +1303,12 @@ func (e *ConsensusEngine) createProposal(shouldIncludeValidatorUpdateTxs bool) (
 	block.HCC.Votes = e.chain.FindVotesByHash(block.HCC.BlockHash).UniqueVoter().FilterByValidators(hccValidators)
 
 	// Add sentry votes.
-	if block.Height >= common.HeightEnableDneroV1 && common.IsCheckPointHeight(block.Height) {
+	if block.Height >= e.ChainParams().HeightEnableDneroV1 && common.IsCheckPointHeight(block.Height) {
 		block.SentryVotes = e.sentry.GetBestVote()
 	}
 
 	// Add elite edge node votes.
-	if block.Height >= common.HeightEnableDneroV2 && common.IsCheckPointHeight(block.Height) {
+	if block.Height >= e.ChainParams().HeightEnableDneroV2 && common.IsCheckPointHeight(block.Height) {
 		block.EliteEdgeNodeVotes = e.eliteEdgeNode.GetBestVote()
 	}
 
@@ -0,0 +1,76 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/metrics"
+	"github.com/dnerochain/dnero/core"
+)
+
+// engineMetrics tracks the progress of the consensus engine. All the methods are called from
+// the main loop of the engine.
+type engineMetrics struct {
+	blockHeight        metrics.Gauge // height of the latest validated block
+	finalizedHeight    metrics.Gauge
+	finalizedTimestamp metrics.Gauge // unix time when the latest block was finalized, for detecting stalls
+	epoch              metrics.Gauge
+
+	voteLatency metrics.Timer // from entering an epoch to casting the vote
+	ccLatency   metrics.Timer // from validating a block to receiving its commit certificate
+
+	epochStartTime  time.Time
+	validatedBlocks map[common.Hash]validatedBlock
+}
+
+type validatedBlock struct {
+	height    uint64
+	timestamp time.Time
+}
+
+func newEngineMetrics() *engineMetrics {
+	return &engineMetrics{
+		blockHeight:        metrics.GetOrRegisterGauge("consensus/block/height", nil),
+		finalizedHeight:    metrics.GetOrRegisterGauge("consensus/finalized/height", nil),
+		finalizedTimestamp: metrics.GetOrRegisterGauge("consensus/finalized/timestamp", nil),
+		epoch:              metrics.GetOrRegisterGauge("consensus/epoch", nil),
+		voteLatency:        metrics.GetOrRegisterTimer("consensus/vote/latency", nil),
+		ccLatency:          metrics.GetOrRegisterTimer("consensus/cc/latency", nil),
+		epochStartTime:     time.Now(),
+		validatedBlocks:    make(map[common.Hash]validatedBlock),
+	}
+}
+
+func (m *engineMetrics) enterEpoch(epoch uint64) {
+	m.epoch.Update(int64(epoch))
+	m.epochStartTime = time.Now()
+}
+
+func (m *engineMetrics) vote() {
+	m.voteLatency.UpdateSince(m.epochStartTime)
+}
+
+func (m *engineMetrics) validateBlock(block *core.Block) {
+	if int64(block.Height) > m.blockHeight.Value() {
+		m.blockHeight.Update(int64(block.Height))
+	}
+	m.validatedBlocks[block.Hash()] = validatedBlock{height: block.Height, timestamp: time.Now()}
+}
+
+func (m *engineMetrics) commitBlock(block *core.ExtendedBlock) {
+	if validated, ok := m.validatedBlocks[block.Hash()]; ok {
+		m.ccLatency.UpdateSince(validated.timestamp)
+	}
+
+	// Blocks below the highest CC block will not be committed anymore
+	for hash, validated := range m.validatedBlocks {
+		if validated.height <= block.Height {
+			delete(m.validatedBlocks, hash)
+		}
+	}
+}
+
+func (m *engineMetrics) finalizeBlock(block *core.ExtendedBlock) {
+	m.finalizedHeight.Update(int64(block.Height))
+	m.finalizedTimestamp.Update(time.Now().Unix())
+}
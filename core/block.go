@@ -146,7 +146,8 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 	if h == nil {
 		return rlp.Encode(w, &BlockHeader{})
 	}
-	if h.Height < common.HeightEnableDneroV1 {
+	chainParams := common.GetChainParams(h.ChainID)
+	if h.Height < chainParams.HeightEnableDneroV1 {
 		return rlp.Encode(w, []interface{}{
 			h.ChainID,
 			h.Epoch,
@@ -164,7 +165,7 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 	}
 
 	// DneroV1.0 fork
-	if h.Height >= common.HeightEnableDneroV1 && h.Height < common.HeightEnableDneroV2 {
+	if h.Height >= chainParams.HeightEnableDneroV1 && h.Height < chainParams.HeightEnableDneroV2 {
 		return rlp.Encode(w, []interface{}{
 			h.ChainID,
 			h.Epoch,
@@ -270,8 +271,10 @@ func (h *BlockHeader) DecodeRLP(stream *rlp.Stream) error {
 		return err
 	}
 
+	chainParams := common.GetChainParams(h.ChainID)
+
 	// DneroV1.0 fork
-	if h.Height >= common.HeightEnableDneroV1 {
+	if h.Height >= chainParams.HeightEnableDneroV1 {
 		raw, err := stream.Raw()
 		if err != nil {
 			return err
@@ -290,7 +293,7 @@ func (h *BlockHeader) DecodeRLP(stream *rlp.Stream) error {
 	}
 
 	// DneroV2.0 fork
-	if h.Height >= common.HeightEnableDneroV2 {
+	if h.Height >= chainParams.HeightEnableDneroV2 {
 		raw, err := stream.Raw()
 		if err != nil {
 			return err
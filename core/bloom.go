@@ -63,6 +63,21 @@ func (b *Bloom) Add(d *big.Int) {
 	b.SetBytes(bin.Bytes())
 }
 
+// AddBytes adds d to the filter. Unlike Add, leading zero bytes of d are hashed as
+// well, so BloomLookup(b, d) returns true for any bytesBacked d added this way.
+func (b *Bloom) AddBytes(d []byte) {
+	bin := new(big.Int).SetBytes(b[:])
+	bin.Or(bin, bloom9(d))
+	b.SetBytes(bin.Bytes())
+}
+
+// Or merges the other filter into b.
+func (b *Bloom) Or(other Bloom) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
 // Big converts b to a big integer.
 func (b Bloom) Big() *big.Int {
 	return new(big.Int).SetBytes(b[:])
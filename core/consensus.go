@@ -13,7 +13,6 @@ type ConsensusEngine interface {
 	GetEpoch() uint64
 	GetLedger() Ledger
 	AddMessage(msg interface{})
-	FinalizedBlocks() chan *Block
 	GetLastFinalizedBlock() *ExtendedBlock
 }
 
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/result"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/rlp"
+)
+
+const (
+	// MaxEquivocationEvidenceAge is the max number of blocks after the conflicting messages that the
+	// evidence can still be included in a block. It equals the stake locking period, so the stake of
+	// the offender cannot be returned before it is slashed.
+	MaxEquivocationEvidenceAge uint64 = ReturnLockingPeriod
+
+	// MaxNumEquivocationEvidencePerBlock is the max number of evidence that can be included in one block
+	MaxNumEquivocationEvidencePerBlock int = 16
+
+	// EquivocationSlashPercentage is the percentage of the offender's stake that is slashed
+	EquivocationSlashPercentage uint64 = 10
+)
+
+//
+// ------- EquivocationEvidence ------- //
+//
+
+// EquivocationEvidence proves that a validator signed two conflicting messages. It is either a pair
+// of votes for two different blocks at the same height, or a pair of blocks proposed in the same epoch.
+// A voting height is monotonically increasing and a proposal is repeated within an epoch, so an honest
+// validator never signs conflicting messages.
+type EquivocationEvidence struct {
+	// The conflicting blocks. For the vote equivocation, the headers prove the heights of the voted blocks.
+	HeaderA *BlockHeader
+	HeaderB *BlockHeader
+
+	// The votes for HeaderA and HeaderB. Empty for the proposal equivocation.
+	Votes []Vote
+}
+
+// NewVoteEquivocationEvidence creates the evidence of two conflicting votes, given the voted headers.
+func NewVoteEquivocationEvidence(voteA Vote, headerA *BlockHeader, voteB Vote, headerB *BlockHeader) *EquivocationEvidence {
+	if bytes.Compare(voteA.Block[:], voteB.Block[:]) > 0 {
+		voteA, voteB = voteB, voteA
+		headerA, headerB = headerB, headerA
+	}
+	return &EquivocationEvidence{
+		HeaderA: headerA,
+		HeaderB: headerB,
+		Votes:   []Vote{voteA, voteB},
+	}
+}
+
+// NewProposalEquivocationEvidence creates the evidence of two conflicting proposals.
+func NewProposalEquivocationEvidence(headerA *BlockHeader, headerB *BlockHeader) *EquivocationEvidence {
+	hashA, hashB := headerA.Hash(), headerB.Hash()
+	if bytes.Compare(hashA[:], hashB[:]) > 0 {
+		headerA, headerB = headerB, headerA
+	}
+	return &EquivocationEvidence{
+		HeaderA: headerA,
+		HeaderB: headerB,
+		Votes:   []Vote{},
+	}
+}
+
+// IsVoteEquivocation returns whether the evidence is about votes rather than proposals.
+func (ev *EquivocationEvidence) IsVoteEquivocation() bool {
+	return len(ev.Votes) > 0
+}
+
+// Offender returns the address of the validator that signed the conflicting messages.
+func (ev *EquivocationEvidence) Offender() common.Address {
+	if ev.IsVoteEquivocation() {
+		return ev.Votes[0].ID
+	}
+	if ev.HeaderA == nil {
+		return common.Address{}
+	}
+	return ev.HeaderA.Proposer
+}
+
+// Height returns the height of the conflicting blocks.
+func (ev *EquivocationEvidence) Height() uint64 {
+	if ev.HeaderA == nil {
+		return 0
+	}
+	return ev.HeaderA.Height
+}
+
+// ID identifies the offense, i.e. all the evidence of the same validator equivocating at the same
+// height (votes) or in the same epoch (proposals) share the same ID. The offender is slashed once per ID.
+func (ev *EquivocationEvidence) ID() common.Hash {
+	var raw []byte
+	if ev.IsVoteEquivocation() {
+		raw, _ = rlp.EncodeToBytes([]interface{}{"vote", ev.Offender(), ev.Height()})
+	} else {
+		raw, _ = rlp.EncodeToBytes([]interface{}{"proposal", ev.Offender(), ev.HeaderA.Epoch})
+	}
+	return crypto.Keccak256Hash(raw)
+}
+
+// Hash calculates the hash of the evidence.
+func (ev *EquivocationEvidence) Hash() common.Hash {
+	raw, _ := rlp.EncodeToBytes(ev)
+	return crypto.Keccak256Hash(raw)
+}
+
+// Validate checks the evidence proves an equivocation on the given chain.
+func (ev *EquivocationEvidence) Validate(chainID string) result.Result {
+	if ev.HeaderA == nil || ev.HeaderB == nil {
+		return result.Error("Conflicting blocks are not specified")
+	}
+	if ev.HeaderA.ChainID != chainID || ev.HeaderB.ChainID != chainID {
+		return result.Error("ChainID mismatch")
+	}
+	if ev.HeaderA.Hash() == ev.HeaderB.Hash() {
+		return result.Error("Blocks are not conflicting")
+	}
+
+	if !ev.IsVoteEquivocation() {
+		return ev.validateProposals()
+	}
+	return ev.validateVotes()
+}
+
+func (ev *EquivocationEvidence) validateVotes() result.Result {
+	if len(ev.Votes) != 2 {
+		return result.Error("Expecting 2 conflicting votes, but got %v", len(ev.Votes))
+	}
+	voteA, voteB := ev.Votes[0], ev.Votes[1]
+	if voteA.ID != voteB.ID {
+		return result.Error("Votes are signed by different validators")
+	}
+	for _, vote := range ev.Votes {
+		if res := vote.Validate(); res.IsError() {
+			return result.Error("Invalid vote %v: %v", vote, res.Message)
+		}
+	}
+	// The vote height is not signed, the heights are proven by the voted headers instead
+	if voteA.Block != ev.HeaderA.Hash() || voteB.Block != ev.HeaderB.Hash() {
+		return result.Error("Votes do not match the blocks")
+	}
+	if ev.HeaderA.Height != ev.HeaderB.Height {
+		return result.Error("Voted blocks are at different heights: %v, %v", ev.HeaderA.Height, ev.HeaderB.Height)
+	}
+	return result.OK
+}
+
+func (ev *EquivocationEvidence) validateProposals() result.Result {
+	headerA, headerB := ev.HeaderA, ev.HeaderB
+	if headerA.Proposer.IsEmpty() || headerA.Proposer != headerB.Proposer {
+		return result.Error("Blocks are proposed by different validators")
+	}
+	if headerA.Epoch != headerB.Epoch {
+		return result.Error("Blocks are proposed in different epochs: %v, %v", headerA.Epoch, headerB.Epoch)
+	}
+	// A block signed twice is not a conflicting proposal
+	if bytes.Equal(headerA.SignBytes(), headerB.SignBytes()) {
+		return result.Error("Blocks are not conflicting")
+	}
+	for _, header := range []*BlockHeader{headerA, headerB} {
+		if header.Signature == nil || header.Signature.IsEmpty() {
+			return result.Error("Block is not signed")
+		}
+		if !header.Signature.Verify(header.SignBytes(), header.Proposer) {
+			return result.Error("Signature verification failed")
+		}
+	}
+	return result.OK
+}
+
+func (ev *EquivocationEvidence) String() string {
+	if ev.IsVoteEquivocation() {
+		return fmt.Sprintf("EquivocationEvidence{offender: %v, height: %v, votes: %v}",
+			ev.Offender().Hex(), ev.Height(), ev.Votes)
+	}
+	return fmt.Sprintf("EquivocationEvidence{offender: %v, epoch: %v, blocks: [%v, %v]}",
+		ev.Offender().Hex(), ev.HeaderA.Epoch, ev.HeaderA.Hash().Hex(), ev.HeaderB.Hash().Hex())
+}
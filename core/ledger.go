@@ -13,6 +13,7 @@ const (
 	DeliveredView ViewSelector = 1
 	CheckedView   ViewSelector = 2
 	ScreenedView  ViewSelector = 3
+	SimulatedView ViewSelector = 4 // a copied view a tx is dry-run against, which is never committed
 )
 
 //
@@ -24,13 +25,21 @@ type TxInfo struct {
 	Sequence          uint64
 }
 
+//
+// TxPrecheck is called with the information of a transaction that passed the sanity check, right before
+// the transaction is applied to the screened view. The transaction is rejected if it returns an error.
+//
+type TxPrecheck func(txInfo *TxInfo) error
+
 //
 // Ledger defines the interface of the ledger
 //
 type Ledger interface {
 	GetCurrentBlock() *Block
 	ScreenTxUnsafe(rawTx common.Bytes) result.Result
-	ScreenTx(rawTx common.Bytes) (priority *TxInfo, res result.Result)
+	ScreenTx(rawTx common.Bytes, precheck TxPrecheck) (priority *TxInfo, res result.Result)
+	ScreenReplacementTx(rawTx common.Bytes) (priority *TxInfo, res result.Result)
+	ResetScreenedState()
 	ProposeBlockTxs(block *Block, shouldIncludeValidatorUpdateTxs bool) (stateRootHash common.Hash, blockRawTxs []common.Bytes, res result.Result)
 	ApplyBlockTxs(block *Block) result.Result
 	ApplyBlockTxsForChainCorrection(block *Block) (common.Hash, result.Result)
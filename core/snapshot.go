@@ -60,6 +60,13 @@ type LastCheckpoint struct {
 	IntermediateHeaders []*BlockHeader
 }
 
+// StateSyncCheckpoint carries the same metadata as a snapshot file, without the state. It is served
+// to the peers that sync the state tries of the checkpoint over the network.
+type StateSyncCheckpoint struct {
+	LastCheckpoint LastCheckpoint
+	Metadata       SnapshotMetadata
+}
+
 func WriteSnapshotHeader(writer *bufio.Writer, snapshotHeader *SnapshotHeader) error {
 	raw, err := rlp.EncodeToBytes(*snapshotHeader)
 	if err != nil {
@@ -287,6 +287,33 @@ func (vcp *ValidatorCandidatePool) ReturnStakes(currentHeight uint64) []*Stake {
 	return returnedStakes
 }
 
+// SlashStake burns the given percentage of all the stakes deposited to the holder, including the stakes
+// withdrawn but not returned yet. The remaining stakes are withdrawn, so the holder leaves the validator
+// set and the stakes are returned after the locking period. It returns the slashed amount.
+func (vcp *ValidatorCandidatePool) SlashStake(holder common.Address, slashPercentage uint64, currentHeight uint64) (*big.Int, error) {
+	candidate := vcp.FindStakeDelegate(holder)
+	if candidate == nil {
+		return nil, fmt.Errorf("No matched stake holder address found: %v", holder)
+	}
+
+	slashedAmount := big.NewInt(0)
+	for _, stake := range candidate.Stakes {
+		amount := new(big.Int).Mul(stake.Amount, new(big.Int).SetUint64(slashPercentage))
+		amount = new(big.Int).Div(amount, big.NewInt(100))
+		stake.Amount = new(big.Int).Sub(stake.Amount, amount)
+		slashedAmount = new(big.Int).Add(slashedAmount, amount)
+
+		if !stake.Withdrawn {
+			stake.Withdrawn = true
+			stake.ReturnHeight = currentHeight + ReturnLockingPeriod
+		}
+	}
+
+	vcp.sortCandidates()
+
+	return slashedAmount, nil
+}
+
 func (vcp *ValidatorCandidatePool) sortCandidates() {
 	sort.Slice(vcp.SortedCandidates[:], func(i, j int) bool { // descending order in (totalStake, holderAddress)
 		stakeCmp := vcp.SortedCandidates[i].TotalStake().Cmp(vcp.SortedCandidates[j].TotalStake())
@@ -2,13 +2,17 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/eventbus"
 	"github.com/dnerochain/dnero/p2p"
+	"github.com/dnerochain/dnero/p2p/scoring"
 	p2ptypes "github.com/dnerochain/dnero/p2p/types"
 	"github.com/dnerochain/dnero/p2pl"
 
@@ -24,6 +28,9 @@ type Dispatcher struct {
 	p2pnet  p2p.Network
 	p2plnet p2pl.Network
 
+	eventBus *eventbus.EventBus
+	scorer   *scoring.Scorer
+
 	// Life cycle
 	wg      *sync.WaitGroup
 	quit    chan struct{}
@@ -41,8 +48,107 @@ func NewDispatcher(p2pnet p2p.Network, p2plnet p2pl.Network) *Dispatcher {
 	}
 }
 
-// Start is called when the dispatcher starts
+// peerConnectionNotifier is implemented by the networks which report the newly connected peers
+type peerConnectionNotifier interface {
+	AddPeerConnectedListener(listener func(peerID string))
+}
+
+// SetEventBus sets the event bus to which the newly connected peers are published
+func (dp *Dispatcher) SetEventBus(bus *eventbus.EventBus) {
+	dp.eventBus = bus
+
+	listener := func(peerID string) {
+		bus.Publish(eventbus.TopicPeerConnected, &eventbus.PeerConnectedEvent{PeerID: peerID})
+	}
+	if network, ok := dp.p2pnet.(peerConnectionNotifier); ok && !reflect.ValueOf(dp.p2pnet).IsNil() {
+		network.AddPeerConnectedListener(listener)
+	}
+	if network, ok := dp.p2plnet.(peerConnectionNotifier); ok && !reflect.ValueOf(dp.p2plnet).IsNil() {
+		network.AddPeerConnectedListener(listener)
+	}
+}
+
+// SetPeerScorer sets the scorer the misbehaving peers are reported to
+func (dp *Dispatcher) SetPeerScorer(scorer *scoring.Scorer) {
+	dp.scorer = scorer
+}
+
+// ReportPeer penalizes the peer for the offense. It is a no-op if no scorer is set.
+func (dp *Dispatcher) ReportPeer(peerID string, offense scoring.Offense) {
+	dp.scorer.Report(peerID, offense)
+}
+
+// RecordMessageSource records the peer a block or a vote is received from, so the misbehavior
+// found later can be reported with ReportMessageSource
+func (dp *Dispatcher) RecordMessageSource(hash common.Hash, peerID string) {
+	dp.scorer.RecordSource(hash, peerID)
+}
+
+// ReportMessageSource penalizes the peer the block or the vote is received from, if known
+func (dp *Dispatcher) ReportMessageSource(hash common.Hash, offense scoring.Offense) {
+	dp.scorer.ReportSource(hash, offense)
+}
+
+// BanPeer bans the peer for the given duration, and disconnects it
+func (dp *Dispatcher) BanPeer(peerID string, duration time.Duration, reason string) error {
+	if dp.scorer == nil {
+		return errors.New("Peer scoring is not enabled")
+	}
+	dp.scorer.Ban(peerID, duration, reason)
+	return nil
+}
+
+// UnbanPeer lifts the ban of the peer. It returns false if the peer is not banned.
+func (dp *Dispatcher) UnbanPeer(peerID string) (bool, error) {
+	if dp.scorer == nil {
+		return false, errors.New("Peer scoring is not enabled")
+	}
+	return dp.scorer.Unban(peerID), nil
+}
+
+// BannedPeers returns the peers currently banned
+func (dp *Dispatcher) BannedPeers() []*scoring.BannedPeer {
+	if dp.scorer == nil {
+		return []*scoring.BannedPeer{}
+	}
+	return dp.scorer.BannedPeers()
+}
+
+// handshakeInfoExchanger is implemented by the networks which exchange the HandshakeInfo with the peers
+type handshakeInfoExchanger interface {
+	SetHandshakeInfoProvider(provider p2ptypes.HandshakeInfoProvider)
+	PeerHandshakeInfo(peerID string) *p2ptypes.HandshakeInfo
+}
+
+// SetHandshakeInfoProvider sets the provider of the local HandshakeInfo sent to the peers in the handshakes
+func (dp *Dispatcher) SetHandshakeInfoProvider(provider p2ptypes.HandshakeInfoProvider) {
+	if network, ok := dp.p2pnet.(handshakeInfoExchanger); ok && !reflect.ValueOf(dp.p2pnet).IsNil() {
+		network.SetHandshakeInfoProvider(provider)
+	}
+	if network, ok := dp.p2plnet.(handshakeInfoExchanger); ok && !reflect.ValueOf(dp.p2plnet).IsNil() {
+		network.SetHandshakeInfoProvider(provider)
+	}
+}
+
+// PeerHandshakeInfo returns the info the peer sent in the handshake, or nil if unknown
+func (dp *Dispatcher) PeerHandshakeInfo(peerID string) *p2ptypes.HandshakeInfo {
+	if network, ok := dp.p2pnet.(handshakeInfoExchanger); ok && !reflect.ValueOf(dp.p2pnet).IsNil() {
+		if info := network.PeerHandshakeInfo(peerID); info != nil {
+			return info
+		}
+	}
+	if network, ok := dp.p2plnet.(handshakeInfoExchanger); ok && !reflect.ValueOf(dp.p2plnet).IsNil() {
+		return network.PeerHandshakeInfo(peerID)
+	}
+	return nil
+}
+
+// Start is called when the dispatcher starts. Starting a dispatcher that is already started,
+// e.g. by the state sync before the node starts, is a no-op.
 func (dp *Dispatcher) Start(ctx context.Context) error {
+	if dp.ctx != nil {
+		return nil
+	}
 	c, cancel := context.WithCancel(ctx)
 	dp.ctx = c
 	dp.cancel = cancel
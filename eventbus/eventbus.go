@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dnerochain/dnero/common/metrics"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "eventbus"})
+
+// DefaultBufferSize is the default size of the event buffer of a subscription
+const DefaultBufferSize = 1024
+
+//
+// EventBus delivers the chain, consensus, mempool and network events to any number of in-process
+// subscribers. Publishing never blocks: each subscription has its own bounded buffer, and the
+// events are dropped for the subscribers which cannot keep up.
+//
+type EventBus struct {
+	mu          *sync.RWMutex
+	subscribers map[Topic][]*Subscription
+}
+
+// NewEventBus creates a new instance of EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		mu:          &sync.RWMutex{},
+		subscribers: make(map[Topic][]*Subscription),
+	}
+}
+
+// Subscribe creates a subscription to the given topics. The name identifies the subscriber in
+// the logs and metrics. A non-positive buffer size falls back to DefaultBufferSize.
+func (bus *EventBus) Subscribe(name string, bufferSize int, topics ...Topic) *Subscription {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	sub := &Subscription{
+		name:    name,
+		topics:  topics,
+		events:  make(chan Event, bufferSize),
+		dropped: metrics.GetOrRegisterCounter("eventbus/"+name+"/dropped", nil),
+		bus:     bus,
+	}
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	for _, topic := range topics {
+		bus.subscribers[topic] = append(bus.subscribers[topic], sub)
+	}
+	return sub
+}
+
+// Publish delivers the event to all the subscribers of the topic without blocking. It is safe
+// to publish to a nil event bus, which discards the event.
+func (bus *EventBus) Publish(topic Topic, data interface{}) {
+	if bus == nil {
+		return
+	}
+
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	event := Event{Topic: topic, Data: data}
+	for _, sub := range bus.subscribers[topic] {
+		select {
+		case sub.events <- event:
+		default:
+			atomic.AddInt64(&sub.numDropped, 1)
+			sub.dropped.Inc(1)
+			logger.Warnf("Event buffer of %v is full, dropping %v event", sub.name, topic)
+		}
+	}
+}
+
+// NumSubscribers returns the number of subscribers of the topic
+func (bus *EventBus) NumSubscribers(topic Topic) int {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	return len(bus.subscribers[topic])
+}
+
+func (bus *EventBus) unsubscribe(sub *Subscription) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	for _, topic := range sub.topics {
+		subs := bus.subscribers[topic]
+		for i, s := range subs {
+			if s == sub {
+				bus.subscribers[topic] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(bus.subscribers[topic]) == 0 {
+			delete(bus.subscribers, topic)
+		}
+	}
+	close(sub.events)
+}
+
+//
+// Subscription receives the events of the subscribed topics
+//
+type Subscription struct {
+	numDropped int64 // accessed atomically
+
+	name    string
+	topics  []Topic
+	events  chan Event
+	dropped metrics.Counter
+
+	bus       *EventBus
+	unsubOnce sync.Once
+}
+
+// Events returns the channel of the events. The channel is closed after Unsubscribe().
+func (sub *Subscription) Events() <-chan Event {
+	return sub.events
+}
+
+// Unsubscribe stops the delivery of the events and closes the event channel
+func (sub *Subscription) Unsubscribe() {
+	sub.unsubOnce.Do(func() {
+		sub.bus.unsubscribe(sub)
+	})
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (sub *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&sub.numDropped)
+}
@@ -0,0 +1,77 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishToMultipleSubscribers(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	bus := NewEventBus()
+	sub1 := bus.Subscribe("sub1", 10, TopicBlockFinalized)
+	sub2 := bus.Subscribe("sub2", 10, TopicBlockFinalized, TopicPeerConnected)
+	assert.Equal(2, bus.NumSubscribers(TopicBlockFinalized))
+	assert.Equal(1, bus.NumSubscribers(TopicPeerConnected))
+	assert.Equal(0, bus.NumSubscribers(TopicTxDropped))
+
+	bus.Publish(TopicBlockFinalized, &BlockFinalizedEvent{})
+	bus.Publish(TopicPeerConnected, &PeerConnectedEvent{PeerID: "peer1"})
+	bus.Publish(TopicTxDropped, &TxDroppedEvent{Reason: TxDropReasonEvicted})
+
+	require.Equal(1, len(sub1.Events()))
+	event := <-sub1.Events()
+	assert.Equal(TopicBlockFinalized, event.Topic)
+	_, ok := event.Data.(*BlockFinalizedEvent)
+	assert.True(ok)
+
+	require.Equal(2, len(sub2.Events()))
+	assert.Equal(TopicBlockFinalized, (<-sub2.Events()).Topic)
+	event = <-sub2.Events()
+	assert.Equal(TopicPeerConnected, event.Topic)
+	assert.Equal("peer1", event.Data.(*PeerConnectedEvent).PeerID)
+}
+
+func TestPublishDropsEventsForSlowSubscribers(t *testing.T) {
+	assert := assert.New(t)
+
+	bus := NewEventBus()
+	slow := bus.Subscribe("slow", 2, TopicTxAddedToMempool)
+	fast := bus.Subscribe("fast", 10, TopicTxAddedToMempool)
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(TopicTxAddedToMempool, &TxAddedToMempoolEvent{})
+	}
+
+	assert.Equal(2, len(slow.Events()))
+	assert.Equal(int64(3), slow.Dropped())
+	assert.Equal(5, len(fast.Events()))
+	assert.Equal(int64(0), fast.Dropped())
+}
+
+func TestUnsubscribe(t *testing.T) {
+	assert := assert.New(t)
+
+	bus := NewEventBus()
+	sub1 := bus.Subscribe("sub1", 0, TopicBlockProposed, TopicBlockCommitted)
+	sub2 := bus.Subscribe("sub2", 0, TopicBlockProposed)
+	assert.Equal(DefaultBufferSize, cap(sub1.Events()))
+
+	sub1.Unsubscribe()
+	sub1.Unsubscribe()
+	assert.Equal(1, bus.NumSubscribers(TopicBlockProposed))
+	assert.Equal(0, bus.NumSubscribers(TopicBlockCommitted))
+
+	_, ok := <-sub1.Events()
+	assert.False(ok)
+
+	bus.Publish(TopicBlockProposed, &BlockProposedEvent{})
+	assert.Equal(1, len(sub2.Events()))
+
+	// Publishing to a nil event bus is a no-op
+	var nilBus *EventBus
+	nilBus.Publish(TopicBlockProposed, &BlockProposedEvent{})
+}
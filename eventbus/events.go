@@ -0,0 +1,82 @@
+package eventbus
+
+import (
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+)
+
+// Topic identifies a type of events published to the event bus
+type Topic string
+
+const (
+	// TopicBlockProposed is published when the consensus engine validates a proposed block
+	TopicBlockProposed Topic = "BlockProposed"
+
+	// TopicBlockCommitted is published when a block receives a commit certificate
+	TopicBlockCommitted Topic = "BlockCommitted"
+
+	// TopicBlockFinalized is published when a block is finalized
+	TopicBlockFinalized Topic = "BlockFinalized"
+
+	// TopicTxAddedToMempool is published when a transaction is added to the mempool
+	TopicTxAddedToMempool Topic = "TxAddedToMempool"
+
+	// TopicTxDropped is published when a pending transaction leaves the mempool without being
+	// included in a block
+	TopicTxDropped Topic = "TxDropped"
+
+	// TopicPeerConnected is published when a new peer is connected
+	TopicPeerConnected Topic = "PeerConnected"
+
+	// TopicValidatorSetChanged is published when the ledger applies a block that updates the
+	// validator set. The change takes effect once the block is finalized.
+	TopicValidatorSetChanged Topic = "ValidatorSetChanged"
+)
+
+// Event is delivered to the subscribers of its topic. The type of the data is determined by
+// the topic, e.g. *BlockFinalizedEvent for TopicBlockFinalized.
+type Event struct {
+	Topic Topic
+	Data  interface{}
+}
+
+type BlockProposedEvent struct {
+	Block *core.ExtendedBlock
+}
+
+type BlockCommittedEvent struct {
+	Block *core.ExtendedBlock
+}
+
+type BlockFinalizedEvent struct {
+	Block *core.ExtendedBlock
+}
+
+type TxAddedToMempoolEvent struct {
+	RawTx    common.Bytes
+	Replaced bool // true if the transaction replaced a pending transaction of the same sequence
+}
+
+// TxDropReason describes why a pending transaction is dropped from the mempool
+type TxDropReason string
+
+const (
+	TxDropReasonReplaced TxDropReason = "replaced"
+	TxDropReasonEvicted  TxDropReason = "evicted"
+	TxDropReasonInvalid  TxDropReason = "invalid"
+	TxDropReasonExpired  TxDropReason = "expired"
+)
+
+type TxDroppedEvent struct {
+	RawTx  common.Bytes
+	Reason TxDropReason
+}
+
+type PeerConnectedEvent struct {
+	PeerID string
+}
+
+type ValidatorSetChangedEvent struct {
+	BlockHash common.Hash
+	Height    uint64
+}
@@ -0,0 +1,82 @@
+package evidence
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	dp "github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/p2p/types"
+	"github.com/dnerochain/dnero/rlp"
+)
+
+//
+// EvidenceMessageHandler handles the messages received over the
+// ChannelIDEvidence channel
+//
+type EvidenceMessageHandler struct {
+	pool *EvidencePool
+}
+
+// CreateEvidenceMessageHandler create an instance of the EvidenceMessageHandler
+func CreateEvidenceMessageHandler(pool *EvidencePool) *EvidenceMessageHandler {
+	return &EvidenceMessageHandler{
+		pool: pool,
+	}
+}
+
+// GetChannelIDs implements the p2p.MessageHandler interface
+func (emh *EvidenceMessageHandler) GetChannelIDs() []common.ChannelIDEnum {
+	return []common.ChannelIDEnum{
+		common.ChannelIDEvidence,
+	}
+}
+
+// EncodeMessage implements the p2p.MessageHandler interface
+func (emh *EvidenceMessageHandler) EncodeMessage(message interface{}) (common.Bytes, error) {
+	return rlp.EncodeToBytes(message)
+}
+
+// ParseMessage implements the p2p.MessageHandler interface
+func (emh *EvidenceMessageHandler) ParseMessage(peerID string, channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (types.Message, error) {
+	var dataResponse dp.DataResponse
+	rlp.DecodeBytes(rawMessageBytes, &dataResponse)
+
+	message := types.Message{
+		PeerID:    peerID,
+		ChannelID: channelID,
+		Content:   dataResponse.Payload,
+	}
+	return message, nil
+}
+
+// HandleMessage implements the p2p.MessageHandler interface
+func (emh *EvidenceMessageHandler) HandleMessage(message types.Message) error {
+	if message.ChannelID != common.ChannelIDEvidence {
+		return fmt.Errorf("Invalid channel for EvidenceMessageHandler: %v", message.ChannelID)
+	}
+	evidence := &core.EquivocationEvidence{}
+	if err := rlp.DecodeBytes(message.Content.(common.Bytes), evidence); err != nil {
+		return fmt.Errorf("Failed to decode evidence: %v", err)
+	}
+	logger.Debugf("Received gossiped evidence: %v", evidence)
+
+	err := emh.pool.AddEvidence(evidence)
+	if err == DuplicateEvidenceError || err == ExpiredEvidenceError {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// When using libp2p gossip, we don't need to re-broadcast evidence received from other
+	// nodes.
+	p2pOpt := common.P2POptEnum(viper.GetInt(common.CfgP2POpt))
+	if p2pOpt != common.P2POptLibp2p {
+		emh.pool.BroadcastEvidence(evidence)
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package evidence
+
+import (
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	dp "github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/rlp"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "evidence"})
+
+type EvidenceError string
+
+func (e EvidenceError) Error() string {
+	return string(e)
+}
+
+const DuplicateEvidenceError = EvidenceError("Evidence already seen")
+const ExpiredEvidenceError = EvidenceError("Evidence is too old")
+const UnknownOffenderError = EvidenceError("Offender has no stake at the evidence height")
+const UnverifiableEvidenceError = EvidenceError("No block at the evidence height to verify the offender")
+const EvidencePoolFullError = EvidenceError("Evidence pool is full")
+
+// MaxNumPendingEvidence is the max number of pending evidence kept in the pool
+const MaxNumPendingEvidence = 1024
+
+// DetectionWindow is the number of recent block heights for which the signed votes and
+// proposals are kept to detect the conflicting ones
+const DetectionWindow uint64 = 1000
+
+type voteKey struct {
+	voter  common.Address
+	height uint64
+}
+
+type proposalKey struct {
+	proposer common.Address
+	epoch    uint64
+}
+
+type signedVote struct {
+	vote   core.Vote
+	header *core.BlockHeader
+}
+
+//
+// EvidencePool detects the validators that sign conflicting votes or proposals, and keeps the
+// evidence until it is included in a block by the proposer
+//
+type EvidencePool struct {
+	mu *sync.Mutex
+
+	chain      *blockchain.Chain
+	consensus  core.ConsensusEngine
+	dispatcher *dp.Dispatcher
+
+	votes     map[voteKey]signedVote
+	proposals map[proposalKey]*core.BlockHeader
+	maxHeight uint64
+
+	pending map[common.Hash]*core.EquivocationEvidence // evidence ID -> evidence
+}
+
+// NewEvidencePool creates an instance of EvidencePool
+func NewEvidencePool(chain *blockchain.Chain, consensus core.ConsensusEngine, dispatcher *dp.Dispatcher) *EvidencePool {
+	return &EvidencePool{
+		mu:         &sync.Mutex{},
+		chain:      chain,
+		consensus:  consensus,
+		dispatcher: dispatcher,
+		votes:      make(map[voteKey]signedVote),
+		proposals:  make(map[proposalKey]*core.BlockHeader),
+		pending:    make(map[common.Hash]*core.EquivocationEvidence),
+	}
+}
+
+// CheckVote records a validated vote, and creates the evidence if the voter has voted for a
+// different block at the same height. The height of the voted block is proven by its header,
+// so the vote is skipped if the block is not in the chain yet.
+func (pool *EvidencePool) CheckVote(vote core.Vote) {
+	block, err := pool.chain.FindBlock(vote.Block)
+	if err != nil {
+		return
+	}
+	header := block.BlockHeader
+
+	pool.mu.Lock()
+	if !pool.updateHeight(header.Height) {
+		pool.mu.Unlock()
+		return
+	}
+	key := voteKey{voter: vote.ID, height: header.Height}
+	prev, ok := pool.votes[key]
+	if !ok {
+		pool.votes[key] = signedVote{vote: vote, header: header}
+	}
+	pool.mu.Unlock()
+
+	if !ok || prev.vote.Block == vote.Block {
+		return
+	}
+	pool.addDetectedEvidence(core.NewVoteEquivocationEvidence(prev.vote, prev.header, vote, header))
+}
+
+// CheckBlock records a validated block, and creates the evidence if the proposer has proposed
+// a different block in the same epoch.
+func (pool *EvidencePool) CheckBlock(header *core.BlockHeader) {
+	pool.mu.Lock()
+	if !pool.updateHeight(header.Height) {
+		pool.mu.Unlock()
+		return
+	}
+	key := proposalKey{proposer: header.Proposer, epoch: header.Epoch}
+	prev, ok := pool.proposals[key]
+	if !ok {
+		pool.proposals[key] = header
+	}
+	pool.mu.Unlock()
+
+	if !ok || prev.Hash() == header.Hash() {
+		return
+	}
+	pool.addDetectedEvidence(core.NewProposalEquivocationEvidence(prev, header))
+}
+
+// updateHeight prunes the votes and proposals out of the detection window. It returns false
+// if the given height is already out of the window.
+func (pool *EvidencePool) updateHeight(height uint64) bool {
+	if height+DetectionWindow <= pool.maxHeight {
+		return false
+	}
+	if height <= pool.maxHeight {
+		return true
+	}
+	pool.maxHeight = height
+	for key := range pool.votes {
+		if key.height+DetectionWindow <= height {
+			delete(pool.votes, key)
+		}
+	}
+	for key, header := range pool.proposals {
+		if header.Height+DetectionWindow <= height {
+			delete(pool.proposals, key)
+		}
+	}
+	return true
+}
+
+func (pool *EvidencePool) addDetectedEvidence(evidence *core.EquivocationEvidence) {
+	err := pool.AddEvidence(evidence)
+	if err == DuplicateEvidenceError || err == ExpiredEvidenceError {
+		return
+	}
+	if err != nil {
+		logger.Errorf("Failed to add the detected evidence %v: %v", evidence, err)
+		return
+	}
+	pool.BroadcastEvidence(evidence)
+}
+
+// AddEvidence validates the given evidence and adds it to the pool
+func (pool *EvidencePool) AddEvidence(evidence *core.EquivocationEvidence) error {
+	id := evidence.ID()
+	if pool.hasEvidence(id) {
+		return DuplicateEvidenceError
+	}
+	if res := evidence.Validate(pool.chain.ChainID); res.IsError() {
+		return EvidenceError(res.Message)
+	}
+	if pool.isExpired(evidence) {
+		return ExpiredEvidenceError
+	}
+	if err := pool.checkOffender(evidence); err != nil {
+		return err
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if _, ok := pool.pending[id]; ok {
+		return DuplicateEvidenceError
+	}
+	if len(pool.pending) >= MaxNumPendingEvidence {
+		pool.removeExpiredEvidence()
+		if len(pool.pending) >= MaxNumPendingEvidence {
+			return EvidencePoolFullError
+		}
+	}
+	pool.pending[id] = evidence
+
+	logger.WithFields(log.Fields{"evidence": evidence}).Warn("Validator equivocation detected")
+	return nil
+}
+
+// BroadcastEvidence broadcasts the given evidence to the peers
+func (pool *EvidencePool) BroadcastEvidence(evidence *core.EquivocationEvidence) {
+	payload, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		logger.Errorf("Failed to encode evidence %v: %v", evidence, err)
+		return
+	}
+	msg := dp.DataResponse{
+		ChannelID: common.ChannelIDEvidence,
+		Payload:   payload,
+	}
+	pool.dispatcher.SendData([]string{}, msg)
+}
+
+// PendingEvidence returns at most maxNumEvidence pending evidence, ordered by height. The
+// expired evidence is removed from the pool.
+func (pool *EvidencePool) PendingEvidence(maxNumEvidence int) []*core.EquivocationEvidence {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.removeExpiredEvidence()
+	ret := []*core.EquivocationEvidence{}
+	for _, evidence := range pool.pending {
+		ret = append(ret, evidence)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Height() != ret[j].Height() {
+			return ret[i].Height() < ret[j].Height()
+		}
+		idI, idJ := ret[i].ID(), ret[j].ID()
+		return idI.Hex() < idJ.Hex()
+	})
+	if len(ret) > maxNumEvidence {
+		ret = ret[:maxNumEvidence]
+	}
+	return ret
+}
+
+// RemoveEvidence removes the evidence with the given IDs, e.g. after the offenders are slashed
+func (pool *EvidencePool) RemoveEvidence(ids []common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for _, id := range ids {
+		delete(pool.pending, id)
+	}
+}
+
+// Size returns the number of pending evidence
+func (pool *EvidencePool) Size() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return len(pool.pending)
+}
+
+func (pool *EvidencePool) hasEvidence(id common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	_, ok := pool.pending[id]
+	return ok
+}
+
+// checkOffender verifies that the offender has stake in the validator candidate pool at the height of the
+// evidence, i.e. the offender could sign the conflicting votes or proposals as a validator. The pool is
+// loaded for the conflicting blocks, or for another block at the same height if neither is in the chain.
+func (pool *EvidencePool) checkOffender(evidence *core.EquivocationEvidence) error {
+	blockHashes := []common.Hash{evidence.HeaderA.Hash(), evidence.HeaderB.Hash()}
+	for _, block := range pool.chain.FindBlocksByHeight(evidence.Height()) {
+		blockHashes = append(blockHashes, block.Hash())
+	}
+
+	for _, blockHash := range blockHashes {
+		if _, err := pool.chain.FindBlock(blockHash); err != nil {
+			continue
+		}
+		vcp, err := pool.consensus.GetLedger().GetFinalizedValidatorCandidatePool(blockHash, false)
+		if err != nil || vcp == nil {
+			continue
+		}
+		if vcp.FindStakeDelegate(evidence.Offender()) == nil {
+			return UnknownOffenderError
+		}
+		return nil
+	}
+	return UnverifiableEvidenceError
+}
+
+// removeExpiredEvidence removes the expired evidence from the pool. The caller needs to hold the lock.
+func (pool *EvidencePool) removeExpiredEvidence() {
+	for id, evidence := range pool.pending {
+		if pool.isExpired(evidence) {
+			delete(pool.pending, id)
+		}
+	}
+}
+
+func (pool *EvidencePool) isExpired(evidence *core.EquivocationEvidence) bool {
+	lfb := pool.consensus.GetLastFinalizedBlock()
+	return lfb != nil && evidence.Height()+core.MaxEquivocationEvidenceAge < lfb.Height
+}
@@ -0,0 +1,252 @@
+package evidence
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	dp "github.com/dnerochain/dnero/dispatcher"
+	p2psim "github.com/dnerochain/dnero/p2p/simulation"
+	p2plmsg "github.com/dnerochain/dnero/p2pl/messenger"
+	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/dnerochain/dnero/store/kvstore"
+)
+
+const testChainID = "testchain"
+
+func TestCheckBlockDetectsConflictingProposals(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	pool, chain := newTestEvidencePool()
+	proposer, _, _ := crypto.GenerateKeyPair()
+	other, _, _ := crypto.GenerateKeyPair()
+	addTestStakeHolders(pool, proposer)
+
+	headerA := createTestHeader(proposer, 10, 5, 1)
+	headerB := createTestHeader(proposer, 10, 5, 2)
+	addTestBlocks(t, chain, headerA, headerB)
+
+	pool.CheckBlock(headerA)
+	pool.CheckBlock(headerA)
+	assert.Equal(0, pool.Size())
+
+	// Proposals in different epochs, or by different proposers, are not conflicting
+	pool.CheckBlock(createTestHeader(proposer, 11, 6, 2))
+	pool.CheckBlock(createTestHeader(other, 10, 5, 2))
+	assert.Equal(0, pool.Size())
+
+	pool.CheckBlock(headerB)
+	require.Equal(1, pool.Size())
+
+	pending := pool.PendingEvidence(core.MaxNumEquivocationEvidencePerBlock)
+	require.Equal(1, len(pending))
+	assert.False(pending[0].IsVoteEquivocation())
+	assert.Equal(proposer.PublicKey().Address(), pending[0].Offender())
+	assert.True(pending[0].Validate(testChainID).IsOK())
+
+	// The same offense detected in the reversed order shares the ID
+	assert.Equal(DuplicateEvidenceError, pool.AddEvidence(core.NewProposalEquivocationEvidence(headerB, headerA)))
+
+	pool.RemoveEvidence([]common.Hash{pending[0].ID()})
+	assert.Equal(0, pool.Size())
+}
+
+func TestCheckVoteDetectsConflictingVotes(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	pool, chain := newTestEvidencePool()
+	proposer, _, _ := crypto.GenerateKeyPair()
+	voter, _, _ := crypto.GenerateKeyPair()
+	addTestStakeHolders(pool, voter)
+
+	headerA := createTestHeader(proposer, 10, 5, 1)
+	headerB := createTestHeader(proposer, 10, 6, 2)
+	headerC := createTestHeader(proposer, 11, 7, 3)
+	addTestBlocks(t, chain, headerA, headerB, headerC)
+
+	pool.CheckVote(createTestVote(voter, headerA, 5))
+	pool.CheckVote(createTestVote(voter, headerC, 7))
+	assert.Equal(0, pool.Size())
+
+	// Votes for the blocks not in the chain are skipped
+	pool.CheckVote(createTestVote(voter, createTestHeader(proposer, 10, 8, 4), 8))
+	assert.Equal(0, pool.Size())
+
+	pool.CheckVote(createTestVote(voter, headerB, 6))
+	require.Equal(1, pool.Size())
+
+	ev := pool.PendingEvidence(core.MaxNumEquivocationEvidencePerBlock)[0]
+	assert.True(ev.IsVoteEquivocation())
+	assert.Equal(voter.PublicKey().Address(), ev.Offender())
+	assert.Equal(uint64(10), ev.Height())
+	assert.True(ev.Validate(testChainID).IsOK())
+	assert.True(ev.Validate("otherchain").IsError())
+}
+
+func TestAddEvidenceRejectsInvalidEvidence(t *testing.T) {
+	assert := assert.New(t)
+
+	pool, _ := newTestEvidencePool()
+	keyA, _, _ := crypto.GenerateKeyPair()
+	keyB, _, _ := crypto.GenerateKeyPair()
+
+	// Different proposers
+	ev := core.NewProposalEquivocationEvidence(createTestHeader(keyA, 10, 5, 1), createTestHeader(keyB, 10, 5, 2))
+	assert.NotNil(pool.AddEvidence(ev))
+
+	// Votes for blocks at different heights
+	headerA := createTestHeader(keyA, 10, 5, 1)
+	headerB := createTestHeader(keyA, 11, 6, 2)
+	ev = core.NewVoteEquivocationEvidence(createTestVote(keyB, headerA, 5), headerA, createTestVote(keyB, headerB, 6), headerB)
+	assert.NotNil(pool.AddEvidence(ev))
+
+	// Tampered signature
+	headerB = createTestHeader(keyA, 10, 5, 2)
+	headerB.Timestamp = big.NewInt(100)
+	ev = core.NewProposalEquivocationEvidence(headerA, headerB)
+	assert.NotNil(pool.AddEvidence(ev))
+
+	assert.Equal(0, pool.Size())
+}
+
+func TestEvidenceExpiry(t *testing.T) {
+	assert := assert.New(t)
+
+	pool, chain := newTestEvidencePool()
+	consensus := pool.consensus.(*mockConsensus)
+	key, _, _ := crypto.GenerateKeyPair()
+	addTestStakeHolders(pool, key)
+
+	headerA := createTestHeader(key, 10, 5, 1)
+	addTestBlocks(t, chain, headerA)
+	ev := core.NewProposalEquivocationEvidence(headerA, createTestHeader(key, 10, 5, 2))
+	assert.Nil(pool.AddEvidence(ev))
+	assert.Equal(1, len(pool.PendingEvidence(core.MaxNumEquivocationEvidencePerBlock)))
+
+	consensus.lfbHeight = 11 + core.MaxEquivocationEvidenceAge
+	assert.Equal(0, len(pool.PendingEvidence(core.MaxNumEquivocationEvidencePerBlock)))
+	assert.Equal(0, pool.Size())
+	assert.Equal(ExpiredEvidenceError, pool.AddEvidence(ev))
+}
+
+func TestAddEvidenceChecksOffender(t *testing.T) {
+	assert := assert.New(t)
+
+	pool, chain := newTestEvidencePool()
+	staker, _, _ := crypto.GenerateKeyPair()
+	nonStaker, _, _ := crypto.GenerateKeyPair()
+	addTestStakeHolders(pool, staker)
+
+	// No block at the evidence height to load the validator candidate pool
+	ev := core.NewProposalEquivocationEvidence(createTestHeader(staker, 10, 5, 1), createTestHeader(staker, 10, 5, 2))
+	assert.Equal(UnverifiableEvidenceError, pool.AddEvidence(ev))
+
+	addTestBlocks(t, chain, createTestHeader(nonStaker, 10, 4, 3))
+	assert.Nil(pool.AddEvidence(ev))
+
+	ev = core.NewProposalEquivocationEvidence(createTestHeader(nonStaker, 10, 5, 1), createTestHeader(nonStaker, 10, 5, 2))
+	assert.Equal(UnknownOffenderError, pool.AddEvidence(ev))
+	assert.Equal(1, pool.Size())
+}
+
+func TestEvidencePoolCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	pool, chain := newTestEvidencePool()
+	key, _, _ := crypto.GenerateKeyPair()
+	addTestStakeHolders(pool, key)
+	addTestBlocks(t, chain, createTestHeader(key, 10, 5, 0))
+
+	for i := 0; i < MaxNumPendingEvidence; i++ {
+		ev := core.NewProposalEquivocationEvidence(createTestHeader(key, 10, uint64(i), 1), createTestHeader(key, 10, uint64(i), 2))
+		assert.Nil(pool.AddEvidence(ev))
+	}
+	assert.Equal(MaxNumPendingEvidence, pool.Size())
+
+	ev := core.NewProposalEquivocationEvidence(createTestHeader(key, 10, MaxNumPendingEvidence, 1), createTestHeader(key, 10, MaxNumPendingEvidence, 2))
+	assert.Equal(EvidencePoolFullError, pool.AddEvidence(ev))
+	assert.Equal(MaxNumPendingEvidence, pool.Size())
+}
+
+// --------------- Test Utilities --------------- //
+
+type mockConsensus struct {
+	core.ConsensusEngine
+	lfbHeight uint64
+	ledger    *mockLedger
+}
+
+func (c *mockConsensus) GetLastFinalizedBlock() *core.ExtendedBlock {
+	return &core.ExtendedBlock{Block: &core.Block{BlockHeader: &core.BlockHeader{Height: c.lfbHeight}}}
+}
+
+func (c *mockConsensus) GetLedger() core.Ledger {
+	return c.ledger
+}
+
+type mockLedger struct {
+	core.Ledger
+	vcp *core.ValidatorCandidatePool
+}
+
+func (l *mockLedger) GetFinalizedValidatorCandidatePool(blockHash common.Hash, isNext bool) (*core.ValidatorCandidatePool, error) {
+	return l.vcp, nil
+}
+
+func addTestStakeHolders(pool *EvidencePool, keys ...*crypto.PrivateKey) {
+	vcp := pool.consensus.(*mockConsensus).ledger.vcp
+	for _, key := range keys {
+		stake := core.NewStake(key.PublicKey().Address(), core.MinValidatorStakeDeposit)
+		vcp.SortedCandidates = append(vcp.SortedCandidates, core.NewStakeHolder(key.PublicKey().Address(), []*core.Stake{stake}))
+	}
+}
+
+func addTestBlocks(t *testing.T, chain *blockchain.Chain, headers ...*core.BlockHeader) {
+	for _, header := range headers {
+		_, err := chain.AddBlock(&core.Block{BlockHeader: header})
+		require.Nil(t, err)
+	}
+}
+
+func newTestEvidencePool() (*EvidencePool, *blockchain.Chain) {
+	root := &core.Block{BlockHeader: &core.BlockHeader{ChainID: testChainID}}
+	store := kvstore.NewKVStore(backend.NewMemDatabase())
+	chain := blockchain.NewChain(testChainID, store, root)
+
+	messenger := p2psim.NewSimnetWithHandler(nil).AddEndpoint("peer0")
+	var p2plnet *p2plmsg.Messenger
+	dispatcher := dp.NewDispatcher(messenger, p2plnet)
+
+	consensus := &mockConsensus{ledger: &mockLedger{vcp: &core.ValidatorCandidatePool{}}}
+	return NewEvidencePool(chain, consensus, dispatcher), chain
+}
+
+func createTestHeader(key *crypto.PrivateKey, height uint64, epoch uint64, timestamp int64) *core.BlockHeader {
+	header := &core.BlockHeader{
+		ChainID:   testChainID,
+		Epoch:     epoch,
+		Height:    height,
+		Timestamp: big.NewInt(timestamp),
+		Proposer:  key.PublicKey().Address(),
+	}
+	header.Signature, _ = key.Sign(header.SignBytes())
+	return header
+}
+
+func createTestVote(key *crypto.PrivateKey, header *core.BlockHeader, epoch uint64) core.Vote {
+	vote := core.Vote{
+		Block:  header.Hash(),
+		Height: header.Height,
+		Epoch:  epoch,
+		ID:     key.PublicKey().Address(),
+	}
+	vote.Sign(key)
+	return vote
+}
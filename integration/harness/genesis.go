@@ -0,0 +1,142 @@
+package harness
+
+import (
+	"bufio"
+	"math/big"
+	"os"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	p2ptypes "github.com/dnerochain/dnero/p2p/types"
+	"github.com/dnerochain/dnero/store/database/backend"
+)
+
+// initialBalance is the balance of each simulated node's account in the genesis, enough for its stake
+var initialBalance = new(big.Int).Mul(new(big.Int).SetUint64(10000000), new(big.Int).SetUint64(1e18))
+
+// generateGenesis generates the genesis snapshot where the validators, the sentries and the elite
+// edge nodes have their stakes deposited, and writes it to the given file. It returns the genesis
+// block header.
+func generateGenesis(chainID string, timestamp int64, nodes []*SimNode, eens []*SimEliteEdgeNode,
+	chainParams *common.ChainParams, snapshotFilePath string) (*core.BlockHeader, error) {
+	genesisHeight := core.GenesisBlockHeight
+	sv := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+
+	holders := []common.Address{}
+	for _, node := range nodes {
+		holders = append(holders, node.Address())
+	}
+	for _, een := range eens {
+		holders = append(holders, een.Address())
+	}
+	for _, holder := range holders {
+		sv.SetAccount(holder, &types.Account{
+			Address:  holder,
+			Root:     common.Hash{},
+			CodeHash: types.EmptyCodeHash,
+			Balance: types.Coins{
+				DneroWei:  new(big.Int).Set(initialBalance),
+				DTokenWei: new(big.Int).Mul(new(big.Int).SetUint64(5), initialBalance),
+			},
+		})
+	}
+
+	// Each node stakes to itself
+	vcp := &core.ValidatorCandidatePool{}
+	scp := core.NewSentryCandidatePool()
+	for _, node := range nodes {
+		var err error
+		switch node.Role {
+		case p2ptypes.NodeRoleValidator:
+			err = vcp.DepositStake(node.Address(), node.Address(), core.MinValidatorStakeDeposit, genesisHeight)
+			if err == nil {
+				debit(sv, node.Address(), core.MinValidatorStakeDeposit)
+			}
+		case p2ptypes.NodeRoleSentry:
+			err = scp.DepositStake(node.Address(), node.Address(), core.MinSentryStakeDeposit, node.BLSKey().PublicKey(), genesisHeight)
+			if err == nil {
+				debit(sv, node.Address(), core.MinSentryStakeDeposit)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	sv.UpdateValidatorCandidatePool(vcp)
+	sv.UpdateSentryCandidatePool(scp)
+
+	eenp := state.NewEliteEdgeNodePool(sv, false)
+	for _, een := range eens {
+		err := eenp.DepositStake(een.Address(), een.Address(), core.MinEliteEdgeNodeStakeDeposit, een.BLSKey().PublicKey(), genesisHeight)
+		if err != nil {
+			return nil, err
+		}
+		debit(sv, een.Address(), core.MinEliteEdgeNodeStakeDeposit)
+	}
+
+	hl := &types.HeightList{}
+	hl.Append(genesisHeight)
+	sv.UpdateStakeTransactionHeightList(hl)
+	sv.UpdateChainParams(chainParams)
+
+	genesisBlock := core.NewBlock()
+	genesisBlock.ChainID = chainID
+	genesisBlock.Height = genesisHeight
+	genesisBlock.Epoch = genesisBlock.Height
+	genesisBlock.Parent = common.Hash{}
+	genesisBlock.StateHash = sv.Hash()
+	genesisBlock.Timestamp = big.NewInt(timestamp)
+
+	metadata := &core.SnapshotMetadata{
+		TailTrio: core.SnapshotBlockTrio{
+			First:  core.SnapshotFirstBlock{},
+			Second: core.SnapshotSecondBlock{Header: genesisBlock.BlockHeader},
+			Third:  core.SnapshotThirdBlock{},
+		},
+	}
+	if err := writeGenesisSnapshot(sv, metadata, snapshotFilePath); err != nil {
+		return nil, err
+	}
+	return genesisBlock.BlockHeader, nil
+}
+
+// debit deducts the staked amount from the account balance
+func debit(sv *state.StoreView, address common.Address, amount *big.Int) {
+	account := sv.GetAccount(address)
+	account.Balance = account.Balance.Minus(types.Coins{
+		DneroWei:  amount,
+		DTokenWei: new(big.Int).SetUint64(0),
+	})
+	sv.SetAccount(address, account)
+}
+
+// writeGenesisSnapshot writes the genesis snapshot in the format of the generate_genesis tool
+func writeGenesisSnapshot(sv *state.StoreView, metadata *core.SnapshotMetadata, snapshotFilePath string) error {
+	file, err := os.Create(snapshotFilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := core.WriteMetadata(writer, metadata); err != nil {
+		return err
+	}
+	height := core.Itobytes(sv.Height())
+	if err := core.WriteRecord(writer, []byte{core.SVStart}, height); err != nil {
+		return err
+	}
+	sv.GetStore().Traverse(nil, func(k, v common.Bytes) bool {
+		err = core.WriteRecord(writer, k, v)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := core.WriteRecord(writer, []byte{core.SVEnd}, height); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
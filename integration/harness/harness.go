@@ -0,0 +1,271 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/p2p/simulation"
+	p2ptypes "github.com/dnerochain/dnero/p2p/types"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "harness"})
+
+//
+// Config specifies the network simulated by the Harness
+//
+type Config struct {
+	ChainID           string
+	Seed              int64 // the seed the node keys and the network conditions are derived from
+	NumValidators     int
+	NumSentries       int
+	NumEliteEdgeNodes int
+	DefaultLink       simulation.LinkConfig // the conditions of the links between the nodes
+
+	MinBlockInterval int // in seconds
+	MaxEpochLength   int // in seconds
+}
+
+// DefaultConfig returns the config of a small network of four validators on perfect links
+func DefaultConfig() Config {
+	return Config{
+		ChainID:          "simnet",
+		NumValidators:    4,
+		MinBlockInterval: 1,
+		MaxEpochLength:   5,
+	}
+}
+
+//
+// Harness runs a network of full nodes, i.e. validators and sentries, and simulated elite edge
+// nodes on a Simnet, so the consensus can be tested end to end in a single process. The network
+// conditions, the partitions and the node crashes are controlled by the tests.
+//
+// The nodes share the process-wide configuration, thus only one Harness can run at a time.
+//
+// The seed only fixes the node keys and the per-message drop and delay draws of the network. The
+// nodes and the network run on the wall clock and the goroutine scheduling differs between runs,
+// so a run cannot be replayed, and the produced chains may differ between runs with the same seed.
+// The scenarios thus check the safety and liveness properties rather than the exact blocks.
+//
+type Harness struct {
+	Config     Config
+	Simnet     *simulation.Simnet
+	Validators []*SimNode
+	Sentries   []*SimNode
+	EENs       []*SimEliteEdgeNode
+	Genesis    *core.BlockHeader
+
+	dir          string
+	snapshotPath string
+
+	// Life cycle
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewHarness creates the nodes and the genesis where all of them have their stakes deposited
+func NewHarness(config Config) (*Harness, error) {
+	if config.NumValidators < 1 {
+		return nil, fmt.Errorf("At least one validator is required")
+	}
+	if config.MinBlockInterval < 1 || config.MaxEpochLength <= config.MinBlockInterval {
+		return nil, fmt.Errorf("Invalid block interval %v and epoch length %v", config.MinBlockInterval, config.MaxEpochLength)
+	}
+
+	dir, err := ioutil.TempDir("", "dnero-harness")
+	if err != nil {
+		return nil, err
+	}
+	h := &Harness{
+		Config:       config,
+		Simnet:       simulation.NewSimnetWithSeed(nil, config.Seed),
+		dir:          dir,
+		snapshotPath: path.Join(dir, "genesis"),
+	}
+	if err := h.init(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *Harness) init() error {
+	config := h.Config
+	chainParams := common.DevnetChainParams(config.ChainID)
+	if err := common.RegisterChainParams(chainParams); err != nil {
+		return err
+	}
+
+	viper.Set(common.CfgGenesisChainID, config.ChainID)
+	viper.Set(common.CfgConsensusMinBlockInterval, config.MinBlockInterval)
+	viper.Set(common.CfgConsensusMaxEpochLength, config.MaxEpochLength)
+	viper.Set(common.CfgStorageRollingEnabled, false)
+	viper.Set(common.CfgRPCEnabled, false)
+	viper.Set(common.CfgMetricsEnabled, false)
+
+	for i := 0; i < config.NumValidators; i++ {
+		n, err := newSimNode(config.Seed, p2ptypes.NodeRoleValidator, i, h.dir)
+		if err != nil {
+			return err
+		}
+		h.Validators = append(h.Validators, n)
+	}
+	for i := 0; i < config.NumSentries; i++ {
+		n, err := newSimNode(config.Seed, p2ptypes.NodeRoleSentry, i, h.dir)
+		if err != nil {
+			return err
+		}
+		h.Sentries = append(h.Sentries, n)
+	}
+	nodes := h.Nodes()
+	for i := 0; i < config.NumEliteEdgeNodes; i++ {
+		een, err := newSimEliteEdgeNode(config.Seed, i)
+		if err != nil {
+			return err
+		}
+		een.Host = nodes[i%len(nodes)]
+		h.EENs = append(h.EENs, een)
+	}
+
+	genesis, err := generateGenesis(config.ChainID, h.Simnet.Clock.Now().Unix(), nodes, h.EENs, chainParams, h.snapshotPath)
+	if err != nil {
+		return err
+	}
+	h.Genesis = genesis
+	viper.Set(common.CfgGenesisHash, genesis.Hash().Hex())
+
+	h.Simnet.SetDefaultLinkConfig(config.DefaultLink)
+	h.Simnet.SetMessageLogging(false)
+	for _, n := range nodes {
+		n.Endpoint = h.Simnet.AddEndpoint(n.ID())
+	}
+	for _, een := range h.EENs {
+		een.Endpoint = h.Simnet.AddEndpoint(een.ID())
+	}
+	return nil
+}
+
+// Nodes returns all the full nodes, the validators first
+func (h *Harness) Nodes() []*SimNode {
+	nodes := []*SimNode{}
+	nodes = append(nodes, h.Validators...)
+	nodes = append(nodes, h.Sentries...)
+	return nodes
+}
+
+// RunningNodes returns the full nodes that are not crashed
+func (h *Harness) RunningNodes() []*SimNode {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	nodes := []*SimNode{}
+	for _, n := range h.Nodes() {
+		if n.IsRunning() {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// Start starts the Simnet and all the nodes
+func (h *Harness) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	h.ctx = c
+	h.cancel = cancel
+
+	h.Simnet.Start(c)
+
+	h.mu.Lock()
+	for _, n := range h.Nodes() {
+		n.start(c, h.Config.ChainID, h.Genesis, h.snapshotPath)
+	}
+	h.mu.Unlock()
+
+	for _, een := range h.EENs {
+		een.start(c)
+	}
+}
+
+// Stop stops the nodes and the Simnet, and removes the node data
+func (h *Harness) Stop() {
+	h.mu.Lock()
+	for _, n := range h.Nodes() {
+		if n.IsRunning() {
+			n.stop()
+		}
+	}
+	h.mu.Unlock()
+
+	h.cancel()
+	h.Simnet.Wait()
+	os.RemoveAll(h.dir)
+}
+
+// CrashNode stops the node as if it crashed. It is disconnected from the network until restarted.
+func (h *Harness) CrashNode(n *SimNode) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !n.IsRunning() {
+		return
+	}
+	logger.Infof("Crashing node %v", n)
+	n.stop()
+}
+
+// RestartNode restarts the crashed node from its database
+func (h *Harness) RestartNode(n *SimNode) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n.IsRunning() {
+		return
+	}
+	logger.Infof("Restarting node %v", n)
+	n.start(h.ctx, h.Config.ChainID, h.Genesis, h.snapshotPath)
+
+	// The elite edge nodes follow the restarted host
+	for _, een := range h.EENs {
+		if een.Host == n {
+			een.start(h.ctx)
+		}
+	}
+}
+
+// Partition splits the nodes into the given groups. The nodes in different groups cannot reach
+// each other, and the elite edge nodes stay with their hosts.
+func (h *Harness) Partition(groups ...[]*SimNode) {
+	idGroups := [][]string{}
+	for _, group := range groups {
+		ids := []string{}
+		for _, n := range group {
+			ids = append(ids, n.ID())
+			for _, een := range h.EENs {
+				if een.Host == n {
+					ids = append(ids, een.ID())
+				}
+			}
+		}
+		idGroups = append(idGroups, ids)
+	}
+	h.Simnet.Partition(idGroups...)
+}
+
+// Heal removes the partitions
+func (h *Harness) Heal() {
+	h.Simnet.Heal()
+}
+
+// Schedule schedules the action to be taken at the given time after the Harness starts. It must be
+// called before the Harness starts.
+func (h *Harness) Schedule(at time.Duration, action func()) {
+	h.Simnet.Schedule(at, action)
+}
@@ -0,0 +1,110 @@
+// +build integration
+
+package harness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dnerochain/dnero/p2p/simulation"
+)
+
+func startHarness(t *testing.T, config Config) *Harness {
+	h, err := NewHarness(config)
+	require.Nil(t, err)
+	h.Start(context.Background())
+	return h
+}
+
+func TestHarnessFinality(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig()
+	config.NumSentries = 1
+	config.NumEliteEdgeNodes = 1
+	h := startHarness(t, config)
+	defer h.Stop()
+
+	require.Nil(WaitForFinalizedHeight(h.Nodes(), 5, 60*time.Second))
+	require.Nil(CheckFinalityConsistency(h.Nodes()))
+	require.Nil(CheckForkChoice(h.Nodes()))
+}
+
+func TestHarnessPartitionRecovery(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig()
+	config.Seed = 1
+	h := startHarness(t, config)
+	defer h.Stop()
+
+	require.Nil(WaitForFinalizedHeight(h.Nodes(), 3, 60*time.Second))
+
+	// Neither side has the 2/3 majority, so no block can be finalized during the partition
+	h.Partition(h.Validators[:2], h.Validators[2:])
+	heightAtPartition := MaxFinalizedHeight(h.Nodes())
+	time.Sleep(time.Duration(2*config.MaxEpochLength) * time.Second)
+	require.True(MaxFinalizedHeight(h.Nodes()) <= heightAtPartition+1) // the votes in flight may finalize one more block
+	require.Nil(CheckFinalityConsistency(h.Nodes()))
+
+	h.Heal()
+	require.Nil(WaitForProgress(h.Nodes(), 3, 90*time.Second))
+	require.Nil(CheckFinalityConsistency(h.Nodes()))
+	require.Nil(CheckForkChoice(h.Nodes()))
+}
+
+func TestHarnessCrashRestart(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig()
+	config.Seed = 2
+	h := startHarness(t, config)
+	defer h.Stop()
+
+	require.Nil(WaitForFinalizedHeight(h.Nodes(), 3, 60*time.Second))
+
+	// The remaining three validators still have the 2/3 majority
+	crashed := h.Validators[3]
+	h.CrashNode(crashed)
+	require.Nil(WaitForProgress(h.RunningNodes(), 2, 60*time.Second))
+
+	h.RestartNode(crashed)
+	require.Nil(WaitForProgress(h.Nodes(), 2, 90*time.Second))
+	require.Nil(CheckFinalityConsistency(h.Nodes()))
+}
+
+func TestHarnessLossyLinks(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig()
+	config.Seed = 3
+	config.DefaultLink = simulation.LinkConfig{
+		Latency:  50 * time.Millisecond,
+		Jitter:   100 * time.Millisecond,
+		DropRate: 0.05,
+	}
+	h, err := NewHarness(config)
+	require.Nil(err)
+
+	// A burst of heavy loss on the links of one validator
+	congested := h.Validators[0].ID()
+	h.Schedule(3*time.Second, func() {
+		for _, n := range h.Nodes()[1:] {
+			h.Simnet.SetBidirectionalLinkConfig(congested, n.ID(), simulation.LinkConfig{DropRate: 0.5})
+		}
+	})
+	h.Schedule(8*time.Second, func() {
+		for _, n := range h.Nodes()[1:] {
+			h.Simnet.SetBidirectionalLinkConfig(congested, n.ID(), config.DefaultLink)
+		}
+	})
+	h.Start(context.Background())
+	defer h.Stop()
+
+	require.Nil(WaitForFinalizedHeight(h.Nodes(), 5, 120*time.Second))
+	require.Nil(CheckFinalityConsistency(h.Nodes()))
+	require.Nil(CheckForkChoice(h.Nodes()))
+}
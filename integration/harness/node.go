@@ -0,0 +1,284 @@
+package harness
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/crypto/bls"
+	"github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/eventbus"
+	"github.com/dnerochain/dnero/node"
+	"github.com/dnerochain/dnero/p2p/simulation"
+	p2ptypes "github.com/dnerochain/dnero/p2p/types"
+	msgl "github.com/dnerochain/dnero/p2pl/messenger"
+	"github.com/dnerochain/dnero/rlp"
+	"github.com/dnerochain/dnero/store/database"
+	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/dnerochain/dnero/store/rollingdb"
+)
+
+// deriveKeys derives the keys of a simulated node from the seed, so the node IDs, and in turn the
+// proposer rotation, are the same in the runs with the same seed
+func deriveKeys(seed int64, role p2ptypes.NodeRole, index int) (*crypto.PrivateKey, *bls.SecretKey, error) {
+	skBytes := sha256.Sum256([]byte(fmt.Sprintf("simulation/%v/%v/%v", seed, role, index)))
+	privKey, err := crypto.PrivateKeyFromBytes(skBytes[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	// The consensus engine derives the BLS key of the node from its public key the same way
+	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(privKey.PublicKey().ToBytes())))
+	if err != nil {
+		return nil, nil, err
+	}
+	return privKey, blsKey, nil
+}
+
+//
+// SimNode is a blockchain node, i.e. a validator or a sentry, running on the Simnet
+//
+type SimNode struct {
+	Role     p2ptypes.NodeRole
+	Index    int
+	Endpoint *simulation.SimnetEndpoint
+
+	privKey *crypto.PrivateKey
+	blsKey  *bls.SecretKey
+	db      database.Database
+	dir     string
+
+	mu       sync.Mutex
+	fullNode *node.Node // replaced on each restart
+	running  bool
+}
+
+func newSimNode(seed int64, role p2ptypes.NodeRole, index int, dir string) (*SimNode, error) {
+	privKey, blsKey, err := deriveKeys(seed, role, index)
+	if err != nil {
+		return nil, err
+	}
+	n := &SimNode{
+		Role:    role,
+		Index:   index,
+		privKey: privKey,
+		blsKey:  blsKey,
+		db:      backend.NewMemDatabase(),
+	}
+	n.dir = path.Join(dir, n.ID())
+	if err := os.MkdirAll(path.Join(n.dir, "db"), 0700); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// ID returns the ID of the node, i.e. the hex address of its key, as on the real networks
+func (n *SimNode) ID() string {
+	return n.Address().Hex()
+}
+
+// Address returns the address of the node
+func (n *SimNode) Address() common.Address {
+	return n.privKey.PublicKey().Address()
+}
+
+// BLSKey returns the BLS key the node signs the sentry votes with
+func (n *SimNode) BLSKey() *bls.SecretKey {
+	return n.blsKey
+}
+
+func (n *SimNode) String() string {
+	return fmt.Sprintf("%v-%v", n.Role, n.Index)
+}
+
+// Node returns the node instance of the current run
+func (n *SimNode) Node() *node.Node {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.fullNode
+}
+
+// IsRunning indicates whether the node is running, i.e. started and not crashed
+func (n *SimNode) IsRunning() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.running
+}
+
+// start creates the node on top of its database and starts it. The node imports the genesis
+// snapshot on the first start, and resumes from its database on a restart.
+func (n *SimNode) start(ctx context.Context, chainID string, genesis *core.BlockHeader, snapshotPath string) {
+	rdb := rollingdb.NewRollingDB(n.dir, n.db)
+	fullNode := node.NewNode(&node.Params{
+		ChainID:      chainID,
+		PrivateKey:   n.privKey,
+		Root:         &core.Block{BlockHeader: genesis},
+		NetworkOld:   n.Endpoint,
+		Network:      (*msgl.Messenger)(nil),
+		DB:           n.db,
+		RollingDB:    rdb,
+		SnapshotPath: snapshotPath,
+	})
+	n.Endpoint.Restart()
+	fullNode.Start(ctx)
+
+	n.mu.Lock()
+	n.fullNode = fullNode
+	n.running = true
+	n.mu.Unlock()
+}
+
+// stop stops the node as if it crashed. Its database is kept for the restart.
+func (n *SimNode) stop() {
+	n.mu.Lock()
+	fullNode := n.fullNode
+	n.running = false
+	n.mu.Unlock()
+
+	n.Endpoint.Stop()
+	fullNode.Stop()
+	fullNode.Wait()
+}
+
+// FinalizedHeight returns the height of the last finalized block of the node
+func (n *SimNode) FinalizedHeight() uint64 {
+	return n.Node().Consensus.GetLastFinalizedBlock().Height
+}
+
+// FinalizedBlockHash returns the hash of the block the node finalized at the given height. It
+// returns false if the node has not finalized a block at the height.
+func (n *SimNode) FinalizedBlockHash(height uint64) (common.Hash, bool) {
+	for _, block := range n.Node().Chain.FindBlocksByHeight(height) {
+		if block.Status.IsFinalized() {
+			return block.Hash(), true
+		}
+	}
+	return common.Hash{}, false
+}
+
+//
+// SimEliteEdgeNode simulates an elite edge node. Instead of running the edge node software, it
+// follows the checkpoints finalized by its host, a blockchain node, and broadcasts the signed
+// votes for them, like the real elite edge nodes do.
+//
+type SimEliteEdgeNode struct {
+	Index    int
+	Endpoint *simulation.SimnetEndpoint
+	Host     *SimNode
+
+	privKey  *crypto.PrivateKey
+	blsKey   *bls.SecretKey
+	numVotes uint64
+}
+
+func newSimEliteEdgeNode(seed int64, index int) (*SimEliteEdgeNode, error) {
+	privKey, blsKey, err := deriveKeys(seed, p2ptypes.NodeRoleEdge, index)
+	if err != nil {
+		return nil, err
+	}
+	return &SimEliteEdgeNode{
+		Index:   index,
+		privKey: privKey,
+		blsKey:  blsKey,
+	}, nil
+}
+
+// ID returns the ID of the elite edge node
+func (e *SimEliteEdgeNode) ID() string {
+	return e.Address().Hex()
+}
+
+// Address returns the address of the elite edge node
+func (e *SimEliteEdgeNode) Address() common.Address {
+	return e.privKey.PublicKey().Address()
+}
+
+// BLSKey returns the BLS key the elite edge node signs the votes with
+func (e *SimEliteEdgeNode) BLSKey() *bls.SecretKey {
+	return e.blsKey
+}
+
+func (e *SimEliteEdgeNode) String() string {
+	return fmt.Sprintf("%v-%v", p2ptypes.NodeRoleEdge, e.Index)
+}
+
+// NumVotes returns the number of votes the elite edge node has broadcast
+func (e *SimEliteEdgeNode) NumVotes() uint64 {
+	return atomic.LoadUint64(&e.numVotes)
+}
+
+// start registers the message handler of the elite edge node, and starts voting for the
+// checkpoints finalized by the host
+func (e *SimEliteEdgeNode) start(ctx context.Context) {
+	host := e.Host.Node()
+	e.Endpoint.RegisterMessageHandler(&eenMessageHandler{encoder: host.SyncManager})
+
+	sub := host.EventBus.Subscribe("simulation/"+e.String(), 0, eventbus.TopicBlockFinalized)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-sub.Events():
+				block := event.Data.(*eventbus.BlockFinalizedEvent).Block
+				if common.IsCheckPointHeight(block.Height) {
+					e.vote(block)
+				}
+			}
+		}
+	}()
+}
+
+func (e *SimEliteEdgeNode) vote(block *core.ExtendedBlock) {
+	signBytes, err := rlp.EncodeToBytes(&core.EENBlsSigMsg{Block: block.Hash()})
+	if err != nil {
+		logger.Errorf("Failed to encode the elite edge node vote: %v", err)
+		return
+	}
+	vote := core.NewEENVote(block.Hash(), block.Height, e.Address(), e.blsKey.Sign(signBytes))
+	payload, err := rlp.EncodeToBytes(vote)
+	if err != nil {
+		logger.Errorf("Failed to encode the elite edge node vote: %v", err)
+		return
+	}
+	e.Endpoint.Broadcast(p2ptypes.Message{
+		ChannelID: common.ChannelIDEliteEdgeNodeVote,
+		Content: dispatcher.DataResponse{
+			ChannelID: common.ChannelIDEliteEdgeNodeVote,
+			Payload:   payload,
+		},
+	}, false)
+	atomic.AddUint64(&e.numVotes, 1)
+}
+
+// eenMessageHandler encodes the votes of a simulated elite edge node in the format of the
+// blockchain nodes, and ignores the incoming messages
+type eenMessageHandler struct {
+	encoder interface {
+		EncodeMessage(message interface{}) (common.Bytes, error)
+	}
+}
+
+func (h *eenMessageHandler) GetChannelIDs() []common.ChannelIDEnum {
+	return []common.ChannelIDEnum{common.ChannelIDEliteEdgeNodeVote}
+}
+
+func (h *eenMessageHandler) ParseMessage(peerID string, channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (p2ptypes.Message, error) {
+	return p2ptypes.Message{PeerID: peerID, ChannelID: channelID}, nil
+}
+
+func (h *eenMessageHandler) EncodeMessage(message interface{}) (common.Bytes, error) {
+	return h.encoder.EncodeMessage(message)
+}
+
+func (h *eenMessageHandler) HandleMessage(message p2ptypes.Message) error {
+	return nil
+}
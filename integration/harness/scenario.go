@@ -0,0 +1,100 @@
+package harness
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dnerochain/dnero/common"
+)
+
+// pollInterval is the interval the scenario checks poll the nodes at
+const pollInterval = 200 * time.Millisecond
+
+// WaitForFinalizedHeight blocks until all the given nodes have finalized the given height. It
+// returns an error with the heights of the lagging nodes if they do not within the timeout.
+func WaitForFinalizedHeight(nodes []*SimNode, height uint64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		lagging := []string{}
+		for _, n := range nodes {
+			if h := n.FinalizedHeight(); h < height {
+				lagging = append(lagging, fmt.Sprintf("%v at height %v", n, h))
+			}
+		}
+		if len(lagging) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Nodes failed to finalize height %v within %v: %v", height, timeout, lagging)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// WaitForProgress blocks until all the given nodes have finalized the given number of blocks on
+// top of the highest block finalized by any of them when called, e.g. to check the network
+// recovers after a partition heals.
+func WaitForProgress(nodes []*SimNode, numBlocks uint64, timeout time.Duration) error {
+	return WaitForFinalizedHeight(nodes, MaxFinalizedHeight(nodes)+numBlocks, timeout)
+}
+
+// MaxFinalizedHeight returns the highest height finalized by any of the given nodes
+func MaxFinalizedHeight(nodes []*SimNode) uint64 {
+	max := uint64(0)
+	for _, n := range nodes {
+		if h := n.FinalizedHeight(); h > max {
+			max = h
+		}
+	}
+	return max
+}
+
+// CheckFinalityConsistency checks the safety of the consensus, i.e. the given nodes have finalized
+// the same block at each height up to the lowest height all of them have finalized.
+func CheckFinalityConsistency(nodes []*SimNode) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	minHeight := nodes[0].FinalizedHeight()
+	for _, n := range nodes[1:] {
+		if h := n.FinalizedHeight(); h < minHeight {
+			minHeight = h
+		}
+	}
+
+	for height := uint64(1); height <= minHeight; height++ {
+		var expected common.Hash
+		var expectedNode *SimNode
+		for _, n := range nodes {
+			hash, ok := n.FinalizedBlockHash(height)
+			if !ok {
+				// Not all the heights have a block, e.g. an epoch without a proposal
+				continue
+			}
+			if expectedNode == nil {
+				expected, expectedNode = hash, n
+				continue
+			}
+			if hash != expected {
+				return fmt.Errorf("Conflicting blocks finalized at height %v: %v by %v, %v by %v",
+					height, expected.Hex(), expectedNode, hash.Hex(), n)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckForkChoice checks each of the given nodes extends its chain from the last finalized block,
+// i.e. its fork choice never reverts the finality.
+func CheckForkChoice(nodes []*SimNode) error {
+	for _, n := range nodes {
+		fullNode := n.Node()
+		lfb := fullNode.Consensus.GetLastFinalizedBlock()
+		tip := fullNode.Consensus.GetTip(true)
+		if !fullNode.Chain.IsDescendant(lfb.Hash(), tip.Hash()) {
+			return fmt.Errorf("The tip %v of %v does not descend from the last finalized block %v",
+				tip.Hash().Hex(), n, lfb.Hash().Hex())
+		}
+	}
+	return nil
+}
@@ -40,10 +40,13 @@ type StakeDeposit struct {
 // pushd $DNERO_HOME/integration/privatenet/node
 // generate_genesis -chainID=privatenet -allocationsnapshot=./data/genesis_dnero_allocation_snapshot.json -stake_deposit=./data/genesis_stake_deposit.json -genesis=./genesis
 //
+// To store a custom fork schedule (e.g. the one written by "dnero init --devnet") in the genesis snapshot:
+// generate_genesis -chainID=privatenet ... -chain_params=./chain_params.json
+//
 func main() {
-	chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath, genesisSnapshotFilePath := parseArguments()
+	chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath, genesisSnapshotFilePath, chainParamsFilePath := parseArguments()
 
-	sv, metadata, err := generateGenesisSnapshot(chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath)
+	sv, metadata, err := generateGenesisSnapshot(chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath, chainParamsFilePath)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to generate genesis snapshot: %v", err))
 	}
@@ -70,29 +73,42 @@ func main() {
 	fmt.Println("")
 }
 
-func parseArguments() (chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath, genesisSnapshotFilePath string) {
+func parseArguments() (chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath, genesisSnapshotFilePath, chainParamsFilePath string) {
 	chainIDPtr := flag.String("chainID", "local_chain", "the ID of the chain")
 	allocationSnapshotJSONFilePathPtr := flag.String("allocationsnapshot", "./dnero_allocation_snapshot.json", "the json file contain the ALLOCATION balance snapshot")
 	stakeDepositFilePathPtr := flag.String("stake_deposit", "./stake_deposit.json", "the initial stake deposits")
 	genesisSnapshotFilePathPtr := flag.String("genesis", "./genesis", "the genesis snapshot")
+	chainParamsFilePathPtr := flag.String("chain_params", "", "the json file contain the fork schedule, mainnet schedule if not specified")
 	flag.Parse()
 
 	chainID = *chainIDPtr
 	allocationSnapshotJSONFilePath = *allocationSnapshotJSONFilePathPtr
 	stakeDepositFilePath = *stakeDepositFilePathPtr
 	genesisSnapshotFilePath = *genesisSnapshotFilePathPtr
+	chainParamsFilePath = *chainParamsFilePathPtr
 
 	return
 }
 
 // generateGenesisSnapshot generates the genesis snapshot.
-func generateGenesisSnapshot(chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath string) (*state.StoreView, *core.SnapshotMetadata, error) {
+func generateGenesisSnapshot(chainID, allocationSnapshotJSONFilePath, stakeDepositFilePath, chainParamsFilePath string) (*state.StoreView, *core.SnapshotMetadata, error) {
 	metadata := &core.SnapshotMetadata{}
 	genesisHeight := core.GenesisBlockHeight
 
 	sv := loadInitialBalances(allocationSnapshotJSONFilePath)
 	performInitialStakeDeposit(stakeDepositFilePath, genesisHeight, sv)
 
+	if chainParamsFilePath != "" {
+		chainParams, err := common.LoadChainParams(chainParamsFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if chainParams.ChainID != chainID {
+			return nil, nil, fmt.Errorf("Chain ID mismatch: chain params are specified for %v, expected %v", chainParams.ChainID, chainID)
+		}
+		sv.UpdateChainParams(chainParams)
+	}
+
 	stateHash := sv.Hash()
 
 	genesisBlock := core.NewBlock()
@@ -7,6 +7,7 @@ import (
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/common/result"
 	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
 	"github.com/dnerochain/dnero/ledger/state"
 	"github.com/dnerochain/dnero/ledger/types"
 )
@@ -162,14 +163,14 @@ func validateInputsBasic(ins []types.TxInput) result.Result {
 }
 
 // Validate inputs and compute total amount of coins
-func validateInputsAdvanced(accounts map[string]*types.Account, signBytes []byte, ins []types.TxInput, blockHeight uint64) (total types.Coins, res result.Result) {
+func validateInputsAdvanced(chainParams *common.ChainParams, viewSel core.ViewSelector, accounts map[string]*types.Account, signBytes []byte, ins []types.TxInput, blockHeight uint64) (total types.Coins, res result.Result) {
 	total = types.NewCoins(0, 0)
 	for _, in := range ins {
 		acc := accounts[string(in.Address[:])]
 		if acc == nil {
 			panic("validateInputsAdvanced() expects account in accounts")
 		}
-		res = validateInputAdvanced(acc, signBytes, in, blockHeight)
+		res = validateInputAdvanced(chainParams, viewSel, acc, signBytes, in, blockHeight)
 		if res.IsError() {
 			return
 		}
@@ -179,7 +180,7 @@ func validateInputsAdvanced(accounts map[string]*types.Account, signBytes []byte
 	return total, result.OK
 }
 
-func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInput, blockHeight uint64) result.Result {
+func validateInputAdvanced(chainParams *common.ChainParams, viewSel core.ViewSelector, acc *types.Account, signBytes []byte, in types.TxInput, blockHeight uint64) result.Result {
 	// Check sequence/coins
 	seq, balance := acc.Sequence, acc.Balance
 	if seq+1 != in.Sequence {
@@ -194,8 +195,11 @@ func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInpu
 	}
 
 	// Check signatures
+	if isUnsignedSimulation(viewSel, in.Signature) {
+		return result.OK
+	}
 	signatureValid := in.Signature.Verify(signBytes, acc.Address)
-	if blockHeight >= common.HeightTxWrapperExtension {
+	if blockHeight >= chainParams.HeightTxWrapperExtension {
 		signBytesV2 := types.ChangeEthereumTxWrapper(signBytes, 2)
 		signatureValid = signatureValid || in.Signature.Verify(signBytesV2, acc.Address)
 	}
@@ -208,6 +212,12 @@ func validateInputAdvanced(acc *types.Account, signBytes []byte, in types.TxInpu
 	return result.OK
 }
 
+// isUnsignedSimulation returns whether the signature is absent from a simulated tx, which is not verified
+// so that the effect of a tx can be previewed before it is signed
+func isUnsignedSimulation(viewSel core.ViewSelector, sig *crypto.Signature) bool {
+	return viewSel == core.SimulatedView && (sig == nil || sig.IsEmpty())
+}
+
 func validateOutputsBasic(outs []types.TxOutput) result.Result {
 	for _, out := range outs {
 		// Check TxOutput basic
@@ -254,12 +264,12 @@ func adjustByOutputs(view *state.StoreView, accounts map[string]*types.Account,
 	}
 }
 
-func sanityCheckForGasPrice(gasPrice *big.Int, blockHeight uint64) bool {
+func sanityCheckForGasPrice(chainParams *common.ChainParams, gasPrice *big.Int, blockHeight uint64) bool {
 	if gasPrice == nil {
 		return false
 	}
 
-	minimumGasPrice := types.GetMinimumGasPrice(blockHeight)
+	minimumGasPrice := types.GetMinimumGasPrice(chainParams, blockHeight)
 	if gasPrice.Cmp(minimumGasPrice) < 0 {
 		return false
 	}
@@ -267,17 +277,17 @@ func sanityCheckForGasPrice(gasPrice *big.Int, blockHeight uint64) bool {
 	return true
 }
 
-func sanityCheckForFee(fee types.Coins, blockHeight uint64) (minimumFee *big.Int, success bool) {
+func sanityCheckForFee(chainParams *common.ChainParams, fee types.Coins, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetMinimumTransactionFeeDTokenWei(blockHeight)
+	minimumFee = types.GetMinimumTransactionFeeDTokenWei(chainParams, blockHeight)
 	success = (fee.DneroWei.Cmp(types.Zero) == 0 && fee.DTokenWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
 }
 
-func sanityCheckForSendTxFee(fee types.Coins, numAccountsAffected uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
+func sanityCheckForSendTxFee(chainParams *common.ChainParams, fee types.Coins, numAccountsAffected uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetSendTxMinimumTransactionFeeDTokenWei(numAccountsAffected, blockHeight)
+	minimumFee = types.GetSendTxMinimumTransactionFeeDTokenWei(chainParams, numAccountsAffected, blockHeight)
 	success = (fee.DneroWei.Cmp(types.Zero) == 0 && fee.DTokenWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
@@ -299,7 +309,7 @@ func getBlockHeight(ledgerState *state.LedgerState) uint64 {
 
 func getRegularTxGas(ledgerState *state.LedgerState) uint64 {
 	blockHeight := getBlockHeight(ledgerState)
-	if blockHeight < common.HeightNewFeeAdjustment {
+	if blockHeight < ledgerState.ChainParams().HeightNewFeeAdjustment {
 		return types.GasRegularTx
 	}
 	return types.GasRegularTxNewFee
@@ -45,6 +45,7 @@ type Executor struct {
 	depositStakeTxExec            *DepositStakeExecutor
 	withdrawStakeTxExec           *WithdrawStakeExecutor
 	stakeRewardDistributionTxExec *StakeRewardDistributionTxExecutor
+	equivocationSlashTxExec       *EquivocationSlashTxExecutor
 
 	skipSanityCheck bool
 }
@@ -68,6 +69,7 @@ func NewExecutor(db database.Database, chain *blockchain.Chain, state *st.Ledger
 		depositStakeTxExec:            NewDepositStakeExecutor(state),
 		withdrawStakeTxExec:           NewWithdrawStakeExecutor(state),
 		stakeRewardDistributionTxExec: NewStakeRewardDistributionTxExecutor(state),
+		equivocationSlashTxExec:       NewEquivocationSlashTxExecutor(state, consensus, valMgr),
 		skipSanityCheck:               false,
 	}
 
@@ -95,6 +97,57 @@ func (exec *Executor) ScreenTx(tx types.Tx) (common.Hash, result.Result) {
 	return exec.processTx(tx, core.ScreenedView)
 }
 
+// ScreenTxWithPrecheck checks the validity of the given transaction like ScreenTx. The precheck, if any, is
+// called after the sanity check, and the screened view is left untouched if the precheck fails.
+func (exec *Executor) ScreenTxWithPrecheck(tx types.Tx, precheck core.TxPrecheck) (*core.TxInfo, result.Result) {
+	chainID := exec.state.GetChainID()
+	view := exec.state.Screened()
+
+	sanityCheckResult := exec.sanityCheck(chainID, view, core.ScreenedView, tx)
+	if sanityCheckResult.IsError() {
+		return nil, sanityCheckResult
+	}
+
+	txInfo, res := exec.GetTxInfo(tx)
+	if res.IsError() {
+		return nil, res
+	}
+
+	if precheck != nil {
+		if err := precheck(txInfo); err != nil {
+			return nil, result.Error("Tx precheck failed: %v", err)
+		}
+	}
+
+	_, processResult := exec.process(chainID, view, core.ScreenedView, tx)
+	if processResult.IsError() {
+		return nil, processResult
+	}
+
+	return txInfo, processResult
+}
+
+// ScreenReplacementTx checks the validity of the given transaction, which replaces a pending transaction
+// of the same sequence. The transaction is checked against a copy of the screened view where the account
+// sequence is rewound to right before the replaced transaction, and the screened view is left untouched.
+// Note that the balance of the account is not rewound, so the check is stricter than necessary.
+func (exec *Executor) ScreenReplacementTx(tx types.Tx, txInfo *core.TxInfo) result.Result {
+	view, err := exec.state.Screened().Copy()
+	if err != nil {
+		return result.Error("Failed to copy the screened view: %v", err)
+	}
+
+	account := view.GetAccount(txInfo.Address)
+	if account == nil || txInfo.Sequence == 0 || account.Sequence < txInfo.Sequence {
+		return result.Error("No pending transaction with sequence %v to replace", txInfo.Sequence).
+			WithErrorCode(result.CodeInvalidSequence)
+	}
+	account.Sequence = txInfo.Sequence - 1
+	view.SetAccount(txInfo.Address, account)
+
+	return exec.sanityCheck(exec.state.GetChainID(), view, core.ScreenedView, tx)
+}
+
 // GetTxInfo extracts tx information used by mempool to sort Txs.
 func (exec *Executor) GetTxInfo(tx types.Tx) (*core.TxInfo, result.Result) {
 	txExecutor := exec.getTxExecutor(tx)
@@ -171,14 +224,19 @@ func (exec *Executor) process(chainID string, view *st.StoreView, viewSel core.V
 
 func (exec *Executor) isTxTypeSupported(view *st.StoreView, tx types.Tx) bool {
 	blockHeight := view.Height() + 1
+	chainParams := exec.state.ChainParams()
 
 	switch tx.(type) {
 	case *types.SmartContractTx:
-		if blockHeight < common.HeightEnableSmartContract {
+		if blockHeight < chainParams.HeightEnableSmartContract {
 			return false
 		}
 	case *types.StakeRewardDistributionTx:
-		if blockHeight < common.HeightEnableDneroV2 {
+		if blockHeight < chainParams.HeightEnableDneroV2 {
+			return false
+		}
+	case *types.EquivocationSlashTx:
+		if blockHeight < chainParams.HeightEnableEquivocationSlashing {
 			return false
 		}
 	default:
@@ -215,6 +273,8 @@ func (exec *Executor) getTxExecutor(tx types.Tx) TxExecutor {
 		txExecutor = exec.depositStakeTxExec
 	case *types.StakeRewardDistributionTx:
 		txExecutor = exec.stakeRewardDistributionTxExec
+	case *types.EquivocationSlashTx:
+		txExecutor = exec.equivocationSlashTxExec
 	default:
 		txExecutor = nil
 	}
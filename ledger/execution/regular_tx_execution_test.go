@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/common/result"
+	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/ledger/types"
 )
 
@@ -134,12 +135,12 @@ func TestValidateInputsAdvanced(t *testing.T) {
 	signBytes := tx.SignBytes(et.chainID)
 
 	//test bad case, unsigned
-	totalCoins, res := validateInputsAdvanced(accMap, signBytes, tx.Inputs, 1)
+	totalCoins, res := validateInputsAdvanced(et.state().ChainParams(), core.CheckedView, accMap, signBytes, tx.Inputs, 1)
 	assert.True(res.IsError(), "validateInputsAdvanced: expected an error on an unsigned tx input")
 
 	//test good case sgined
 	et.signSendTx(tx, accIn1, accIn2, accIn3, et.accOut)
-	totalCoins, res = validateInputsAdvanced(accMap, signBytes, tx.Inputs, 1)
+	totalCoins, res = validateInputsAdvanced(et.state().ChainParams(), core.CheckedView, accMap, signBytes, tx.Inputs, 1)
 	assert.True(res.IsOK(), "validateInputsAdvanced: expected no error on good tx input. Error: %v", res.Message)
 
 	txTotalCoins := tx.Inputs[0].Coins.
@@ -161,25 +162,25 @@ func TestValidateInputAdvanced(t *testing.T) {
 	signBytes := tx.SignBytes(et.chainID)
 
 	//unsigned case
-	res := validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res := validateInputAdvanced(et.state().ChainParams(), core.CheckedView, &et.accIn.Account, signBytes, tx.Inputs[0], 1)
 	assert.True(res.IsError(), "validateInputAdvanced: expected error on tx input without signature")
 
 	//good signed case
 	et.signSendTx(tx, et.accIn, et.accOut)
-	res = validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res = validateInputAdvanced(et.state().ChainParams(), core.CheckedView, &et.accIn.Account, signBytes, tx.Inputs[0], 1)
 	assert.True(res.IsOK(), "validateInputAdvanced: expected no error on good tx input. Error: %v", res.Message)
 
 	//bad sequence case
 	et.accIn.Sequence = 1
 	et.signSendTx(tx, et.accIn, et.accOut)
-	res = validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res = validateInputAdvanced(et.state().ChainParams(), core.CheckedView, &et.accIn.Account, signBytes, tx.Inputs[0], 1)
 	assert.Equal(result.CodeInvalidSequence, res.Code, "validateInputAdvanced: expected error on tx input with bad sequence")
 	et.accIn.Sequence = 0 //restore sequence
 
 	//bad balance case
 	et.accIn.Balance = types.NewCoins(2, 0)
 	et.signSendTx(tx, et.accIn, et.accOut)
-	res = validateInputAdvanced(&et.accIn.Account, signBytes, tx.Inputs[0], 1)
+	res = validateInputAdvanced(et.state().ChainParams(), core.CheckedView, &et.accIn.Account, signBytes, tx.Inputs[0], 1)
 	assert.Equal(result.CodeInsufficientFund, res.Code,
 		"validateInputAdvanced: expected error on tx input with insufficient funds %v", et.accIn.Sequence)
 }
@@ -544,7 +545,7 @@ func TestReserveFundTx(t *testing.T) {
 		Duration:    1000,
 	}
 	tx.Source.Signature = user1.Sign(tx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), tx)
+	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, tx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeReservedFundNotSpecified)
 
@@ -561,7 +562,7 @@ func TestReserveFundTx(t *testing.T) {
 		Duration:    1000,
 	}
 	tx.Source.Signature = user1.Sign(tx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), tx)
+	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, tx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeInsufficientFund)
 
@@ -578,7 +579,7 @@ func TestReserveFundTx(t *testing.T) {
 		Duration:    1000,
 	}
 	tx.Source.Signature = user1.Sign(tx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), tx)
+	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, tx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeReserveFundCheckFailed, res.Message)
 
@@ -595,9 +596,9 @@ func TestReserveFundTx(t *testing.T) {
 		Duration:    1000,
 	}
 	tx.Source.Signature = user1.Sign(tx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), tx)
+	res = et.executor.getTxExecutor(tx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, tx)
 	assert.True(res.IsOK(), res.String())
-	_, res = et.executor.getTxExecutor(tx).process(et.chainID, et.state().Delivered(), tx)
+	_, res = et.executor.getTxExecutor(tx).process(et.chainID, et.state().Delivered(), core.DeliveredView, tx)
 	assert.True(res.IsOK(), res.String())
 
 	retrievedUserAcc := et.state().Delivered().GetAccount(user1.Address)
@@ -636,9 +637,9 @@ func TestReleaseFundTx(t *testing.T) {
 		Duration:    1000,
 	}
 	reserveFundTx.Source.Signature = user1.Sign(reserveFundTx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(reserveFundTx).sanityCheck(et.chainID, et.state().Delivered(), reserveFundTx)
+	res = et.executor.getTxExecutor(reserveFundTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, reserveFundTx)
 	assert.True(res.IsOK(), res.String())
-	_, res = et.executor.getTxExecutor(reserveFundTx).process(et.chainID, et.state().Delivered(), reserveFundTx)
+	_, res = et.executor.getTxExecutor(reserveFundTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, reserveFundTx)
 	assert.True(res.IsOK(), res.String())
 
 	et.state().Commit()
@@ -653,7 +654,7 @@ func TestReleaseFundTx(t *testing.T) {
 		ReserveSequence: 1,
 	}
 	releaseFundTx.Source.Signature = user1.Sign(releaseFundTx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), releaseFundTx)
+	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, releaseFundTx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeInvalidFee, res.String())
 
@@ -666,7 +667,7 @@ func TestReleaseFundTx(t *testing.T) {
 		ReserveSequence: 1,
 	}
 	releaseFundTx.Source.Signature = user1.Sign(releaseFundTx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), releaseFundTx)
+	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, releaseFundTx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeInvalidFee, res.String())
 
@@ -680,7 +681,7 @@ func TestReleaseFundTx(t *testing.T) {
 		ReserveSequence: 1,
 	}
 	releaseFundTx.Source.Signature = user1.Sign(releaseFundTx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), releaseFundTx)
+	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, releaseFundTx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeReleaseFundCheckFailed, res.String())
 
@@ -696,7 +697,7 @@ func TestReleaseFundTx(t *testing.T) {
 		ReserveSequence: 99,
 	}
 	releaseFundTx.Source.Signature = user1.Sign(releaseFundTx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), releaseFundTx)
+	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, releaseFundTx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeReleaseFundCheckFailed, res.String())
 
@@ -715,7 +716,7 @@ func TestReleaseFundTx(t *testing.T) {
 		ReserveSequence: 1,
 	}
 	releaseFundTx.Source.Signature = user1.Sign(releaseFundTx.SignBytes(et.chainID))
-	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), releaseFundTx)
+	res = et.executor.getTxExecutor(releaseFundTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, releaseFundTx)
 	assert.False(res.IsOK(), res.String())
 	assert.Equal(res.Code, result.CodeReleaseFundCheckFailed, res.String())
 }
@@ -739,9 +740,9 @@ func TestServicePaymentTxNormalExecutionAndSlash(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 10*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 50*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx1 := createServicePaymentTx(et.chainID, &alice, &bob, payAmount1, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res := et.executor.getTxExecutor(servicePaymentTx1).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx1)
+	res := et.executor.getTxExecutor(servicePaymentTx1).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx1)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(servicePaymentTx1).process(et.chainID, et.state().Delivered(), servicePaymentTx1)
+	_, res = et.executor.getTxExecutor(servicePaymentTx1).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx1)
 	assert.True(res.IsOK(), res.Message)
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
 
@@ -758,9 +759,9 @@ func TestServicePaymentTxNormalExecutionAndSlash(t *testing.T) {
 	srcSeq, tgtSeq, paymentSeq, reserveSeq = 1, 2, 2, 1
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 30*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx2 := createServicePaymentTx(et.chainID, &alice, &bob, payAmount2, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx2).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx2)
+	res = et.executor.getTxExecutor(servicePaymentTx2).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx2)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(servicePaymentTx2).process(et.chainID, et.state().Delivered(), servicePaymentTx2)
+	_, res = et.executor.getTxExecutor(servicePaymentTx2).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx2)
 	assert.True(res.IsOK(), res.Message)
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
 
@@ -776,9 +777,9 @@ func TestServicePaymentTxNormalExecutionAndSlash(t *testing.T) {
 	srcSeq, tgtSeq, paymentSeq, reserveSeq = 1, 1, 3, 1
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 30*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx3 := createServicePaymentTx(et.chainID, &alice, &carol, payAmount3, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx3).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx3)
+	res = et.executor.getTxExecutor(servicePaymentTx3).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx3)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(servicePaymentTx3).process(et.chainID, et.state().Delivered(), servicePaymentTx3)
+	_, res = et.executor.getTxExecutor(servicePaymentTx3).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx3)
 	assert.True(res.IsOK(), res.Message)
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
 
@@ -794,11 +795,11 @@ func TestServicePaymentTxNormalExecutionAndSlash(t *testing.T) {
 	srcSeq, tgtSeq, paymentSeq, reserveSeq = 1, 2, 4, 1
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 70000*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx4 := createServicePaymentTx(et.chainID, &alice, &carol, payAmount4, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx4).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx4)
+	res = et.executor.getTxExecutor(servicePaymentTx4).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx4)
 	assert.True(res.IsOK(), res.Message) // the following process() call will create an SlashIntent
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx4).process(et.chainID, et.state().Delivered(), servicePaymentTx4)
+	_, res = et.executor.getTxExecutor(servicePaymentTx4).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx4)
 	assert.True(res.IsOK(), res.Message)
 	//assert.Equal(1, len(et.state().Delivered().GetSlashIntents()))
 }
@@ -822,9 +823,9 @@ func TestServicePaymentTxExpiration(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 10*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 50*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx1 := createServicePaymentTx(et.chainID, &alice, &bob, payAmount1, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res := et.executor.getTxExecutor(servicePaymentTx1).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx1)
+	res := et.executor.getTxExecutor(servicePaymentTx1).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx1)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(servicePaymentTx1).process(et.chainID, et.state().Delivered(), servicePaymentTx1)
+	_, res = et.executor.getTxExecutor(servicePaymentTx1).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx1)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -841,7 +842,7 @@ func TestServicePaymentTxExpiration(t *testing.T) {
 	srcSeq, tgtSeq, paymentSeq, reserveSeq = 1, 2, 2, 1
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 30*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx2 := createServicePaymentTx(et.chainID, &alice, &bob, payAmount2, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx2).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx2)
+	res = et.executor.getTxExecutor(servicePaymentTx2).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx2)
 	assert.False(res.IsOK(), res.Message)
 	assert.Equal(result.CodeCheckTransferReservedFundFailed, res.Code)
 	log.Infof("Service payment check message: %v", res.Message)
@@ -943,9 +944,9 @@ func TestSplitRuleTxNormalExecution(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -954,11 +955,11 @@ func TestSplitRuleTxNormalExecution(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 100*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 500*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &bob, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1005,9 +1006,9 @@ func TestSplitRuleTxExpiration(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.fastforwardBy(105) // The split rule should expire after the fastforward
@@ -1018,11 +1019,11 @@ func TestSplitRuleTxExpiration(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 100, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &bob, 500, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &bob, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1071,9 +1072,9 @@ func TestSplitRuleTxUpdate(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	splitRule := et.executor.state.Delivered().GetSplitRule(resourceID)
@@ -1095,10 +1096,10 @@ func TestSplitRuleTxUpdate(t *testing.T) {
 	signBytes = fakeSplitRuleUpdateTx.SignBytes(et.chainID)
 	fakeSplitRuleUpdateTx.Initiator.Signature = fakeInitiator.Sign(signBytes)
 
-	res = et.executor.getTxExecutor(fakeSplitRuleUpdateTx).sanityCheck(et.chainID, et.state().Delivered(), fakeSplitRuleUpdateTx)
+	res = et.executor.getTxExecutor(fakeSplitRuleUpdateTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, fakeSplitRuleUpdateTx)
 	assert.False(res.IsOK(), res.Message)
 	assert.Equal(result.CodeUnauthorizedToUpdateSplitRule, res.Code)
-	_, res = et.executor.getTxExecutor(fakeSplitRuleUpdateTx).process(et.chainID, et.state().Delivered(), fakeSplitRuleUpdateTx)
+	_, res = et.executor.getTxExecutor(fakeSplitRuleUpdateTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, fakeSplitRuleUpdateTx)
 	assert.False(res.IsOK(), res.Message)
 	assert.Equal(result.CodeUnauthorizedToUpdateSplitRule, res.Code)
 
@@ -1123,9 +1124,9 @@ func TestSplitRuleTxUpdate(t *testing.T) {
 	signBytes = splitRuleUpdateTx.SignBytes(et.chainID)
 	splitRuleUpdateTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res = et.executor.getTxExecutor(splitRuleUpdateTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleUpdateTx)
+	res = et.executor.getTxExecutor(splitRuleUpdateTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleUpdateTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleUpdateTx).process(et.chainID, et.state().Delivered(), splitRuleUpdateTx)
+	_, res = et.executor.getTxExecutor(splitRuleUpdateTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleUpdateTx)
 	assert.True(res.IsOK(), res.Message)
 
 	splitRule2 := et.executor.state.Delivered().GetSplitRule(resourceID)
@@ -1218,9 +1219,9 @@ func TestSplitRuleTxTargetAddressAlsoSplits(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1231,11 +1232,11 @@ func TestSplitRuleTxTargetAddressAlsoSplits(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 100*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 500*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1313,9 +1314,9 @@ func TestSplitRuleTxManyDups(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1324,11 +1325,11 @@ func TestSplitRuleTxManyDups(t *testing.T) {
 
 	// Alice send the service payment to Carol, whose address is included in the split address list
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1403,9 +1404,9 @@ func TestSplitRuleTxSmallAmount(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1414,13 +1415,13 @@ func TestSplitRuleTxSmallAmount(t *testing.T) {
 
 	// Alice send the service payment to Carol, whose address is included in the split address list
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	log.Infof("Payment amount: %v", payAmount)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1509,9 +1510,9 @@ func TestSplitRuleTxSmallPercentage(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1520,13 +1521,13 @@ func TestSplitRuleTxSmallPercentage(t *testing.T) {
 
 	// Alice send the service payment to Carol, whose address is included in the split address list
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	log.Infof("Payment amount: %v", payAmount)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1599,9 +1600,9 @@ func TestSplitRuleHundredPercSplits(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1612,11 +1613,11 @@ func TestSplitRuleHundredPercSplits(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 100*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 500*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1681,7 +1682,7 @@ func TestSplitRuleOverHundredPercSplits(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.False(res.IsOK(), res.Message) // should be rejected
 }
 
@@ -1716,9 +1717,9 @@ func TestSplitRuleSingleHundredPercSplits(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1729,11 +1730,11 @@ func TestSplitRuleSingleHundredPercSplits(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 100*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 500*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1796,9 +1797,9 @@ func TestSplitRuleSplitZeroPercSplits(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1809,11 +1810,11 @@ func TestSplitRuleSplitZeroPercSplits(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 100*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 500*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1868,9 +1869,9 @@ func TestSplitRuleSplitEmptyRule(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1881,11 +1882,11 @@ func TestSplitRuleSplitEmptyRule(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 100*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 500*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -1950,9 +1951,9 @@ func TestSplitRuleSplitZeroPayment(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -1963,11 +1964,11 @@ func TestSplitRuleSplitZeroPayment(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 0, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 0, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -2033,9 +2034,9 @@ func TestSplitRuleTxSplitPaymentRounding(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 
 	// Simulate micropayment #1 between Alice and Bob, Carol should get a cut
@@ -2046,11 +2047,11 @@ func TestSplitRuleTxSplitPaymentRounding(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 100, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 500, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -2119,9 +2120,9 @@ func TestSplitRuleExpiration(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 	et.state().Commit()
 
@@ -2145,11 +2146,11 @@ func TestSplitRuleExpiration(t *testing.T) {
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 100*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	_ = createServicePaymentTx(et.chainID, &alice, &carol, 500*txFee, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
 	servicePaymentTx := createServicePaymentTx(et.chainID, &alice, &carol, payAmount, srcSeq, tgtSeq, paymentSeq, reserveSeq, resourceID)
-	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), servicePaymentTx)
+	res = et.executor.getTxExecutor(servicePaymentTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	assert.Equal(0, len(et.state().Delivered().GetSlashIntents()))
-	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), servicePaymentTx)
+	_, res = et.executor.getTxExecutor(servicePaymentTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, servicePaymentTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -2217,9 +2218,9 @@ func TestSplitRuleZeroDuration(t *testing.T) {
 	signBytes := splitRuleTx.SignBytes(et.chainID)
 	splitRuleTx.Initiator.Signature = initiator.Sign(signBytes)
 
-	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx)
+	res := et.executor.getTxExecutor(splitRuleTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), splitRuleTx)
+	_, res = et.executor.getTxExecutor(splitRuleTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx)
 	assert.True(res.IsOK(), res.Message)
 	et.state().Commit()
 
@@ -2242,9 +2243,9 @@ func TestSplitRuleZeroDuration(t *testing.T) {
 	signBytes2 := splitRuleTx2.SignBytes(et.chainID)
 	splitRuleTx2.Initiator.Signature = initiator.Sign(signBytes2)
 
-	res = et.executor.getTxExecutor(splitRuleTx2).sanityCheck(et.chainID, et.state().Delivered(), splitRuleTx2)
+	res = et.executor.getTxExecutor(splitRuleTx2).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx2)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(splitRuleTx2).process(et.chainID, et.state().Delivered(), splitRuleTx2)
+	_, res = et.executor.getTxExecutor(splitRuleTx2).process(et.chainID, et.state().Delivered(), core.DeliveredView, splitRuleTx2)
 	assert.True(res.IsOK(), res.Message)
 	et.state().Commit()
 
@@ -0,0 +1,160 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/result"
+	"github.com/dnerochain/dnero/core"
+	st "github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+)
+
+// ------------------------------- Transaction Simulation -----------------------------------
+
+//
+// SimulationResult is the effect of a simulated transaction
+//
+type SimulationResult struct {
+	TxHash          common.Hash
+	Result          result.Result
+	GasUsed         uint64
+	Logs            []*types.Log
+	BalanceChanges  []*types.BalanceChange
+	Accounts        []*types.Account // the resulting states of the accounts involved
+	EvmReturn       common.Bytes
+	EvmError        error
+	ContractAddress common.Address
+}
+
+// SimulateTx executes the transaction on the given view as if it is included in the next block. The
+// view is modified but never committed, the caller is expected to pass in a copy. The signatures are
+// only verified if present, so the effect of a transaction can be previewed before it is signed.
+func (exec *Executor) SimulateTx(view *st.StoreView, tx types.Tx) *SimulationResult {
+	chainID := exec.state.GetChainID()
+	sim := &SimulationResult{}
+
+	// The balance changes are the differences to the original view
+	original, err := view.Copy()
+	if err != nil {
+		sim.Result = result.Error("Failed to copy the view: %v", err)
+		return sim
+	}
+
+	txExecutor := exec.getTxExecutor(tx)
+	if txExecutor == nil {
+		sim.Result = result.Error("Unknown tx type")
+		return sim
+	}
+	if !exec.isTxTypeSupported(view, tx) {
+		sim.Result = result.Error("tx type not supported yet")
+		return sim
+	}
+	sim.Result = txExecutor.sanityCheck(chainID, view, core.SimulatedView, tx)
+	if sim.Result.IsError() {
+		return sim
+	}
+	sim.TxHash, sim.Result = txExecutor.process(chainID, view, core.SimulatedView, tx)
+	if sim.Result.IsError() {
+		return sim
+	}
+
+	switch tx := tx.(type) {
+	case *types.SmartContractTx:
+		info := sim.Result.Info
+		sim.GasUsed, _ = info["gasUsed"].(uint64)
+		sim.Logs, _ = info["logs"].([]*types.Log)
+		sim.EvmReturn, _ = info["evmReturn"].(common.Bytes)
+		sim.EvmError, _ = info["evmError"].(error)
+		sim.ContractAddress, _ = info["contractAddress"].(common.Address)
+
+	case *types.SendTx:
+		sim.GasUsed = getSendTxGas(exec.state, tx)
+	default:
+		sim.GasUsed = getRegularTxGas(exec.state)
+	}
+
+	sim.BalanceChanges = []*types.BalanceChange{}
+	for _, address := range involvedAddresses(tx, sim) {
+		if account := view.GetAccount(address); account != nil {
+			sim.Accounts = append(sim.Accounts, account)
+		}
+
+		before, after := getBalance(original, address), getBalance(view, address)
+		sim.BalanceChanges = appendBalanceChange(sim.BalanceChanges, address, 0, before.DneroWei, after.DneroWei)
+		sim.BalanceChanges = appendBalanceChange(sim.BalanceChanges, address, 1, before.DTokenWei, after.DTokenWei)
+	}
+
+	return sim
+}
+
+// involvedAddresses returns the accounts the transaction spends from or pays to, including the accounts
+// the smart contract transfers the coins to, and the contract deployed
+func involvedAddresses(tx types.Tx, sim *SimulationResult) []common.Address {
+	addresses := []common.Address{}
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		for _, in := range tx.Inputs {
+			addresses = append(addresses, in.Address)
+		}
+		for _, out := range tx.Outputs {
+			addresses = append(addresses, out.Address)
+		}
+	case *types.ReserveFundTx:
+		addresses = append(addresses, tx.Source.Address)
+	case *types.ReleaseFundTx:
+		addresses = append(addresses, tx.Source.Address)
+	case *types.ServicePaymentTx:
+		addresses = append(addresses, tx.Source.Address, tx.Target.Address)
+	case *types.SplitRuleTx:
+		addresses = append(addresses, tx.Initiator.Address)
+	case *types.SmartContractTx:
+		addresses = append(addresses, tx.From.Address, tx.To.Address, sim.ContractAddress)
+		evmBalanceChanges, _ := sim.Result.Info["balanceChanges"].([]*types.BalanceChange)
+		for _, bc := range evmBalanceChanges {
+			addresses = append(addresses, bc.Address)
+		}
+	case *types.DepositStakeTx:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.DepositStakeTxV1:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.WithdrawStakeTx:
+		addresses = append(addresses, tx.Source.Address, tx.Holder.Address)
+	case *types.StakeRewardDistributionTx:
+		addresses = append(addresses, tx.Holder.Address, tx.Beneficiary.Address)
+	}
+
+	// Deduplicated, in the order of appearance
+	seen := make(map[common.Address]bool)
+	unique := []common.Address{}
+	for _, address := range addresses {
+		if seen[address] || (address == common.Address{}) {
+			continue
+		}
+		seen[address] = true
+		unique = append(unique, address)
+	}
+	return unique
+}
+
+func getBalance(view *st.StoreView, address common.Address) types.Coins {
+	account := view.GetAccount(address)
+	if account == nil {
+		return types.NewCoins(0, 0)
+	}
+	return account.Balance.NoNil()
+}
+
+// appendBalanceChange appends the change of the balance of the token type, i.e. dnero=0, dtoken=1, if any
+func appendBalanceChange(changes []*types.BalanceChange, address common.Address, tokenType uint, before, after *big.Int) []*types.BalanceChange {
+	delta := new(big.Int).Sub(after, before)
+	if delta.Sign() == 0 {
+		return changes
+	}
+	return append(changes, &types.BalanceChange{
+		Address:    address,
+		TokenType:  tokenType,
+		IsNegative: delta.Sign() < 0,
+		Delta:      delta.Abs(delta),
+	})
+}
@@ -274,7 +274,7 @@ func deploySmartContract(et *execTest, deployerPrivAcc *types.PrivAccount,
 	parentBlock := &core.Block{
 		BlockHeader: &core.BlockHeader{
 			Height:    1,
-			Timestamp: 1601599331,
+			Timestamp: big.NewInt(1601599331),
 		},
 	}
 	stateCopy, err := et.state().Delivered().Copy()
@@ -284,9 +284,9 @@ func deploySmartContract(et *execTest, deployerPrivAcc *types.PrivAccount,
 	log.Infof("[Deployment] gas used: %v", gasUsed)
 
 	// The actual on-chain deplpoyment
-	res := et.executor.getTxExecutor(deploySCTx).sanityCheck(et.chainID, et.state().Delivered(), deploySCTx)
+	res := et.executor.getTxExecutor(deploySCTx).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, deploySCTx)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(deploySCTx).process(et.chainID, et.state().Delivered(), deploySCTx)
+	_, res = et.executor.getTxExecutor(deploySCTx).process(et.chainID, et.state().Delivered(), core.DeliveredView, deploySCTx)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -340,10 +340,10 @@ func callSmartContract(et *execTest, contractAddr common.Address, callerPrivAcc
 	parentBlock := &core.Block{
 		BlockHeader: &core.BlockHeader{
 			Height:    1,
-			Timestamp: 1601599331,
+			Timestamp: big.NewInt(1601599331),
 		},
 	}
-	vmRet, execContractAddr, gasUsed, vmErr := vm.Execute(callSCTX, stateCopy)
+	vmRet, execContractAddr, gasUsed, vmErr := vm.Execute(parentBlock, callSCTX, stateCopy)
 	assert.Equal(contractAddr, execContractAddr)
 	log.Infof("[Call      ] gas used: %v", gasUsed)
 
@@ -373,9 +373,9 @@ func executeSmartContract(et *execTest, contractAddr common.Address, callerPrivA
 	execSCTX.From.Signature = callerPrivAcc.Sign(signBytes)
 
 	// Execute the on-chain smart contract
-	res := et.executor.getTxExecutor(execSCTX).sanityCheck(et.chainID, et.state().Delivered(), execSCTX)
+	res := et.executor.getTxExecutor(execSCTX).sanityCheck(et.chainID, et.state().Delivered(), core.DeliveredView, execSCTX)
 	assert.True(res.IsOK(), res.Message)
-	_, res = et.executor.getTxExecutor(execSCTX).process(et.chainID, et.state().Delivered(), execSCTX)
+	_, res = et.executor.getTxExecutor(execSCTX).process(et.chainID, et.state().Delivered(), core.DeliveredView, execSCTX)
 	assert.True(res.IsOK(), res.Message)
 
 	et.state().Commit()
@@ -98,7 +98,7 @@ func (exec *CoinbaseTxExecutor) sanityCheck(chainID string, view *st.StoreView,
 	sentryVotes := currentBlock.SentryVotes
 	eliteEdgeNodeVotes := currentBlock.EliteEdgeNodeVotes
 	sentryPool, eliteEdgeNodePool := RetrievePools(exec.consensus.GetLedger(), exec.chain, exec.db, tx.BlockHeight, sentryVotes, eliteEdgeNodeVotes)
-	expectedRewards = CalculateReward(exec.consensus.GetLedger(), view, validatorSet, sentryVotes, sentryPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
+	expectedRewards = CalculateReward(exec.consensus.GetLedger(), view, exec.state.ChainParams(), validatorSet, sentryVotes, sentryPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
 
 	if len(expectedRewards) != len(tx.Outputs) {
 		return result.Error("Number of rewarded account is incorrect")
@@ -145,10 +145,11 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 	sentryPool = nil
 	eliteEdgeNodePool = nil
 
-	if blockHeight < common.HeightEnableDneroV1 {
+	chainParams := common.GetChainParams(chain.ChainID)
+	if blockHeight < chainParams.HeightEnableDneroV1 {
 		sentryPool = nil
 		eliteEdgeNodePool = nil
-	} else if blockHeight < common.HeightEnableDneroV2 {
+	} else if blockHeight < chainParams.HeightEnableDneroV2 {
 		if sentryVotes != nil {
 			guradianVoteBlock, err := chain.FindBlock(sentryVotes.Block)
 			if err != nil {
@@ -157,7 +158,7 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 			storeView := st.NewStoreView(guradianVoteBlock.Height, guradianVoteBlock.StateHash, db)
 			sentryPool = storeView.GetSentryCandidatePool()
 		}
-	} else { // blockHeight >= common.HeightEnableDneroV2
+	} else { // blockHeight >= chainParams.HeightEnableDneroV2
 		// won't reward the elite edge nodes without the sentry votes, since we need to sentry votes to confirm that
 		// the edge nodes vote for the correct checkpoint
 		if sentryVotes != nil {
@@ -185,20 +186,20 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 }
 
 // CalculateReward calculates the block reward for each account
-func CalculateReward(ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet,
+func CalculateReward(ledger core.Ledger, view *st.StoreView, chainParams *common.ChainParams, validatorSet *core.ValidatorSet,
 	sentryVotes *core.AggregatedVotes, sentryPool *core.SentryCandidatePool,
 	eliteEdgeNodeVotes *core.AggregatedEENVotes, eliteEdgeNodePool core.EliteEdgeNodePool) map[string]types.Coins {
 	accountReward := map[string]types.Coins{}
 	blockHeight := view.Height() + 1 // view points to the parent block
-	if blockHeight < common.HeightEnableValidatorReward {
+	if blockHeight < chainParams.HeightEnableValidatorReward {
 		grantValidatorsWithZeroReward(validatorSet, &accountReward)
-	} else if blockHeight < common.HeightEnableDneroV1 || sentryVotes == nil || sentryPool == nil {
+	} else if blockHeight < chainParams.HeightEnableDneroV1 || sentryVotes == nil || sentryPool == nil {
 		grantValidatorReward(ledger, view, validatorSet, &accountReward, blockHeight)
-	} else if blockHeight < common.HeightEnableDneroV2 {
-		grantValidatorAndSentryReward(ledger, view, validatorSet, sentryVotes, sentryPool, &accountReward, blockHeight)
-	} else { // blockHeight >= common.HeightEnableDneroV2
-		grantValidatorAndSentryReward(ledger, view, validatorSet, sentryVotes, sentryPool, &accountReward, blockHeight)
-		grantEliteEdgeNodeReward(ledger, view, sentryVotes, eliteEdgeNodeVotes, eliteEdgeNodePool, &accountReward, blockHeight)
+	} else if blockHeight < chainParams.HeightEnableDneroV2 {
+		grantValidatorAndSentryReward(ledger, view, validatorSet, sentryVotes, sentryPool, &accountReward, chainParams, blockHeight)
+	} else { // blockHeight >= chainParams.HeightEnableDneroV2
+		grantValidatorAndSentryReward(ledger, view, validatorSet, sentryVotes, sentryPool, &accountReward, chainParams, blockHeight)
+		grantEliteEdgeNodeReward(ledger, view, sentryVotes, eliteEdgeNodeVotes, eliteEdgeNodePool, &accountReward, chainParams, blockHeight)
 	}
 
 	addrs := []string{}
@@ -282,7 +283,7 @@ func grantValidatorReward(ledger core.Ledger, view *st.StoreView, validatorSet *
 
 // grant block rewards to both the validators and active sentrys (they are both dnero stakers)
 func grantValidatorAndSentryReward(ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet, sentryVotes *core.AggregatedVotes,
-	sentryPool *core.SentryCandidatePool, accountReward *map[string]types.Coins, blockHeight uint64) {
+	sentryPool *core.SentryCandidatePool, accountReward *map[string]types.Coins, chainParams *common.ChainParams, blockHeight uint64) {
 	if !common.IsCheckPointHeight(blockHeight) {
 		return
 	}
@@ -352,11 +353,11 @@ func grantValidatorAndSentryReward(ledger core.Ledger, view *st.StoreView, valid
 	totalReward := big.NewInt(1).Mul(dtokenRewardPerBlock, big.NewInt(common.CheckpointInterval))
 
 	var srdsr *st.StakeRewardDistributionRuleSet
-	if blockHeight >= common.HeightEnableDneroV2 {
+	if blockHeight >= chainParams.HeightEnableDneroV2 {
 		srdsr = state.NewStakeRewardDistributionRuleSet(view)
 	}
 
-	if blockHeight < common.HeightSampleStakingReward {
+	if blockHeight < chainParams.HeightSampleStakingReward {
 		// the source of the stake divides the block reward proportional to their stake
 		issueFixedReward(effectiveStakes, totalStake, accountReward, totalReward, srdsr, "Block")
 	} else {
@@ -368,7 +369,7 @@ func grantValidatorAndSentryReward(ledger core.Ledger, view *st.StoreView, valid
 
 // grant uptime mining rewards to active elite edge nodes (they are the dtoken stakers)
 func grantEliteEdgeNodeReward(ledger core.Ledger, view *st.StoreView, sentryVotes *core.AggregatedVotes, eliteEdgeNodeVotes *core.AggregatedEENVotes,
-	eliteEdgeNodePool core.EliteEdgeNodePool, accountReward *map[string]types.Coins, blockHeight uint64) {
+	eliteEdgeNodePool core.EliteEdgeNodePool, accountReward *map[string]types.Coins, chainParams *common.ChainParams, blockHeight uint64) {
 	if !common.IsCheckPointHeight(blockHeight) {
 		return
 	}
@@ -434,7 +435,7 @@ func grantEliteEdgeNodeReward(ledger core.Ledger, view *st.StoreView, sentryVote
 	logger.Debugf("grantEliteEdgeNodeReward: totalEffectiveStake = %v, totalReward = %v", totalEffectiveStake, totalReward)
 
 	var srdsr *st.StakeRewardDistributionRuleSet
-	if blockHeight >= common.HeightEnableDneroV2 {
+	if blockHeight >= chainParams.HeightEnableDneroV2 {
 		srdsr = state.NewStakeRewardDistributionRuleSet(view)
 	}
 
@@ -31,7 +31,8 @@ func NewDepositStakeExecutor(state *st.LedgerState) *DepositStakeExecutor {
 func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
 	// Feature block height check
 	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	if _, ok := transaction.(*types.DepositStakeTxV1); ok && blockHeight < common.HeightEnableDneroV1 {
+	chainParams := exec.state.ChainParams()
+	if _, ok := transaction.(*types.DepositStakeTxV1); ok && blockHeight < chainParams.HeightEnableDneroV1 {
 		return result.Error("Feature sentry is not active yet")
 	}
 
@@ -48,13 +49,13 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 	}
 
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source, blockHeight)
+	res = validateInputAdvanced(exec.state.ChainParams(), viewSel, sourceAccount, signBytes, tx.Source, blockHeight)
 	if res.IsError() {
 		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.Source.Address.Hex(), res))
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(exec.state.ChainParams(), tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v DTokenWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -104,8 +105,8 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 	}
 
 	if tx.Purpose == core.StakeForEliteEdgeNode {
-		if blockHeight < common.HeightEnableDneroV2 {
-			return result.Error(fmt.Sprintf("Elite Edge Node staking not enabled yet, please wait until block height %v", common.HeightEnableDneroV2)).WithErrorCode(result.CodeGenericError)
+		if blockHeight < chainParams.HeightEnableDneroV2 {
+			return result.Error(fmt.Sprintf("Elite Edge Node staking not enabled yet, please wait until block height %v", chainParams.HeightEnableDneroV2)).WithErrorCode(result.CodeGenericError)
 		}
 
 		minEliteEdgeNodeStake := core.MinEliteEdgeNodeStakeDeposit
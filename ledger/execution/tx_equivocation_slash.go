@@ -0,0 +1,141 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/result"
+	"github.com/dnerochain/dnero/core"
+	st "github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+)
+
+var _ TxExecutor = (*EquivocationSlashTxExecutor)(nil)
+
+// ------------------------------- EquivocationSlash Transaction -----------------------------------
+
+// EquivocationSlashTxExecutor implements the TxExecutor interface
+type EquivocationSlashTxExecutor struct {
+	state     *st.LedgerState
+	consensus core.ConsensusEngine
+	valMgr    core.ValidatorManager
+}
+
+// NewEquivocationSlashTxExecutor creates a new instance of EquivocationSlashTxExecutor
+func NewEquivocationSlashTxExecutor(state *st.LedgerState, consensus core.ConsensusEngine, valMgr core.ValidatorManager) *EquivocationSlashTxExecutor {
+	return &EquivocationSlashTxExecutor{
+		state:     state,
+		consensus: consensus,
+		valMgr:    valMgr,
+	}
+}
+
+func (exec *EquivocationSlashTxExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+	tx := transaction.(*types.EquivocationSlashTx)
+
+	validatorSet := getValidatorSet(exec.consensus.GetLedger(), exec.valMgr)
+	validatorAddresses := getValidatorAddresses(validatorSet)
+
+	// Validate proposer, basic
+	res := tx.Proposer.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	// verify the proposer is one of the validators
+	res = isAValidator(tx.Proposer.Address, validatorAddresses)
+	if res.IsError() {
+		return res
+	}
+
+	proposerAccount, res := getOrMakeInput(view, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	// verify the proposer's signature
+	signBytes := tx.SignBytes(chainID)
+	if !tx.Proposer.Signature.Verify(signBytes, proposerAccount.Address) {
+		return result.Error("SignBytes: %X", signBytes)
+	}
+
+	evidence := &tx.Evidence
+	res = evidence.Validate(chainID)
+	if res.IsError() {
+		return result.Error("Invalid equivocation evidence: %v", res.Message)
+	}
+
+	if evidence.Height()+core.MaxEquivocationEvidenceAge < blockHeight {
+		return result.Error("Equivocation evidence at height %v is too old", evidence.Height())
+	}
+
+	if view.IsEquivocationSlashed(evidence.ID()) {
+		return result.Error("Validator %v has already been slashed for the equivocation", evidence.Offender())
+	}
+
+	vcp := view.GetValidatorCandidatePool()
+	if vcp == nil || vcp.FindStakeDelegate(evidence.Offender()) == nil {
+		return result.Error("Validator %v has no stake to slash", evidence.Offender())
+	}
+
+	// The chain cannot make progress without validators
+	hasOtherValidators := false
+	for _, candidate := range vcp.SortedCandidates {
+		if candidate.Holder != evidence.Offender() && candidate.TotalStake().Cmp(core.Zero) > 0 {
+			hasOtherValidators = true
+			break
+		}
+	}
+	if !hasOtherValidators {
+		return result.Error("Cannot slash the last validator %v", evidence.Offender())
+	}
+
+	return result.OK
+}
+
+func (exec *EquivocationSlashTxExecutor) process(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.EquivocationSlashTx)
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	evidence := &tx.Evidence
+	offender := evidence.Offender()
+
+	vcp := view.GetValidatorCandidatePool()
+	if vcp == nil {
+		return common.Hash{}, result.Error("Failed to retrieve the validator candidate pool")
+	}
+	slashedAmount, err := vcp.SlashStake(offender, core.EquivocationSlashPercentage, exec.state.Height())
+	if err != nil {
+		return common.Hash{}, result.Error("Failed to slash stake, err: %v", err)
+	}
+	view.UpdateValidatorCandidatePool(vcp)
+	view.SetEquivocationSlashed(evidence.ID(), blockHeight)
+
+	// The validator set changes, similar to the validator stake withdrawal
+	hl := view.GetStakeTransactionHeightList()
+	if hl == nil {
+		hl = &types.HeightList{}
+	}
+	hl.Append(blockHeight)
+	view.UpdateStakeTransactionHeightList(hl)
+
+	logger.Infof("Slashed validator %v for equivocation: slashedAmount = %v, evidence = %v",
+		offender, slashedAmount, evidence)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *EquivocationSlashTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.EquivocationSlashTx)
+	return &core.TxInfo{
+		Address:           tx.Proposer.Address,
+		Sequence:          tx.Proposer.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *EquivocationSlashTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	return new(big.Int).SetUint64(0)
+}
@@ -0,0 +1,250 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/result"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	st "github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/store/database/backend"
+)
+
+const slashTestChainID = "test_chain_id"
+
+// slashTestHeight is the height of the block the slash txs are included in
+var slashTestHeight = core.MaxEquivocationEvidenceAge + 100
+
+type slashTestLedger struct {
+	core.Ledger
+	currentBlock *core.Block
+}
+
+func (l *slashTestLedger) GetCurrentBlock() *core.Block {
+	return l.currentBlock
+}
+
+type slashTestConsensus struct {
+	*TestConsensusEngine
+	ledger core.Ledger
+}
+
+func (c *slashTestConsensus) GetLedger() core.Ledger {
+	return c.ledger
+}
+
+type slashTest struct {
+	executor *EquivocationSlashTxExecutor
+	state    *st.LedgerState
+
+	proposer *crypto.PrivateKey
+	offender *crypto.PrivateKey
+	sequence uint64
+}
+
+func newSlashTest(t *testing.T) *slashTest {
+	proposer, _, err := crypto.GenerateKeyPair()
+	require.Nil(t, err)
+	offender, _, err := crypto.GenerateKeyPair()
+	require.Nil(t, err)
+
+	parentBlock := &core.Block{
+		BlockHeader: &core.BlockHeader{
+			ChainID: slashTestChainID,
+			Height:  slashTestHeight - 1,
+		},
+	}
+	ledgerState := st.NewLedgerState(slashTestChainID, backend.NewMemDatabase(), nil)
+	ledgerState.ResetState(parentBlock)
+
+	proposerValidator := core.NewValidator(proposer.PublicKey().Address().String(), core.MinValidatorStakeDeposit)
+	valSet := core.NewValidatorSet()
+	valSet.AddValidator(proposerValidator)
+	consensus := &slashTestConsensus{
+		TestConsensusEngine: NewTestConsensusEngine("localseed"),
+		ledger:              &slashTestLedger{currentBlock: parentBlock},
+	}
+	valMgr := NewTestValidatorManager(proposerValidator, valSet)
+
+	return &slashTest{
+		executor: NewEquivocationSlashTxExecutor(ledgerState, consensus, valMgr),
+		state:    ledgerState,
+		proposer: proposer,
+		offender: offender,
+	}
+}
+
+func (s *slashTest) view() *st.StoreView {
+	return s.state.Delivered()
+}
+
+func (s *slashTest) depositStake(t *testing.T, source common.Address, holder common.Address, amount *big.Int) {
+	vcp := s.view().GetValidatorCandidatePool()
+	if vcp == nil {
+		vcp = &core.ValidatorCandidatePool{}
+	}
+	require.Nil(t, vcp.DepositStake(source, holder, amount, 0))
+	s.view().UpdateValidatorCandidatePool(vcp)
+}
+
+func (s *slashTest) withdrawStake(t *testing.T, source common.Address, holder common.Address) {
+	vcp := s.view().GetValidatorCandidatePool()
+	require.Nil(t, vcp.WithdrawStake(source, holder, slashTestHeight-10))
+	s.view().UpdateValidatorCandidatePool(vcp)
+}
+
+// newEvidence creates the evidence of the offender proposing two blocks at the given height in the same epoch
+func (s *slashTest) newEvidence(height uint64, timestampA, timestampB int64) core.EquivocationEvidence {
+	newHeader := func(timestamp int64) *core.BlockHeader {
+		header := &core.BlockHeader{
+			ChainID:   slashTestChainID,
+			Epoch:     height,
+			Height:    height,
+			Timestamp: big.NewInt(timestamp),
+			Proposer:  s.offender.PublicKey().Address(),
+		}
+		header.Signature, _ = s.offender.Sign(header.SignBytes())
+		return header
+	}
+	return *core.NewProposalEquivocationEvidence(newHeader(timestampA), newHeader(timestampB))
+}
+
+func (s *slashTest) newSlashTx(evidence core.EquivocationEvidence) *types.EquivocationSlashTx {
+	s.sequence++
+	tx := &types.EquivocationSlashTx{
+		Proposer: types.TxInput{
+			Address:  s.proposer.PublicKey().Address(),
+			Sequence: s.sequence,
+		},
+		Evidence: evidence,
+	}
+	tx.Proposer.Signature, _ = s.proposer.Sign(tx.SignBytes(slashTestChainID))
+	return tx
+}
+
+func (s *slashTest) sanityCheck(tx *types.EquivocationSlashTx) result.Result {
+	return s.executor.sanityCheck(slashTestChainID, s.view(), core.DeliveredView, tx)
+}
+
+func (s *slashTest) process(t *testing.T, tx *types.EquivocationSlashTx) {
+	_, res := s.executor.process(slashTestChainID, s.view(), core.DeliveredView, tx)
+	require.True(t, res.IsOK(), res.Message)
+}
+
+func TestEquivocationSlashTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := newSlashTest(t)
+	offenderAddr := s.offender.PublicKey().Address()
+	sourceAddr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	withdrawnAddr := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	// The amounts are not multiples of 10 to check the rounding of the slashed amount
+	ownStake := new(big.Int).Add(core.MinValidatorStakeDeposit, big.NewInt(19))
+	delegatedStake := new(big.Int).Add(core.MinValidatorStakeDeposit, big.NewInt(7))
+	withdrawnStake := new(big.Int).Add(core.MinValidatorStakeDeposit, big.NewInt(3))
+	s.depositStake(t, s.proposer.PublicKey().Address(), s.proposer.PublicKey().Address(), core.MinValidatorStakeDeposit)
+	s.depositStake(t, offenderAddr, offenderAddr, ownStake)
+	s.depositStake(t, sourceAddr, offenderAddr, delegatedStake)
+	s.depositStake(t, withdrawnAddr, offenderAddr, withdrawnStake)
+	s.withdrawStake(t, withdrawnAddr, offenderAddr)
+	withdrawnReturnHeight := slashTestHeight - 10 + core.ReturnLockingPeriod
+
+	evidence := s.newEvidence(slashTestHeight-1, 1, 2)
+	tx := s.newSlashTx(evidence)
+	res := s.sanityCheck(tx)
+	require.True(res.IsOK(), res.Message)
+	s.process(t, tx)
+
+	// Each stake is slashed by the percentage, rounded down, and the slashed amount is burned
+	slashed := func(amount *big.Int) *big.Int {
+		slashedAmount := new(big.Int).Mul(amount, new(big.Int).SetUint64(core.EquivocationSlashPercentage))
+		slashedAmount.Div(slashedAmount, big.NewInt(100))
+		return new(big.Int).Sub(amount, slashedAmount)
+	}
+	expected := map[common.Address]*big.Int{
+		offenderAddr:  slashed(ownStake),
+		sourceAddr:    slashed(delegatedStake),
+		withdrawnAddr: slashed(withdrawnStake),
+	}
+	candidate := s.view().GetValidatorCandidatePool().FindStakeDelegate(offenderAddr)
+	require.NotNil(candidate)
+	require.Equal(3, len(candidate.Stakes))
+	for _, stake := range candidate.Stakes {
+		assert.Equal(expected[stake.Source], stake.Amount, stake.Source.Hex())
+		assert.True(stake.Withdrawn)
+		if stake.Source == withdrawnAddr {
+			// The stake already being returned keeps its return height
+			assert.Equal(withdrawnReturnHeight, stake.ReturnHeight)
+		} else {
+			assert.Equal(s.state.Height()+core.ReturnLockingPeriod, stake.ReturnHeight)
+		}
+	}
+	assert.Equal(core.Zero, candidate.TotalStake())
+	assert.Nil(s.view().GetAccount(offenderAddr))
+
+	// The slash is recorded, and the validator set changes at the height of the block
+	assert.True(s.view().IsEquivocationSlashed(evidence.ID()))
+	hl := s.view().GetStakeTransactionHeightList()
+	require.NotNil(hl)
+	assert.True(hl.Contains(slashTestHeight))
+
+	// Other evidence of the same offense is not slashed again
+	evidence2 := s.newEvidence(slashTestHeight-1, 1, 3)
+	assert.NotEqual(evidence.Hash(), evidence2.Hash())
+	assert.Equal(evidence.ID(), evidence2.ID())
+	assert.Contains(s.sanityCheck(s.newSlashTx(evidence)).Message, "already been slashed")
+	assert.Contains(s.sanityCheck(s.newSlashTx(evidence2)).Message, "already been slashed")
+}
+
+func TestEquivocationSlashTxExpiredEvidence(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newSlashTest(t)
+	offenderAddr := s.offender.PublicKey().Address()
+	s.depositStake(t, s.proposer.PublicKey().Address(), s.proposer.PublicKey().Address(), core.MinValidatorStakeDeposit)
+	s.depositStake(t, offenderAddr, offenderAddr, core.MinValidatorStakeDeposit)
+
+	oldest := slashTestHeight - core.MaxEquivocationEvidenceAge
+	assert.True(s.sanityCheck(s.newSlashTx(s.newEvidence(oldest, 1, 2))).IsOK())
+	assert.Contains(s.sanityCheck(s.newSlashTx(s.newEvidence(oldest-1, 1, 2))).Message, "too old")
+}
+
+func TestEquivocationSlashTxNoStake(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newSlashTest(t)
+	s.depositStake(t, s.proposer.PublicKey().Address(), s.proposer.PublicKey().Address(), core.MinValidatorStakeDeposit)
+
+	// The offender never staked
+	tx := s.newSlashTx(s.newEvidence(slashTestHeight-1, 1, 2))
+	assert.Contains(s.sanityCheck(tx).Message, "no stake")
+
+	_, res := s.executor.process(slashTestChainID, s.view(), core.DeliveredView, tx)
+	assert.True(res.IsError())
+	assert.False(s.view().IsEquivocationSlashed(tx.Evidence.ID()))
+}
+
+func TestEquivocationSlashTxLastValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newSlashTest(t)
+	offenderAddr := s.offender.PublicKey().Address()
+	s.depositStake(t, offenderAddr, offenderAddr, core.MinValidatorStakeDeposit)
+
+	// The offender is the only one with stake
+	tx := s.newSlashTx(s.newEvidence(slashTestHeight-1, 1, 2))
+	assert.Contains(s.sanityCheck(tx).Message, "last validator")
+
+	// Withdrawn stakes do not count either
+	otherAddr := s.proposer.PublicKey().Address()
+	s.depositStake(t, otherAddr, otherAddr, core.MinValidatorStakeDeposit)
+	s.withdrawStake(t, otherAddr, otherAddr)
+	assert.Contains(s.sanityCheck(tx).Message, "last validator")
+}
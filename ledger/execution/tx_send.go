@@ -63,7 +63,7 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, view
 	}
 
 	blockHeight := view.Height() + 1
-	if blockHeight >= common.HeightEnableSmartContract {
+	if blockHeight >= exec.state.ChainParams().HeightEnableSmartContract {
 		for _, outAcc := range accounts {
 			if outAcc.IsASmartContract() {
 				return result.Error(
@@ -74,12 +74,12 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, view
 
 	// Validate inputs and outputs, advanced
 	signBytes := tx.SignBytes(chainID)
-	inTotal, res := validateInputsAdvanced(accounts, signBytes, tx.Inputs, blockHeight)
+	inTotal, res := validateInputsAdvanced(exec.state.ChainParams(), viewSel, accounts, signBytes, tx.Inputs, blockHeight)
 	if res.IsError() {
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForSendTxFee(tx.Fee, numAccountsAffected, blockHeight); !success {
+	if minTxFee, success := sanityCheckForSendTxFee(exec.state.ChainParams(), tx.Fee, numAccountsAffected, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v DTokenWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -126,14 +126,19 @@ func (exec *SendTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
 func (exec *SendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
 	tx := transaction.(*types.SendTx)
 	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getSendTxGas(exec.state, tx))
+	effectiveGasPrice := new(big.Int).Div(fee.DTokenWei, gas)
+	return effectiveGasPrice
+}
+
+// getSendTxGas returns the gas of the send tx, which is charged per account affected
+func getSendTxGas(ledgerState *st.LedgerState, tx *types.SendTx) uint64 {
 	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
 
-	gasSendTxPerAccount := getRegularTxGas(exec.state) / 2
-	gasUint64 := gasSendTxPerAccount * numAccountsAffected
-	if gasUint64 < 2*gasSendTxPerAccount {
-		gasUint64 = 2 * gasSendTxPerAccount // to prevent spamming with invalid transactions, e.g. empty inputs/outputs
+	gasSendTxPerAccount := getRegularTxGas(ledgerState) / 2
+	gas := gasSendTxPerAccount * numAccountsAffected
+	if gas < 2*gasSendTxPerAccount {
+		gas = 2 * gasSendTxPerAccount // to prevent spamming with invalid transactions, e.g. empty inputs/outputs
 	}
-	gas := new(big.Int).SetUint64(gasUint64)
-	effectiveGasPrice := new(big.Int).Div(fee.DTokenWei, gas)
-	return effectiveGasPrice
+	return gas
 }
@@ -64,21 +64,21 @@ func (exec *ServicePaymentTxExecutor) sanityCheck(chainID string, view *st.Store
 
 	// Verify source
 	sourceSignBytes := tx.SourceSignBytes(chainID)
-	if !tx.Source.Signature.Verify(sourceSignBytes, sourceAccount.Address) {
+	if !isUnsignedSimulation(viewSel, tx.Source.Signature) && !tx.Source.Signature.Verify(sourceSignBytes, sourceAccount.Address) {
 		errMsg := fmt.Sprintf("sanityCheckForServicePaymentTx failed on source signature, addr: %v", sourceAddress.Hex())
 		logger.Infof(errMsg)
 		return result.Error(errMsg)
 	}
 
 	targetSignBytes := tx.TargetSignBytes(chainID)
-	if !tx.Target.Signature.Verify(targetSignBytes, targetAccount.Address) {
+	if !isUnsignedSimulation(viewSel, tx.Target.Signature) && !tx.Target.Signature.Verify(targetSignBytes, targetAccount.Address) {
 		errMsg := fmt.Sprintf("sanityCheckForServicePaymentTx failed on target signature, addr: %v", targetAddress.Hex())
 		logger.Infof(errMsg)
 		return result.Error(errMsg)
 	}
 
 	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(exec.state.ChainParams(), tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v DTokenWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -37,6 +37,7 @@ func NewSmartContractTxExecutor(chain *blockchain.Chain, state *st.LedgerState,
 
 func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreView, viewSel core.ViewSelector, transaction types.Tx) result.Result {
 	blockHeight := getBlockHeight(exec.state)
+	chainParams := exec.state.ChainParams()
 	tx := transaction.(*types.SmartContractTx)
 
 	// Validate from, basic
@@ -48,13 +49,13 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 	// Check signatures
 	signBytes := tx.SignBytes(chainID)
 	nativeSignatureValid := tx.From.Signature.Verify(signBytes, tx.From.Address)
-	if blockHeight >= common.HeightTxWrapperExtension {
+	if blockHeight >= chainParams.HeightTxWrapperExtension {
 		signBytesV2 := types.ChangeEthereumTxWrapper(signBytes, 2)
 		nativeSignatureValid = nativeSignatureValid || tx.From.Signature.Verify(signBytesV2, tx.From.Address)
 	}
 
-	if !nativeSignatureValid {
-		if blockHeight < common.HeightRPCCompatibility {
+	if !nativeSignatureValid && !isUnsignedSimulation(viewSel, tx.From.Signature) {
+		if blockHeight < chainParams.HeightRPCCompatibility {
 			return result.Error("Signature verification failed, SignBytes: %v",
 				hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
 		}
@@ -99,19 +100,19 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 			WithErrorCode(result.CodeInvalidValueToTransfer)
 	}
 
-	if !sanityCheckForGasPrice(tx.GasPrice, blockHeight) {
-		minimumGasPrice := types.GetMinimumGasPrice(blockHeight)
+	if !sanityCheckForGasPrice(chainParams, tx.GasPrice, blockHeight) {
+		minimumGasPrice := types.GetMinimumGasPrice(chainParams, blockHeight)
 		return result.Error("Insufficient gas price. Gas price needs to be at least %v DTokenWei", minimumGasPrice).
 			WithErrorCode(result.CodeInvalidGasPrice)
 	}
 
-	maxGasLimit := types.GetMaxGasLimit(blockHeight)
+	maxGasLimit := types.GetMaxGasLimit(chainParams, blockHeight)
 	if new(big.Int).SetUint64(tx.GasLimit).Cmp(maxGasLimit) > 0 {
 		return result.Error("Invalid gas limit. Gas limit needs to be at most %v", maxGasLimit).
 			WithErrorCode(result.CodeInvalidGasLimit)
 	}
 
-	if vm.SupportWrappedDnero(blockHeight) {
+	if vm.SupportWrappedDnero(chainParams, blockHeight) {
 		err := exec.checkIntrinsicGas(tx)
 		if err != nil {
 			return result.Error("Intrinsic gas check failed: %v", err).
@@ -131,7 +132,7 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 	var minimalBalance types.Coins
 	value := coins.DTokenWei      // NoNil() already guarantees value is NOT nil
 	dneroValue := coins.DneroWei // NoNil() already guarantees value is NOT nil
-	if !vm.SupportDneroTransferInEVM(blockHeight) {
+	if !vm.SupportDneroTransferInEVM(chainParams, blockHeight) {
 		minimalBalance = types.Coins{
 			DneroWei: zero,
 			DTokenWei: feeLimit.Add(feeLimit, value),
@@ -199,6 +200,17 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		exec.chain.AddTxReceipt(exec.ledger.GetCurrentBlock(), tx, logs, balanceChanges, evmRet, contractAddr, gasUsed, evmErr)
 	}
 
+	if viewSel == core.SimulatedView { // the receipt of a simulated tx is returned to the caller instead
+		return txHash, result.OKWith(result.Info{
+			"gasUsed":         gasUsed,
+			"logs":            logs,
+			"balanceChanges":  balanceChanges,
+			"evmReturn":       evmRet,
+			"evmError":        evmErr,
+			"contractAddress": contractAddr,
+		})
+	}
+
 	return txHash, result.OK
 }
 
@@ -46,7 +46,7 @@ func (exec *StakeRewardDistributionTxExecutor) sanityCheck(chainID string, view
 
 	// Validate inputs and outputs, advanced
 	signBytes := tx.SignBytes(chainID)
-	res = validateInputAdvanced(stakeHolderAccount, signBytes, tx.Holder, blockHeight)
+	res = validateInputAdvanced(exec.state.ChainParams(), viewSel, stakeHolderAccount, signBytes, tx.Holder, blockHeight)
 	if res.IsError() {
 		return res
 	}
@@ -99,7 +99,7 @@ func (exec *StakeRewardDistributionTxExecutor) sanityCheck(chainID string, view
 	// 	return result.Error("Invalid purpose: %v", tx.Purpose)
 	// }
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(exec.state.ChainParams(), tx.Fee, blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v DTokenWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -18,6 +18,8 @@ import (
 	"github.com/dnerochain/dnero/common/result"
 	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/eventbus"
+	"github.com/dnerochain/dnero/evidence"
 	exec "github.com/dnerochain/dnero/ledger/execution"
 	"github.com/dnerochain/dnero/ledger/state"
 	st "github.com/dnerochain/dnero/ledger/state"
@@ -39,6 +41,8 @@ type Ledger struct {
 	consensus    core.ConsensusEngine
 	valMgr       core.ValidatorManager
 	mempool      *mp.Mempool
+	evidencePool *evidence.EvidencePool
+	eventBus     *eventbus.EventBus
 	currentBlock *core.Block
 
 	mu       *sync.RWMutex // Lock for accessing ledger state.
@@ -68,6 +72,16 @@ func (ledger *Ledger) SetExecutor(executor *exec.Executor) {
 	ledger.executor = executor
 }
 
+// SetEvidencePool sets the pool that provides the evidence of validator equivocation to the proposer
+func (ledger *Ledger) SetEvidencePool(pool *evidence.EvidencePool) {
+	ledger.evidencePool = pool
+}
+
+// SetEventBus sets the event bus to which the validator set changes are published
+func (ledger *Ledger) SetEventBus(bus *eventbus.EventBus) {
+	ledger.eventBus = bus
+}
+
 // State returns the state of the ledger
 func (ledger *Ledger) State() *st.LedgerState {
 	return ledger.state
@@ -222,8 +236,9 @@ func (ledger *Ledger) ScreenTxUnsafe(rawTx common.Bytes) (res result.Result) {
 	return res
 }
 
-// ScreenTx screens the given transaction
-func (ledger *Ledger) ScreenTx(rawTx common.Bytes) (txInfo *core.TxInfo, res result.Result) {
+// ScreenTx screens the given transaction. The precheck, if any, can reject the transaction before it is
+// applied to the screened view
+func (ledger *Ledger) ScreenTx(rawTx common.Bytes, precheck core.TxPrecheck) (txInfo *core.TxInfo, res result.Result) {
 	var tx types.Tx
 	tx, err := types.TxFromBytes(rawTx)
 	if err != nil {
@@ -238,12 +253,31 @@ func (ledger *Ledger) ScreenTx(rawTx common.Bytes) (txInfo *core.TxInfo, res res
 	ledger.mu.RLock()
 	defer ledger.mu.RUnlock()
 
-	_, res = ledger.executor.ScreenTx(tx)
+	return ledger.executor.ScreenTxWithPrecheck(tx, precheck)
+}
+
+// ScreenReplacementTx screens the given transaction which replaces a pending transaction of the same sequence
+func (ledger *Ledger) ScreenReplacementTx(rawTx common.Bytes) (txInfo *core.TxInfo, res result.Result) {
+	var tx types.Tx
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, result.Error("Error decoding tx: %v", err)
+	}
+
+	if ledger.shouldSkipCheckTx(tx) {
+		return nil, result.Error("Unauthorized transaction, should skip").
+			WithErrorCode(result.CodeUnauthorizedTx)
+	}
+
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	txInfo, res = ledger.executor.GetTxInfo(tx)
 	if res.IsError() {
 		return nil, res
 	}
 
-	txInfo, res = ledger.executor.GetTxInfo(tx)
+	res = ledger.executor.ScreenReplacementTx(tx, txInfo)
 	if res.IsError() {
 		return nil, res
 	}
@@ -251,6 +285,30 @@ func (ledger *Ledger) ScreenTx(rawTx common.Bytes) (txInfo *core.TxInfo, res res
 	return txInfo, res
 }
 
+// ResetScreenedState resets the screened view to the delivered view, e.g. for the mempool to screen its
+// remaining transactions again after evicting some of them
+func (ledger *Ledger) ResetScreenedState() {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	if res := ledger.state.ResetScreened(); res.IsError() {
+		logger.Errorf("Failed to reset the screened state: %v", res.Message)
+	}
+}
+
+// GetTxInfo returns the tx information, e.g. the effective gas price, of the given transaction
+func (ledger *Ledger) GetTxInfo(rawTx common.Bytes) (txInfo *core.TxInfo, res result.Result) {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, result.Error("Error decoding tx: %v", err)
+	}
+
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	return ledger.executor.GetTxInfo(tx)
+}
+
 // ProposeBlockTxs collects and executes a list of transactions, which will be used to assemble the next blockl
 // It also clears these transactions from the mempool.
 func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorUpdateTxs bool) (stateRootHash common.Hash, blockRawTxs []common.Bytes, res result.Result) {
@@ -303,11 +361,18 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block, shouldIncludeValidatorU
 			if _, ok := tx.(*types.WithdrawStakeTx); ok {
 				continue
 			}
+			if _, ok := tx.(*types.EquivocationSlashTx); ok {
+				continue
+			}
 		}
 
 		_, res := ledger.executor.CheckTx(tx)
 		if res.IsError() {
 			logger.Errorf("Transaction check failed: errMsg = %v, tx = %v", res.Message, tx)
+			if slashTx, ok := tx.(*types.EquivocationSlashTx); ok && ledger.evidencePool != nil {
+				// Otherwise the evidence would be proposed again and again
+				ledger.evidencePool.RemoveEvidence([]common.Hash{slashTx.Evidence.ID()})
+			}
 			continue
 		}
 		blockRawTxs = append(blockRawTxs, rawTxCandidate)
@@ -359,6 +424,7 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 	logger.Debugf("ApplyBlockTxs: Start applying block transactions, block.height = %v", block.Height)
 
 	hasValidatorUpdate := false
+	slashedEvidenceIDs := []common.Hash{}
 	txProcessTime := []time.Duration{}
 	for _, rawTx := range blockRawTxs {
 		start := time.Now()
@@ -372,6 +438,9 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 			hasValidatorUpdate = true
 		} else if wtx, ok := tx.(*types.WithdrawStakeTx); ok && wtx.Purpose == core.StakeForValidator {
 			hasValidatorUpdate = true
+		} else if stx, ok := tx.(*types.EquivocationSlashTx); ok {
+			hasValidatorUpdate = true
+			slashedEvidenceIDs = append(slashedEvidenceIDs, stx.Evidence.ID())
 		}
 		_, res := ledger.executor.ExecuteTx(tx)
 		if res.IsError() {
@@ -410,6 +479,17 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 		ledger.mempool.UpdateUnsafe(blockRawTxs) // clear txs from the mempool
 	}()
 
+	if ledger.evidencePool != nil && len(slashedEvidenceIDs) > 0 {
+		ledger.evidencePool.RemoveEvidence(slashedEvidenceIDs)
+	}
+
+	if hasValidatorUpdate {
+		ledger.eventBus.Publish(eventbus.TopicValidatorSetChanged, &eventbus.ValidatorSetChangedEvent{
+			BlockHash: block.Hash(),
+			Height:    block.Height,
+		})
+	}
+
 	logger.Debugf("ApplyBlockTxs: Cleared mempool transactions, block.height = %v", block.Height)
 
 	logger.Debugf("ApplyBlockTxs: Done, block.height = %v, txProcessTime = %v, handleDelayedUpdateTime = %v, commitTime = %v",
@@ -453,6 +533,8 @@ func (ledger *Ledger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 			hasValidatorUpdate = true
 		} else if wtx, ok := tx.(*types.WithdrawStakeTx); ok && wtx.Purpose == core.StakeForValidator {
 			hasValidatorUpdate = true
+		} else if _, ok := tx.(*types.EquivocationSlashTx); ok {
+			hasValidatorUpdate = true
 		}
 		_, res := ledger.executor.ExecuteTx(tx)
 		if res.IsError() {
@@ -469,6 +551,78 @@ func (ledger *Ledger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 	return view.Hash(), result.OKWith(result.Info{"hasValidatorUpdate": hasValidatorUpdate})
 }
 
+// ReplayBlockTxs re-executes the first numTxs transactions of the given block on top of the
+// state of its parent block. It returns the resulting store view together with the parent
+// block, which can be used to execute the next transaction of the block. Nothing is committed,
+// neither to the ledger state nor to the persistent storage.
+func (ledger *Ledger) ReplayBlockTxs(block *core.ExtendedBlock, numTxs int) (*st.StoreView, *core.Block, error) {
+	if numTxs > len(block.Txs) {
+		return nil, nil, fmt.Errorf("block %v only has %v transactions", block.Hash().Hex(), len(block.Txs))
+	}
+
+	extParentBlock, err := ledger.chain.FindBlock(block.Parent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find the parent block %v: %v", block.Parent.Hex(), err)
+	}
+	parentBlock := extParentBlock.Block
+
+	replayState := st.NewLedgerState(ledger.state.GetChainID(), ledger.db, nil)
+	if res := replayState.ResetState(parentBlock); res.IsError() {
+		return nil, nil, fmt.Errorf("the state for height %v is not available, it might have been pruned", parentBlock.Height)
+	}
+
+	// The replayer only serves as the core.Ledger for the tx executors, so the
+	// executors can look up the block being replayed
+	replayer := &Ledger{
+		db:           ledger.db,
+		chain:        ledger.chain,
+		consensus:    ledger.consensus,
+		valMgr:       ledger.valMgr,
+		currentBlock: block.Block,
+		mu:           &sync.RWMutex{},
+		state:        replayState,
+	}
+	executor := exec.NewExecutor(ledger.db, ledger.chain, replayState, ledger.consensus, ledger.valMgr, replayer)
+	executor.SetSkipSanityCheck(true) // the block has already been validated
+	replayer.SetExecutor(executor)
+
+	for i := 0; i < numTxs; i++ {
+		tx, err := types.TxFromBytes(block.Txs[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse transaction: %v", hex.EncodeToString(block.Txs[i]))
+		}
+		// Use the checked view, since executing on the delivered view would overwrite the tx receipts
+		_, res := executor.CheckTx(tx)
+		if res.IsError() {
+			return nil, nil, fmt.Errorf("failed to replay transaction %v of block %v: %v", i, block.Hash().Hex(), res.Message)
+		}
+	}
+
+	return replayState.Checked(), parentBlock, nil
+}
+
+// SimulateTx executes the given transaction on top of the view, which is the state after the given block,
+// as if the transaction is included in the next block. Nothing is committed, neither to the ledger state
+// nor to the persistent storage. The view is modified, and should not be shared.
+func (ledger *Ledger) SimulateTx(block *core.Block, view *st.StoreView, rawTx common.Bytes) (*exec.SimulationResult, error) {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %v", err)
+	}
+	if ledger.shouldSkipCheckTx(tx) {
+		return nil, fmt.Errorf("transaction of type %T can't be simulated", tx)
+	}
+
+	// Same as ReplayBlockTxs, a separate executor runs the tx against the state of the given block
+	simState := st.NewLedgerState(ledger.state.GetChainID(), ledger.db, nil)
+	if res := simState.ResetState(block); res.IsError() {
+		return nil, fmt.Errorf("the state for height %v is not available, it might have been pruned", block.Height)
+	}
+	executor := exec.NewExecutor(ledger.db, ledger.chain, simState, ledger.consensus, ledger.valMgr, ledger)
+
+	return executor.SimulateTx(view, tx), nil
+}
+
 // PruneState attempts to prune the state up to the targetEndHeight
 func (ledger *Ledger) PruneState(targetEndHeight uint64) error {
 	// Permanently disabled
@@ -594,6 +748,43 @@ func (ledger *Ledger) pruneStateForRange(startHeight, endHeight uint64) error {
 	return nil
 }
 
+// stateCompactor is implemented by the DBs that remove the historical state when compacted, e.g. the rollingdb
+type stateCompactor interface {
+	CompactedHeight() (uint64, bool)
+}
+
+// PrunedHeight returns the lowest height whose state survived the pruning and the DB compaction, and
+// whether any state in the DB has been removed at all. The state of the heights below is incomplete.
+func PrunedHeight(db database.Database) (uint64, bool) {
+	var height uint64
+	pruned := false
+
+	var processedHeight uint64
+	if err := kvstore.NewKVStore(db).Get(state.StatePruningProgressKey(), &processedHeight); err == nil {
+		height = processedHeight + 1
+		pruned = true
+	}
+	if compactor, ok := db.(stateCompactor); ok {
+		if compactedHeight, compacted := compactor.CompactedHeight(); compacted {
+			if compactedHeight > height {
+				height = compactedHeight
+			}
+			pruned = true
+		}
+	}
+	return height, pruned
+}
+
+// GetLowestStateHeight returns the lowest height from which the full state of every finalized block is
+// present, which is the root block of the chain unless the older state has been pruned
+func (ledger *Ledger) GetLowestStateHeight() uint64 {
+	height := ledger.chain.Root().Height
+	if prunedHeight, pruned := PrunedHeight(ledger.db); pruned && prunedHeight > height {
+		height = prunedHeight
+	}
+	return height
+}
+
 // ResetState sets the ledger state with the designated root
 //func (ledger *Ledger) ResetState(height uint64, rootHash common.Hash) result.Result {
 func (ledger *Ledger) ResetState(block *core.Block) result.Result {
@@ -640,6 +831,8 @@ func (ledger *Ledger) shouldSkipCheckTx(tx types.Tx) bool {
 		return true
 	case *types.SlashTx:
 		return true
+	case *types.EquivocationSlashTx:
+		return true
 	default:
 		return false
 	}
@@ -652,7 +845,7 @@ func (ledger *Ledger) handleDelayedStateUpdates(view *st.StoreView) {
 	ledger.handleSentryStakeReturn(view)
 
 	blockHeight := view.Height() + 1
-	if blockHeight >= common.HeightEnableDneroV2 {
+	if blockHeight >= ledger.state.ChainParams().HeightEnableDneroV2 {
 		ledger.handleEliteEdgeNodeStakeReturns(view)
 	}
 }
@@ -772,6 +965,10 @@ func (ledger *Ledger) addSpecialTransactions(block *core.Block, view *st.StoreVi
 
 	ledger.addCoinbaseTx(view, &proposer, validatorSet, rawTxs)
 	//ledger.addSlashTxs(view, &proposer, &validators, rawTxs)
+
+	if ledger.evidencePool != nil && block.Height >= ledger.state.ChainParams().HeightEnableEquivocationSlashing {
+		ledger.addEquivocationSlashTxs(view, &proposer, rawTxs)
+	}
 }
 
 // addCoinbaseTx adds a Coinbase transaction
@@ -788,11 +985,11 @@ func (ledger *Ledger) addCoinbaseTx(view *st.StoreView, proposer *core.Validator
 	sentryVotes := currentBlock.SentryVotes
 	eliteEdgeNodeVotes := currentBlock.EliteEdgeNodeVotes
 
-	if sentryVotes != nil && ch >= common.HeightEnableDneroV1 && common.IsCheckPointHeight(ch) {
+	if sentryVotes != nil && ch >= ledger.state.ChainParams().HeightEnableDneroV1 && common.IsCheckPointHeight(ch) {
 		sentryPool, eliteEdgeNodePool := exec.RetrievePools(ledger, ledger.chain, ledger.db, ch, sentryVotes, eliteEdgeNodeVotes)
-		accountRewardMap = exec.CalculateReward(ledger, view, validatorSet, sentryVotes, sentryPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
-	} else { // for compatibility with lower versions (e.g. blockHeight < common.HeightEnableValidatorReward)
-		accountRewardMap = exec.CalculateReward(ledger, view, validatorSet, nil, nil, nil, nil)
+		accountRewardMap = exec.CalculateReward(ledger, view, ledger.state.ChainParams(), validatorSet, sentryVotes, sentryPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
+	} else { // for compatibility with lower versions (e.g. blockHeight < HeightEnableValidatorReward)
+		accountRewardMap = exec.CalculateReward(ledger, view, ledger.state.ChainParams(), validatorSet, nil, nil, nil, nil)
 	}
 
 	coinbaseTxOutputs := []types.TxOutput{}
@@ -861,6 +1058,44 @@ func (ledger *Ledger) addSlashTxs(view *st.StoreView, proposer *core.Validator,
 	view.ClearSlashIntents()
 }
 
+// addEquivocationSlashTxs adds the EquivocationSlash transactions for the pending evidence
+func (ledger *Ledger) addEquivocationSlashTxs(view *st.StoreView, proposer *core.Validator, rawTxs *[]common.Bytes) {
+	proposerAddress := proposer.Address
+	proposerTxIn := types.TxInput{
+		Address: proposerAddress,
+	}
+
+	slashedEvidenceIDs := []common.Hash{}
+	evidenceList := ledger.evidencePool.PendingEvidence(core.MaxNumEquivocationEvidencePerBlock)
+	for _, evidence := range evidenceList {
+		if view.IsEquivocationSlashed(evidence.ID()) {
+			slashedEvidenceIDs = append(slashedEvidenceIDs, evidence.ID())
+			continue
+		}
+
+		slashTx := &types.EquivocationSlashTx{
+			Proposer: proposerTxIn,
+			Evidence: *evidence,
+		}
+
+		signature, err := ledger.signTransaction(slashTx)
+		if err != nil {
+			logger.Errorf("Failed to add equivocation slash transaction: %v", err)
+			continue
+		}
+		slashTx.SetSignature(proposerAddress, signature)
+		slashTxBytes, err := types.TxToBytes(slashTx)
+		if err != nil {
+			logger.Errorf("Failed to add equivocation slash transaction: %v", err)
+			continue
+		}
+
+		*rawTxs = append(*rawTxs, slashTxBytes)
+		logger.Debugf("Adding equivocation slash transaction: tx: %v, bytes: %v", slashTx, hex.EncodeToString(slashTxBytes))
+	}
+	ledger.evidencePool.RemoveEvidence(slashedEvidenceIDs)
+}
+
 // signTransaction signs the given transaction
 func (ledger *Ledger) signTransaction(tx types.Tx) (*crypto.Signature, error) {
 	chainID := ledger.state.GetChainID()
@@ -18,6 +18,7 @@ import (
 	st "github.com/dnerochain/dnero/ledger/state"
 	"github.com/dnerochain/dnero/ledger/types"
 	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/dnerochain/dnero/store/kvstore"
 )
 
 func TestLedgerSetup(t *testing.T) {
@@ -36,11 +37,11 @@ func TestLedgerScreenTx(t *testing.T) {
 	accOut, accIns := prepareInitLedgerState(ledger, numInAccs)
 
 	sendTxBytes := newRawSendTx(chainID, 1, true, accOut, accIns[0], false)
-	_, res := ledger.ScreenTx(sendTxBytes)
+	_, res := ledger.ScreenTx(sendTxBytes, nil)
 	assert.True(res.IsOK(), res.Message)
 
 	coinbaseTxBytes := newRawCoinbaseTx(chainID, ledger, 1)
-	_, res = ledger.ScreenTx(coinbaseTxBytes)
+	_, res = ledger.ScreenTx(coinbaseTxBytes, nil)
 	assert.Equal(result.CodeUnauthorizedTx, res.Code, res.Message)
 }
 
@@ -678,3 +679,40 @@ func TestSentryStakeUpdate(t *testing.T) {
 	assert.True(returnedCoins.DTokenWei.Cmp(core.Zero) == 0)
 	log.Infof("Returned coins: %v", returnedCoins)
 }
+
+type testCompactedDB struct {
+	*backend.MemDatabase
+	compactedHeight uint64
+}
+
+func (db *testCompactedDB) CompactedHeight() (uint64, bool) {
+	return db.compactedHeight, db.compactedHeight > 0
+}
+
+func TestPrunedHeight(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	db := backend.NewMemDatabase()
+	_, pruned := PrunedHeight(db)
+	assert.False(pruned)
+
+	require.Nil(kvstore.NewKVStore(db).Put(st.StatePruningProgressKey(), uint64(100)))
+	height, pruned := PrunedHeight(db)
+	assert.True(pruned)
+	assert.Equal(uint64(101), height)
+
+	compactedDB := &testCompactedDB{MemDatabase: backend.NewMemDatabase()}
+	_, pruned = PrunedHeight(compactedDB)
+	assert.False(pruned)
+
+	compactedDB.compactedHeight = 14450
+	height, pruned = PrunedHeight(compactedDB)
+	assert.True(pruned)
+	assert.Equal(uint64(14450), height)
+
+	// The higher of the pruning progress and the compaction wins
+	require.Nil(kvstore.NewKVStore(compactedDB).Put(st.StatePruningProgressKey(), uint64(20000)))
+	height, _ = PrunedHeight(compactedDB)
+	assert.Equal(uint64(20001), height)
+}
@@ -15,6 +15,11 @@ func ChainIDKey() common.Bytes {
 	return common.Bytes("chainid")
 }
 
+// ChainParamsKey returns the key for the fork schedule of the chain
+func ChainParamsKey() common.Bytes {
+	return common.Bytes("chainparams")
+}
+
 // AccountKey constructs the state key for the given address
 func AccountKey(addr common.Address) common.Bytes {
 	return append(common.Bytes("ls/a/"), addr[:]...)
@@ -98,3 +103,13 @@ func EliteEdgeNodeStakeReturnsKey(height uint64) common.Bytes {
 func EliteEdgeNodesTotalActiveStakeKey() common.Bytes {
 	return common.Bytes("ls/eentas")
 }
+
+// EquivocationSlashKeyPrefix returns the prefix of the equivocation slash key
+func EquivocationSlashKeyPrefix() common.Bytes {
+	return common.Bytes("ls/eqs/")
+}
+
+// EquivocationSlashKey returns the key that records the slash for the given equivocation evidence ID
+func EquivocationSlashKey(evidenceID common.Hash) common.Bytes {
+	return append(EquivocationSlashKeyPrefix(), evidenceID[:]...)
+}
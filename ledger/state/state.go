@@ -77,6 +77,16 @@ func (s *LedgerState) ResetState(block *core.Block) result.Result {
 	return result.OK
 }
 
+// ResetScreened discards the screened transactions by resetting the screened view to the delivered view
+func (s *LedgerState) ResetScreened() result.Result {
+	screened, err := s.delivered.Copy()
+	if err != nil {
+		return result.Error(fmt.Sprintf("Failed to copy to the screened view: %v", err))
+	}
+	s.screened = screened
+	return result.OK
+}
+
 // Finalize updates the finalized view.
 func (s *LedgerState) Finalize(height uint64, stateRootHash common.Hash) result.Result {
 	storeview := NewStoreView(height, stateRootHash, s.db)
@@ -96,6 +106,11 @@ func (s *LedgerState) GetChainID() string {
 	return s.chainID
 }
 
+// ChainParams returns the fork schedule of the chain.
+func (s *LedgerState) ChainParams() *common.ChainParams {
+	return common.GetChainParams(s.GetChainID())
+}
+
 // DB returns the database instance of the ledger state
 func (s *LedgerState) DB() database.Database {
 	return s.db
@@ -111,6 +111,20 @@ func (sv *StoreView) ProveVCP(vcpKey []byte, vp *core.VCPProof) error {
 	return sv.store.ProveVCP(vcpKey, vp)
 }
 
+// ProveAccount writes the merkle proof of the account against the state root to proofDb.
+func (sv *StoreView) ProveAccount(addr common.Address, proofDb database.Putter) error {
+	return sv.store.Prove(AccountKey(addr), proofDb)
+}
+
+// ProveStorage writes the merkle proof of the storage slot against the storage root of the account to proofDb.
+func (sv *StoreView) ProveStorage(addr common.Address, key common.Hash, proofDb database.Putter) error {
+	account := sv.GetAccount(addr)
+	if account == nil {
+		return fmt.Errorf("Account with address %v is not found", addr.Hex())
+	}
+	return sv.getAccountStorage(account).Prove(key[:], proofDb)
+}
+
 // Delete removes the value corresponding to the key
 func (sv *StoreView) Delete(key common.Bytes) {
 	sv.store.Delete(key)
@@ -295,6 +309,31 @@ func (sv *StoreView) DeleteExpiredSplitRules(currentBlockHeight uint64) bool {
 	return true
 }
 
+// GetChainParams gets the fork schedule stored in the state (e.g. in the genesis snapshot).
+func (sv *StoreView) GetChainParams() *common.ChainParams {
+	data := sv.Get(ChainParamsKey())
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	params := &common.ChainParams{}
+	err := types.FromBytes(data, params)
+	if err != nil {
+		log.Panicf("Error reading chain params %X, error: %v",
+			data, err.Error())
+	}
+	return params
+}
+
+// UpdateChainParams updates the fork schedule stored in the state.
+func (sv *StoreView) UpdateChainParams(params *common.ChainParams) {
+	paramsBytes, err := types.ToBytes(params)
+	if err != nil {
+		log.Panicf("Error writing chain params %v, error: %v",
+			params, err.Error())
+	}
+	sv.Set(ChainParamsKey(), paramsBytes)
+}
+
 // GetValidatorCandidatePool gets the validator candidate pool.
 func (sv *StoreView) GetValidatorCandidatePool() *core.ValidatorCandidatePool {
 	data := sv.Get(ValidatorCandidatePoolKey())
@@ -418,6 +457,21 @@ func (sv *StoreView) SetTotalEENStake(amount *big.Int) {
 	sv.Set(EliteEdgeNodesTotalActiveStakeKey(), amount.Bytes())
 }
 
+// IsEquivocationSlashed returns whether the offender has been slashed for the given equivocation evidence ID
+func (sv *StoreView) IsEquivocationSlashed(evidenceID common.Hash) bool {
+	data := sv.Get(EquivocationSlashKey(evidenceID))
+	return len(data) > 0
+}
+
+// SetEquivocationSlashed records the height of the block that slashes the offender for the given evidence ID
+func (sv *StoreView) SetEquivocationSlashed(evidenceID common.Hash, height uint64) {
+	heightBytes, err := types.ToBytes(height)
+	if err != nil {
+		log.Panicf("Error writing equivocation slash height %v, error: %v", height, err)
+	}
+	sv.Set(EquivocationSlashKey(evidenceID), heightBytes)
+}
+
 func (sv *StoreView) GetStore() *treestore.TreeStore {
 	return sv.store
 }
@@ -92,24 +92,24 @@ const (
 	ReservedFundFreezePeriodDuration uint64 = 5
 )
 
-func GetMinimumGasPrice(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightNewFeeAdjustment {
+func GetMinimumGasPrice(chainParams *common.ChainParams, blockHeight uint64) *big.Int {
+	if blockHeight < chainParams.HeightNewFeeAdjustment {
 		return new(big.Int).SetUint64(MinimumGasPrice)
 	}
 
 	return new(big.Int).SetUint64(MinimumGasPriceNewFee)
 }
 
-func GetMaxGasLimit(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightNewFeeAdjustment {
+func GetMaxGasLimit(chainParams *common.ChainParams, blockHeight uint64) *big.Int {
+	if blockHeight < chainParams.HeightNewFeeAdjustment {
 		return new(big.Int).SetUint64(MaximumTxGasLimit)
 	}
 
 	return new(big.Int).SetUint64(MaximumTxGasLimitNewFee)
 }
 
-func GetMinimumTransactionFeeDTokenWei(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightNewFeeAdjustment {
+func GetMinimumTransactionFeeDTokenWei(chainParams *common.ChainParams, blockHeight uint64) *big.Int {
+	if blockHeight < chainParams.HeightNewFeeAdjustment {
 		return new(big.Int).SetUint64(MinimumTransactionFeeDTokenWei)
 	}
 
@@ -117,8 +117,8 @@ func GetMinimumTransactionFeeDTokenWei(blockHeight uint64) *big.Int {
 }
 
 // Special handling for many-to-many SendTx
-func GetSendTxMinimumTransactionFeeDTokenWei(numAccountsAffected uint64, blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightNewFeeAdjustment {
+func GetSendTxMinimumTransactionFeeDTokenWei(chainParams *common.ChainParams, numAccountsAffected uint64, blockHeight uint64) *big.Int {
+	if blockHeight < chainParams.HeightNewFeeAdjustment {
 		return new(big.Int).SetUint64(MinimumTransactionFeeDTokenWei) // backward compatiblity
 	}
 
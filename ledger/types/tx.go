@@ -35,6 +35,7 @@ Transaction Types:
  - WithdrawStakeTx         Withdraw stake from a target address (e.g. a validator)
  - SmartContractTx         Execute smart contract
  - StakeRewardDistribution Defines how stake reward is distributed
+ - EquivocationSlashTx     Transaction for slashing a validator that signed conflicting votes or proposals
 */
 
 // Gas of regular transactions
@@ -1018,6 +1019,43 @@ func (tx *StakeRewardDistributionTx) String() string {
 		tx.Holder.Address, tx.Beneficiary.Address, tx.SplitBasisPoint)
 }
 
+//-----------------------------------------------------------------------------
+
+//
+// EquivocationSlashTx is added to a block by the proposer, similar to the CoinbaseTx. It slashes the stake of the
+// validator that signed two conflicting votes or proposals, as proven by the evidence.
+//
+type EquivocationSlashTx struct {
+	Proposer TxInput                   `json:"proposer"`
+	Evidence core.EquivocationEvidence `json:"evidence"`
+}
+
+func (_ *EquivocationSlashTx) AssertIsTx() {}
+
+func (tx *EquivocationSlashTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Proposer.Signature
+	tx.Proposer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Proposer.Signature = sig
+	return signBytes
+}
+
+func (tx *EquivocationSlashTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Proposer.Address == addr {
+		tx.Proposer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *EquivocationSlashTx) String() string {
+	return fmt.Sprintf("EquivocationSlashTx{proposer: %v, evidence: %v}", tx.Proposer.Address, tx.Evidence.String())
+}
+
 // --------------- Utils --------------- //
 
 type EthereumTxWrapper struct {
@@ -1098,7 +1136,7 @@ const CHAIN_ID_OFFSET int64 = 5646 //360
 
 func MapChainID(chainIDStr string, blockHeight uint64) *big.Int {
 	chainIDWithoutOffset := mapChainIDWithoutOffset(chainIDStr)
-	if blockHeight < common.HeightRPCCompatibility {
+	if blockHeight < common.GetChainParams(chainIDStr).HeightRPCCompatibility {
 		return chainIDWithoutOffset
 	}
 
@@ -33,7 +33,7 @@ import (
 // requires a deterministic gas count based on the input size of the Run method of the
 // contract.
 type PrecompiledContract interface {
-	RequiredGas(input []byte, blockHeight uint64) uint64                                       // RequiredPrice calculates the contract gas use
+	RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64      // RequiredPrice calculates the contract gas use
 	Run(evm *EVM, input []byte, callerAddr common.Address, contract *Contract) ([]byte, error) // Run runs the precompiled contract
 }
 
@@ -99,7 +99,7 @@ var PrecompiledContractsWrappedDneroSupport = map[common.Address]PrecompiledCont
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 func RunPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contract *Contract) (ret []byte, err error) {
 	blockHeight := evm.StateDB.GetBlockHeight()
-	gas := p.RequiredGas(input, blockHeight)
+	gas := p.RequiredGas(input, evm.ChainParams, blockHeight)
 	if contract.UseGas(gas) {
 		callerAddr := contract.CallerAddress
 		return p.Run(evm, input, callerAddr, contract)
@@ -110,7 +110,7 @@ func RunPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contr
 // ECRECOVER implemented as a native contract.
 type ecrecover struct{}
 
-func (c *ecrecover) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *ecrecover) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.EcrecoverGas
 }
 
@@ -147,7 +147,7 @@ type sha256hash struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *sha256hash) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *sha256hash) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.Sha256PerWordGas + params.Sha256BaseGas
 }
 func (c *sha256hash) Run(evm *EVM, input []byte, callerAddr common.Address, contract *Contract) ([]byte, error) {
@@ -162,7 +162,7 @@ type ripemd160hash struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *ripemd160hash) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *ripemd160hash) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.Ripemd160PerWordGas + params.Ripemd160BaseGas
 }
 func (c *ripemd160hash) Run(evm *EVM, input []byte, callerAddr common.Address, contract *Contract) ([]byte, error) {
@@ -178,7 +178,7 @@ type dataCopy struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *dataCopy) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *dataCopy) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.IdentityPerWordGas + params.IdentityBaseGas
 }
 func (c *dataCopy) Run(evm *EVM, in []byte, callerAddr common.Address, contract *Contract) ([]byte, error) {
@@ -203,7 +203,7 @@ var (
 )
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bigModExp) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *bigModExp) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	var (
 		baseLen = new(big.Int).SetBytes(getData(input, 0, 32))
 		expLen  = new(big.Int).SetBytes(getData(input, 32, 32))
@@ -314,8 +314,8 @@ func newTwistPoint(blob []byte) (*bn256.G2, error) {
 type bn256Add struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256Add) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightNewFeeAdjustment {
+func (c *bn256Add) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
+	if blockHeight < chainParams.HeightNewFeeAdjustment {
 		return params.Bn256AddGas
 	}
 	return params.Bn256AddGasIstanbul
@@ -339,8 +339,8 @@ func (c *bn256Add) Run(evm *EVM, input []byte, callerAddr common.Address, contra
 type bn256ScalarMul struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256ScalarMul) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightNewFeeAdjustment {
+func (c *bn256ScalarMul) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
+	if blockHeight < chainParams.HeightNewFeeAdjustment {
 		return params.Bn256ScalarMulGas
 	}
 
@@ -372,8 +372,8 @@ var (
 type bn256Pairing struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256Pairing) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightNewFeeAdjustment {
+func (c *bn256Pairing) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
+	if blockHeight < chainParams.HeightNewFeeAdjustment {
 		return params.Bn256PairingBaseGas + uint64(len(input)/192)*params.Bn256PairingPerPointGas
 	}
 
@@ -414,7 +414,7 @@ type dneroBalance struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *dneroBalance) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *dneroBalance) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.DneroBalanceGas
 }
 
@@ -431,7 +431,7 @@ type dneroStake struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *dneroStake) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *dneroStake) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.DneroStakeGas
 }
 
@@ -448,7 +448,7 @@ type transferDnero struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *transferDnero) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *transferDnero) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.DneroTransferGas
 }
 
@@ -470,7 +470,7 @@ type stakeToSentry struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *stakeToSentry) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *stakeToSentry) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.StakeToSentryGas
 }
 
@@ -491,7 +491,7 @@ type unstakeFromSentry struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *unstakeFromSentry) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *unstakeFromSentry) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.UnstakeFromSentryGas
 }
 
@@ -509,7 +509,7 @@ type stakeToEEN struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *stakeToEEN) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *stakeToEEN) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.StakeToSentryGas
 }
 
@@ -529,7 +529,7 @@ type unstakeFromEEN struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *unstakeFromEEN) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *unstakeFromEEN) RequiredGas(input []byte, chainParams *common.ChainParams, blockHeight uint64) uint64 {
 	return params.UnstakeFromSentryGas
 }
 
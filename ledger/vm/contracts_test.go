@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/store/database/backend"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -336,13 +338,19 @@ var bn256PairingTests = []precompiledTest{
 	},
 }
 
+func newPrecompiledTestEVM() *EVM {
+	store := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	return NewEVM(Context{}, store, nil, Config{})
+}
+
 func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
 	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
+	evm := newPrecompiledTestEVM()
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
-		nil, new(big.Int), new(big.Int), p.RequiredGas(in))
+		nil, new(big.Int), new(big.Int), p.RequiredGas(in, evm.ChainParams, evm.StateDB.GetBlockHeight()))
 	t.Run(fmt.Sprintf("%s-Gas=%d", test.name, contract.Gas), func(t *testing.T) {
-		if res, err := RunPrecompiledContract(nil, p, in, contract); err != nil {
+		if res, err := RunPrecompiledContract(evm, p, in, contract); err != nil {
 			t.Error(err)
 		} else if common.Bytes2Hex(res) != test.expected {
 			t.Errorf("Expected %v, got %v", test.expected, common.Bytes2Hex(res))
@@ -356,7 +364,8 @@ func benchmarkPrecompiled(addr string, test precompiledTest, bench *testing.B) {
 	}
 	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
-	reqGas := p.RequiredGas(in)
+	evm := newPrecompiledTestEVM()
+	reqGas := p.RequiredGas(in, evm.ChainParams, evm.StateDB.GetBlockHeight())
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
 		nil, new(big.Int), new(big.Int), reqGas)
 
@@ -371,7 +380,7 @@ func benchmarkPrecompiled(addr string, test precompiledTest, bench *testing.B) {
 		for i := 0; i < bench.N; i++ {
 			contract.Gas = reqGas
 			copy(data, in)
-			res, err = RunPrecompiledContract(nil, p, data, contract)
+			res, err = RunPrecompiledContract(evm, p, data, contract)
 		}
 		bench.StopTimer()
 		//Check if it is correct
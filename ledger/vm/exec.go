@@ -13,6 +13,13 @@ import (
 
 // Execute executes the given smart contract
 func Execute(parentBlock *core.Block, tx *types.SmartContractTx, storeView *state.StoreView) (evmRet common.Bytes,
+	contractAddr common.Address, gasUsed uint64, evmErr error) {
+	return ExecuteWithConfig(parentBlock, tx, storeView, Config{})
+}
+
+// ExecuteWithConfig executes the given smart contract with the given interpreter configuration,
+// e.g. with Debug enabled and a Tracer attached to collect the execution traces
+func ExecuteWithConfig(parentBlock *core.Block, tx *types.SmartContractTx, storeView *state.StoreView, config Config) (evmRet common.Bytes,
 	contractAddr common.Address, gasUsed uint64, evmErr error) {
 	context := Context{
 		CanTransfer: CanTransfer,
@@ -23,12 +30,12 @@ func Execute(parentBlock *core.Block, tx *types.SmartContractTx, storeView *stat
 		BlockNumber: new(big.Int).SetUint64(parentBlock.Height + 1),
 		Time:        parentBlock.Timestamp,
 		Difficulty:  new(big.Int).SetInt64(0),
+		ChainParams: common.GetChainParams(parentBlock.ChainID),
 	}
 	chainIDBigInt := types.MapChainID(parentBlock.ChainID, context.BlockNumber.Uint64())
 	chainConfig := &params.ChainConfig{
 		ChainID: chainIDBigInt,
 	}
-	config := Config{}
 	evm := NewEVM(context, storeView, chainConfig, config)
 
 	value := tx.From.Coins.DTokenWei
@@ -50,7 +57,7 @@ func Execute(parentBlock *core.Block, tx *types.SmartContractTx, storeView *stat
 	// 	return common.Bytes{}, common.Address{}, 0, ErrInvalidGasLimit
 	// }
 	blockHeight := storeView.Height() + 1
-	maxGasLimit := types.GetMaxGasLimit(blockHeight)
+	maxGasLimit := types.GetMaxGasLimit(context.ChainParams, blockHeight)
 	if new(big.Int).SetUint64(gasLimit).Cmp(maxGasLimit) > 0 {
 		return common.Bytes{}, common.Address{}, 0, ErrInvalidGasLimit
 	}
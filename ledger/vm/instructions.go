@@ -583,7 +583,7 @@ func opGasLimit(pc *uint64, interpreter *EVMInterpreter, contract *Contract, mem
 // opChainID implements CHAINID opcode
 func opChainID(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	var chainID *big.Int
-	if !SupportWrappedDnero(interpreter.evm.StateDB.GetBlockHeight()) {
+	if !SupportWrappedDnero(interpreter.evm.ChainParams, interpreter.evm.StateDB.GetBlockHeight()) {
 		chainID = interpreter.evm.chainConfig.ChainID
 	} else {
 		chainID = big.NewInt(0).Set(interpreter.evm.chainConfig.ChainID)
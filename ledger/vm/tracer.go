@@ -0,0 +1,230 @@
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+)
+
+var _ Tracer = (*CallTracer)(nil)
+
+// revertSelector is the 4-byte selector of Error(string), which the Solidity compiler
+// uses to encode the reason string passed to revert() and require()
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// CallFrame describes a single call frame of a smart contract execution
+type CallFrame struct {
+	Type         string            `json:"type"`
+	From         common.Address    `json:"from"`
+	To           common.Address    `json:"to"`
+	Value        *common.JSONBig   `json:"value,omitempty"`
+	Gas          common.JSONUint64 `json:"gas"`
+	GasUsed      common.JSONUint64 `json:"gas_used"`
+	Input        hexutil.Bytes     `json:"input"`
+	Output       hexutil.Bytes     `json:"output"`
+	Error        string            `json:"error,omitempty"`
+	RevertReason string            `json:"revert_reason,omitempty"`
+	Calls        []*CallFrame      `json:"calls,omitempty"`
+}
+
+// CallTracer is an EVM tracer which records the call tree of an execution,
+// including the gas used by each frame and the revert reasons.
+type CallTracer struct {
+	root  *CallFrame
+	stack []*CallFrame
+}
+
+// NewCallTracer returns a new call tracer
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// CaptureStart implements the Tracer interface to record the outermost call frame.
+func (ct *CallTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	typ := CALL
+	if create {
+		typ = CREATE
+	}
+	ct.root = newCallFrame(typ, from, to, input, gas, value)
+	ct.stack = []*CallFrame{ct.root}
+	return nil
+}
+
+// CaptureState implements the Tracer interface.
+func (ct *CallTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureFault implements the Tracer interface.
+func (ct *CallTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnter implements the Tracer interface to record a nested call frame.
+func (ct *CallTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	if len(ct.stack) == 0 {
+		return nil
+	}
+	frame := newCallFrame(typ, from, to, input, gas, value)
+	parent := ct.stack[len(ct.stack)-1]
+	parent.Calls = append(parent.Calls, frame)
+	ct.stack = append(ct.stack, frame)
+	return nil
+}
+
+// CaptureExit implements the Tracer interface to finalize a nested call frame.
+func (ct *CallTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	if len(ct.stack) <= 1 {
+		return nil
+	}
+	frame := ct.stack[len(ct.stack)-1]
+	ct.stack = ct.stack[:len(ct.stack)-1]
+	frame.finalize(output, gasUsed, err)
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface to finalize the outermost call frame.
+func (ct *CallTracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	if ct.root == nil {
+		return nil
+	}
+	ct.root.finalize(output, gasUsed, err)
+	ct.stack = nil
+	return nil
+}
+
+// CallFrame returns the root of the captured call tree, or nil if nothing was captured
+func (ct *CallTracer) CallFrame() *CallFrame {
+	return ct.root
+}
+
+func newCallFrame(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) *CallFrame {
+	frame := &CallFrame{
+		Type:  typ.String(),
+		From:  from,
+		To:    to,
+		Gas:   common.JSONUint64(gas),
+		Input: common.CopyBytes(input),
+	}
+	if value != nil {
+		frame.Value = (*common.JSONBig)(new(big.Int).Set(value))
+	}
+	return frame
+}
+
+func (frame *CallFrame) finalize(output []byte, gasUsed uint64, err error) {
+	frame.GasUsed = common.JSONUint64(gasUsed)
+	frame.Output = common.CopyBytes(output)
+	if err == nil {
+		return
+	}
+	frame.Error = err.Error()
+	if err == errExecutionReverted {
+		if reason, ok := UnpackRevertReason(output); ok {
+			frame.RevertReason = reason
+		}
+	}
+}
+
+// IsExecutionReverted returns true if the given error indicates that the execution
+// was reverted by the REVERT opcode, i.e. the remaining gas was refunded.
+func IsExecutionReverted(err error) bool {
+	return err == errExecutionReverted
+}
+
+// UnpackRevertReason decodes the reason string from the return data of a reverted
+// execution. It returns false if the data is not an ABI encoded Error(string).
+func UnpackRevertReason(data []byte) (string, bool) {
+	if len(data) < 4+32+32 || !bytes.Equal(data[:4], revertSelector) {
+		return "", false
+	}
+	data = data[4:]
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(data)) {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[offset.Uint64():start])
+	if !length.IsUint64() || start+length.Uint64() > uint64(len(data)) {
+		return "", false
+	}
+	return string(data[start : start+length.Uint64()]), true
+}
+
+// MultiTracer dispatches the tracing events to multiple tracers, e.g. to collect
+// both the struct logs and the call tree in a single execution.
+type MultiTracer struct {
+	tracers []Tracer
+}
+
+var _ Tracer = (*MultiTracer)(nil)
+
+// NewMultiTracer returns a new tracer which dispatches the events to the given tracers
+func NewMultiTracer(tracers ...Tracer) *MultiTracer {
+	return &MultiTracer{
+		tracers: tracers,
+	}
+}
+
+// CaptureStart implements the Tracer interface.
+func (mt *MultiTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	for _, t := range mt.tracers {
+		if err := t.CaptureStart(from, to, create, input, gas, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CaptureState implements the Tracer interface.
+func (mt *MultiTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	for _, t := range mt.tracers {
+		if terr := t.CaptureState(env, pc, op, gas, cost, memory, stack, contract, depth, err); terr != nil {
+			return terr
+		}
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface.
+func (mt *MultiTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	for _, t := range mt.tracers {
+		if terr := t.CaptureFault(env, pc, op, gas, cost, memory, stack, contract, depth, err); terr != nil {
+			return terr
+		}
+	}
+	return nil
+}
+
+// CaptureEnter implements the Tracer interface.
+func (mt *MultiTracer) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) error {
+	for _, t := range mt.tracers {
+		if err := t.CaptureEnter(typ, from, to, input, gas, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CaptureExit implements the Tracer interface.
+func (mt *MultiTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	for _, t := range mt.tracers {
+		if terr := t.CaptureExit(output, gasUsed, err); terr != nil {
+			return terr
+		}
+	}
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface.
+func (mt *MultiTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	for _, t := range mt.tracers {
+		if terr := t.CaptureEnd(output, gasUsed, d, err); terr != nil {
+			return terr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package vm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/vm/params"
+	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallTracer(t *testing.T) {
+	assert := assert.New(t)
+
+	storeView := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	callerAddr := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	outerAddr := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	innerAddr := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	storeView.CreateAccount(callerAddr)
+
+	// ASM:
+	// push 0x0 (out size), push 0x0 (out offset), push 0x0 (in size), push 0x0 (in offset)
+	// push 0x0 (value), push20 innerAddr, push2 0xffff (gas)
+	// call
+	// stop
+	outerCode, _ := hex.DecodeString("60006000600060006000" + "73" + hex.EncodeToString(innerAddr[:]) + "61ffff" + "f1" + "00")
+	storeView.SetCode(outerAddr, outerCode)
+
+	// ASM:
+	// push 0x0, push 0x0, revert
+	innerCode, _ := hex.DecodeString("60006000fd")
+	storeView.SetCode(innerAddr, innerCode)
+
+	tracer := NewCallTracer()
+	structLogger := NewStructLogger(nil)
+	config := Config{
+		Debug:  true,
+		Tracer: NewMultiTracer(structLogger, tracer),
+	}
+	context := Context{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		Origin:      callerAddr,
+		GasPrice:    big.NewInt(1),
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(0),
+		Difficulty:  big.NewInt(0),
+	}
+	evm := NewEVM(context, storeView, params.TestChainConfig, config)
+
+	gas := uint64(100000)
+	_, leftOverGas, err := evm.Call(AccountRef(callerAddr), outerAddr, nil, gas, big.NewInt(0), big.NewInt(0))
+	assert.Nil(err)
+
+	root := tracer.CallFrame()
+	assert.NotNil(root)
+	assert.Equal("CALL", root.Type)
+	assert.Equal(callerAddr, root.From)
+	assert.Equal(outerAddr, root.To)
+	assert.Equal(common.JSONUint64(gas-leftOverGas), root.GasUsed)
+	assert.Equal("", root.Error)
+
+	assert.Equal(1, len(root.Calls))
+	inner := root.Calls[0]
+	assert.Equal("CALL", inner.Type)
+	assert.Equal(outerAddr, inner.From)
+	assert.Equal(innerAddr, inner.To)
+	assert.Equal(common.JSONUint64(0xffff), inner.Gas)
+	assert.Equal(errExecutionReverted.Error(), inner.Error)
+	assert.True(uint64(inner.GasUsed) < uint64(inner.Gas)) // revert refunds the remaining gas
+
+	assert.True(len(structLogger.StructLogs()) > 0)
+	maxDepth := 0
+	for _, log := range structLogger.StructLogs() {
+		if log.Depth > maxDepth {
+			maxDepth = log.Depth
+		}
+	}
+	assert.Equal(2, maxDepth)
+}
+
+func TestUnpackRevertReason(t *testing.T) {
+	assert := assert.New(t)
+
+	// Error("Insufficient balance")
+	data, _ := hex.DecodeString("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000014" +
+		"496e73756666696369656e742062616c616e6365000000000000000000000000")
+	reason, ok := UnpackRevertReason(data)
+	assert.True(ok)
+	assert.Equal("Insufficient balance", reason)
+
+	_, ok = UnpackRevertReason(data[:40])
+	assert.False(ok)
+
+	_, ok = UnpackRevertReason(nil)
+	assert.False(ok)
+
+	data[3] = 0xa1 // wrong selector
+	_, ok = UnpackRevertReason(data)
+	assert.False(ok)
+}
@@ -39,12 +39,12 @@ type (
 	GetHashFunc func(uint64) common.Hash
 )
 
-func SupportDneroTransferInEVM(blockHeight uint64) bool {
-	return blockHeight >= common.HeightSupportDneroTokenInSmartContract
+func SupportDneroTransferInEVM(chainParams *common.ChainParams, blockHeight uint64) bool {
+	return blockHeight >= chainParams.HeightSupportDneroTokenInSmartContract
 }
 
-func SupportWrappedDnero(blockHeight uint64) bool {
-	return blockHeight >= common.HeightSupportWrappedDnero
+func SupportWrappedDnero(chainParams *common.ChainParams, blockHeight uint64) bool {
+	return blockHeight >= chainParams.HeightSupportWrappedDnero
 }
 
 // CanTransfer checks whether there are enough funds in the address' account to make a transfer.
@@ -242,11 +242,11 @@ func UnstakeFromEEN(db StateDB, addr common.Address, eenAddr common.Address) boo
 	return true
 }
 
-func getPrecompiledContracts(blockHeight uint64) map[common.Address]PrecompiledContract {
+func getPrecompiledContracts(chainParams *common.ChainParams, blockHeight uint64) map[common.Address]PrecompiledContract {
 	var precompiles map[common.Address]PrecompiledContract
-	if blockHeight < common.HeightSupportDneroTokenInSmartContract {
+	if blockHeight < chainParams.HeightSupportDneroTokenInSmartContract {
 		precompiles = PrecompiledContractsByzantium
-	} else if blockHeight < common.HeightSupportWrappedDnero {
+	} else if blockHeight < chainParams.HeightSupportWrappedDnero {
 		precompiles = PrecompiledContractsDneroSupport
 	} else {
 		precompiles = PrecompiledContractsWrappedDneroSupport
@@ -258,7 +258,7 @@ func getPrecompiledContracts(blockHeight uint64) map[common.Address]PrecompiledC
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	if contract.CodeAddr != nil {
 		blockHeight := evm.StateDB.GetBlockHeight()
-		precompiles := getPrecompiledContracts(blockHeight)
+		precompiles := getPrecompiledContracts(evm.ChainParams, blockHeight)
 		if p := precompiles[*contract.CodeAddr]; p != nil {
 			return RunPrecompiledContract(evm, p, input, contract)
 		}
@@ -279,6 +279,26 @@ func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, err
 	return nil, ErrNoCompatibleInterpreter
 }
 
+// captureEnter notifies the tracer that a call frame is entered. The outermost
+// frame is reported through CaptureStart, and the nested ones through CaptureEnter.
+func (evm *EVM) captureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if evm.depth == 0 {
+		create := (typ == CREATE || typ == CREATE2)
+		evm.vmConfig.Tracer.CaptureStart(from, to, create, input, gas, value)
+		return
+	}
+	evm.vmConfig.Tracer.CaptureEnter(typ, from, to, input, gas, value)
+}
+
+// captureExit notifies the tracer that a call frame is exited.
+func (evm *EVM) captureExit(output []byte, gasUsed uint64, t time.Duration, err error) {
+	if evm.depth == 0 {
+		evm.vmConfig.Tracer.CaptureEnd(output, gasUsed, t, err)
+		return
+	}
+	evm.vmConfig.Tracer.CaptureExit(output, gasUsed, err)
+}
+
 // Context provides the EVM with auxiliary information. Once provided
 // it shouldn't be modified.
 type Context struct {
@@ -300,6 +320,9 @@ type Context struct {
 	BlockNumber *big.Int       // Provides information for NUMBER
 	Time        *big.Int       // Provides information for TIME
 	Difficulty  *big.Int       // Provides information for DIFFICULTY
+
+	// Chain information
+	ChainParams *common.ChainParams // Provides the fork schedule, defaults to the mainnet schedule
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -342,6 +365,9 @@ type EVM struct {
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
 // only ever be used *once*.
 func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmConfig Config) *EVM {
+	if ctx.ChainParams == nil {
+		ctx.ChainParams = common.DefaultChainParams("")
+	}
 	evm := &EVM{
 		Context:     ctx,
 		StateDB:     statedb,
@@ -391,7 +417,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		return nil, gas, ErrInsufficientBalance
 	}
 
-	if SupportDneroTransferInEVM(blockHeight) && !CanTransferDnero(evm.StateDB, caller.Address(), dneroValue) {
+	if SupportDneroTransferInEVM(evm.ChainParams, blockHeight) && !CanTransferDnero(evm.StateDB, caller.Address(), dneroValue) {
 		return nil, gas, ErrInsufficientDneroBlance
 	}
 
@@ -401,17 +427,17 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	)
 	if !evm.StateDB.Exist(addr) {
 
-		precompiles := getPrecompiledContracts(blockHeight)
+		precompiles := getPrecompiledContracts(evm.ChainParams, blockHeight)
 		if precompiles[addr] == nil && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
-			if evm.vmConfig.Debug && evm.depth == 0 {
-				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
-				evm.vmConfig.Tracer.CaptureEnd(ret, 0, 0, nil)
+			if evm.vmConfig.Debug {
+				evm.captureEnter(CALL, caller.Address(), addr, input, gas, value)
+				evm.captureExit(ret, 0, 0, nil)
 			}
 			return nil, gas, nil
 		}
 
-		if !SupportDneroTransferInEVM(blockHeight) { // just for backward compatibility
+		if !SupportDneroTransferInEVM(evm.ChainParams, blockHeight) { // just for backward compatibility
 			evm.StateDB.CreateAccount(addr)
 		} else { // should not wipe out the Dnero/DToken balance sent to the contract address prior to contract creation
 			evm.StateDB.CreateAccountWithPreviousBalance(addr)
@@ -419,7 +445,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	}
 	Transfer(evm.StateDB, caller.Address(), to.Address(), value)
 
-	if SupportDneroTransferInEVM(blockHeight) {
+	if SupportDneroTransferInEVM(evm.ChainParams, blockHeight) {
 		TransferDnero(evm.StateDB, caller.Address(), to.Address(), dneroValue)
 	}
 
@@ -428,6 +454,15 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	contract := NewContract(caller, to, value, dneroValue, gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	// Capture the tracer start/end events in debug mode
+	if evm.vmConfig.Debug {
+		evm.captureEnter(CALL, caller.Address(), addr, input, gas, value)
+		start := time.Now()
+		defer func() { // Lazy evaluation of the parameters
+			evm.captureExit(ret, gas-leftOverGas, time.Since(start), err)
+		}()
+	}
+
 	ret, err = run(evm, contract, input, false)
 
 	// When an error was returned by the EVM or when setting the creation code
@@ -464,7 +499,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	}
 
 	blockHeight := evm.StateDB.GetBlockHeight()
-	if SupportWrappedDnero(blockHeight) && !CanTransferDnero(evm.StateDB, caller.Address(), dneroValue) {
+	if SupportWrappedDnero(evm.ChainParams, blockHeight) && !CanTransferDnero(evm.StateDB, caller.Address(), dneroValue) {
 		return nil, gas, ErrInsufficientDneroBlance
 	}
 
@@ -478,6 +513,14 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	contract := NewContract(caller, to, value, dneroValue, gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	if evm.vmConfig.Debug {
+		evm.captureEnter(CALLCODE, caller.Address(), addr, input, gas, value)
+		start := time.Now()
+		defer func() {
+			evm.captureExit(ret, gas-leftOverGas, time.Since(start), err)
+		}()
+	}
+
 	ret, err = run(evm, contract, input, false)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
@@ -511,6 +554,14 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	contract := NewContract(caller, to, nil, nil, gas).AsDelegate()
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	if evm.vmConfig.Debug {
+		evm.captureEnter(DELEGATECALL, caller.Address(), addr, input, gas, nil)
+		start := time.Now()
+		defer func() {
+			evm.captureExit(ret, gas-leftOverGas, time.Since(start), err)
+		}()
+	}
+
 	ret, err = run(evm, contract, input, false)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
@@ -544,6 +595,14 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	contract := NewContract(caller, to, new(big.Int), new(big.Int), gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
+	if evm.vmConfig.Debug {
+		evm.captureEnter(STATICCALL, caller.Address(), addr, input, gas, nil)
+		start := time.Now()
+		defer func() {
+			evm.captureExit(ret, gas-leftOverGas, time.Since(start), err)
+		}()
+	}
+
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in Homestead this also counts for code storage gas errors.
@@ -570,7 +629,7 @@ func (c *codeAndHash) Hash() common.Hash {
 }
 
 // create creates a new contract using code as deployment code.
-func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, dneroValue *big.Int, address common.Address) ([]byte, common.Address, uint64, error) {
+func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64, value *big.Int, dneroValue *big.Int, address common.Address, typ OpCode) ([]byte, common.Address, uint64, error) {
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
 	if evm.depth > int(params.CallCreateDepth) {
@@ -581,7 +640,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	}
 
 	blockHeight := evm.StateDB.GetBlockHeight()
-	if SupportDneroTransferInEVM(blockHeight) && !CanTransferDnero(evm.StateDB, caller.Address(), dneroValue) {
+	if SupportDneroTransferInEVM(evm.ChainParams, blockHeight) && !CanTransferDnero(evm.StateDB, caller.Address(), dneroValue) {
 		return nil, common.Address{}, gas, ErrInsufficientDneroBlance
 	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
@@ -595,14 +654,14 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	// Create a new account on the state
 	snapshot := evm.StateDB.Snapshot()
 
-	if !SupportDneroTransferInEVM(blockHeight) { // just for backward compatibility
+	if !SupportDneroTransferInEVM(evm.ChainParams, blockHeight) { // just for backward compatibility
 		evm.StateDB.CreateAccount(address)
 	} else { // should not wipe out the Dnero/DToken balance sent to the contract address prior to contract creation
 		evm.StateDB.CreateAccountWithPreviousBalance(address)
 	}
 	Transfer(evm.StateDB, caller.Address(), address, value)
 
-	if SupportDneroTransferInEVM(blockHeight) {
+	if SupportDneroTransferInEVM(evm.ChainParams, blockHeight) {
 		TransferDnero(evm.StateDB, caller.Address(), address, dneroValue)
 	}
 
@@ -616,8 +675,8 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		return nil, address, gas, nil
 	}
 
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureStart(caller.Address(), address, true, codeAndHash.code, gas, value)
+	if evm.vmConfig.Debug {
+		evm.captureEnter(typ, caller.Address(), address, codeAndHash.code, gas, value)
 	}
 	start := time.Now()
 
@@ -651,8 +710,8 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if maxCodeSizeExceeded && err == nil {
 		err = errMaxCodeSizeExceeded
 	}
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
+	if evm.vmConfig.Debug {
+		evm.captureExit(ret, gas-contract.Gas, time.Since(start), err)
 	}
 	return ret, address, contract.Gas, err
 
@@ -661,7 +720,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 // Create creates a new contract using code as deployment code.
 func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int, dneroValue *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
-	return evm.create(caller, &codeAndHash{code: code}, gas, value, dneroValue, contractAddr)
+	return evm.create(caller, &codeAndHash{code: code}, gas, value, dneroValue, contractAddr, CREATE)
 }
 
 // Create2 creates a new contract using code as deployment code.
@@ -671,7 +730,7 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, dneroEndowment *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
 	codeAndHash := &codeAndHash{code: code}
 	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), codeAndHash.Hash().Bytes())
-	return evm.create(caller, codeAndHash, gas, endowment, dneroEndowment, contractAddr)
+	return evm.create(caller, codeAndHash, gas, endowment, dneroEndowment, contractAddr, CREATE2)
 }
 
 // ChainConfig returns the environment's chain configuration
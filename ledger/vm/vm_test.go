@@ -90,7 +90,7 @@ func TestVMCreate(t *testing.T) {
 	store.SetAccount(addr, account)
 
 	evm := NewEVM(context, store, nil, Config{})
-	_, contractAddress, gas, err := evm.Create(AccountRef(addr), code, math.MaxUint64, big.NewInt(123))
+	_, contractAddress, gas, err := evm.Create(AccountRef(addr), code, math.MaxUint64, big.NewInt(123), big.NewInt(0))
 
 	assert.Nil(err)
 	assert.True(gas < math.MaxUint64)
@@ -147,13 +147,13 @@ func TestContractDeployment(t *testing.T) {
 	store.SetAccount(addr, account)
 
 	evm := NewEVM(context, store, nil, Config{})
-	_, contractAddress, _, err := evm.Create(AccountRef(addr), deployCode, math.MaxUint64, big.NewInt(123))
+	_, contractAddress, _, err := evm.Create(AccountRef(addr), deployCode, math.MaxUint64, big.NewInt(123), big.NewInt(0))
 
 	assert.Nil(err)
 	ccode := store.GetCode(contractAddress)
 	assert.True(bytes.Equal(code, ccode))
 
-	ret, leftOverGas, err := evm.Call(AccountRef(addr), contractAddress, nil, math.MaxUint64, big.NewInt(123))
+	ret, leftOverGas, err := evm.Call(AccountRef(addr), contractAddress, nil, math.MaxUint64, big.NewInt(123), big.NewInt(0))
 	assert.Nil(err)
 	assert.True(leftOverGas < math.MaxUint64)
 	assert.Equal([]byte{0x3}, ret)
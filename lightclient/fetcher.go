@@ -0,0 +1,101 @@
+package lightclient
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/rpc"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// ProofFetcher fetches the merkle proofs from the full nodes. The proofs are NOT trusted, and
+// need to be verified against the finalized headers.
+type ProofFetcher interface {
+	GetVCPProof(blockHash common.Hash) ([]hexutil.Bytes, error)
+	GetAccountProof(address common.Address, height uint64) (*rpc.GetAccountProofResult, error)
+	GetStorageProof(address common.Address, slots []common.Hash, height uint64) (*rpc.GetStorageProofResult, error)
+}
+
+var _ ProofFetcher = (*RPCProofFetcher)(nil)
+
+// RPCProofFetcher fetches the proofs through the RPC endpoints of the full nodes. The endpoints
+// are tried in turn until one of them succeeds.
+type RPCProofFetcher struct {
+	mu      *sync.Mutex
+	clients []*rpcc.RPCClient
+	next    int
+}
+
+func NewRPCProofFetcher(endpoints []string) *RPCProofFetcher {
+	f := &RPCProofFetcher{
+		mu: &sync.Mutex{},
+	}
+	for _, endpoint := range endpoints {
+		f.clients = append(f.clients, rpcc.NewRPCClient(endpoint))
+	}
+	return f
+}
+
+func (f *RPCProofFetcher) GetVCPProof(blockHash common.Hash) ([]hexutil.Bytes, error) {
+	result := &rpc.GetVCPProofResult{}
+	if err := f.call("dnero.GetVCPProof", rpc.GetVCPProofArgs{BlockHash: blockHash}, result); err != nil {
+		return nil, err
+	}
+	return result.Proof, nil
+}
+
+func (f *RPCProofFetcher) GetAccountProof(address common.Address, height uint64) (*rpc.GetAccountProofResult, error) {
+	result := &rpc.GetAccountProofResult{}
+	err := f.call("dnero.GetAccountProof", rpc.GetAccountProofArgs{
+		Address: address.Hex(),
+		Height:  common.JSONUint64(height),
+	}, result)
+	return result, err
+}
+
+func (f *RPCProofFetcher) GetStorageProof(address common.Address, slots []common.Hash, height uint64) (*rpc.GetStorageProofResult, error) {
+	args := rpc.GetStorageProofArgs{
+		Address: address.Hex(),
+		Height:  common.JSONUint64(height),
+	}
+	for _, slot := range slots {
+		args.Slots = append(args.Slots, slot.Hex())
+	}
+	result := &rpc.GetStorageProofResult{}
+	err := f.call("dnero.GetStorageProof", args, result)
+	return result, err
+}
+
+func (f *RPCProofFetcher) call(method string, args interface{}, result interface{}) error {
+	if len(f.clients) == 0 {
+		return errors.New("No RPC endpoint is configured for the light client")
+	}
+
+	var err error
+	for i := 0; i < len(f.clients); i++ {
+		client := f.nextClient()
+		var res *rpcc.RPCResponse
+		res, err = client.Call(method, args)
+		if err != nil {
+			continue
+		}
+		if res.Error != nil {
+			err = res.Error
+			continue
+		}
+		if err = res.GetObject(result); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (f *RPCProofFetcher) nextClient() *rpcc.RPCClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	client := f.clients[f.next%len(f.clients)]
+	f.next++
+	return client
+}
@@ -0,0 +1,525 @@
+package lightclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	log "github.com/sirupsen/logrus"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/netsync"
+	"github.com/dnerochain/dnero/snapshot"
+	"github.com/dnerochain/dnero/store"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "lightclient"})
+
+const (
+	// maxPendingHeight is how far ahead of the latest finalized header the pending headers and votes can be
+	maxPendingHeight = uint64(1024)
+
+	// maxPendingPerHeight caps the pending headers, and the blocks with pending votes, at each height. The
+	// honest chain has a single block at most heights, so the cap only bounds the forks a peer can inject.
+	maxPendingPerHeight = 4
+
+	// maxPendingHeadersPerPeer and maxPendingVotesPerPeer cap the pending headers and votes added by each
+	// peer. An honest peer sends about one header, and one vote per validator, for each pending height.
+	maxPendingHeadersPerPeer = 2 * int(maxPendingHeight)
+	maxPendingVotesPerPeer   = 64 * int(maxPendingHeight)
+
+	validatorSetCacheLimit = 64
+)
+
+var (
+	errHeaderNotFound         = errors.New("Header not found")
+	errNotFinalized           = errors.New("Header for the validator set is not finalized yet")
+	errValidatorSetNotFetched = errors.New("Validator set is not fetched yet")
+	errTooManyHeaders         = errors.New("Too many pending headers from the peer")
+)
+
+var _ netsync.HeaderConsumer = (*LightClient)(nil)
+
+// LightClient tracks the finalized block headers without executing the blocks. A header is
+// committed if its commit certificate, i.e. the HCC votes carried by a child header or the votes
+// gossiped by the validators, has the majority of the stake of the validator set, and it is
+// finalized if a committed child also has it as the HCC. The validator set of a header is
+// selected from the validator candidate pool of its HCC grandparent (or of the trusted root),
+// which is proven by the full nodes against the StateHash of that finalized header.
+type LightClient struct {
+	mu *sync.Mutex
+
+	chainID string
+	store   store.Store
+	fetcher ProofFetcher
+
+	root *core.BlockHeader // trusted root, e.g. the snapshot checkpoint
+	lfb  *core.BlockHeader // latest finalized header
+
+	pending   map[common.Hash]*core.BlockHeader // headers above the lfb
+	committed map[common.Hash]bool
+	votes     map[common.Hash]*core.VoteSet
+
+	pendingHeights map[uint64]int // number of pending headers at each height
+	voteHeights    map[uint64]int // number of blocks with pending votes at each height
+	headerQuota    *peerQuota
+	voteQuota      *peerQuota
+
+	validatorSets *lru.Cache
+}
+
+// NewLightClient creates a light client that trusts the given root header. If the client has
+// finalized headers in the store, it resumes from the latest one.
+func NewLightClient(root *core.BlockHeader, store store.Store, fetcher ProofFetcher) *LightClient {
+	validatorSets, _ := lru.New(validatorSetCacheLimit)
+	lc := &LightClient{
+		mu:            &sync.Mutex{},
+		chainID:       root.ChainID,
+		store:         store,
+		fetcher:       fetcher,
+		root:          root,
+		lfb:           root,
+		pending:       make(map[common.Hash]*core.BlockHeader),
+		committed:     make(map[common.Hash]bool),
+		votes:         make(map[common.Hash]*core.VoteSet),
+		validatorSets: validatorSets,
+
+		pendingHeights: make(map[uint64]int),
+		voteHeights:    make(map[uint64]int),
+		headerQuota:    newPeerQuota(maxPendingHeadersPerPeer),
+		voteQuota:      newPeerQuota(maxPendingVotesPerPeer),
+	}
+
+	lfbHash := common.Hash{}
+	if err := store.Get(latestFinalizedKey(), &lfbHash); err == nil {
+		if lfb := lc.getFinalizedHeader(lfbHash); lfb != nil && lfb.Height > root.Height {
+			lc.lfb = lfb
+		}
+	}
+	if lc.lfb == root {
+		lc.saveFinalizedHeader(root)
+		lc.saveLatestFinalized(root)
+	}
+
+	logger.Infof("Light client starts from height %v, hash %v", lc.lfb.Height, lc.lfb.Hash().Hex())
+	return lc
+}
+
+// GetLatestFinalizedHeader returns the latest finalized header.
+func (lc *LightClient) GetLatestFinalizedHeader() *core.BlockHeader {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.lfb
+}
+
+// GetFinalizedHeaderByHeight returns the finalized header at the given height, or nil if the
+// height is not finalized yet or is below the trusted root.
+func (lc *LightClient) GetFinalizedHeaderByHeight(height uint64) *core.BlockHeader {
+	hash := common.Hash{}
+	if err := lc.store.Get(finalizedHeightKey(height), &hash); err != nil {
+		return nil
+	}
+	return lc.getFinalizedHeader(hash)
+}
+
+// AddHeader implements the netsync.HeaderConsumer interface. It returns an error if the header is
+// invalid, or if the peer exceeds its quota of pending headers.
+func (lc *LightClient) AddHeader(header *core.BlockHeader, peerID string) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	hash := header.Hash()
+	if header.Height <= lc.lfb.Height || header.Height > lc.lfb.Height+maxPendingHeight {
+		return nil
+	}
+	if _, ok := lc.pending[hash]; ok {
+		return nil
+	}
+	if res := header.Validate(lc.chainID); res.IsError() {
+		logger.WithFields(log.Fields{
+			"header.Hash":   hash.Hex(),
+			"header.Height": header.Height,
+			"peer":          peerID,
+			"error":         res.Message,
+		}).Debug("Ignoring invalid header")
+		return errors.New(res.Message)
+	}
+	if lc.pendingHeights[header.Height] >= maxPendingPerHeight {
+		logger.WithFields(log.Fields{
+			"header.Hash":   hash.Hex(),
+			"header.Height": header.Height,
+			"peer":          peerID,
+		}).Debug("Ignoring header, too many pending headers at the height")
+		return nil
+	}
+	if !lc.headerQuota.take(peerID, header.Height) {
+		return errTooManyHeaders
+	}
+
+	lc.pending[hash] = header
+	lc.pendingHeights[header.Height]++
+	if header.HCC.Votes != nil {
+		// The HCC votes of a header form the commit certificate of its HCC block.
+		lc.addVotes(header.HCC.BlockHash, header.HCC.Votes)
+	}
+	lc.process()
+	return nil
+}
+
+// AddVote implements the netsync.HeaderConsumer interface. The votes from the addresses outside the
+// recently fetched validator sets are ignored. It returns an error if the vote is invalid.
+func (lc *LightClient) AddVote(vote core.Vote, peerID string) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if vote.Height <= lc.lfb.Height || vote.Height > lc.lfb.Height+maxPendingHeight {
+		return nil
+	}
+	if res := vote.Validate(); res.IsError() {
+		return errors.New(res.Message)
+	}
+	if !lc.isKnownValidator(vote.ID) {
+		return nil
+	}
+	_, hasVotes := lc.votes[vote.Block]
+	if !hasVotes && lc.voteHeights[vote.Height] >= maxPendingPerHeight {
+		return nil
+	}
+	if !lc.voteQuota.take(peerID, vote.Height) {
+		return nil
+	}
+	if !hasVotes {
+		lc.voteHeights[vote.Height]++
+	}
+
+	voteSet := core.NewVoteSet()
+	voteSet.AddVote(vote)
+	lc.addVotes(vote.Block, voteSet)
+	lc.process()
+	return nil
+}
+
+// GetInventoryStarts implements the netsync.HeaderConsumer interface.
+func (lc *LightClient) GetInventoryStarts() []string {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	starts := []string{}
+	var tip *core.BlockHeader
+	for _, header := range lc.pending {
+		if tip == nil || header.Height > tip.Height {
+			tip = header
+		}
+	}
+	if tip != nil {
+		starts = append(starts, tip.Hash().Hex())
+	}
+	return append(starts, lc.lfb.Hash().Hex())
+}
+
+// isKnownValidator tells whether the address is in any of the recently fetched validator sets.
+func (lc *LightClient) isKnownValidator(address common.Address) bool {
+	for _, key := range lc.validatorSets.Keys() {
+		if validatorSet, ok := lc.validatorSets.Peek(key); ok {
+			if _, err := validatorSet.(*core.ValidatorSet).GetValidator(address); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (lc *LightClient) addVotes(blockHash common.Hash, voteSet *core.VoteSet) {
+	if existing, ok := lc.votes[blockHash]; ok {
+		lc.votes[blockHash] = existing.Merge(voteSet).UniqueVoterAndBlock()
+	} else {
+		lc.votes[blockHash] = voteSet.UniqueVoterAndBlock()
+	}
+}
+
+// process commits and finalizes the pending headers until no more progress can be made. It must be
+// called with lc.mu held. The lock is released while the validator sets are fetched from the full
+// nodes, so the other calls are not blocked by the RPCs.
+func (lc *LightClient) process() {
+	for {
+		committed := false
+		anchors := make(map[common.Hash]*core.BlockHeader)
+		for hash, header := range lc.pending {
+			if lc.committed[hash] {
+				continue
+			}
+			voteSet, ok := lc.votes[hash]
+			if !ok {
+				continue
+			}
+			validatorSet, anchor, err := lc.getValidatorSet(header)
+			if err == errValidatorSetNotFetched {
+				anchors[anchor.Hash()] = anchor
+				continue
+			}
+			if err == nil && lc.commit(header, voteSet, validatorSet) == nil {
+				committed = true
+			}
+		}
+		finalized := lc.tryFinalize()
+		if committed || finalized {
+			continue
+		}
+		if len(anchors) == 0 {
+			return
+		}
+
+		lc.mu.Unlock()
+		fetched := false
+		for _, anchor := range anchors {
+			if err := lc.fetchValidatorSet(anchor); err != nil {
+				logger.WithFields(log.Fields{
+					"anchor.Hash":   anchor.Hash().Hex(),
+					"anchor.Height": anchor.Height,
+					"error":         err,
+				}).Debug("Failed to fetch the validator set")
+				continue
+			}
+			fetched = true
+		}
+		lc.mu.Lock()
+		if !fetched {
+			return
+		}
+	}
+}
+
+// tryFinalize finalizes the highest pending header that has a committed child with it as both
+// the parent and the HCC, which is the same finalization rule as the consensus engine.
+func (lc *LightClient) tryFinalize() bool {
+	var target *core.BlockHeader
+	for hash, header := range lc.pending {
+		if !lc.committed[hash] || header.Parent != header.HCC.BlockHash {
+			continue
+		}
+		parent, ok := lc.pending[header.Parent]
+		if !ok {
+			continue
+		}
+		if target == nil || parent.Height > target.Height {
+			target = parent
+		}
+	}
+	if target == nil {
+		return false
+	}
+	return lc.finalize(target)
+}
+
+func (lc *LightClient) commit(header *core.BlockHeader, voteSet *core.VoteSet, validatorSet *core.ValidatorSet) error {
+	votes := voteSet.FilterByValidators(validatorSet).UniqueVoter()
+	if err := snapshot.ValidateVotes(validatorSet, header, votes); err != nil {
+		return err
+	}
+
+	lc.committed[header.Hash()] = true
+	logger.WithFields(log.Fields{
+		"header.Hash":   header.Hash().Hex(),
+		"header.Height": header.Height,
+	}).Debug("Header committed")
+	return nil
+}
+
+// finalize marks the header and its ancestors down to the current lfb as finalized.
+func (lc *LightClient) finalize(header *core.BlockHeader) bool {
+	lfbHash := lc.lfb.Hash()
+	chain := []*core.BlockHeader{}
+	for curr := header; curr.Hash() != lfbHash; {
+		chain = append(chain, curr)
+		parent, ok := lc.pending[curr.Parent]
+		if !ok {
+			if curr.Parent != lfbHash {
+				logger.WithFields(log.Fields{
+					"header.Hash":   header.Hash().Hex(),
+					"header.Height": header.Height,
+					"lfb.Hash":      lfbHash.Hex(),
+				}).Error("Finalized header does not extend the latest finalized header")
+				return false
+			}
+			break
+		}
+		curr = parent
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		lc.saveFinalizedHeader(chain[i])
+	}
+	lc.saveLatestFinalized(header)
+	lc.lfb = header
+
+	for hash, h := range lc.pending {
+		if h.Height <= header.Height {
+			delete(lc.pending, hash)
+			delete(lc.committed, hash)
+		}
+	}
+	for hash, voteSet := range lc.votes {
+		votes := voteSet.Votes()
+		if len(votes) == 0 || votes[0].Height <= header.Height {
+			delete(lc.votes, hash)
+		}
+	}
+	for height := range lc.pendingHeights {
+		if height <= header.Height {
+			delete(lc.pendingHeights, height)
+		}
+	}
+	for height := range lc.voteHeights {
+		if height <= header.Height {
+			delete(lc.voteHeights, height)
+		}
+	}
+	lc.headerQuota.release(header.Height)
+	lc.voteQuota.release(header.Height)
+
+	logger.WithFields(log.Fields{
+		"header.Hash":   header.Hash().Hex(),
+		"header.Height": header.Height,
+	}).Info("Header finalized")
+	return true
+}
+
+// getValidatorSet returns the validator set for the given header, following the same rule as
+// Ledger.GetFinalizedValidatorCandidatePool: the validator candidate pool is taken from the
+// HCC grandparent of the header, or from the trusted root if it is reached earlier. If the validator
+// set is not fetched yet, it returns errValidatorSetNotFetched along with the header to fetch the
+// validator set for with fetchValidatorSet.
+func (lc *LightClient) getValidatorSet(header *core.BlockHeader) (*core.ValidatorSet, *core.BlockHeader, error) {
+	rootHash := lc.root.Hash()
+	anchor := header
+	for i := 2; i > 0 && anchor.Hash() != rootHash && !anchor.HCC.BlockHash.IsEmpty(); i-- {
+		hccHash := anchor.HCC.BlockHash
+		if h, ok := lc.pending[hccHash]; ok {
+			anchor = h
+		} else if h := lc.getFinalizedHeader(hccHash); h != nil {
+			anchor = h
+		} else {
+			return nil, nil, errHeaderNotFound
+		}
+	}
+
+	anchorHash := anchor.Hash()
+	if cached, ok := lc.validatorSets.Get(anchorHash); ok {
+		return cached.(*core.ValidatorSet), anchor, nil
+	}
+	if anchorHash != rootHash && lc.getFinalizedHeader(anchorHash) == nil {
+		return nil, anchor, errNotFinalized
+	}
+	return nil, anchor, errValidatorSetNotFetched
+}
+
+// fetchValidatorSet fetches the validator candidate pool of the given finalized header from the full
+// nodes, and verifies it against the state hash of the header. It does not access the state guarded
+// by lc.mu, and should be called without holding the lock.
+func (lc *LightClient) fetchValidatorSet(anchor *core.BlockHeader) error {
+	anchorHash := anchor.Hash()
+	proof, err := lc.fetcher.GetVCPProof(anchorHash)
+	if err != nil {
+		return err
+	}
+	vcpProof := &core.VCPProof{}
+	for _, node := range proof {
+		vcpProof.Put(crypto.Keccak256(node), node)
+	}
+	validatorSet, err := snapshot.GetValidatorSetFromVCPProof(anchor.StateHash, vcpProof)
+	if err != nil {
+		return fmt.Errorf("Failed to verify the VCP proof for block %v: %v", anchorHash.Hex(), err)
+	}
+
+	lc.validatorSets.Add(anchorHash, validatorSet)
+	return nil
+}
+
+// peerQuota counts the pending entries added by each peer at each height, so the entries of a peer
+// are released once their heights are finalized.
+type peerQuota struct {
+	limit    int
+	counts   map[string]int
+	byHeight map[uint64]map[string]int
+}
+
+func newPeerQuota(limit int) *peerQuota {
+	return &peerQuota{
+		limit:    limit,
+		counts:   make(map[string]int),
+		byHeight: make(map[uint64]map[string]int),
+	}
+}
+
+// take counts an entry at the given height against the quota of the peer. It returns false if the
+// quota is used up.
+func (q *peerQuota) take(peerID string, height uint64) bool {
+	if q.counts[peerID] >= q.limit {
+		return false
+	}
+	q.counts[peerID]++
+	if _, ok := q.byHeight[height]; !ok {
+		q.byHeight[height] = make(map[string]int)
+	}
+	q.byHeight[height][peerID]++
+	return true
+}
+
+// release gives back the quota of the entries at or below the given height.
+func (q *peerQuota) release(height uint64) {
+	for h, peerCounts := range q.byHeight {
+		if h > height {
+			continue
+		}
+		for peerID, count := range peerCounts {
+			q.counts[peerID] -= count
+			if q.counts[peerID] <= 0 {
+				delete(q.counts, peerID)
+			}
+		}
+		delete(q.byHeight, h)
+	}
+}
+
+// ------------------------------ Store ------------------------------
+
+func finalizedHeaderKey(hash common.Hash) common.Bytes {
+	return append(common.Bytes("lc/b/"), hash[:]...)
+}
+
+func finalizedHeightKey(height uint64) common.Bytes {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, height)
+	return append(common.Bytes("lc/h/"), buf...)
+}
+
+func latestFinalizedKey() common.Bytes {
+	return common.Bytes("lc/lfb")
+}
+
+func (lc *LightClient) getFinalizedHeader(hash common.Hash) *core.BlockHeader {
+	header := &core.BlockHeader{}
+	if err := lc.store.Get(finalizedHeaderKey(hash), header); err != nil {
+		return nil
+	}
+	return header
+}
+
+func (lc *LightClient) saveFinalizedHeader(header *core.BlockHeader) {
+	hash := header.Hash()
+	if err := lc.store.Put(finalizedHeaderKey(hash), header); err != nil {
+		logger.Panic(err)
+	}
+	if err := lc.store.Put(finalizedHeightKey(header.Height), hash); err != nil {
+		logger.Panic(err)
+	}
+}
+
+func (lc *LightClient) saveLatestFinalized(header *core.BlockHeader) {
+	if err := lc.store.Put(latestFinalizedKey(), header.Hash()); err != nil {
+		logger.Panic(err)
+	}
+}
@@ -0,0 +1,199 @@
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/rpc"
+	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/dnerochain/dnero/store/kvstore"
+)
+
+type mockProofFetcher struct {
+	vcpProof []hexutil.Bytes
+
+	lc              *LightClient // to check that the proofs are not fetched while holding the lock
+	numVCPProofCall int
+}
+
+func (f *mockProofFetcher) Put(key []byte, value []byte) error {
+	f.vcpProof = append(f.vcpProof, value)
+	return nil
+}
+
+func (f *mockProofFetcher) GetVCPProof(blockHash common.Hash) ([]hexutil.Bytes, error) {
+	if f.lc != nil {
+		f.lc.GetLatestFinalizedHeader()
+	}
+	f.numVCPProofCall++
+	return f.vcpProof, nil
+}
+
+func (f *mockProofFetcher) GetAccountProof(address common.Address, height uint64) (*rpc.GetAccountProofResult, error) {
+	return nil, errors.New("Not supported")
+}
+
+func (f *mockProofFetcher) GetStorageProof(address common.Address, slots []common.Hash, height uint64) (*rpc.GetStorageProofResult, error) {
+	return nil, errors.New("Not supported")
+}
+
+func createTestHeader(key *crypto.PrivateKey, parent *core.BlockHeader, hccVotes *core.VoteSet) *core.BlockHeader {
+	header := &core.BlockHeader{
+		ChainID:   parent.ChainID,
+		Epoch:     parent.Epoch + 1,
+		Height:    parent.Height + 1,
+		Parent:    parent.Hash(),
+		HCC:       core.CommitCertificate{BlockHash: parent.Hash(), Votes: hccVotes},
+		StateHash: parent.StateHash,
+		Timestamp: big.NewInt(int64(parent.Height + 1)),
+		Proposer:  key.PublicKey().Address(),
+	}
+	header.Signature, _ = key.Sign(header.SignBytes())
+	return header
+}
+
+func createTestVote(key *crypto.PrivateKey, header *core.BlockHeader) core.Vote {
+	vote := core.Vote{
+		Block:  header.Hash(),
+		Height: header.Height,
+		Epoch:  header.Epoch,
+		ID:     key.PublicKey().Address(),
+	}
+	vote.Sign(key)
+	return vote
+}
+
+func createTestVoteSet(keys []*crypto.PrivateKey, header *core.BlockHeader) *core.VoteSet {
+	voteSet := core.NewVoteSet()
+	for _, key := range keys {
+		voteSet.AddVote(createTestVote(key, header))
+	}
+	return voteSet
+}
+
+func TestLightClient(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	keys := []*crypto.PrivateKey{}
+	vcp := &core.ValidatorCandidatePool{}
+	for i := 0; i < 3; i++ {
+		key, _, err := crypto.GenerateKeyPair()
+		require.Nil(err)
+		keys = append(keys, key)
+		addr := key.PublicKey().Address()
+		require.Nil(vcp.DepositStake(addr, addr, core.MinValidatorStakeDeposit, 0))
+	}
+	outsider, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+
+	sv := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	sv.UpdateValidatorCandidatePool(vcp)
+	stateHash := sv.Save()
+	fetcher := &mockProofFetcher{}
+	require.Nil(sv.GetStore().Prove(state.ValidatorCandidatePoolKey(), fetcher))
+
+	root := &core.BlockHeader{
+		ChainID:   "testchain",
+		Epoch:     10,
+		Height:    10,
+		StateHash: stateHash,
+		Timestamp: big.NewInt(10),
+	}
+	db := backend.NewMemDatabase()
+	lc := NewLightClient(root, kvstore.NewKVStore(db), fetcher)
+	fetcher.lc = lc
+	assert.Equal(root.Hash(), lc.GetLatestFinalizedHeader().Hash())
+
+	h11 := createTestHeader(keys[0], root, nil)
+	h12 := createTestHeader(keys[1], h11, createTestVoteSet(keys, h11))
+	h13 := createTestHeader(keys[2], h12, createTestVoteSet(keys, h12))
+	h14 := createTestHeader(keys[0], h13, createTestVoteSet(keys, h13))
+
+	// h11 is committed by the HCC votes in h12, but nothing is finalized yet
+	assert.Nil(lc.AddHeader(h11, "peer"))
+	assert.Nil(lc.AddHeader(h12, "peer"))
+	assert.Equal(root.Hash(), lc.GetLatestFinalizedHeader().Hash())
+	assert.Equal(h12.Hash().Hex(), lc.GetInventoryStarts()[0])
+
+	// h12 is committed with h11 as both the parent and the HCC, so h11 is finalized
+	assert.Nil(lc.AddHeader(h13, "peer"))
+	assert.Equal(h11.Hash(), lc.GetLatestFinalizedHeader().Hash())
+
+	assert.Nil(lc.AddHeader(h14, "peer"))
+	assert.Equal(h12.Hash(), lc.GetLatestFinalizedHeader().Hash())
+	assert.Equal(h11.Hash(), lc.GetFinalizedHeaderByHeight(11).Hash())
+	assert.True(fetcher.numVCPProofCall > 0)
+
+	// headers with invalid signatures are rejected
+	forged := createTestHeader(keys[0], h14, nil)
+	forged.Proposer = keys[1].PublicKey().Address()
+	assert.NotNil(lc.AddHeader(forged, "peer"))
+	assert.NotContains(lc.pending, forged.Hash())
+
+	// votes from the non-validators are ignored, and votes without the majority can't commit h14
+	assert.Nil(lc.AddVote(createTestVote(outsider, h14), "peer"))
+	assert.NotContains(lc.votes, h14.Hash())
+	assert.Nil(lc.AddVote(createTestVote(keys[0], h14), "peer"))
+	assert.Nil(lc.AddVote(createTestVote(keys[1], h14), "peer"))
+	assert.Equal(h12.Hash(), lc.GetLatestFinalizedHeader().Hash())
+
+	forgedVote := createTestVote(keys[2], h14)
+	forgedVote.ID = keys[1].PublicKey().Address()
+	assert.NotNil(lc.AddVote(forgedVote, "peer"))
+
+	assert.Nil(lc.AddVote(createTestVote(keys[2], h14), "peer"))
+	assert.Equal(h13.Hash(), lc.GetLatestFinalizedHeader().Hash())
+
+	// resume from the store
+	lc = NewLightClient(root, kvstore.NewKVStore(db), fetcher)
+	assert.Equal(h13.Hash(), lc.GetLatestFinalizedHeader().Hash())
+	assert.Equal(h12.Hash(), lc.GetFinalizedHeaderByHeight(12).Hash())
+}
+
+func TestLightClientPendingCaps(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	key, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	root := &core.BlockHeader{
+		ChainID:   "testchain",
+		Epoch:     10,
+		Height:    10,
+		Timestamp: big.NewInt(10),
+	}
+	lc := NewLightClient(root, kvstore.NewKVStore(backend.NewMemDatabase()), &mockProofFetcher{})
+
+	// Only maxPendingPerHeight forks are kept at a height
+	for i := 0; i <= maxPendingPerHeight; i++ {
+		fork := createTestHeader(key, root, nil)
+		fork.Timestamp = big.NewInt(int64(100 + i))
+		fork.Signature, _ = key.Sign(fork.SignBytes())
+		assert.Nil(lc.AddHeader(fork, fmt.Sprintf("peer%v", i)))
+	}
+	assert.Equal(maxPendingPerHeight, len(lc.pending))
+	assert.Equal(maxPendingPerHeight, lc.pendingHeights[11])
+
+	// The quota of a peer is released once the heights of its entries are finalized
+	quota := newPeerQuota(2)
+	assert.True(quota.take("peer", 11))
+	assert.True(quota.take("peer", 12))
+	assert.False(quota.take("peer", 13))
+	assert.True(quota.take("other", 13))
+	quota.release(11)
+	assert.True(quota.take("peer", 13))
+	assert.False(quota.take("peer", 14))
+	quota.release(13)
+	assert.Equal(0, len(quota.counts))
+	assert.Equal(0, len(quota.byHeight))
+}
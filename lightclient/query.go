@@ -0,0 +1,71 @@
+package lightclient
+
+import (
+	"fmt"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/rpc/client"
+)
+
+// GetAccount returns the account at the given finalized height (zero means the latest finalized
+// header). The account is fetched from a full node and verified against the StateHash of the
+// finalized header. A nil account is returned if the account is proven to be absent.
+func (lc *LightClient) GetAccount(address common.Address, height uint64) (*types.Account, *core.BlockHeader, error) {
+	header, err := lc.getHeaderForQuery(height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := lc.fetcher.GetAccountProof(address, header.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.BlockHash != header.Hash() {
+		return nil, nil, fmt.Errorf("Block hash mismatch, finalized: %v, returned: %v", header.Hash().Hex(), result.BlockHash.Hex())
+	}
+	account, err := client.VerifyGetAccountProofResult(header.StateHash, result)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, header, nil
+}
+
+// GetStorage returns the values of the storage slots of a contract at the given finalized height
+// (zero means the latest finalized header), verified against the StateHash of the finalized header.
+func (lc *LightClient) GetStorage(address common.Address, slots []common.Hash, height uint64) (map[common.Hash]common.Hash, *core.BlockHeader, error) {
+	header, err := lc.getHeaderForQuery(height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := lc.fetcher.GetStorageProof(address, slots, header.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.BlockHash != header.Hash() {
+		return nil, nil, fmt.Errorf("Block hash mismatch, finalized: %v, returned: %v", header.Hash().Hex(), result.BlockHash.Hex())
+	}
+	values, err := client.VerifyGetStorageProofResult(header.StateHash, result)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, slot := range slots {
+		if _, ok := values[slot]; !ok {
+			return nil, nil, fmt.Errorf("Slot %v is not proven", slot.Hex())
+		}
+	}
+	return values, header, nil
+}
+
+func (lc *LightClient) getHeaderForQuery(height uint64) (*core.BlockHeader, error) {
+	if height == 0 {
+		return lc.GetLatestFinalizedHeader(), nil
+	}
+	header := lc.GetFinalizedHeaderByHeight(height)
+	if header == nil {
+		return nil, fmt.Errorf("Finalized header for height %v is not found", height)
+	}
+	return header, nil
+}
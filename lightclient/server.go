@@ -0,0 +1,199 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/ledger/types"
+	drpc "github.com/dnerochain/dnero/rpc"
+	"github.com/dnerochain/dnero/rpc/lib/rpc-codec/jsonrpc2"
+	"golang.org/x/net/netutil"
+)
+
+// LightRPCService serves the queries verified by the light client. It is registered under the
+// same "dnero" namespace as the full node RPC service, with a subset of its methods.
+type LightRPCService struct {
+	lc *LightClient
+}
+
+// LightRPCServer is an instance of the light client RPC service.
+type LightRPCServer struct {
+	*LightRPCService
+
+	server *http.Server
+
+	wg     *sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewLightRPCServer creates a new instance of LightRPCServer.
+func NewLightRPCServer(lc *LightClient) *LightRPCServer {
+	t := &LightRPCServer{
+		LightRPCService: &LightRPCService{lc: lc},
+		wg:              &sync.WaitGroup{},
+	}
+
+	s := rpc.NewServer()
+	s.RegisterName("dnero", t.LightRPCService)
+
+	router := mux.NewRouter()
+	router.Handle("/rpc", drpc.TimeoutHandler(jsonrpc2.HTTPHandler(s), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, ""))
+	t.server = &http.Server{
+		Handler: router,
+	}
+	return t
+}
+
+// Start creates the main goroutine.
+func (t *LightRPCServer) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	t.ctx = c
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go t.mainLoop()
+}
+
+func (t *LightRPCServer) mainLoop() {
+	defer t.wg.Done()
+
+	go t.serve()
+
+	<-t.ctx.Done()
+	t.server.Shutdown(t.ctx)
+}
+
+func (t *LightRPCServer) serve() {
+	address := viper.GetString(common.CfgRPCAddress)
+	port := viper.GetString(common.CfgRPCPort)
+	l, err := net.Listen("tcp", address+":"+port)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Fatal("Failed to create listener")
+	} else {
+		logger.WithFields(log.Fields{"address": address, "port": port}).Info("Light client RPC server started")
+	}
+	defer l.Close()
+
+	ll := netutil.LimitListener(l, viper.GetInt(common.CfgRPCMaxConnections))
+	logger.Info(t.server.Serve(ll))
+}
+
+// Stop notifies all goroutines to stop without blocking.
+func (t *LightRPCServer) Stop() {
+	t.cancel()
+}
+
+// Wait blocks until all goroutines stop.
+func (t *LightRPCServer) Wait() {
+	t.wg.Wait()
+}
+
+// ------------------------------- GetStatus -----------------------------------
+
+type GetStatusArgs struct{}
+
+type GetStatusResult struct {
+	ChainID                    string            `json:"chain_id"`
+	LatestFinalizedBlockHash   common.Hash       `json:"latest_finalized_block_hash"`
+	LatestFinalizedBlockHeight common.JSONUint64 `json:"latest_finalized_block_height"`
+	LatestFinalizedStateHash   common.Hash       `json:"latest_finalized_state_hash"`
+	TrustedBlockHash           common.Hash       `json:"trusted_block_hash"`
+	TrustedBlockHeight         common.JSONUint64 `json:"trusted_block_height"`
+}
+
+func (t *LightRPCService) GetStatus(args *GetStatusArgs, result *GetStatusResult) (err error) {
+	lfb := t.lc.GetLatestFinalizedHeader()
+	result.ChainID = t.lc.chainID
+	result.LatestFinalizedBlockHash = lfb.Hash()
+	result.LatestFinalizedBlockHeight = common.JSONUint64(lfb.Height)
+	result.LatestFinalizedStateHash = lfb.StateHash
+	result.TrustedBlockHash = t.lc.root.Hash()
+	result.TrustedBlockHeight = common.JSONUint64(t.lc.root.Height)
+	return nil
+}
+
+// ------------------------------- GetAccount -----------------------------------
+
+type GetAccountArgs struct {
+	Address string            `json:"address"`
+	Height  common.JSONUint64 `json:"height"` // zero means the latest finalized block
+}
+
+type GetAccountResult struct {
+	*types.Account
+	Address     string            `json:"address"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+}
+
+func (t *LightRPCService) GetAccount(args *GetAccountArgs, result *GetAccountResult) (err error) {
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	account, header, err := t.lc.GetAccount(common.HexToAddress(args.Address), uint64(args.Height))
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return fmt.Errorf("Account with address %v is not found", args.Address)
+	}
+	result.Account = account
+	result.Address = args.Address
+	result.BlockHash = header.Hash()
+	result.BlockHeight = common.JSONUint64(header.Height)
+	return nil
+}
+
+// ------------------------------- GetStorage -----------------------------------
+
+type GetStorageArgs struct {
+	Address string            `json:"address"`
+	Slots   []string          `json:"slots"`
+	Height  common.JSONUint64 `json:"height"` // zero means the latest finalized block
+}
+
+type StorageSlot struct {
+	Slot  common.Hash `json:"slot"`
+	Value common.Hash `json:"value"`
+}
+
+type GetStorageResult struct {
+	Address     string            `json:"address"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Slots       []*StorageSlot    `json:"slots"`
+}
+
+func (t *LightRPCService) GetStorage(args *GetStorageArgs, result *GetStorageResult) (err error) {
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	slots := []common.Hash{}
+	for _, slot := range args.Slots {
+		slots = append(slots, common.HexToHash(slot))
+	}
+	values, header, err := t.lc.GetStorage(common.HexToAddress(args.Address), slots, uint64(args.Height))
+	if err != nil {
+		return err
+	}
+
+	result.Address = args.Address
+	result.BlockHash = header.Hash()
+	result.BlockHeight = common.JSONUint64(header.Height)
+	result.Slots = []*StorageSlot{}
+	for _, slot := range slots {
+		result.Slots = append(result.Slots, &StorageSlot{Slot: slot, Value: values[slot]})
+	}
+	return nil
+}
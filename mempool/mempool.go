@@ -3,21 +3,24 @@ package mempool
 import (
 	"context"
 	"encoding/hex"
-	"errors"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/spf13/viper"
+
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/common/clist"
+	"github.com/dnerochain/dnero/common/metrics"
 	"github.com/dnerochain/dnero/common/math"
 	"github.com/dnerochain/dnero/common/pqueue"
 	"github.com/dnerochain/dnero/common/result"
-	"github.com/dnerochain/dnero/consensus"
 	"github.com/dnerochain/dnero/core"
 	dp "github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/eventbus"
 )
 
 var logger *log.Entry = log.WithFields(log.Fields{"prefix": "mempool"})
@@ -30,8 +33,19 @@ func (m MempoolError) Error() string {
 
 const DuplicateTxError = MempoolError("Transaction already seen")
 const FastsyncSkipTxError = MempoolError("Skip tx during fastsync")
+const MempoolFullError = MempoolError("Mempool is full, please submit your transaction again later or with a higher gas price")
+const AccountTxQuotaExceededError = MempoolError("Too many pending transactions from the account, please submit your transaction again later")
+const ReplacementTxUnderpricedError = MempoolError("Replacement transaction underpriced")
+
+// TxScreeningError is returned when a transaction fails the screening
+type TxScreeningError struct {
+	Code    result.ErrorCode
+	Message string
+}
 
-const MaxMempoolTxCount int = 25600
+func (e *TxScreeningError) Error() string {
+	return e.Message
+}
 
 //
 // mempoolTransaction implements the pqueue.Element interface
@@ -69,9 +83,10 @@ func createMempoolTransaction(rawTransaction common.Bytes, txInfo *core.TxInfo)
 // their lowest sequence transaction.
 //
 type mempoolTransactionGroup struct {
-	address common.Address
-	txs     *pqueue.PriorityQueue
-	index   int
+	address   common.Address
+	txs       *pqueue.PriorityQueue
+	index     int
+	evictable *evictableTxGroup
 }
 
 var _ pqueue.Element = (*mempoolTransactionGroup)(nil)
@@ -105,6 +120,43 @@ func (mtg *mempoolTransactionGroup) IsEmpty() bool {
 	return mtg.txs.IsEmpty()
 }
 
+func (mtg *mempoolTransactionGroup) Size() int {
+	return mtg.txs.NumElements()
+}
+
+// SortedTxs returns the transactions of the group in the ascending order of the sequence
+func (mtg *mempoolTransactionGroup) SortedTxs() []*mempoolTransaction {
+	mptxs := []*mempoolTransaction{}
+	for _, elem := range *mtg.txs.ElementList() {
+		mptxs = append(mptxs, elem.(*mempoolTransaction))
+	}
+	sort.Slice(mptxs, func(i, j int) bool {
+		return mptxs[i].txInfo.Sequence < mptxs[j].txInfo.Sequence
+	})
+	return mptxs
+}
+
+// FindTx returns the transaction with the given sequence, or nil if not found.
+func (mtg *mempoolTransactionGroup) FindTx(sequence uint64) *mempoolTransaction {
+	for _, elem := range *mtg.txs.ElementList() {
+		mptx := elem.(*mempoolTransaction)
+		if mptx.txInfo.Sequence == sequence {
+			return mptx
+		}
+	}
+	return nil
+}
+
+// HasAnyTx tells whether the transaction group contains any of the given Txs.
+func (mtg *mempoolTransactionGroup) HasAnyTx(rawTxMap map[string]bool) bool {
+	for _, elem := range *mtg.txs.ElementList() {
+		if _, exists := rawTxMap[string(elem.(*mempoolTransaction).rawTransaction)]; exists {
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveTxs removes matching Txs from transaction group. Returns number of Txs removed.
 func (mtg *mempoolTransactionGroup) RemoveTxs(committedRawTxMap map[string]bool) (numRemoved int) {
 	elementList := mtg.txs.ElementList()
@@ -129,10 +181,41 @@ func createMempoolTransactionGroup(rawTx common.Bytes, txInfo *core.TxInfo) *mem
 		address: txInfo.Address,
 		txs:     pqueue.CreatePriorityQueue(),
 	}
+	txGroup.evictable = &evictableTxGroup{txGroup: txGroup}
 	txGroup.AddTx(rawTx, txInfo)
 	return txGroup
 }
 
+//
+// evictableTxGroup wraps a transaction group for the eviction queue, where the groups are ordered by the
+// priority of their lowest sequence transaction from low to high.
+//
+type evictableTxGroup struct {
+	txGroup *mempoolTransactionGroup
+	index   int
+}
+
+var _ pqueue.Element = (*evictableTxGroup)(nil)
+
+func (etg *evictableTxGroup) Priority() *big.Int {
+	return new(big.Int).Neg(etg.txGroup.Priority())
+}
+
+func (etg *evictableTxGroup) SetIndex(index int) {
+	etg.index = index
+}
+
+func (etg *evictableTxGroup) GetIndex() int {
+	return etg.index
+}
+
+//
+// SyncChecker tells whether the node has caught up with the network, i.e. the consensus engine
+//
+type SyncChecker interface {
+	HasSynced() bool
+}
+
 //
 // Mempool manages the transactions submitted by the clients
 // or relayed from peers
@@ -140,16 +223,23 @@ func createMempoolTransactionGroup(rawTx common.Bytes, txInfo *core.TxInfo) *mem
 type Mempool struct {
 	mutex *sync.Mutex
 
-	consensus  *consensus.ConsensusEngine
+	consensus  SyncChecker
 	ledger     core.Ledger
 	dispatcher *dp.Dispatcher
 
 	newTxs           *clist.CList          // new transactions, to be gossiped to other nodes
 	candidateTxs     *pqueue.PriorityQueue // candidate transactions for new block assembly, ordered by the transaction fee (high to low)
+	evictableTxs     *pqueue.PriorityQueue // the same transaction groups as candidateTxs, ordered by the transaction fee (low to high)
 	txBookeepper     transactionBookkeeper
 	addressToTxGroup map[common.Address]*mempoolTransactionGroup
 	size             int
 
+	maxNumTxs                int // capacity of the mempool
+	maxNumTxsPerAccount      int // maximum number of pending transactions per account
+	replaceTxMinGasPriceBump int // minimal gas price bump (in percent) to replace a pending transaction
+
+	eventBus *eventbus.EventBus
+
 	// Life cycle
 	wg      *sync.WaitGroup
 	quit    chan struct{}
@@ -159,17 +249,26 @@ type Mempool struct {
 }
 
 // CreateMempool creates an instance of Mempool
-func CreateMempool(dispatcher *dp.Dispatcher, engine *consensus.ConsensusEngine) *Mempool {
-	return &Mempool{
+func CreateMempool(dispatcher *dp.Dispatcher, engine SyncChecker) *Mempool {
+	mp := &Mempool{
 		mutex:            &sync.Mutex{},
 		consensus:        engine,
 		dispatcher:       dispatcher,
 		newTxs:           clist.New(),
 		candidateTxs:     pqueue.CreatePriorityQueue(),
+		evictableTxs:     pqueue.CreatePriorityQueue(),
 		addressToTxGroup: make(map[common.Address]*mempoolTransactionGroup),
 		txBookeepper:     createTransactionBookkeeper(defaultMaxNumTxs),
 		wg:               &sync.WaitGroup{},
+
+		maxNumTxs:                viper.GetInt(common.CfgMempoolMaxNumTxs),
+		maxNumTxsPerAccount:      viper.GetInt(common.CfgMempoolMaxNumTxsPerAccount),
+		replaceTxMinGasPriceBump: viper.GetInt(common.CfgMempoolReplaceTxMinGasPriceBump),
 	}
+	metrics.NewRegisteredFunctionalGauge("mempool/size", nil, func() int64 {
+		return int64(mp.Size())
+	})
+	return mp
 }
 
 // SetLedger sets the ledger for the mempool
@@ -177,6 +276,15 @@ func (mp *Mempool) SetLedger(ledger core.Ledger) {
 	mp.ledger = ledger
 }
 
+// SetEventBus sets the event bus to which the added and dropped transactions are published
+func (mp *Mempool) SetEventBus(bus *eventbus.EventBus) {
+	mp.eventBus = bus
+}
+
+func (mp *Mempool) publishTxDropped(rawTx common.Bytes, reason eventbus.TxDropReason) {
+	mp.eventBus.Publish(eventbus.TopicTxDropped, &eventbus.TxDroppedEvent{RawTx: rawTx, Reason: reason})
+}
+
 // InsertTransaction inserts the incoming transaction to mempool (submitted by the clients or relayed from peers)
 func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 	mp.mutex.Lock()
@@ -188,46 +296,217 @@ func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 		return DuplicateTxError
 	}
 
-	// if mp.size >= MaxMempoolTxCount {
-	// 	logger.Debugf("Mempool is full")
-	// 	return errors.New("mempool is full, please submit your transaction again later")
-	// }
-
 	var txInfo *core.TxInfo
 	var checkTxRes result.Result
 
 	// Delay tx verification when in fast sync
 	if mp.consensus.HasSynced() {
-		txInfo, checkTxRes = mp.ledger.ScreenTx(rawTx)
+		// The quota and the capacity are checked before the transaction is applied to the screened
+		// view, so a rejected transaction leaves no trace in the view
+		var precheckErr error
+		txInfo, checkTxRes = mp.ledger.ScreenTx(rawTx, func(txInfo *core.TxInfo) error {
+			precheckErr = mp.precheckTransactionUnsafe(rawTx, txInfo)
+			return precheckErr
+		})
+		if precheckErr != nil {
+			return precheckErr
+		}
+		if checkTxRes.Code == result.CodeInvalidSequence {
+			// The transaction might replace a pending transaction with the same sequence
+			if replacementTxInfo, res := mp.ledger.ScreenReplacementTx(rawTx); res.IsOK() {
+				if txGroup, ok := mp.addressToTxGroup[replacementTxInfo.Address]; ok {
+					if replacedTx := txGroup.FindTx(replacementTxInfo.Sequence); replacedTx != nil {
+						return mp.replaceTransactionUnsafe(txGroup, replacedTx, rawTx, replacementTxInfo)
+					}
+				}
+			}
+		}
 		if !checkTxRes.IsOK() {
 			logger.Debugf("Transaction screening failed, tx: %v, error: %v", hex.EncodeToString(rawTx), checkTxRes.Message)
-			return errors.New(checkTxRes.Message)
+			return &TxScreeningError{Code: checkTxRes.Code, Message: checkTxRes.Message}
 		}
 
-		// only record the transactions that passed the screening. This is because that
-		// an invalid transaction could becoume valid later on. For example, assume expected
-		// sequence for an account is 6. The account accidentally submits txA (seq = 7), got rejected.
-		// He then submit txB(seq = 6), and then txA(seq = 7) again. For the second submission, txA
-		// should not be rejected even though it has been submitted earlier.
-		mp.txBookeepper.record(rawTx)
-
-		txGroup, ok := mp.addressToTxGroup[txInfo.Address]
-		if ok {
-			txGroup.AddTx(rawTx, txInfo)
-			mp.candidateTxs.Remove(txGroup.index) // Need to re-insert txGroup into queue since its priority could change.
-		} else {
-			txGroup = createMempoolTransactionGroup(rawTx, txInfo)
-			mp.addressToTxGroup[txInfo.Address] = txGroup
+		return mp.addTransactionUnsafe(rawTx, txInfo)
+	}
+
+	return FastsyncSkipTxError
+}
+
+// precheckTransactionUnsafe checks whether a transaction can be added to the candidate pool without
+// exceeding the pending tx quota of the account or the capacity of the mempool. It does not modify the mempool.
+func (mp *Mempool) precheckTransactionUnsafe(rawTx common.Bytes, txInfo *core.TxInfo) error {
+	txGroup, ok := mp.addressToTxGroup[txInfo.Address]
+	if ok && txGroup.Size() >= mp.maxNumTxsPerAccount {
+		logger.Debugf("Account %v exceeded the pending tx quota, tx.hash: 0x%v", txInfo.Address, getTransactionHash(rawTx))
+		return AccountTxQuotaExceededError
+	}
+
+	if mp.size >= mp.maxNumTxs && mp.findEvictableTxGroupUnsafe(txInfo) == nil {
+		logger.Debugf("Mempool is full, size: %v", mp.size)
+		return MempoolFullError
+	}
+
+	return nil
+}
+
+// addTransactionUnsafe adds a transaction that passed the screening to the candidate pool. If the
+// mempool is full, the transaction group with the lowest gas price is evicted to make room for it.
+func (mp *Mempool) addTransactionUnsafe(rawTx common.Bytes, txInfo *core.TxInfo) error {
+	if err := mp.precheckTransactionUnsafe(rawTx, txInfo); err != nil {
+		return err
+	}
+
+	var evictedTxGroup *mempoolTransactionGroup
+	if mp.size >= mp.maxNumTxs {
+		evictedTxGroup = mp.findEvictableTxGroupUnsafe(txInfo)
+	}
+
+	txGroup, ok := mp.addressToTxGroup[txInfo.Address]
+
+	// only record the transactions that passed the screening. This is because that
+	// an invalid transaction could becoume valid later on. For example, assume expected
+	// sequence for an account is 6. The account accidentally submits txA (seq = 7), got rejected.
+	// He then submit txB(seq = 6), and then txA(seq = 7) again. For the second submission, txA
+	// should not be rejected even though it has been submitted earlier.
+	mp.txBookeepper.record(rawTx)
+
+	if ok {
+		mp.removeTxGroupUnsafe(txGroup) // Need to re-insert txGroup into queue since its priority could change.
+		txGroup.AddTx(rawTx, txInfo)
+	} else {
+		txGroup = createMempoolTransactionGroup(rawTx, txInfo)
+		mp.addressToTxGroup[txInfo.Address] = txGroup
+	}
+	mp.pushTxGroupUnsafe(txGroup)
+	logger.Debugf("rawTx: %v, txInfo: %v", hex.EncodeToString(rawTx), txInfo)
+	logger.Infof("Insert tx, tx.hash: 0x%v", getTransactionHash(rawTx))
+	mp.size++
+	mp.eventBus.Publish(eventbus.TopicTxAddedToMempool, &eventbus.TxAddedToMempoolEvent{RawTx: rawTx})
+
+	if evictedTxGroup != nil {
+		mp.evictTxGroupUnsafe(evictedTxGroup)
+	}
+
+	return nil
+}
+
+// pushTxGroupUnsafe inserts the transaction group into both the candidate and the eviction queues
+func (mp *Mempool) pushTxGroupUnsafe(txGroup *mempoolTransactionGroup) {
+	mp.candidateTxs.Push(txGroup)
+	mp.evictableTxs.Push(txGroup.evictable)
+}
+
+// removeTxGroupUnsafe removes the transaction group from both the candidate and the eviction queues
+func (mp *Mempool) removeTxGroupUnsafe(txGroup *mempoolTransactionGroup) {
+	mp.candidateTxs.Remove(txGroup.GetIndex())
+	mp.evictableTxs.Remove(txGroup.evictable.GetIndex())
+}
+
+// replaceTransactionUnsafe replaces a pending transaction with a transaction of the same sequence, given
+// that the effective gas price of the new transaction is higher by at least replaceTxMinGasPriceBump percent.
+// The replaced transaction is marked as abandoned.
+func (mp *Mempool) replaceTransactionUnsafe(txGroup *mempoolTransactionGroup, replacedTx *mempoolTransaction,
+	rawTx common.Bytes, txInfo *core.TxInfo) error {
+	oldGasPrice := replacedTx.txInfo.EffectiveGasPrice
+	minGasPrice := new(big.Int).Mul(oldGasPrice, big.NewInt(int64(100+mp.replaceTxMinGasPriceBump)))
+	minGasPrice.Div(minGasPrice, big.NewInt(100))
+	if txInfo.EffectiveGasPrice.Cmp(oldGasPrice) <= 0 || txInfo.EffectiveGasPrice.Cmp(minGasPrice) < 0 {
+		logger.Debugf("Replacement tx underpriced, tx.hash: 0x%v, gas price: %v, min gas price: %v",
+			getTransactionHash(rawTx), txInfo.EffectiveGasPrice, minGasPrice)
+		return ReplacementTxUnderpricedError
+	}
+
+	mp.removeTxGroupUnsafe(txGroup) // Need to re-insert txGroup into queue since its priority could change.
+	txGroup.txs.Remove(replacedTx.index)
+	mp.txBookeepper.markAbandoned(replacedTx.rawTransaction)
+
+	mp.txBookeepper.record(rawTx)
+	txGroup.AddTx(rawTx, txInfo)
+	mp.pushTxGroupUnsafe(txGroup)
+	logger.Infof("Replace tx, tx.hash: 0x%v, replaced tx.hash: 0x%v",
+		getTransactionHash(rawTx), getTransactionHash(replacedTx.rawTransaction))
+	mp.eventBus.Publish(eventbus.TopicTxAddedToMempool, &eventbus.TxAddedToMempoolEvent{RawTx: rawTx, Replaced: true})
+	mp.publishTxDropped(replacedTx.rawTransaction, eventbus.TxDropReasonReplaced)
+
+	return nil
+}
+
+// findEvictableTxGroupUnsafe returns the transaction group with the lowest gas price in the candidate
+// pool, given that the incoming transaction pays a strictly higher gas price. Otherwise it returns nil.
+// The group of the sender of the incoming transaction is never evictable.
+func (mp *Mempool) findEvictableTxGroupUnsafe(txInfo *core.TxInfo) *mempoolTransactionGroup {
+	// The group with the lowest gas price is at the root of the eviction queue. If it belongs to the
+	// sender, the group with the next lowest gas price is one of the children of the root.
+	var lowest *mempoolTransactionGroup
+	elementList := *mp.evictableTxs.ElementList()
+	for i := 0; i < len(elementList) && i < 3; i++ {
+		txGroup := elementList[i].(*evictableTxGroup).txGroup
+		if txGroup.address == txInfo.Address {
+			continue
+		}
+		if lowest == nil || txGroup.Priority().Cmp(lowest.Priority()) < 0 {
+			lowest = txGroup
 		}
-		mp.candidateTxs.Push(txGroup)
-		logger.Debugf("rawTx: %v, txInfo: %v", hex.EncodeToString(rawTx), txInfo)
-		logger.Infof("Insert tx, tx.hash: 0x%v", getTransactionHash(rawTx))
-		mp.size++
+	}
 
+	if lowest == nil || txInfo.EffectiveGasPrice == nil || txInfo.EffectiveGasPrice.Cmp(lowest.Priority()) <= 0 {
 		return nil
 	}
+	return lowest
+}
+
+// evictTxGroupUnsafe removes the given transaction group from the candidate pool, and rebuilds the
+// screened view without the evicted transactions, so they can be submitted again.
+func (mp *Mempool) evictTxGroupUnsafe(txGroup *mempoolTransactionGroup) {
+	mp.removeTxGroupUnsafe(txGroup)
+	delete(mp.addressToTxGroup, txGroup.address)
+	for !txGroup.IsEmpty() {
+		rawTx, _ := txGroup.PopTx()
+		mp.txBookeepper.markAbandoned(rawTx)
+		mp.size--
+		logger.Infof("Evict tx, tx.hash: 0x%v", getTransactionHash(rawTx))
+		mp.publishTxDropped(rawTx, eventbus.TxDropReasonEvicted)
+	}
 
-	return FastsyncSkipTxError
+	if mp.ledger != nil {
+		mp.rebuildScreenedViewUnsafe()
+	}
+}
+
+// rebuildScreenedViewUnsafe resets the screened view to the delivered view and screens the candidate
+// transactions again, which undoes both the debits and the credits of the evicted transactions. Since a
+// transaction may spend what a transaction from another account credited, the candidate transactions are
+// screened in rounds until no more of them pass. The candidate transactions that never pass are dropped.
+func (mp *Mempool) rebuildScreenedViewUnsafe() {
+	mp.ledger.ResetScreenedState()
+
+	pendingTxs := [][]*mempoolTransaction{}
+	for _, elem := range *mp.candidateTxs.ElementList() {
+		pendingTxs = append(pendingTxs, elem.(*mempoolTransactionGroup).SortedTxs())
+	}
+	for progress := true; progress; {
+		progress = false
+		for i, mptxs := range pendingTxs {
+			for len(mptxs) > 0 {
+				if _, res := mp.ledger.ScreenTx(mptxs[0].rawTransaction, nil); !res.IsOK() {
+					break // the later transactions of the account can only pass after this one
+				}
+				mptxs = mptxs[1:]
+				progress = true
+			}
+			pendingTxs[i] = mptxs
+		}
+	}
+
+	invalidTxs := []common.Bytes{}
+	for _, mptxs := range pendingTxs {
+		for _, mptx := range mptxs {
+			invalidTxs = append(invalidTxs, mptx.rawTransaction)
+			mp.txBookeepper.markAbandoned(mptx.rawTransaction)
+			mp.publishTxDropped(mptx.rawTransaction, eventbus.TxDropReasonInvalid)
+		}
+	}
+	mp.removeTxs(invalidTxs)
 }
 
 // Start needs to be called when the Mempool starts
@@ -293,7 +572,8 @@ func (mp *Mempool) ReapUnsafe(maxNumTxs int) []common.Bytes {
 		if mp.candidateTxs.IsEmpty() {
 			break
 		}
-		txGroup := mp.candidateTxs.Pop().(*mempoolTransactionGroup)
+		txGroup := mp.candidateTxs.Peek().(*mempoolTransactionGroup)
+		mp.removeTxGroupUnsafe(txGroup)
 		rawTx, txInfo := txGroup.PopTx()
 
 		// Check for outdated txs
@@ -307,7 +587,7 @@ func (mp *Mempool) ReapUnsafe(maxNumTxs int) []common.Bytes {
 		if txGroup.IsEmpty() {
 			delete(mp.addressToTxGroup, txGroup.address)
 		} else {
-			mp.candidateTxs.Push(txGroup)
+			mp.pushTxGroupUnsafe(txGroup)
 		}
 
 		logger.Debugf("Reap tx: %v, txInfo: %v",
@@ -354,6 +634,7 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 			if !exists {
 				// Tx has been removed from bookkeeper due to timeout
 				invalidTxs = append(invalidTxs, mempoolTx.rawTransaction)
+				mp.publishTxDropped(mempoolTx.rawTransaction, eventbus.TxDropReasonExpired)
 				continue
 			}
 
@@ -361,6 +642,7 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 			if !checkTxRes.IsOK() {
 				invalidTxs = append(invalidTxs, mempoolTx.rawTransaction)
 				mp.txBookeepper.markAbandoned(mempoolTx.rawTransaction)
+				mp.publishTxDropped(mempoolTx.rawTransaction, eventbus.TxDropReasonInvalid)
 			}
 		}
 	}
@@ -380,22 +662,28 @@ func (mp *Mempool) removeTxs(committedRawTxs []common.Bytes) {
 	}
 
 	elementList := mp.candidateTxs.ElementList()
-	elemsTobeRemoved := []pqueue.Element{}
+	txGroupsToBeUpdated := []*mempoolTransactionGroup{}
 	for _, elem := range *elementList {
 		txGroup := elem.(*mempoolTransactionGroup)
+		if txGroup.HasAnyTx(committedRawTxMap) {
+			txGroupsToBeUpdated = append(txGroupsToBeUpdated, txGroup)
+		}
+	}
+
+	// Note after each iteration, the indices of the elems in the priority queue
+	// could change. So we need elem.GetIndex() to return the updated index.
+	// The groups are taken out of the queues before their txs are removed, and
+	// the non-empty ones are re-inserted since their priority could change.
+	for _, txGroup := range txGroupsToBeUpdated {
+		mp.removeTxGroupUnsafe(txGroup)
 		numRemoved := txGroup.RemoveTxs(committedRawTxMap)
 		mp.size -= numRemoved
 		if txGroup.IsEmpty() {
 			delete(mp.addressToTxGroup, txGroup.address)
-			elemsTobeRemoved = append(elemsTobeRemoved, txGroup)
+		} else {
+			mp.pushTxGroupUnsafe(txGroup)
 		}
 	}
-
-	// Note after each iteration, the indices of the elems in the priority queue
-	// could change. So we need elem.GetIndex() to return the updated index
-	for _, elem := range elemsTobeRemoved {
-		mp.candidateTxs.Remove(elem.GetIndex())
-	}
 }
 
 func (mp *Mempool) GetTransactionStatus(hash string) (TxStatus, bool) {
@@ -423,6 +711,29 @@ func (mp *Mempool) GetCandidateTransactionHashes() []string {
 	return txHashes
 }
 
+// GetCandidateTransactions returns the raw candidate transactions, e.g. to reconstruct a compact block from
+func (mp *Mempool) GetCandidateTransactions() []common.Bytes {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	rawTxs := []common.Bytes{}
+	txgElemList := mp.candidateTxs.ElementList()
+	for _, txgElem := range *txgElemList {
+		txg := txgElem.(*mempoolTransactionGroup)
+		txElemList := txg.txs.ElementList()
+		for _, txElem := range *txElemList {
+			rawTxs = append(rawTxs, txElem.(*mempoolTransaction).rawTransaction)
+		}
+	}
+	return rawTxs
+}
+
+// HasSeenTransaction indicates whether the transaction has passed through the Mempool recently, i.e. it
+// has likely been gossiped to the peers
+func (mp *Mempool) HasSeenTransaction(rawTx common.Bytes) bool {
+	return mp.txBookeepper.hasSeen(rawTx)
+}
+
 // Flush removes all transactions from the Mempool and the transactionBookkeeper
 func (mp *Mempool) Flush() {
 	mp.mutex.Lock()
@@ -433,6 +744,10 @@ func (mp *Mempool) Flush() {
 	for !mp.candidateTxs.IsEmpty() {
 		mp.candidateTxs.Pop()
 	}
+	for !mp.evictableTxs.IsEmpty() {
+		mp.evictableTxs.Pop()
+	}
+	mp.addressToTxGroup = make(map[common.Address]*mempoolTransactionGroup)
 	mp.size = 0
 }
 
@@ -7,7 +7,10 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/result"
 	dp "github.com/dnerochain/dnero/dispatcher"
+	ltypes "github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/p2p/scoring"
 	"github.com/dnerochain/dnero/p2p/types"
 	"github.com/dnerochain/dnero/rlp"
 )
@@ -50,7 +53,9 @@ func Fuzz(data []byte) int {
 // ParseMessage implements the p2p.MessageHandler interface
 func (mmh *MempoolMessageHandler) ParseMessage(peerID string, channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (types.Message, error) {
 	var dataResponse dp.DataResponse
-	rlp.DecodeBytes(rawMessageBytes, &dataResponse)
+	if err := rlp.DecodeBytes(rawMessageBytes, &dataResponse); err != nil {
+		return types.Message{}, err
+	}
 
 	rawTx := dataResponse.Payload
 	message := types.Message{
@@ -70,10 +75,19 @@ func (mmh *MempoolMessageHandler) HandleMessage(message types.Message) error {
 	logger.Debugf("Received gossiped transaction: %v", hex.EncodeToString(rawTx))
 
 	err := mmh.mempool.InsertTransaction(rawTx)
-	if err == DuplicateTxError {
+	if err == DuplicateTxError || err == MempoolFullError || err == AccountTxQuotaExceededError ||
+		err == ReplacementTxUnderpricedError {
 		return nil
 	}
+	if err == FastsyncSkipTxError {
+		return err
+	}
 	if err != nil {
+		// The transactions that can never pass the screening are penalized lightly, so only
+		// the peers flooding the invalid transactions are banned
+		if mmh.mempool.dispatcher != nil && isPermanentScreeningFailure(rawTx, err) {
+			mmh.mempool.dispatcher.ReportPeer(message.PeerID, scoring.OffenseInvalidTx)
+		}
 		return err
 	}
 
@@ -86,3 +100,15 @@ func (mmh *MempoolMessageHandler) HandleMessage(message types.Message) error {
 
 	return nil
 }
+
+// isPermanentScreeningFailure indicates whether the transaction can never pass the screening, i.e.
+// it is undecodable or its signature is invalid. The other failures, e.g. an invalid sequence, might
+// be transient, since the transactions of an account can be gossiped out of order, so the peers
+// relaying them are not penalized.
+func isPermanentScreeningFailure(rawTx common.Bytes, err error) bool {
+	if _, decodeErr := ltypes.TxFromBytes(rawTx); decodeErr != nil {
+		return true
+	}
+	screeningErr, ok := err.(*TxScreeningError)
+	return ok && screeningErr.Code == result.CodeInvalidSignature
+}
@@ -16,6 +16,7 @@ import (
 	dp "github.com/dnerochain/dnero/dispatcher"
 	p2psim "github.com/dnerochain/dnero/p2p/simulation"
 	p2ptypes "github.com/dnerochain/dnero/p2p/types"
+	p2plmsg "github.com/dnerochain/dnero/p2pl/messenger"
 	"github.com/dnerochain/dnero/rlp"
 )
 
@@ -294,8 +295,12 @@ func TestMempoolBigBatchUpdateAndReaping(t *testing.T) {
 	p2psimnet := p2psim.NewSimnetWithHandler(nil)
 	mempool, _ := newTestMempool("peer0", p2psimnet)
 
-	committedRawTxs := []common.Bytes{}
+	// The TestLedger only has a handful of accounts, so lift the limits
 	multiplier := 30
+	mempool.maxNumTxs = multiplier * core.MaxNumRegularTxsPerBlock
+	mempool.maxNumTxsPerAccount = multiplier * core.MaxNumRegularTxsPerBlock
+
+	committedRawTxs := []common.Bytes{}
 	targetRemainder := 3
 	for i := 0; i < multiplier*core.MaxNumRegularTxsPerBlock; i++ {
 		tx := createTestRawTx("tx_" + strconv.FormatInt(int64(i), 10))
@@ -355,7 +360,7 @@ func TestMempoolTransactionGossip(t *testing.T) {
 	assert := assert.New(t)
 
 	netMsgIntercepter := newTestNetworkMessageInterceptor()
-	p2psimnet := p2psim.NewSimnetWithHandler(netMsgIntercepter)
+	p2psimnet := p2psim.NewSimnet()
 
 	// Add our node
 	mempool, ctx := newTestMempool("peer0", p2psimnet)
@@ -363,9 +368,11 @@ func TestMempoolTransactionGossip(t *testing.T) {
 
 	// Add two peer nodes
 	peer1 := p2psimnet.AddEndpoint("peer1")
+	peer1.RegisterMessageHandler(netMsgIntercepter)
 	peer1.Start(ctx)
 
 	peer2 := p2psimnet.AddEndpoint("peer2")
+	peer2.RegisterMessageHandler(netMsgIntercepter)
 	peer2.Start(ctx)
 
 	p2psimnet.Start(ctx)
@@ -380,6 +387,10 @@ func TestMempoolTransactionGossip(t *testing.T) {
 	assert.Equal(3, mempool.Size())
 	log.Infof(">>> Client submitted tx1, tx2, tx3")
 
+	mempool.BroadcastTx(tx1)
+	mempool.BroadcastTx(tx2)
+	mempool.BroadcastTx(tx3)
+
 	numGossippedTxs := 2 * 3 // 2 peers, each should receive 3 transactions
 	for i := 0; i < numGossippedTxs; i++ {
 		receivedMsg := <-netMsgIntercepter.ReceivedMessages
@@ -391,14 +402,212 @@ func TestMempoolTransactionGossip(t *testing.T) {
 	}
 }
 
+func TestMempoolCapacityAndEviction(t *testing.T) {
+	assert := assert.New(t)
+
+	mempool := CreateMempool(nil, nil)
+	mempool.maxNumTxs = 4
+	mempool.maxNumTxsPerAccount = 2
+
+	newTxInfo := func(address string, sequence uint64, gasPrice int64) *core.TxInfo {
+		return &core.TxInfo{
+			EffectiveGasPrice: big.NewInt(gasPrice),
+			Address:           common.HexToAddress(address),
+			Sequence:          sequence,
+		}
+	}
+
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx1"), newTxInfo("A1", 1, 100)))
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx2"), newTxInfo("A1", 2, 100)))
+	assert.Equal(AccountTxQuotaExceededError, mempool.addTransactionUnsafe(common.Bytes("tx3"), newTxInfo("A1", 3, 1000)))
+
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx4"), newTxInfo("B1", 1, 10)))
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx5"), newTxInfo("B1", 2, 10)))
+	assert.Equal(4, mempool.Size())
+
+	// Not paying more than the cheapest tx group
+	assert.Equal(MempoolFullError, mempool.addTransactionUnsafe(common.Bytes("tx6"), newTxInfo("C1", 1, 10)))
+	assert.Equal(4, mempool.Size())
+
+	// The tx group of B1 is evicted
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx7"), newTxInfo("C1", 1, 50)))
+	assert.Equal(3, mempool.Size())
+	_, ok := mempool.addressToTxGroup[common.HexToAddress("B1")]
+	assert.False(ok)
+	status, _ := mempool.GetTransactionStatus(getTransactionHash(common.Bytes("tx4")))
+	assert.Equal(TxStatusAbandoned, status)
+
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx8"), newTxInfo("C1", 2, 50)))
+	assert.Equal(4, mempool.Size())
+
+	reapedRawTxs := mempool.Reap(-1)
+	assert.Equal(4, len(reapedRawTxs))
+	assert.Equal("tx1", string(reapedRawTxs[0]))
+	assert.Equal("tx2", string(reapedRawTxs[1]))
+	assert.Equal("tx7", string(reapedRawTxs[2]))
+	assert.Equal("tx8", string(reapedRawTxs[3]))
+}
+
+func TestMempoolPrecheckBeforeScreening(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	mempool.maxNumTxs = 4
+	mempool.maxNumTxsPerAccount = 1
+	ledger := mempool.ledger.(*TestLedger)
+
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("tx1"))) // A1
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("tx2"))) // A2
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("tx3"))) // A3
+	assert.Equal(3, ledger.numScreenedTxs)
+
+	// The tx exceeding the account quota is not applied to the screened view
+	assert.Equal(AccountTxQuotaExceededError, mempool.InsertTransaction(createTestRawTx("tx4"))) // A1
+	assert.Equal(3, ledger.numScreenedTxs)
+
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("tx5"))) // B1
+	assert.Equal(4, ledger.numScreenedTxs)
+
+	// The tx not paying more than the cheapest tx group is not applied to the screened view
+	assert.Equal(MempoolFullError, mempool.InsertTransaction(createTestRawTx("tx6"))) // B2
+	assert.Equal(4, ledger.numScreenedTxs)
+	assert.Equal(0, ledger.numScreenedStateResets)
+
+	// The screened view is rebuilt from the remaining txs after A3 is evicted
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("tx7"))) // C1
+	assert.Equal(1, ledger.numScreenedStateResets)
+	assert.Equal(5+4, ledger.numScreenedTxs)
+	assert.Equal(4, mempool.Size())
+}
+
+func TestMempoolEvictionRebuildsScreenedView(t *testing.T) {
+	assert := assert.New(t)
+
+	mempool := CreateMempool(nil, nil)
+	ledger := newTestLedger().(*TestLedger)
+	mempool.SetLedger(ledger)
+	mempool.maxNumTxs = 3
+	mempool.maxNumTxsPerAccount = 3
+
+	newTxInfo := func(address string, sequence uint64, gasPrice int64) *core.TxInfo {
+		return &core.TxInfo{
+			EffectiveGasPrice: big.NewInt(gasPrice),
+			Address:           common.HexToAddress(address),
+			Sequence:          sequence,
+		}
+	}
+
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx1"), newTxInfo("A1", 1, 100)))
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx2"), newTxInfo("A1", 2, 100)))
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx3"), newTxInfo("B1", 1, 10)))
+
+	// tx2 spends what tx3 credited, so it no longer passes the screening once tx3 is evicted
+	ledger.invalidTxs = map[string]bool{"tx2": true}
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx4"), newTxInfo("C1", 1, 50)))
+	assert.Equal(1, ledger.numScreenedStateResets)
+	assert.Equal(2, mempool.Size())
+	status, _ := mempool.GetTransactionStatus(getTransactionHash(common.Bytes("tx2")))
+	assert.Equal(TxStatusAbandoned, status)
+	status, _ = mempool.GetTransactionStatus(getTransactionHash(common.Bytes("tx3")))
+	assert.Equal(TxStatusAbandoned, status)
+
+	// The tx group of the sender is the cheapest, so the next cheapest is evicted
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx5"), newTxInfo("C1", 2, 50)))
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx6"), newTxInfo("C1", 3, 200)))
+	assert.Equal(2, ledger.numScreenedStateResets)
+	_, ok := mempool.addressToTxGroup[common.HexToAddress("A1")]
+	assert.False(ok)
+
+	reapedRawTxs := mempool.Reap(-1)
+	assert.Equal(3, len(reapedRawTxs))
+	assert.Equal("tx4", string(reapedRawTxs[0]))
+	assert.Equal("tx5", string(reapedRawTxs[1]))
+	assert.Equal("tx6", string(reapedRawTxs[2]))
+	assert.True(mempool.evictableTxs.IsEmpty())
+}
+
+func TestMempoolReplaceTransaction(t *testing.T) {
+	assert := assert.New(t)
+
+	mempool := CreateMempool(nil, nil)
+	mempool.maxNumTxs = 16
+	mempool.maxNumTxsPerAccount = 16
+	mempool.replaceTxMinGasPriceBump = 10
+
+	newTxInfo := func(address string, sequence uint64, gasPrice int64) *core.TxInfo {
+		return &core.TxInfo{
+			EffectiveGasPrice: big.NewInt(gasPrice),
+			Address:           common.HexToAddress(address),
+			Sequence:          sequence,
+		}
+	}
+
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx1"), newTxInfo("A1", 1, 100)))
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx2"), newTxInfo("A1", 2, 100)))
+	assert.Nil(mempool.addTransactionUnsafe(common.Bytes("tx3"), newTxInfo("B1", 1, 105)))
+
+	txGroup := mempool.addressToTxGroup[common.HexToAddress("A1")]
+	replacedTx := txGroup.FindTx(1)
+	assert.Equal("tx1", string(replacedTx.rawTransaction))
+	assert.Nil(txGroup.FindTx(3))
+
+	// The gas price bump is less than 10%
+	assert.Equal(ReplacementTxUnderpricedError,
+		mempool.replaceTransactionUnsafe(txGroup, replacedTx, common.Bytes("tx4"), newTxInfo("A1", 1, 109)))
+
+	assert.Nil(mempool.replaceTransactionUnsafe(txGroup, replacedTx, common.Bytes("tx5"), newTxInfo("A1", 1, 110)))
+	assert.Equal(3, mempool.Size())
+	status, _ := mempool.GetTransactionStatus(getTransactionHash(common.Bytes("tx1")))
+	assert.Equal(TxStatusAbandoned, status)
+	status, _ = mempool.GetTransactionStatus(getTransactionHash(common.Bytes("tx5")))
+	assert.Equal(TxStatusPending, status)
+
+	// The tx group of A1 now has a higher priority than B1
+	reapedRawTxs := mempool.Reap(-1)
+	assert.Equal(3, len(reapedRawTxs))
+	assert.Equal("tx5", string(reapedRawTxs[0]))
+	assert.Equal("tx3", string(reapedRawTxs[1]))
+	assert.Equal("tx2", string(reapedRawTxs[2]))
+}
+
+func TestMempoolInsertReplacementTransaction(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+	mempool.replaceTxMinGasPriceBump = 10
+	ledger := &TestLedger{
+		effectiveGasPriceList: []uint64{100, 109, 110},
+		addressList:           []string{"A1", "A1", "A1"},
+		sequenceList:          []uint64{1, 1, 1},
+	}
+	mempool.SetLedger(ledger)
+
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("tx1")))
+
+	// The txs with the same sequence fail the regular screening, and go through the replacement
+	ledger.screenTxErrorCode = result.CodeInvalidSequence
+	assert.Equal(ReplacementTxUnderpricedError, mempool.InsertTransaction(createTestRawTx("tx2")))
+	assert.Nil(mempool.InsertTransaction(createTestRawTx("tx3")))
+	assert.Equal(1, mempool.Size())
+	assert.Equal(1, ledger.numScreenedTxs)
+
+	status, _ := mempool.GetTransactionStatus(getTransactionHash(createTestRawTx("tx1")))
+	assert.Equal(TxStatusAbandoned, status)
+	status, _ = mempool.GetTransactionStatus(getTransactionHash(createTestRawTx("tx3")))
+	assert.Equal(TxStatusPending, status)
+}
+
 // --------------- Test Utilities --------------- //
 
 func newTestMempool(peerID string, simnet *p2psim.Simnet) (*Mempool, context.Context) {
 	ctx := context.Background()
 
 	messenger := simnet.AddEndpoint(peerID)
-	dispatcher := dp.NewDispatcher(messenger, nil)
-	mempool := CreateMempool(dispatcher)
+	var p2plnet *p2plmsg.Messenger
+	dispatcher := dp.NewDispatcher(messenger, p2plnet)
+	mempool := CreateMempool(dispatcher, syncedChecker{})
 	mempool.SetLedger(newTestLedger())
 	txMsgHandler := CreateMempoolMessageHandler(mempool)
 	messenger.RegisterMessageHandler(txMsgHandler)
@@ -406,11 +615,21 @@ func newTestMempool(peerID string, simnet *p2psim.Simnet) (*Mempool, context.Con
 	return mempool, ctx
 }
 
+type syncedChecker struct{}
+
+func (sc syncedChecker) HasSynced() bool {
+	return true
+}
+
 type TestLedger struct {
-	counter               int
-	effectiveGasPriceList []uint64
-	addressList           []string
-	sequenceList          []uint64
+	counter                int
+	numScreenedTxs         int
+	numScreenedStateResets int
+	invalidTxs             map[string]bool  // the txs that fail ScreenTx
+	screenTxErrorCode      result.ErrorCode // if set, ScreenTx fails with the error code
+	effectiveGasPriceList  []uint64
+	addressList            []string
+	sequenceList           []uint64
 }
 
 func newTestLedger() core.Ledger {
@@ -456,20 +675,47 @@ func newTestLedger() core.Ledger {
 }
 
 func (tl *TestLedger) ScreenTxUnsafe(rawTx common.Bytes) result.Result {
-	_, res := tl.ScreenTx(rawTx)
+	_, res := tl.ScreenTx(rawTx, nil)
 	return res
 }
 
-func (tl *TestLedger) ScreenTx(rawTx common.Bytes) (*core.TxInfo, result.Result) {
+func (tl *TestLedger) ScreenTx(rawTx common.Bytes, precheck core.TxPrecheck) (*core.TxInfo, result.Result) {
+	if tl.screenTxErrorCode != result.CodeOK {
+		return nil, result.Error("Tx screening failed").WithErrorCode(tl.screenTxErrorCode)
+	}
+	if tl.invalidTxs[string(rawTx)] {
+		return nil, result.Error("Invalid tx")
+	}
+	txInfo, res := tl.screenTx(precheck)
+	if res.IsOK() {
+		tl.numScreenedTxs++ // only counts the txs applied to the screened view
+	}
+	return txInfo, res
+}
+
+func (tl *TestLedger) screenTx(precheck core.TxPrecheck) (*core.TxInfo, result.Result) {
 	txInfo := &core.TxInfo{
 		EffectiveGasPrice: new(big.Int).SetUint64(tl.effectiveGasPriceList[tl.counter]),
 		Address:           common.HexToAddress(tl.addressList[tl.counter]),
 		Sequence:          tl.sequenceList[tl.counter],
 	}
 	tl.counter = (tl.counter + 1) % len(tl.effectiveGasPriceList)
+	if precheck != nil {
+		if err := precheck(txInfo); err != nil {
+			return nil, result.Error("Tx precheck failed: %v", err)
+		}
+	}
 	return txInfo, result.OK
 }
 
+func (tl *TestLedger) ScreenReplacementTx(rawTx common.Bytes) (*core.TxInfo, result.Result) {
+	return tl.screenTx(nil)
+}
+
+func (tl *TestLedger) ResetScreenedState() {
+	tl.numScreenedStateResets++
+}
+
 func (tl *TestLedger) GetCurrentBlock() *core.Block {
 	return nil
 }
@@ -482,7 +728,7 @@ func (tl *TestLedger) ApplyBlockTxs(block *core.Block) result.Result {
 	return result.OK
 }
 
-func (tl *TestLedger) ResetState(height uint64, rootHash common.Hash) result.Result {
+func (tl *TestLedger) ResetState(block *core.Block) result.Result {
 	return result.OK
 }
 
@@ -498,6 +744,10 @@ func (tl *TestLedger) GetSentryCandidatePool(blockHash common.Hash) (*core.Sentr
 	return nil, nil
 }
 
+func (tl *TestLedger) GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash) (core.EliteEdgeNodePool, error) {
+	return nil, nil
+}
+
 func (tl *TestLedger) PruneState(endHeight uint64) error {
 	return nil
 }
@@ -529,10 +779,14 @@ func (tnmi *TestNetworkMessageInterceptor) EncodeMessage(message interface{}) (c
 }
 
 func (tnmi *TestNetworkMessageInterceptor) ParseMessage(peerID string, channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (p2ptypes.Message, error) {
+	var dataResponse dp.DataResponse
+	if err := rlp.DecodeBytes(rawMessageBytes, &dataResponse); err != nil {
+		return p2ptypes.Message{}, err
+	}
 	message := p2ptypes.Message{
 		PeerID:    peerID,
 		ChannelID: channelID,
-		Content:   rawMessageBytes,
+		Content:   dataResponse,
 	}
 	return message, nil
 }
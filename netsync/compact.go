@@ -0,0 +1,459 @@
+package netsync
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/p2p/scoring"
+	"github.com/dnerochain/dnero/rlp"
+)
+
+// MaxCompactBlockTxs is the max number of transactions a compact block can announce, including the
+// special transactions added by the proposer
+const MaxCompactBlockTxs = 2 * core.MaxNumRegularTxsPerBlock
+
+// CompactBlockMaxDistance is how far ahead of the local tip a block can be to be downloaded as a compact
+// block. The blocks further ahead are downloaded in full, since they are missing from the mempool anyway.
+const CompactBlockMaxDistance = 2
+
+// CompactBlockExpiration is how long a partially reconstructed compact block waits for its missing
+// transactions before it is discarded
+const CompactBlockExpiration = RequestTimeout
+
+// MaxPendingCompactBlocks is the max number of partially reconstructed compact blocks kept at a time
+const MaxPendingCompactBlocks = 16
+
+//
+// TxPool is the pool of the pending transactions the compact blocks are reconstructed from, i.e. the mempool
+//
+type TxPool interface {
+	GetCandidateTransactions() []common.Bytes
+	HasSeenTransaction(rawTx common.Bytes) bool
+}
+
+//
+// PrefilledTx is a transaction sent in full within a compact block
+//
+type PrefilledTx struct {
+	Index uint64
+	Tx    common.Bytes
+}
+
+//
+// CompactBlock carries the header of a block, the short IDs of the transactions the receiver likely
+// has in its mempool, and the transactions the sender predicts the receiver is missing, e.g. the
+// special transactions added by the proposer which are never gossiped.
+//
+type CompactBlock struct {
+	Header       *core.BlockHeader
+	ShortTxIDs   []uint64      // the short IDs of the transactions not prefilled, in the block order
+	PrefilledTxs []PrefilledTx // in the ascending order of the index
+}
+
+//
+// BlockTxs carries the transactions of a block at the requested indexes
+//
+type BlockTxs struct {
+	Block   common.Hash
+	Indexes []uint64
+	Txs     []common.Bytes
+}
+
+// shortTxID returns the short ID of the transaction in the block. The IDs are keyed by the block hash,
+// so a collision can not be crafted to hold for all the blocks.
+func shortTxID(blockHash common.Hash, rawTx common.Bytes) uint64 {
+	txHash := crypto.Keccak256Hash(rawTx)
+	hash := crypto.Keccak256Hash(blockHash[:], txHash[:])
+	return binary.BigEndian.Uint64(hash[:8])
+}
+
+// NewCompactBlock creates the compact block of the block. The transactions for which isMissing returns
+// true are prefilled.
+func NewCompactBlock(block *core.Block, isMissing func(rawTx common.Bytes) bool) *CompactBlock {
+	blockHash := block.Hash()
+	cb := &CompactBlock{
+		Header:       block.BlockHeader,
+		ShortTxIDs:   []uint64{},
+		PrefilledTxs: []PrefilledTx{},
+	}
+	for i, rawTx := range block.Txs {
+		if isMissing(rawTx) {
+			cb.PrefilledTxs = append(cb.PrefilledTxs, PrefilledTx{Index: uint64(i), Tx: rawTx})
+		} else {
+			cb.ShortTxIDs = append(cb.ShortTxIDs, shortTxID(blockHash, rawTx))
+		}
+	}
+	return cb
+}
+
+// NumTxs returns the number of transactions in the block
+func (cb *CompactBlock) NumTxs() int {
+	return len(cb.ShortTxIDs) + len(cb.PrefilledTxs)
+}
+
+// Validate checks the compact block is well-formed
+func (cb *CompactBlock) Validate() error {
+	if cb.Header == nil {
+		return fmt.Errorf("Compact block has no header")
+	}
+	numTxs := cb.NumTxs()
+	if numTxs > MaxCompactBlockTxs {
+		return fmt.Errorf("Compact block has too many transactions: %v", numTxs)
+	}
+	for i, prefilled := range cb.PrefilledTxs {
+		if len(prefilled.Tx) == 0 {
+			return fmt.Errorf("Prefilled transaction %v is empty", prefilled.Index)
+		}
+		if prefilled.Index >= uint64(numTxs) {
+			return fmt.Errorf("Prefilled transaction index %v out of range", prefilled.Index)
+		}
+		if i > 0 && prefilled.Index <= cb.PrefilledTxs[i-1].Index {
+			return fmt.Errorf("Prefilled transaction indexes are not in ascending order")
+		}
+	}
+	return nil
+}
+
+//
+// partialBlock is a compact block under reconstruction
+//
+type partialBlock struct {
+	header    *core.BlockHeader
+	txs       []common.Bytes // nil for the missing transactions
+	peerID    string         // the peer the compact block is received from
+	createdAt time.Time
+}
+
+// newPartialBlock fills in the transactions of the compact block from the prefilled transactions and
+// the given pool. The transactions whose short ID matches more than one transaction in the pool are
+// left missing.
+func newPartialBlock(cb *CompactBlock, pool []common.Bytes, peerID string) *partialBlock {
+	blockHash := cb.Header.Hash()
+	pb := &partialBlock{
+		header:    cb.Header,
+		txs:       make([]common.Bytes, cb.NumTxs()),
+		peerID:    peerID,
+		createdAt: time.Now(),
+	}
+
+	// Only the short IDs in the block are looked up in the pool
+	candidates := make(map[uint64]common.Bytes, len(cb.ShortTxIDs))
+	for _, id := range cb.ShortTxIDs {
+		candidates[id] = nil
+	}
+	collisions := make(map[uint64]bool)
+	for _, rawTx := range pool {
+		id := shortTxID(blockHash, rawTx)
+		found, ok := candidates[id]
+		if !ok {
+			continue
+		}
+		if found != nil {
+			collisions[id] = true
+			continue
+		}
+		candidates[id] = rawTx
+	}
+
+	nextPrefilled := 0
+	nextShortID := 0
+	for i := range pb.txs {
+		if nextPrefilled < len(cb.PrefilledTxs) && cb.PrefilledTxs[nextPrefilled].Index == uint64(i) {
+			pb.txs[i] = cb.PrefilledTxs[nextPrefilled].Tx
+			nextPrefilled++
+			continue
+		}
+		id := cb.ShortTxIDs[nextShortID]
+		nextShortID++
+		if !collisions[id] {
+			pb.txs[i] = candidates[id]
+		}
+	}
+	return pb
+}
+
+// missingIndexes returns the indexes of the transactions yet to be filled in
+func (pb *partialBlock) missingIndexes() []uint64 {
+	indexes := []uint64{}
+	for i, rawTx := range pb.txs {
+		if rawTx == nil {
+			indexes = append(indexes, uint64(i))
+		}
+	}
+	return indexes
+}
+
+// fill fills in the requested transactions
+func (pb *partialBlock) fill(blockTxs *BlockTxs) error {
+	if len(blockTxs.Indexes) != len(blockTxs.Txs) {
+		return fmt.Errorf("Mismatched number of indexes and transactions: %v vs %v", len(blockTxs.Indexes), len(blockTxs.Txs))
+	}
+	for i, index := range blockTxs.Indexes {
+		if index >= uint64(len(pb.txs)) {
+			return fmt.Errorf("Transaction index %v out of range", index)
+		}
+		pb.txs[index] = blockTxs.Txs[i]
+	}
+	return nil
+}
+
+// block returns the reconstructed block. It returns false if the block can not be reconstructed yet,
+// or the transactions do not match the transaction root of the header, e.g. due to a short ID
+// collision with a transaction not in the block.
+func (pb *partialBlock) block() (*core.Block, bool) {
+	for _, rawTx := range pb.txs {
+		if rawTx == nil {
+			return nil, false
+		}
+	}
+	if core.CalculateRootHash(pb.txs) != pb.header.TxHash {
+		return nil, false
+	}
+	return &core.Block{BlockHeader: pb.header, Txs: pb.txs}, true
+}
+
+func (pb *partialBlock) hasExpired() bool {
+	return time.Since(pb.createdAt) > CompactBlockExpiration
+}
+
+// shouldRequestCompactBlock indicates whether to download the block from the peer as a compact block,
+// i.e. the peer serves the compact blocks and the block is close enough to the tip for its
+// transactions to be in the mempool
+func (sm *SyncManager) shouldRequestCompactBlock(peerID string, header *core.BlockHeader) bool {
+	if !viper.GetBool(common.CfgSyncCompactBlockEnabled) || sm.txPool == nil || header == nil {
+		return false
+	}
+	info := sm.dispatcher.PeerHandshakeInfo(peerID)
+	if info == nil || !info.SupportsChannel(common.ChannelIDCompactBlock) {
+		return false
+	}
+	return header.Height <= sm.consensus.GetTip(true).Height+CompactBlockMaxDistance
+}
+
+func (sm *SyncManager) requestCompactBlock(peerID string, hash common.Hash) {
+	request := dispatcher.DataRequest{
+		ChannelID: common.ChannelIDCompactBlock,
+		Entries:   []string{hash.Hex()},
+	}
+	sm.logger.WithFields(log.Fields{
+		"block": hash.Hex(),
+		"peer":  peerID,
+	}).Debug("Sending compact block request")
+	sm.dispatcher.GetData([]string{peerID}, request)
+}
+
+// requestFullBlock downloads the block in full, when the compact block can not be reconstructed
+func (sm *SyncManager) requestFullBlock(peerID string, hash common.Hash) {
+	sm.compactFallbackCounter.Inc(1)
+	request := dispatcher.DataRequest{
+		ChannelID: common.ChannelIDBlock,
+		Entries:   []string{hash.Hex()},
+	}
+	sm.logger.WithFields(log.Fields{
+		"block": hash.Hex(),
+		"peer":  peerID,
+	}).Debug("Falling back to full block request")
+	sm.dispatcher.GetData([]string{peerID}, request)
+}
+
+// sendCompactBlock sends the compact block to the peer. The transactions not seen by the local mempool,
+// e.g. the special transactions, are likely missing from the peer's mempool too, so they are prefilled.
+func (sm *SyncManager) sendCompactBlock(peerID string, hashStr string) {
+	hash := common.HexToHash(hashStr)
+	block, err := sm.chain.FindBlock(hash)
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"hashStr": hashStr,
+			"err":     err,
+			"peerID":  peerID,
+		}).Debug("Failed to find hash string locally")
+		return
+	}
+
+	isMissing := func(rawTx common.Bytes) bool {
+		return sm.txPool == nil || !sm.txPool.HasSeenTransaction(rawTx)
+	}
+	payload, err := rlp.EncodeToBytes(NewCompactBlock(block.Block, isMissing))
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"block":  hashStr,
+			"peerID": peerID,
+		}).Error("Failed to encode compact block")
+		return
+	}
+	sm.logger.WithFields(log.Fields{
+		"block":  hashStr,
+		"peerID": peerID,
+	}).Debug("Sending compact block")
+	sm.dispatcher.SendData([]string{peerID}, dispatcher.DataResponse{
+		ChannelID: common.ChannelIDCompactBlock,
+		Payload:   payload,
+	})
+}
+
+// sendBlockTxs sends the requested transactions of a block to the peer. The first entry of the request
+// is the block hash, followed by the indexes of the transactions.
+func (sm *SyncManager) sendBlockTxs(peerID string, entries []string) {
+	if len(entries) < 2 || len(entries) > MaxCompactBlockTxs+1 {
+		sm.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
+		return
+	}
+	hash := common.HexToHash(entries[0])
+	block, err := sm.chain.FindBlock(hash)
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"hashStr": entries[0],
+			"err":     err,
+			"peerID":  peerID,
+		}).Debug("Failed to find hash string locally")
+		return
+	}
+
+	blockTxs := &BlockTxs{Block: hash}
+	for _, entry := range entries[1:] {
+		index, err := strconv.ParseUint(entry, 10, 64)
+		if err != nil || index >= uint64(len(block.Txs)) {
+			sm.logger.WithFields(log.Fields{
+				"block":  entries[0],
+				"index":  entry,
+				"peerID": peerID,
+			}).Debug("Invalid transaction index in block transactions request")
+			sm.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
+			return
+		}
+		blockTxs.Indexes = append(blockTxs.Indexes, index)
+		blockTxs.Txs = append(blockTxs.Txs, block.Txs[index])
+	}
+	payload, err := rlp.EncodeToBytes(blockTxs)
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"block":  entries[0],
+			"peerID": peerID,
+		}).Error("Failed to encode block transactions")
+		return
+	}
+	sm.dispatcher.SendData([]string{peerID}, dispatcher.DataResponse{
+		ChannelID: common.ChannelIDBlockTxs,
+		Payload:   payload,
+	})
+}
+
+// handleCompactBlock reconstructs the block from the mempool, and requests the missing transactions
+// from the peer if any. Only the compact blocks requested by the RequestManager are handled.
+func (sm *SyncManager) handleCompactBlock(peerID string, cb *CompactBlock) {
+	hash := cb.Header.Hash()
+	if eb, err := sm.chain.FindBlock(hash); err == nil && !eb.Status.IsPending() {
+		return
+	}
+	if _, ok := sm.compactBlocks[hash]; ok {
+		return
+	}
+
+	// The hash commits to the whole header, so the compact block carries the header the block body
+	// was requested for
+	header := sm.requestMgr.GetRequestedHeader(hash, peerID)
+	if header == nil {
+		sm.logger.WithFields(log.Fields{
+			"block.Hash": hash.Hex(),
+			"peer":       peerID,
+		}).Debug("Ignoring unrequested compact block")
+		return
+	}
+	cb.Header = header
+
+	sm.logger.WithFields(log.Fields{
+		"block.Hash":   hash.Hex(),
+		"block.Height": cb.Header.Height,
+		"numTxs":       cb.NumTxs(),
+		"numPrefilled": len(cb.PrefilledTxs),
+		"peer":         peerID,
+	}).Debug("Received compact block")
+
+	pool := []common.Bytes{}
+	if sm.txPool != nil && len(cb.ShortTxIDs) > 0 {
+		pool = sm.txPool.GetCandidateTransactions()
+	}
+	pb := newPartialBlock(cb, pool, peerID)
+	missing := pb.missingIndexes()
+	if len(missing) == 0 {
+		sm.completeCompactBlock(pb)
+		return
+	}
+
+	sm.purgeExpiredCompactBlocks()
+	if len(sm.compactBlocks) >= MaxPendingCompactBlocks {
+		sm.requestFullBlock(peerID, hash)
+		return
+	}
+	sm.compactBlocks[hash] = pb
+
+	entries := []string{hash.Hex()}
+	for _, index := range missing {
+		entries = append(entries, strconv.FormatUint(index, 10))
+	}
+	sm.compactTxsRequestedCounter.Inc(int64(len(missing)))
+	sm.logger.WithFields(log.Fields{
+		"block":      hash.Hex(),
+		"numMissing": len(missing),
+		"peer":       peerID,
+	}).Debug("Requesting missing transactions of compact block")
+	sm.dispatcher.GetData([]string{peerID}, dispatcher.DataRequest{
+		ChannelID: common.ChannelIDBlockTxs,
+		Entries:   entries,
+	})
+}
+
+// handleBlockTxs fills in the missing transactions of a compact block
+func (sm *SyncManager) handleBlockTxs(peerID string, blockTxs *BlockTxs) {
+	pb, ok := sm.compactBlocks[blockTxs.Block]
+	if !ok || pb.peerID != peerID {
+		return
+	}
+	delete(sm.compactBlocks, blockTxs.Block)
+
+	if err := pb.fill(blockTxs); err != nil {
+		sm.logger.WithFields(log.Fields{
+			"block": blockTxs.Block.Hex(),
+			"error": err,
+			"peer":  peerID,
+		}).Warn("Invalid block transactions")
+		sm.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
+		return
+	}
+	sm.completeCompactBlock(pb)
+}
+
+// completeCompactBlock hands the reconstructed block over as if it was received in full. If the
+// reconstruction failed, the block is downloaded in full instead.
+func (sm *SyncManager) completeCompactBlock(pb *partialBlock) {
+	block, ok := pb.block()
+	if !ok {
+		sm.requestFullBlock(pb.peerID, pb.header.Hash())
+		return
+	}
+	sm.compactReconstructedCounter.Inc(1)
+	sm.logger.WithFields(log.Fields{
+		"block.Hash":   block.Hash().Hex(),
+		"block.Parent": block.Parent.Hex(),
+		"block.Height": block.Height,
+		"peer":         pb.peerID,
+	}).Debug("Reconstructed compact block")
+	sm.dispatcher.RecordMessageSource(block.Hash(), pb.peerID)
+	sm.handleBlock(block)
+}
+
+func (sm *SyncManager) purgeExpiredCompactBlocks() {
+	for hash, pb := range sm.compactBlocks {
+		if pb.hasExpired() {
+			delete(sm.compactBlocks, hash)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// +build unit
+
+package netsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/p2p/simulation"
+	p2plmsg "github.com/dnerochain/dnero/p2pl/messenger"
+	"github.com/dnerochain/dnero/rlp"
+)
+
+func newCompactTestBlock(txs ...string) *core.Block {
+	block := core.NewBlock()
+	block.ChainID = "testchain"
+	block.Height = 10
+	rawTxs := []common.Bytes{}
+	for _, tx := range txs {
+		rawTxs = append(rawTxs, common.Bytes(tx))
+	}
+	block.AddTxs(rawTxs)
+	return block
+}
+
+func prefill(txs ...string) func(rawTx common.Bytes) bool {
+	return func(rawTx common.Bytes) bool {
+		for _, tx := range txs {
+			if string(rawTx) == tx {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestCompactBlockEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	block := newCompactTestBlock("coinbase", "tx1", "tx2")
+	cb := NewCompactBlock(block, prefill("coinbase"))
+	assert.Equal(3, cb.NumTxs())
+	assert.Equal(2, len(cb.ShortTxIDs))
+	assert.Equal(1, len(cb.PrefilledTxs))
+	assert.Nil(cb.Validate())
+
+	raw, err := rlp.EncodeToBytes(cb)
+	assert.Nil(err)
+	cb2 := &CompactBlock{}
+	assert.Nil(rlp.DecodeBytes(raw, cb2))
+	assert.Equal(block.Hash(), cb2.Header.Hash())
+	assert.Equal(cb.ShortTxIDs, cb2.ShortTxIDs)
+	assert.Equal(uint64(0), cb2.PrefilledTxs[0].Index)
+	assert.Equal(common.Bytes("coinbase"), cb2.PrefilledTxs[0].Tx)
+}
+
+func TestCompactBlockReconstruction(t *testing.T) {
+	assert := assert.New(t)
+
+	block := newCompactTestBlock("coinbase", "tx1", "tx2", "tx3")
+	cb := NewCompactBlock(block, prefill("coinbase"))
+
+	// All the transactions are in the pool
+	pool := []common.Bytes{common.Bytes("tx3"), common.Bytes("other"), common.Bytes("tx1"), common.Bytes("tx2")}
+	pb := newPartialBlock(cb, pool, "peer1")
+	assert.Equal(0, len(pb.missingIndexes()))
+	reconstructed, ok := pb.block()
+	assert.True(ok)
+	assert.Equal(block.Hash(), reconstructed.Hash())
+	assert.Equal(block.Txs, reconstructed.Txs)
+
+	// Some of the transactions are missing from the pool
+	pool = []common.Bytes{common.Bytes("tx1")}
+	pb = newPartialBlock(cb, pool, "peer1")
+	assert.Equal([]uint64{2, 3}, pb.missingIndexes())
+	_, ok = pb.block()
+	assert.False(ok)
+
+	assert.NotNil(pb.fill(&BlockTxs{Block: block.Hash(), Indexes: []uint64{2}, Txs: []common.Bytes{}}))
+	assert.NotNil(pb.fill(&BlockTxs{Block: block.Hash(), Indexes: []uint64{4}, Txs: []common.Bytes{common.Bytes("tx4")}}))
+
+	assert.Nil(pb.fill(&BlockTxs{
+		Block:   block.Hash(),
+		Indexes: []uint64{2, 3},
+		Txs:     []common.Bytes{common.Bytes("tx2"), common.Bytes("tx3")},
+	}))
+	assert.Equal(0, len(pb.missingIndexes()))
+	reconstructed, ok = pb.block()
+	assert.True(ok)
+	assert.Equal(block.Hash(), reconstructed.Hash())
+}
+
+func TestCompactBlockMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	block := newCompactTestBlock("tx1", "tx2")
+	cb := NewCompactBlock(block, prefill())
+
+	// Colliding short IDs are left missing
+	blockHash := block.Hash()
+	pool := []common.Bytes{common.Bytes("tx1"), common.Bytes("tx1"), common.Bytes("tx2")}
+	assert.Equal(shortTxID(blockHash, pool[0]), shortTxID(blockHash, pool[1]))
+	pb := newPartialBlock(cb, pool, "peer1")
+	assert.Equal([]uint64{0}, pb.missingIndexes())
+
+	// The transactions not matching the transaction root are rejected
+	assert.Nil(pb.fill(&BlockTxs{Block: blockHash, Indexes: []uint64{0}, Txs: []common.Bytes{common.Bytes("bogus")}}))
+	_, ok := pb.block()
+	assert.False(ok)
+}
+
+func TestCompactBlockValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	block := newCompactTestBlock("coinbase", "tx1", "tx2")
+	assert.Nil(NewCompactBlock(block, prefill("coinbase", "tx2")).Validate())
+
+	cb := NewCompactBlock(block, prefill())
+	cb.Header = nil
+	assert.NotNil(cb.Validate())
+
+	cb = NewCompactBlock(block, prefill("coinbase", "tx2"))
+	cb.PrefilledTxs[0], cb.PrefilledTxs[1] = cb.PrefilledTxs[1], cb.PrefilledTxs[0]
+	assert.NotNil(cb.Validate())
+
+	cb = NewCompactBlock(block, prefill("tx2"))
+	cb.PrefilledTxs[0].Index = 3
+	assert.NotNil(cb.Validate())
+
+	cb = NewCompactBlock(block, prefill("tx2"))
+	cb.PrefilledTxs[0].Tx = common.Bytes{}
+	assert.NotNil(cb.Validate())
+
+	cb = NewCompactBlock(block, prefill())
+	cb.ShortTxIDs = make([]uint64, MaxCompactBlockTxs+1)
+	assert.NotNil(cb.Validate())
+}
+
+func TestHandleCompactBlockOnlyRequested(t *testing.T) {
+	assert := assert.New(t)
+	core.ResetTestBlocks()
+
+	chain := blockchain.CreateTestChainByBlocks([]string{"A1", "A0"})
+	simnet := simulation.NewSimnet()
+	net1 := simnet.AddEndpoint("node1")
+	simnet.AddEndpoint("peer1")
+	simnet.AddEndpoint("peer2")
+	simnet.Start(context.Background())
+	defer simnet.Stop()
+
+	var p2plnet *p2plmsg.Messenger
+	dispatch := dispatcher.NewDispatcher(net1, p2plnet)
+	a1, _ := chain.FindBlock(core.GetTestBlock("A1").Hash())
+	sm := NewSyncManager(chain, NewMockConsensus(chain, a1), net1, p2plnet, dispatch, NewMockMessageConsumer(), nil)
+
+	block := newCompactTestBlock("coinbase", "tx1", "tx2")
+	hash := block.Hash()
+	cb := NewCompactBlock(block, prefill("coinbase"))
+
+	// Unknown block
+	sm.handleCompactBlock("peer1", cb)
+	assert.Equal(0, len(sm.compactBlocks))
+
+	// The header is known, but the body has not been requested yet
+	sm.requestMgr.AddHeader(block.BlockHeader, []string{"peer1"})
+	sm.handleCompactBlock("peer1", cb)
+	assert.Equal(0, len(sm.compactBlocks))
+
+	pendingBlock := sm.requestMgr.pendingBlocksByHash[hash.String()].Value.(*PendingBlock)
+	pendingBlock.status = RequestWaitingBodyResp
+
+	// The peer does not have the block
+	sm.handleCompactBlock("peer2", cb)
+	assert.Equal(0, len(sm.compactBlocks))
+
+	// The partial block waits for the missing transactions
+	sm.handleCompactBlock("peer1", cb)
+	assert.Equal(1, len(sm.compactBlocks))
+	assert.Equal(block.BlockHeader, sm.compactBlocks[hash].header)
+	assert.Equal([]uint64{1, 2}, sm.compactBlocks[hash].missingIndexes())
+}
@@ -0,0 +1,210 @@
+package netsync
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/util"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/p2p"
+	"github.com/dnerochain/dnero/p2p/scoring"
+	p2ptypes "github.com/dnerochain/dnero/p2p/types"
+	"github.com/dnerochain/dnero/p2pl"
+	"github.com/dnerochain/dnero/rlp"
+)
+
+const LightSyncRequestInterval = 5 * time.Second
+
+// HeaderConsumer consumes the block headers and votes collected by the LightSyncManager.
+type HeaderConsumer interface {
+	// AddHeader and AddVote add the header or the vote received from the peer. They return an error if the
+	// peer misbehaved, e.g. sent an invalid header or vote.
+	AddHeader(header *core.BlockHeader, peerID string) error
+	AddVote(vote core.Vote, peerID string) error
+
+	// GetInventoryStarts returns the hashes of the headers to resume the header sync from, in the order of preference.
+	GetInventoryStarts() []string
+}
+
+var _ p2p.MessageHandler = (*LightSyncManager)(nil)
+
+// LightSyncManager syncs only the block headers and votes from the full nodes. Unlike the SyncManager, it
+// neither downloads the block bodies nor serves the inventory requests of other peers.
+type LightSyncManager struct {
+	consumer   HeaderConsumer
+	dispatcher *dispatcher.Dispatcher
+
+	wg       *sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stopped  bool
+	incoming chan p2ptypes.Message
+
+	logger *log.Entry
+}
+
+func NewLightSyncManager(networkOld p2p.Network, network p2pl.Network, disp *dispatcher.Dispatcher, consumer HeaderConsumer) *LightSyncManager {
+	sm := &LightSyncManager{
+		consumer:   consumer,
+		dispatcher: disp,
+		wg:         &sync.WaitGroup{},
+		incoming:   make(chan p2ptypes.Message, viper.GetInt(common.CfgSyncMessageQueueSize)),
+	}
+
+	if !reflect.ValueOf(networkOld).IsNil() {
+		networkOld.RegisterMessageHandler(sm)
+	}
+	if !reflect.ValueOf(network).IsNil() {
+		network.RegisterMessageHandler(sm)
+	}
+
+	sm.logger = util.GetLoggerForModule("lightsync")
+
+	return sm
+}
+
+func (sm *LightSyncManager) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	sm.ctx = c
+	sm.cancel = cancel
+
+	sm.wg.Add(1)
+	go sm.mainLoop()
+}
+
+func (sm *LightSyncManager) Stop() {
+	sm.cancel()
+}
+
+func (sm *LightSyncManager) Wait() {
+	sm.wg.Wait()
+}
+
+func (sm *LightSyncManager) mainLoop() {
+	defer sm.wg.Done()
+
+	ticker := time.NewTicker(LightSyncRequestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sm.ctx.Done():
+			sm.stopped = true
+			return
+		case msg := <-sm.incoming:
+			sm.processMessage(msg)
+		case <-ticker.C:
+			sm.requestHeaders()
+		}
+	}
+}
+
+// GetChannelIDs implements the p2p.MessageHandler interface.
+func (sm *LightSyncManager) GetChannelIDs() []common.ChannelIDEnum {
+	return []common.ChannelIDEnum{
+		common.ChannelIDHeader,
+		common.ChannelIDBlock,
+		common.ChannelIDVote,
+	}
+}
+
+// ParseMessage implements p2p.MessageHandler interface.
+func (sm *LightSyncManager) ParseMessage(peerID string, channelID common.ChannelIDEnum,
+	rawMessageBytes common.Bytes) (p2ptypes.Message, error) {
+	message := p2ptypes.Message{
+		PeerID:    peerID,
+		ChannelID: channelID,
+	}
+	data, err := decodeMessage(rawMessageBytes)
+	message.Content = data
+	return message, err
+}
+
+// EncodeMessage implements p2p.MessageHandler interface.
+func (sm *LightSyncManager) EncodeMessage(message interface{}) (common.Bytes, error) {
+	return encodeMessage(message)
+}
+
+// HandleMessage implements p2p.MessageHandler interface.
+func (sm *LightSyncManager) HandleMessage(msg p2ptypes.Message) (err error) {
+	sm.incoming <- msg
+	return
+}
+
+// requestHeaders asks the neighbors for the headers following the latest header we have. Full nodes
+// respond to the inventory request with up to MaxInventorySize headers on the header channel.
+func (sm *LightSyncManager) requestHeaders() {
+	starts := sm.consumer.GetInventoryStarts()
+	if len(starts) == 0 {
+		return
+	}
+	sm.dispatcher.GetInventory([]string{}, dispatcher.InventoryRequest{
+		ChannelID: common.ChannelIDBlock,
+		Starts:    starts,
+	})
+}
+
+func (sm *LightSyncManager) processMessage(message p2ptypes.Message) {
+	switch content := message.Content.(type) {
+	case dispatcher.DataResponse:
+		sm.handleDataResponse(message.PeerID, &content)
+	case dispatcher.InventoryRequest, dispatcher.InventoryResponse, dispatcher.DataRequest:
+		// A light node has no blocks to serve, and does not download the block bodies.
+	default:
+		sm.logger.WithFields(log.Fields{
+			"message": message,
+		}).Warn("Received unknown message")
+	}
+}
+
+func (sm *LightSyncManager) handleDataResponse(peerID string, data *dispatcher.DataResponse) {
+	switch data.ChannelID {
+	case common.ChannelIDHeader:
+		headers := &Headers{}
+		err := rlp.DecodeBytes(data.Payload, headers)
+		if err != nil {
+			sm.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"error":     err,
+				"peerID":    peerID,
+			}).Debug("Failed to decode HeaderResponse payload")
+			return
+		}
+		for _, header := range headers.HeaderArray {
+			if err := sm.consumer.AddHeader(header, peerID); err != nil {
+				sm.logger.WithFields(log.Fields{
+					"error":  err,
+					"peerID": peerID,
+				}).Debug("Rejected header from peer")
+				sm.dispatcher.ReportPeer(peerID, scoring.OffenseInvalidBlock)
+				return
+			}
+		}
+	case common.ChannelIDVote:
+		vote := core.Vote{}
+		err := rlp.DecodeBytes(data.Payload, &vote)
+		if err != nil {
+			sm.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"error":     err,
+				"peerID":    peerID,
+			}).Debug("Failed to decode DataResponse payload")
+			return
+		}
+		if err := sm.consumer.AddVote(vote, peerID); err != nil {
+			sm.logger.WithFields(log.Fields{
+				"error":  err,
+				"peerID": peerID,
+			}).Debug("Rejected vote from peer")
+			sm.dispatcher.ReportPeer(peerID, scoring.OffenseInvalidVote)
+		}
+	default:
+		// Block bodies, proposals, etc. are not needed by a light node.
+	}
+}
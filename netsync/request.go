@@ -5,6 +5,7 @@ import (
 	"container/list"
 	"context"
 	"math/rand"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/dnerochain/dnero/blockchain"
 	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/metrics"
 	"github.com/dnerochain/dnero/common/util"
 	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/dispatcher"
@@ -141,6 +143,9 @@ type RequestManager struct {
 	aplock         *sync.RWMutex
 
 	reporter *rp.Reporter
+
+	pendingBlocksGauge  metrics.Gauge // number of the block hashes waiting to be downloaded
+	pendingHeadersGauge metrics.Gauge // number of the block headers waiting for the block bodies
 }
 
 func NewRequestManager(syncMgr *SyncManager, reporter *rp.Reporter) *RequestManager {
@@ -175,6 +180,9 @@ func NewRequestManager(syncMgr *SyncManager, reporter *rp.Reporter) *RequestMana
 		aplock:         &sync.RWMutex{},
 
 		reporter: reporter,
+
+		pendingBlocksGauge:  metrics.GetOrRegisterGauge("sync/pending/blocks", nil),
+		pendingHeadersGauge: metrics.GetOrRegisterGauge("sync/pending/headers", nil),
 	}
 
 	logger := util.GetLoggerForModule("request")
@@ -337,6 +345,9 @@ func (rm *RequestManager) tryToDownload() {
 		}
 	}
 	rm.pendingBlocksWithHeader = newQ
+
+	rm.pendingBlocksGauge.Update(int64(rm.pendingBlocks.Len()))
+	rm.pendingHeadersGauge.Update(int64(rm.pendingBlocksWithHeader.Len()))
 }
 
 //compatible with older version, download block from hash
@@ -480,6 +491,15 @@ func (rm *RequestManager) downloadBlockFromHeader() {
 				continue
 			}
 
+			// The new blocks are downloaded one by one as compact blocks from the supporting peers
+			if rm.syncMgr.shouldRequestCompactBlock(randomPeerID, pendingBlock.header) {
+				rm.syncMgr.requestCompactBlock(randomPeerID, pendingBlock.hash)
+				pendingBlock.UpdateTimestamp()
+				pendingBlock.status = RequestWaitingBodyResp
+				rm.fastsyncQuota--
+				continue
+			}
+
 			if blockBuffer, ok = peerMap[randomPeerID]; !ok {
 				blockBuffer = []string{}
 			}
@@ -563,7 +583,7 @@ func (rm *RequestManager) getInventory(req dispatcher.InventoryRequest) {
 	}
 	if len(peersToRequest) < targetSize { // resample
 		allPeers := rm.syncMgr.dispatcher.Peers(true) // skip edge nodes
-		samples := util.Sample(allPeers, targetSize)
+		samples := rm.prioritizeSyncPeers(util.Sample(allPeers, targetSize))
 		for _, sample := range samples {
 			duplicate := false
 			for _, pid := range peersToRequest {
@@ -593,6 +613,34 @@ func (rm *RequestManager) getInventory(req dispatcher.InventoryRequest) {
 	rm.syncMgr.dispatcher.GetInventory(peersToRequest, req)
 }
 
+// prioritizeSyncPeers orders the sampled peers by how likely they can serve the blocks we are missing,
+// based on the info they sent in the handshake. The peers ahead of us come first, followed by the peers
+// with no handshake info, and then the peers not ahead of us or not serving the blocks. The handshake
+// info is neither verified nor updated after the handshake, so it only breaks the ties within the
+// random sample, and never decides which peers are sampled.
+func (rm *RequestManager) prioritizeSyncPeers(peerIDs []string) []string {
+	localHeight := rm.syncMgr.consensus.GetLastFinalizedBlock().Height
+	priority := func(peerID string) int {
+		info := rm.syncMgr.dispatcher.PeerHandshakeInfo(peerID)
+		if info == nil {
+			return 1
+		}
+		if info.FinalizedHeight > localHeight && info.SupportsChannel(common.ChannelIDBlock) {
+			return 0
+		}
+		return 2
+	}
+
+	priorities := make(map[string]int, len(peerIDs))
+	for _, peerID := range peerIDs {
+		priorities[peerID] = priority(peerID)
+	}
+	sort.SliceStable(peerIDs, func(i, j int) bool {
+		return priorities[peerIDs[i]] < priorities[peerIDs[j]]
+	})
+	return peerIDs
+}
+
 func (rm *RequestManager) sendBlocksRequest(peerID string, entries []string) {
 	request := dispatcher.DataRequest{
 		ChannelID: common.ChannelIDBlock,
@@ -702,6 +750,28 @@ func (rm *RequestManager) AddHeader(header *core.BlockHeader, peerIDs []string)
 	}
 }
 
+// GetRequestedHeader returns the header of the given block if its body has been requested, and the
+// peer is one of the peers that have the block. Otherwise it returns nil.
+func (rm *RequestManager) GetRequestedHeader(hash common.Hash, peerID string) *core.BlockHeader {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	pendingBlockEl, ok := rm.pendingBlocksByHash[hash.String()]
+	if !ok {
+		return nil
+	}
+	pendingBlock := pendingBlockEl.Value.(*PendingBlock)
+	if pendingBlock.header == nil || pendingBlock.status != RequestWaitingBodyResp {
+		return nil
+	}
+	for _, id := range pendingBlock.peers {
+		if id == peerID {
+			return pendingBlock.header
+		}
+	}
+	return nil
+}
+
 // AddBlock process an incoming block.
 func (rm *RequestManager) AddBlock(block *core.Block) {
 	rm.mu.Lock()
@@ -0,0 +1,459 @@
+package netsync
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/util"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/p2p"
+	p2ptypes "github.com/dnerochain/dnero/p2p/types"
+	"github.com/dnerochain/dnero/p2pl"
+	"github.com/dnerochain/dnero/rlp"
+	"github.com/dnerochain/dnero/snapshot"
+	"github.com/dnerochain/dnero/store/database"
+)
+
+const (
+	// StateSyncRequestTimeout is how long to wait for the peers to respond to a state sync request
+	StateSyncRequestTimeout = 10 * time.Second
+
+	// MaxStateSyncNodesPerRequest is the max number of trie nodes requested from (or served to) a peer at a time
+	MaxStateSyncNodesPerRequest = 256
+
+	// MaxStateSyncStalls is the max number of consecutive requests without any trie node returned, after
+	// which the sync of the current checkpoint is abandoned, e.g. because its state is pruned by the peers
+	MaxStateSyncStalls = 6
+
+	stateSyncResponseQueueSize = 64
+)
+
+var (
+	errStateSyncStopped = errors.New("State sync is stopped")
+	errStateSyncStalled = errors.New("State sync is stalled, no peer serves the missing trie nodes")
+)
+
+// StateSyncPayload is the payload of the DataResponses on the state sync channel. It carries either the
+// checkpoint the peer offers to sync the state for, or the trie nodes requested by hash.
+type StateSyncPayload struct {
+	Checkpoints []core.StateSyncCheckpoint
+	Nodes       []common.Bytes
+}
+
+type stateSyncResponse struct {
+	peerID  string
+	payload *StateSyncPayload
+}
+
+var _ p2p.MessageHandler = (*StateSyncManager)(nil)
+
+// StateSyncManager implements the state sync protocol. A DataRequest on the state sync channel with no
+// entries asks for the checkpoint to sync, i.e. the latest finalized block with the proofs of the validator
+// set changes since the genesis. Otherwise its entries are the hex hashes of the requested trie nodes.
+// Every full node serves the requests, and a new node can sync the state of a checkpoint from the peers
+// instead of importing a snapshot file.
+type StateSyncManager struct {
+	mu         *sync.Mutex
+	db         database.Database
+	chain      *blockchain.Chain
+	consensus  core.ConsensusEngine
+	dispatcher *dispatcher.Dispatcher
+
+	checkpoint       *core.StateSyncCheckpoint // cached checkpoint served to the peers
+	checkpointHeight uint64
+	building         bool        // whether a checkpoint is being built in the background
+	attemptedHash    common.Hash // the block the checkpoint was last built for, successfully or not
+
+	responses chan stateSyncResponse
+	peerIdx   int
+	stalls    int
+
+	wg       *sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	incoming chan p2ptypes.Message
+
+	logger *log.Entry
+}
+
+func NewStateSyncManager(networkOld p2p.Network, network p2pl.Network, disp *dispatcher.Dispatcher, db database.Database) *StateSyncManager {
+	sm := &StateSyncManager{
+		mu:         &sync.Mutex{},
+		db:         db,
+		dispatcher: disp,
+		responses:  make(chan stateSyncResponse, stateSyncResponseQueueSize),
+		wg:         &sync.WaitGroup{},
+		incoming:   make(chan p2ptypes.Message, viper.GetInt(common.CfgSyncMessageQueueSize)),
+	}
+
+	if !reflect.ValueOf(networkOld).IsNil() {
+		networkOld.RegisterMessageHandler(sm)
+	}
+	if !reflect.ValueOf(network).IsNil() {
+		network.RegisterMessageHandler(sm)
+	}
+
+	sm.logger = util.GetLoggerForModule("statesync")
+
+	return sm
+}
+
+// SetChain sets the chain, the consensus engine and the database of the running node, which are needed
+// to serve the checkpoints. Until then the node serves only the trie nodes in the database it syncs into.
+func (sm *StateSyncManager) SetChain(chain *blockchain.Chain, consensus core.ConsensusEngine, db database.Database) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.chain = chain
+	sm.consensus = consensus
+	sm.db = db
+}
+
+func (sm *StateSyncManager) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	sm.ctx = c
+	sm.cancel = cancel
+
+	sm.wg.Add(1)
+	go sm.mainLoop()
+}
+
+func (sm *StateSyncManager) Stop() {
+	sm.cancel()
+}
+
+func (sm *StateSyncManager) Wait() {
+	sm.wg.Wait()
+}
+
+func (sm *StateSyncManager) mainLoop() {
+	defer sm.wg.Done()
+
+	for {
+		select {
+		case <-sm.ctx.Done():
+			return
+		case msg := <-sm.incoming:
+			sm.processMessage(msg)
+		}
+	}
+}
+
+// GetChannelIDs implements the p2p.MessageHandler interface.
+func (sm *StateSyncManager) GetChannelIDs() []common.ChannelIDEnum {
+	return []common.ChannelIDEnum{
+		common.ChannelIDStateSync,
+	}
+}
+
+// ParseMessage implements p2p.MessageHandler interface.
+func (sm *StateSyncManager) ParseMessage(peerID string, channelID common.ChannelIDEnum,
+	rawMessageBytes common.Bytes) (p2ptypes.Message, error) {
+	message := p2ptypes.Message{
+		PeerID:    peerID,
+		ChannelID: channelID,
+	}
+	data, err := decodeMessage(rawMessageBytes)
+	message.Content = data
+	return message, err
+}
+
+// EncodeMessage implements p2p.MessageHandler interface.
+func (sm *StateSyncManager) EncodeMessage(message interface{}) (common.Bytes, error) {
+	return encodeMessage(message)
+}
+
+// HandleMessage implements p2p.MessageHandler interface.
+func (sm *StateSyncManager) HandleMessage(msg p2ptypes.Message) (err error) {
+	sm.incoming <- msg
+	return
+}
+
+func (sm *StateSyncManager) processMessage(message p2ptypes.Message) {
+	switch content := message.Content.(type) {
+	case dispatcher.DataRequest:
+		sm.handleDataRequest(message.PeerID, &content)
+	case dispatcher.DataResponse:
+		sm.handleDataResponse(message.PeerID, &content)
+	default:
+		sm.logger.WithFields(log.Fields{
+			"message": message,
+		}).Warn("Received unknown message")
+	}
+}
+
+func (sm *StateSyncManager) handleDataRequest(peerID string, data *dispatcher.DataRequest) {
+	payload := &StateSyncPayload{}
+	if len(data.Entries) == 0 {
+		checkpoint := sm.getCheckpoint()
+		if checkpoint == nil {
+			return
+		}
+		payload.Checkpoints = []core.StateSyncCheckpoint{*checkpoint}
+	} else {
+		entries := data.Entries
+		if len(entries) > MaxStateSyncNodesPerRequest {
+			entries = entries[:MaxStateSyncNodesPerRequest]
+		}
+		db := sm.getDB()
+		for _, entry := range entries {
+			hash := common.HexToHash(entry)
+			node, err := db.Get(hash.Bytes())
+			if err != nil {
+				continue
+			}
+			payload.Nodes = append(payload.Nodes, node)
+		}
+	}
+
+	raw, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"error": err,
+		}).Error("Failed to encode state sync payload")
+		return
+	}
+	sm.dispatcher.SendData([]string{peerID}, dispatcher.DataResponse{
+		ChannelID: common.ChannelIDStateSync,
+		Payload:   raw,
+	})
+}
+
+func (sm *StateSyncManager) getDB() database.Database {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.db
+}
+
+// getCheckpoint returns the cached checkpoint. Once the latest finalized block passes a new checkpoint
+// height, the checkpoint is rebuilt for it in the background, so the requests from the peers neither block
+// the message loop nor trigger more than one rebuild per checkpoint interval. A failed build is retried
+// only after the latest finalized block changes.
+func (sm *StateSyncManager) getCheckpoint() *core.StateSyncCheckpoint {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.chain == nil || sm.consensus == nil || sm.building {
+		return sm.checkpoint
+	}
+	lfb := sm.consensus.GetLastFinalizedBlock()
+	if lfb == nil || lfb.Hash() == sm.attemptedHash {
+		return sm.checkpoint
+	}
+	if sm.checkpoint != nil && common.LastCheckPointHeight(lfb.Height) <= common.LastCheckPointHeight(sm.checkpointHeight) {
+		return sm.checkpoint
+	}
+
+	sm.building = true
+	sm.attemptedHash = lfb.Hash()
+	sm.wg.Add(1)
+	go sm.buildCheckpoint(sm.db, sm.chain, lfb)
+	return sm.checkpoint
+}
+
+// buildCheckpoint builds the checkpoint for the given finalized block, and caches it if succeeded.
+func (sm *StateSyncManager) buildCheckpoint(db database.Database, chain *blockchain.Chain, lfb *core.ExtendedBlock) {
+	defer sm.wg.Done()
+
+	checkpoint, err := snapshot.BuildStateSyncCheckpoint(db, chain, lfb)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.building = false
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"block":  lfb.Hash().Hex(),
+			"height": lfb.Height,
+			"error":  err,
+		}).Debug("Failed to build state sync checkpoint")
+		return
+	}
+	sm.checkpoint = checkpoint
+	sm.checkpointHeight = lfb.Height
+}
+
+func (sm *StateSyncManager) handleDataResponse(peerID string, data *dispatcher.DataResponse) {
+	payload := &StateSyncPayload{}
+	if err := rlp.DecodeBytes(data.Payload, payload); err != nil {
+		sm.logger.WithFields(log.Fields{
+			"error":  err,
+			"peerID": peerID,
+		}).Debug("Failed to decode state sync payload")
+		return
+	}
+	select {
+	case sm.responses <- stateSyncResponse{peerID: peerID, payload: payload}:
+	default:
+		// Not syncing, or the responses are not consumed fast enough
+	}
+}
+
+// SyncState syncs the state of a checkpoint offered by the peers into the database, and returns the header
+// of the checkpoint block to be used as the root of the chain. The checkpoints are verified against the
+// validator set changes proven from the genesis block, and the highest valid one is synced. It blocks
+// until the state is synced or the manager is stopped.
+func (sm *StateSyncManager) SyncState() (*core.BlockHeader, error) {
+	tried := make(map[common.Hash]bool)
+	for {
+		for _, checkpoint := range sm.requestCheckpoints() {
+			blockHash := checkpoint.Metadata.TailTrio.Second.Header.Hash()
+			if tried[blockHash] {
+				continue
+			}
+			tried[blockHash] = true
+
+			sm.logger.WithFields(log.Fields{
+				"block":  blockHash.Hex(),
+				"height": checkpoint.Metadata.TailTrio.Second.Header.Height,
+			}).Info("Syncing state for checkpoint")
+			sm.stalls = 0
+			header, err := snapshot.ImportStateSyncCheckpoint(checkpoint, sm.getDB(), sm.fetchNodes)
+			if err == nil {
+				return header, nil
+			}
+			if err == errStateSyncStopped {
+				return nil, err
+			}
+			sm.logger.WithFields(log.Fields{
+				"block": blockHash.Hex(),
+				"error": err,
+			}).Warn("Failed to sync state for checkpoint")
+			if err == errStateSyncStalled {
+				// The checkpoint is likely pruned by the peers, try a newer one while keeping the synced nodes
+				tried = make(map[common.Hash]bool)
+				break
+			}
+		}
+
+		select {
+		case <-sm.ctx.Done():
+			return nil, errStateSyncStopped
+		case <-time.After(StateSyncRequestTimeout):
+		}
+	}
+}
+
+// requestCheckpoints asks the neighbors for their checkpoints, and returns the received ones ordered by
+// height, highest first.
+func (sm *StateSyncManager) requestCheckpoints() []*core.StateSyncCheckpoint {
+	sm.drainResponses()
+	sm.dispatcher.GetData([]string{}, dispatcher.DataRequest{
+		ChannelID: common.ChannelIDStateSync,
+	})
+
+	checkpoints := []*core.StateSyncCheckpoint{}
+	timeout := time.After(StateSyncRequestTimeout)
+	for {
+		select {
+		case <-sm.ctx.Done():
+			return checkpoints
+		case <-timeout:
+			sort.Slice(checkpoints, func(i, j int) bool {
+				return checkpoints[i].Metadata.TailTrio.Second.Header.Height > checkpoints[j].Metadata.TailTrio.Second.Header.Height
+			})
+			return checkpoints
+		case response := <-sm.responses:
+			for i := range response.payload.Checkpoints {
+				checkpoint := &response.payload.Checkpoints[i]
+				if checkpoint.Metadata.TailTrio.Second.Header == nil {
+					continue
+				}
+				checkpoints = append(checkpoints, checkpoint)
+			}
+		}
+	}
+}
+
+// fetchNodes implements the snapshot.NodeFetcher. The hashes are split among the peers, and the nodes
+// returned within the timeout are collected. The nodes are verified by the caller.
+func (sm *StateSyncManager) fetchNodes(hashes []common.Hash) (map[common.Hash]common.Bytes, error) {
+	nodes := make(map[common.Hash]common.Bytes)
+	wanted := make(map[common.Hash]bool)
+	for _, hash := range hashes {
+		wanted[hash] = true
+	}
+
+	peers := sm.dispatcher.Peers(true)
+	if len(peers) == 0 {
+		select {
+		case <-sm.ctx.Done():
+			return nil, errStateSyncStopped
+		case <-time.After(StateSyncRequestTimeout):
+			return nodes, nil
+		}
+	}
+
+	sm.drainResponses()
+	requested := make(map[string]int)
+	for start := 0; start < len(hashes); start += MaxStateSyncNodesPerRequest {
+		end := start + MaxStateSyncNodesPerRequest
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		entries := []string{}
+		for _, hash := range hashes[start:end] {
+			entries = append(entries, hash.Hex())
+		}
+		sm.peerIdx = (sm.peerIdx + 1) % len(peers)
+		peerID := peers[sm.peerIdx]
+		requested[peerID]++
+		sm.dispatcher.GetData([]string{peerID}, dispatcher.DataRequest{
+			ChannelID: common.ChannelIDStateSync,
+			Entries:   entries,
+		})
+	}
+
+	timeout := time.After(StateSyncRequestTimeout)
+	for pending := len(hashes); pending > 0 && len(requested) > 0; {
+		select {
+		case <-sm.ctx.Done():
+			return nil, errStateSyncStopped
+		case <-timeout:
+			pending = 0
+		case response := <-sm.responses:
+			for _, node := range response.payload.Nodes {
+				hash := crypto.Keccak256Hash(node)
+				if wanted[hash] {
+					delete(wanted, hash)
+					nodes[hash] = node
+					pending--
+				}
+			}
+			if requested[response.peerID] > 1 {
+				requested[response.peerID]--
+			} else {
+				delete(requested, response.peerID)
+			}
+		}
+	}
+
+	if len(nodes) == 0 {
+		sm.stalls++
+		if sm.stalls >= MaxStateSyncStalls {
+			return nil, errStateSyncStalled
+		}
+	} else {
+		sm.stalls = 0
+	}
+	return nodes, nil
+}
+
+func (sm *StateSyncManager) drainResponses() {
+	for {
+		select {
+		case <-sm.responses:
+		default:
+			return
+		}
+	}
+}
@@ -11,10 +11,12 @@ import (
 	"github.com/spf13/viper"
 	"github.com/dnerochain/dnero/blockchain"
 	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/metrics"
 	"github.com/dnerochain/dnero/common/util"
 	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/dispatcher"
 	"github.com/dnerochain/dnero/p2p"
+	"github.com/dnerochain/dnero/p2p/scoring"
 	p2ptypes "github.com/dnerochain/dnero/p2p/types"
 	"github.com/dnerochain/dnero/p2pl"
 	rp "github.com/dnerochain/dnero/report"
@@ -59,6 +61,13 @@ type SyncManager struct {
 	logger *log.Entry
 
 	voteCache *lru.Cache // Cache for votes
+
+	txPool        TxPool
+	compactBlocks map[common.Hash]*partialBlock // the compact blocks waiting for the missing transactions
+
+	compactReconstructedCounter metrics.Counter // number of the compact blocks reconstructed
+	compactFallbackCounter      metrics.Counter // number of the compact blocks downloaded in full instead
+	compactTxsRequestedCounter  metrics.Counter // number of the transactions requested for the compact blocks
 }
 
 func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkOld p2p.Network, network p2pl.Network, disp *dispatcher.Dispatcher, consumer MessageConsumer, reporter *rp.Reporter) *SyncManager {
@@ -72,6 +81,12 @@ func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkO
 		incoming:   make(chan p2ptypes.Message, viper.GetInt(common.CfgSyncMessageQueueSize)),
 
 		voteCache: voteCache,
+
+		compactBlocks: make(map[common.Hash]*partialBlock),
+
+		compactReconstructedCounter: metrics.GetOrRegisterCounter("sync/compact/reconstructed", nil),
+		compactFallbackCounter:      metrics.GetOrRegisterCounter("sync/compact/fallbacks", nil),
+		compactTxsRequestedCounter:  metrics.GetOrRegisterCounter("sync/compact/txs/requested", nil),
 	}
 	sm.requestMgr = NewRequestManager(sm, reporter)
 
@@ -95,6 +110,12 @@ func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkO
 	return sm
 }
 
+// SetTxPool sets the pool the compact blocks are reconstructed from. The compact blocks are neither
+// requested nor served if no pool is set.
+func (sm *SyncManager) SetTxPool(pool TxPool) {
+	sm.txPool = pool
+}
+
 func (sm *SyncManager) Start(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
 	sm.ctx = c
@@ -140,6 +161,8 @@ func (sm *SyncManager) GetChannelIDs() []common.ChannelIDEnum {
 		common.ChannelIDSentry,
 		common.ChannelIDEliteEdgeNodeVote,
 		common.ChannelIDAggregatedEliteEdgeNodeVotes,
+		common.ChannelIDCompactBlock,
+		common.ChannelIDBlockTxs,
 	}
 }
 
@@ -454,6 +477,12 @@ func (m *SyncManager) handleDataRequest(peerID string, data *dispatcher.DataRequ
 			}).Debug("Sending requested block")
 			m.dispatcher.SendData([]string{peerID}, sendData)
 		}
+	case common.ChannelIDCompactBlock:
+		for _, hashStr := range data.Entries {
+			m.sendCompactBlock(peerID, hashStr)
+		}
+	case common.ChannelIDBlockTxs:
+		m.sendBlockTxs(peerID, data.Entries)
 	default:
 		m.logger.WithFields(log.Fields{
 			"channelID": data.ChannelID,
@@ -545,6 +574,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 					"error":     err,
 					"peerID":    peerID,
 				}).Warn("Failed to decode DataResponse payload")
+				m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
 				return
 			}
 			for _, block = range blocks.BlockArray {
@@ -554,6 +584,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 					"block.Height": block.Height,
 					"peer":         peerID,
 				}).Debug("Received block")
+				m.dispatcher.RecordMessageSource(block.Hash(), peerID)
 				m.handleBlock(block)
 				if block.Height > maxReceivedHeight {
 					maxReceivedHeight = block.Height
@@ -566,6 +597,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 				"block.Height": block.Height,
 				"peer":         peerID,
 			}).Debug("Received block")
+			m.dispatcher.RecordMessageSource(block.Hash(), peerID)
 			m.handleBlock(block)
 			maxReceivedHeight = block.Height
 		}
@@ -579,6 +611,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 				"error":     err,
 				"peerID":    peerID,
 			}).Warn("Failed to decode DataResponse payload")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
 			return
 		}
 		m.logger.WithFields(log.Fields{
@@ -587,6 +620,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 			"vote.Epoch": vote.Epoch,
 			"peer":       peerID,
 		}).Debug("Received vote")
+		m.dispatcher.RecordMessageSource(vote.Hash(), peerID)
 		m.handleVote(vote)
 	case common.ChannelIDProposal:
 		proposal := &core.Proposal{}
@@ -598,12 +632,14 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 				"error":     err,
 				"peerID":    peerID,
 			}).Warn("Failed to decode DataResponse payload")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
 			return
 		}
 		m.logger.WithFields(log.Fields{
 			"proposal": proposal,
 			"peer":     peerID,
 		}).Debug("Received proposal")
+		m.recordProposalSource(proposal, peerID)
 		m.handleProposal(proposal)
 	case common.ChannelIDSentry:
 		vote := &core.AggregatedVotes{}
@@ -615,6 +651,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 				"error":     err,
 				"peerID":    peerID,
 			}).Warn("Failed to decode DataResponse payload")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
 			return
 		}
 		m.logger.WithFields(log.Fields{
@@ -634,6 +671,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 				"error":     err,
 				"peerID":    peerID,
 			}).Warn("Failed to decode DataResponse payload")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
 			return
 		}
 		// m.logger.WithFields(log.Fields{
@@ -651,6 +689,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 				"error":     err,
 				"peerID":    peerID,
 			}).Warn("Failed to decode DataResponse payload")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
 			return
 		}
 		m.logger.WithFields(log.Fields{
@@ -669,6 +708,7 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 				"error":     err,
 				"peerID":    peerID,
 			}).Debug("Failed to decode HeaderResponse payload")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
 			return
 		}
 		for _, header := range headers.HeaderArray {
@@ -680,6 +720,35 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 			}).Debug("Received header")
 			m.handleHeader(header, []string{peerID})
 		}
+	case common.ChannelIDCompactBlock:
+		cb := &CompactBlock{}
+		err := rlp.DecodeBytes(data.Payload, cb)
+		if err == nil {
+			err = cb.Validate()
+		}
+		if err != nil {
+			m.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"error":     err,
+				"peerID":    peerID,
+			}).Warn("Failed to decode compact block")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
+			return
+		}
+		m.handleCompactBlock(peerID, cb)
+	case common.ChannelIDBlockTxs:
+		blockTxs := &BlockTxs{}
+		err := rlp.DecodeBytes(data.Payload, blockTxs)
+		if err != nil {
+			m.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"error":     err,
+				"peerID":    peerID,
+			}).Warn("Failed to decode block transactions")
+			m.dispatcher.ReportPeer(peerID, scoring.OffenseUndecodableMessage)
+			return
+		}
+		m.handleBlockTxs(peerID, blockTxs)
 	default:
 		m.logger.WithFields(log.Fields{
 			"channelID": data.ChannelID,
@@ -687,6 +756,18 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 	}
 }
 
+// recordProposalSource records the peer the block and the votes of the proposal are received from
+func (sm *SyncManager) recordProposalSource(p *core.Proposal, peerID string) {
+	if p.Block != nil {
+		sm.dispatcher.RecordMessageSource(p.Block.Hash(), peerID)
+	}
+	if p.Votes != nil {
+		for _, vote := range p.Votes.Votes() {
+			sm.dispatcher.RecordMessageSource(vote.Hash(), peerID)
+		}
+	}
+}
+
 func (sm *SyncManager) handleProposal(p *core.Proposal) {
 	if p.Votes != nil {
 		for _, vote := range p.Votes.Votes() {
@@ -744,6 +825,7 @@ func (sm *SyncManager) handleBlock(block *core.Block) {
 			"block hash":   block.Hash().String(),
 			"block height": block.Height,
 		}).Debug("chain ID is invalid")
+		sm.dispatcher.ReportMessageSource(block.Hash(), scoring.OffenseInvalidBlock)
 		return
 	}
 
@@ -21,10 +21,12 @@ import (
 	"github.com/dnerochain/dnero/blockchain"
 	"github.com/dnerochain/dnero/p2p/simulation"
 	"github.com/dnerochain/dnero/p2p/types"
+	p2plmsg "github.com/dnerochain/dnero/p2pl/messenger"
 )
 
 type MockMessageConsumer struct {
 	Received []interface{}
+	chain    *blockchain.Chain // if set, the received blocks are marked valid like the consensus engine does
 }
 
 func NewMockMessageConsumer() *MockMessageConsumer {
@@ -35,6 +37,9 @@ func NewMockMessageConsumer() *MockMessageConsumer {
 
 func (m *MockMessageConsumer) AddMessage(msg interface{}) {
 	m.Received = append(m.Received, msg)
+	if block, ok := msg.(*core.Block); ok && m.chain != nil {
+		m.chain.MarkBlockValid(block.Hash())
+	}
 }
 
 type MockMsgHandler struct {
@@ -42,15 +47,19 @@ type MockMsgHandler struct {
 }
 
 func (m *MockMsgHandler) GetChannelIDs() []common.ChannelIDEnum {
-	return []common.ChannelIDEnum{}
+	return []common.ChannelIDEnum{
+		common.ChannelIDHeader,
+		common.ChannelIDBlock,
+	}
 }
 
 func (m *MockMsgHandler) ParseMessage(peerID string, channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (types.Message, error) {
-	return types.Message{}, nil
+	data, err := decodeMessage(rawMessageBytes)
+	return types.Message{PeerID: peerID, ChannelID: channelID, Content: data}, err
 }
 
 func (m *MockMsgHandler) EncodeMessage(message interface{}) (common.Bytes, error) {
-	return nil, nil
+	return encodeMessage(message)
 }
 
 func (m *MockMsgHandler) HandleMessage(message types.Message) error {
@@ -85,11 +94,13 @@ func TestSyncManager(t *testing.T) {
 	privKey, _, _ := crypto.GenerateKeyPair()
 	valMgr := consensus.NewFixedValidatorManager()
 	db := kvstore.NewKVStore(backend.NewMemDatabase())
-	dispatch := dispatcher.NewDispatcher(net1, nil)
+	var p2plnet *p2plmsg.Messenger
+	dispatch := dispatcher.NewDispatcher(net1, p2plnet)
 	consensus := consensus.NewConsensusEngine(privKey, db, initChain, dispatch, valMgr)
 	mockMsgConsumer := NewMockMessageConsumer()
+	mockMsgConsumer.chain = initChain
 
-	sm := NewSyncManager(initChain, consensus, net1, nil, dispatch, mockMsgConsumer)
+	sm := NewSyncManager(initChain, consensus, net1, p2plnet, dispatch, mockMsgConsumer, nil)
 	sm.Start(context.Background())
 
 	// Send block A4 to node1
@@ -100,20 +111,20 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Payload:   payload,
 		},
-	})
+	}, false)
 
-	// node1 should broadcast InventoryResponse
+	// node1 should broadcast the header, and then InventoryResponse
 	var res interface{}
 	res = <-mockMsgHandler.C
-	msg1, ok := res.(dispatcher.InventoryResponse)
+	msg11, ok := res.(dispatcher.DataResponse)
 	assert.True(ok)
-	assert.Equal(common.ChannelIDBlock, msg1.ChannelID)
-	assert.Equal(core.GetTestBlock("A4").Hash().Hex(), msg1.Entries[0])
+	assert.Equal(common.ChannelIDHeader, msg11.ChannelID)
 
 	res = <-mockMsgHandler.C
-	msg11, ok := res.(dispatcher.DataResponse)
+	msg1, ok := res.(dispatcher.InventoryResponse)
 	assert.True(ok)
-	assert.Equal(common.ChannelIDHeader, msg11.ChannelID)
+	assert.Equal(common.ChannelIDBlock, msg1.ChannelID)
+	assert.Equal(core.GetTestBlock("A4").Hash().Hex(), msg1.Entries[0])
 
 	res = <-mockMsgHandler.C
 	msg2, ok := res.(dispatcher.InventoryRequest)
@@ -135,7 +146,7 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Entries:   entries,
 		},
-	})
+	}, false)
 
 	// node2 replies with A3 first
 	payload, _ = rlp.EncodeToBytes(core.CreateTestBlock("A3", "A2"))
@@ -145,7 +156,7 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Payload:   payload,
 		},
-	})
+	}, false)
 
 	time.Sleep(1 * time.Second)
 
@@ -157,9 +168,10 @@ func TestSyncManager(t *testing.T) {
 			ChannelID: common.ChannelIDBlock,
 			Payload:   payload,
 		},
-	})
+	}, false)
 
-	time.Sleep(1 * time.Second)
+	// The ready blocks are passed down one height per tick of the request manager
+	time.Sleep(4 * time.Second)
 
 	sm.Stop()
 	sm.Wait()
@@ -245,12 +257,13 @@ func TestCollectBlocks(t *testing.T) {
 	net2.RegisterMessageHandler(mockMsgHandler)
 	simnet.Start(context.Background())
 
-	dispatch := dispatcher.NewDispatcher(net1, nil)
+	var p2plnet *p2plmsg.Messenger
+	dispatch := dispatcher.NewDispatcher(net1, p2plnet)
 	a3, _ := initChain.FindBlock(core.GetTestBlock("A3").Hash())
 	consensus := NewMockConsensus(initChain, a3)
 	mockMsgConsumer := NewMockMessageConsumer()
 
-	sm := NewSyncManager(initChain, consensus, net1, nil, dispatch, mockMsgConsumer)
+	sm := NewSyncManager(initChain, consensus, net1, p2plnet, dispatch, mockMsgConsumer, nil)
 
 	blocks := sm.collectBlocks(core.GetTestBlock("A1").Hash(), core.GetTestBlock("A5").Hash())
 	// Expected blocks: [A1, A2, A3, A4, D4, A5, A3]
@@ -0,0 +1,75 @@
+package node
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/metrics"
+	"github.com/dnerochain/dnero/common/metrics/prometheus"
+)
+
+// MetricsServer serves the collected metrics at /metrics in the Prometheus text format
+type MetricsServer struct {
+	server *http.Server
+
+	// Life cycle
+	wg     *sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewMetricsServer creates a new instance of MetricsServer
+func NewMetricsServer() *MetricsServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+
+	return &MetricsServer{
+		server: &http.Server{
+			Handler: mux,
+		},
+		wg: &sync.WaitGroup{},
+	}
+}
+
+// Start creates the main goroutine
+func (ms *MetricsServer) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	ms.ctx = c
+	ms.cancel = cancel
+
+	address := viper.GetString(common.CfgMetricsAddress)
+	port := viper.GetString(common.CfgMetricsPort)
+	l, err := net.Listen("tcp", address+":"+port)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("Failed to create the metrics listener")
+	}
+	log.WithFields(log.Fields{"address": address, "port": port}).Info("Metrics server started")
+
+	ms.wg.Add(1)
+	go func() {
+		defer ms.wg.Done()
+		ms.server.Serve(l)
+	}()
+
+	ms.wg.Add(1)
+	go func() {
+		defer ms.wg.Done()
+		<-ms.ctx.Done()
+		ms.server.Close()
+	}()
+}
+
+// Stop notifies all goroutines to stop without blocking
+func (ms *MetricsServer) Stop() {
+	ms.cancel()
+}
+
+// Wait blocks until all goroutines stop
+func (ms *MetricsServer) Wait() {
+	ms.wg.Wait()
+}
@@ -13,10 +13,14 @@ import (
 	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/crypto"
 	dp "github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/eventbus"
+	"github.com/dnerochain/dnero/evidence"
 	ld "github.com/dnerochain/dnero/ledger"
 	mp "github.com/dnerochain/dnero/mempool"
 	"github.com/dnerochain/dnero/netsync"
 	"github.com/dnerochain/dnero/p2p"
+	"github.com/dnerochain/dnero/p2p/scoring"
+	p2ptypes "github.com/dnerochain/dnero/p2p/types"
 	"github.com/dnerochain/dnero/p2pl"
 	rp "github.com/dnerochain/dnero/report"
 	"github.com/dnerochain/dnero/rpc"
@@ -33,10 +37,13 @@ type Node struct {
 	Consensus        *consensus.ConsensusEngine
 	ValidatorManager core.ValidatorManager
 	SyncManager      *netsync.SyncManager
+	StateSyncManager *netsync.StateSyncManager
 	Dispatcher       *dp.Dispatcher
 	Ledger           core.Ledger
 	Mempool          *mp.Mempool
+	EventBus         *eventbus.EventBus
 	RPC              *rpc.DneroRPCServer
+	Metrics          *MetricsServer
 	reporter         *rp.Reporter
 
 	// Life cycle
@@ -58,6 +65,15 @@ type Params struct {
 	SnapshotPath        string
 	ChainImportDirPath  string
 	ChainCorrectionPath string
+
+	// Set if the root state is synced from the peers instead of imported from the snapshot. The state
+	// sync starts the dispatcher before the node, and the node reuses it with the state sync manager.
+	StateSynced      bool
+	Dispatcher       *dp.Dispatcher
+	StateSyncManager *netsync.StateSyncManager
+
+	// The scorer the misbehaving peers are reported to, shared with the networks
+	PeerScorer *scoring.Scorer
 }
 
 func NewNode(params *Params) *Node {
@@ -66,29 +82,54 @@ func NewNode(params *Params) *Node {
 	params.RollingDB.SetChain(chain)
 
 	validatorManager := consensus.NewRotatingValidatorManager()
-	dispatcher := dp.NewDispatcher(params.NetworkOld, params.Network)
+	dispatcher := params.Dispatcher
+	if dispatcher == nil {
+		dispatcher = dp.NewDispatcher(params.NetworkOld, params.Network)
+	}
+	if params.PeerScorer != nil {
+		dispatcher.SetPeerScorer(params.PeerScorer)
+	}
 	consensus := consensus.NewConsensusEngine(params.PrivateKey, store, chain, dispatcher, validatorManager)
 	reporter := rp.NewReporter(dispatcher, consensus, chain)
 
 	// TODO: check if this is a sentry node
 	syncMgr := netsync.NewSyncManager(chain, consensus, params.NetworkOld, params.Network, dispatcher, consensus, reporter)
+	stateSyncMgr := params.StateSyncManager
+	if stateSyncMgr == nil {
+		stateSyncMgr = netsync.NewStateSyncManager(params.NetworkOld, params.Network, dispatcher, params.DB)
+	}
+	stateSyncMgr.SetChain(chain, consensus, params.RollingDB)
 	mempool := mp.CreateMempool(dispatcher, consensus)
 	ledger := ld.NewLedger(params.ChainID, params.RollingDB, params.RollingDB, chain, consensus, validatorManager, mempool)
 
 	validatorManager.SetConsensusEngine(consensus)
 	consensus.SetLedger(ledger)
 	mempool.SetLedger(ledger)
+	syncMgr.SetTxPool(mempool)
+
+	eventBus := eventbus.NewEventBus()
+	consensus.SetEventBus(eventBus)
+	mempool.SetEventBus(eventBus)
+	ledger.SetEventBus(eventBus)
+	dispatcher.SetEventBus(eventBus)
 	txMsgHandler := mp.CreateMempoolMessageHandler(mempool)
 
+	evidencePool := evidence.NewEvidencePool(chain, consensus, dispatcher)
+	consensus.SetEvidencePool(evidencePool)
+	ledger.SetEvidencePool(evidencePool)
+	evidenceMsgHandler := evidence.CreateEvidenceMessageHandler(evidencePool)
+
 	if !reflect.ValueOf(params.Network).IsNil() {
 		params.Network.RegisterMessageHandler(txMsgHandler)
+		params.Network.RegisterMessageHandler(evidenceMsgHandler)
 	}
 	if !reflect.ValueOf(params.NetworkOld).IsNil() {
 		params.NetworkOld.RegisterMessageHandler(txMsgHandler)
+		params.NetworkOld.RegisterMessageHandler(evidenceMsgHandler)
 	}
 
 	currentHeight := consensus.GetLastFinalizedBlock().Height
-	if currentHeight <= params.Root.Height {
+	if currentHeight <= params.Root.Height && !params.StateSynced {
 		snapshotPath := params.SnapshotPath
 		chainImportDirPath := params.ChainImportDirPath
 		chainCorrectionPath := params.ChainCorrectionPath
@@ -112,18 +153,60 @@ func NewNode(params *Params) *Node {
 		Consensus:        consensus,
 		ValidatorManager: validatorManager,
 		SyncManager:      syncMgr,
+		StateSyncManager: stateSyncMgr,
 		Dispatcher:       dispatcher,
 		Ledger:           ledger,
 		Mempool:          mempool,
+		EventBus:         eventBus,
 		reporter:         reporter,
 	}
 
+	dispatcher.SetHandshakeInfoProvider(newHandshakeInfoProvider(params.PrivateKey, consensus, validatorManager))
+
 	if viper.GetBool(common.CfgRPCEnabled) {
-		node.RPC = rpc.NewDneroRPCServer(mempool, ledger, dispatcher, chain, consensus)
+		node.RPC = rpc.NewDneroRPCServer(mempool, ledger, dispatcher, chain, consensus, eventBus)
+	}
+	if viper.GetBool(common.CfgMetricsEnabled) {
+		node.Metrics = NewMetricsServer()
 	}
 	return node
 }
 
+// newHandshakeInfoProvider returns the provider of the local HandshakeInfo, which reports the latest
+// finalized height, and whether the node is in the validator set
+func newHandshakeInfoProvider(privKey *crypto.PrivateKey, consensus *consensus.ConsensusEngine,
+	validatorManager core.ValidatorManager) p2ptypes.HandshakeInfoProvider {
+	var mtx sync.Mutex
+	var roleBlockHash common.Hash
+	role := p2ptypes.NodeRoleSentry
+
+	return func() *p2ptypes.HandshakeInfo {
+		info := p2ptypes.CreateLocalHandshakeInfo()
+		if info.ChainID == core.MainnetChainID {
+			info.GenesisHash = common.HexToHash(core.MainnetGenesisBlockHash)
+		}
+		lfb := consensus.GetLastFinalizedBlock()
+		info.FinalizedHeight = lfb.Height
+		if info.Role == p2ptypes.NodeRoleEdge {
+			return info
+		}
+
+		// The validator set only changes with the finalized block
+		mtx.Lock()
+		defer mtx.Unlock()
+		if lfb.Hash() != roleBlockHash {
+			roleBlockHash = lfb.Hash()
+			role = p2ptypes.NodeRoleSentry
+			valSet := validatorManager.GetNextValidatorSet(roleBlockHash)
+			if _, err := valSet.GetValidator(privKey.PublicKey().Address()); err == nil {
+				role = p2ptypes.NodeRoleValidator
+			}
+		}
+		info.Role = role
+		return info
+	}
+}
+
 // Start starts sub components and kick off the main loop.
 func (n *Node) Start(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
@@ -132,6 +215,7 @@ func (n *Node) Start(ctx context.Context) {
 
 	n.Consensus.Start(n.ctx)
 	n.SyncManager.Start(n.ctx)
+	n.StateSyncManager.Start(n.ctx)
 	n.Dispatcher.Start(n.ctx)
 	n.Mempool.Start(n.ctx)
 	n.reporter.Start(n.ctx)
@@ -139,6 +223,9 @@ func (n *Node) Start(ctx context.Context) {
 	if viper.GetBool(common.CfgRPCEnabled) {
 		n.RPC.Start(n.ctx)
 	}
+	if n.Metrics != nil {
+		n.Metrics.Start(n.ctx)
+	}
 }
 
 // Stop notifies all sub components to stop without blocking.
@@ -150,7 +237,11 @@ func (n *Node) Stop() {
 func (n *Node) Wait() {
 	n.Consensus.Wait()
 	n.SyncManager.Wait()
+	n.StateSyncManager.Wait()
 	if n.RPC != nil {
 		n.RPC.Wait()
 	}
+	if n.Metrics != nil {
+		n.Metrics.Wait()
+	}
 }
@@ -1,9 +1,41 @@
 package connection
 
 import (
+	"strconv"
+
 	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/metrics"
 )
 
+// channelNames are the names of the channels in the configurations and the metrics
+var channelNames = map[common.ChannelIDEnum]string{
+	common.ChannelIDCheckpoint:                   "checkpoint",
+	common.ChannelIDHeader:                       "header",
+	common.ChannelIDBlock:                        "block",
+	common.ChannelIDProposal:                     "proposal",
+	common.ChannelIDCC:                           "cc",
+	common.ChannelIDVote:                         "vote",
+	common.ChannelIDTransaction:                  "transaction",
+	common.ChannelIDPeerDiscovery:                "peer_discovery",
+	common.ChannelIDPing:                         "ping",
+	common.ChannelIDSentry:                       "sentry",
+	common.ChannelIDNATMapping:                   "nat_mapping",
+	common.ChannelIDEliteEdgeNodeVote:            "een_vote",
+	common.ChannelIDAggregatedEliteEdgeNodeVotes: "aggregated_een_votes",
+	common.ChannelIDStateSync:                    "state_sync",
+	common.ChannelIDEvidence:                     "evidence",
+	common.ChannelIDCompactBlock:                 "compact_block",
+	common.ChannelIDBlockTxs:                     "block_txs",
+}
+
+// getChannelName returns the name of the channel
+func getChannelName(channelID common.ChannelIDEnum) string {
+	if name, ok := channelNames[channelID]; ok {
+		return name
+	}
+	return strconv.Itoa(int(channelID))
+}
+
 //
 // Channel models a bi-directional channel for messsaging between two peers
 //
@@ -13,6 +45,11 @@ type Channel struct {
 	sendBuf SendBuffer
 	recvBuf RecvBuffer
 
+	sendLimiter RateLimiter
+	recvLimiter RateLimiter
+
+	metrics *channelMetrics
+
 	config ChannelConfig
 }
 
@@ -20,12 +57,40 @@ type Channel struct {
 // ChannelConfig specifies the configuration of a Channel
 //
 type ChannelConfig struct {
-	priority uint
+	Priority uint  // the max number of packets the channel sends in each round, at least 1
+	SendRate int64 // the max number of bytes sent per second, zero for no limit
+	RecvRate int64 // the max number of bytes received per second, zero for no limit
+}
+
+//
+// channelMetrics tracks the traffic of a channel, aggregated over all the connections
+//
+type channelMetrics struct {
+	queueDepth    metrics.Histogram // number of the messages queued or waiting to be queued, sampled at each enqueue
+	enqueueFailed metrics.Counter   // number of the messages failed to be queued
+	bytesSent     metrics.Counter
+	bytesReceived metrics.Counter
+	dropped       metrics.Counter // number of the received messages dropped by the rate limit
+}
+
+func getChannelMetrics(channelID common.ChannelIDEnum) *channelMetrics {
+	prefix := "p2p/channel/" + getChannelName(channelID) + "/"
+	return &channelMetrics{
+		queueDepth:    metrics.GetOrRegisterHistogram(prefix+"queue", nil, metrics.NewExpDecaySample(1028, 0.015)),
+		enqueueFailed: metrics.GetOrRegisterCounter(prefix+"enqueue/failed", nil),
+		bytesSent:     metrics.GetOrRegisterCounter(prefix+"bytes/sent", nil),
+		bytesReceived: metrics.GetOrRegisterCounter(prefix+"bytes/received", nil),
+		dropped:       metrics.GetOrRegisterCounter(prefix+"dropped", nil),
+	}
 }
 
 // createDefaultChannel creates a channel with default configs
 func createDefaultChannel(channelID common.ChannelIDEnum) Channel {
-	chCfg := getDefaultChannelConfig()
+	return createChannelWithConfig(channelID, getDefaultChannelConfig())
+}
+
+// createChannelWithConfig creates a channel with the given channel config and the default buffer configs
+func createChannelWithConfig(channelID common.ChannelIDEnum, chCfg ChannelConfig) Channel {
 	sbCfg := getDefaultSendBufferConfig()
 	rbCfg := getDefaultRecvBufferConfig()
 
@@ -37,18 +102,26 @@ func createDefaultChannel(channelID common.ChannelIDEnum) Channel {
 func createChannel(channelID common.ChannelIDEnum, channelConf ChannelConfig, sbConf SendBufferConfig, rbConf RecvBufferConfig) Channel {
 	sendBuf := createSendBuffer(sbConf)
 	recvBuf := createRecvBuffer(rbConf)
+	if channelConf.Priority == 0 {
+		channelConf.Priority = 1
+	}
 	return Channel{
-		id:      channelID,
-		sendBuf: sendBuf,
-		recvBuf: recvBuf,
-		config:  channelConf,
+		id:          channelID,
+		sendBuf:     sendBuf,
+		recvBuf:     recvBuf,
+		sendLimiter: createRateLimiter(channelConf.SendRate),
+		recvLimiter: createRateLimiter(channelConf.RecvRate),
+		metrics:     getChannelMetrics(channelID),
+		config:      channelConf,
 	}
 }
 
 // createChannel creates the default channel config
 func getDefaultChannelConfig() ChannelConfig {
 	return ChannelConfig{
-		priority: 0,
+		Priority: 1,
+		SendRate: 0,
+		RecvRate: 0,
 	}
 }
 
@@ -57,21 +130,44 @@ func (ch *Channel) getID() common.ChannelIDEnum {
 	return ch.id
 }
 
+// getPriority returns the max number of packets the channel sends in each round
+func (ch *Channel) getPriority() uint {
+	return ch.config.Priority
+}
+
 // enqueueMessage queues the the given message into the channel
 func (ch *Channel) enqueueMessage(bytes []byte) bool {
+	ch.metrics.queueDepth.Update(int64(ch.sendBuf.getDepth()))
 	success := ch.sendBuf.insert(bytes)
+	if !success {
+		ch.metrics.enqueueFailed.Inc(1)
+	}
 	return success
 }
 
 // attemptToEnqueueMessage attempts to queue the given message into the channel (non-blocking)
 func (ch *Channel) attemptToEnqueueMessage(bytes []byte) bool {
+	ch.metrics.queueDepth.Update(int64(ch.sendBuf.getDepth()))
 	success := ch.sendBuf.attemptInsert(bytes)
+	if !success {
+		ch.metrics.enqueueFailed.Inc(1)
+	}
 	return success
 }
 
-// receivePacket receives packet and return the converted bytes
+// receivePacket receives packet and return the converted bytes. The messages received beyond the
+// rate limit of the channel are dropped, in which case nil bytes are returned.
 func (ch *Channel) receivePacket(packet *Packet) ([]byte, bool) {
+	ch.metrics.bytesReceived.Inc(int64(len(packet.Bytes)))
 	bytes, success := ch.recvBuf.receivePacket(packet)
+	if !success || bytes == nil {
+		return bytes, success
+	}
+	if !ch.recvLimiter.allow() {
+		ch.metrics.dropped.Inc(1)
+		return nil, true
+	}
+	ch.recvLimiter.consume(len(bytes))
 	return bytes, success
 }
 
@@ -86,9 +182,10 @@ func (ch *Channel) sendPacketTo(conn *Connection) (nonemptyPacket bool, numBytes
 	if err != nil {
 		return true, int(0), nil
 	}
-	numBytes = 0
+	numBytes = len(packet.Bytes) // counted towards the send rate of the connection
+	ch.sendLimiter.consume(numBytes)
+	ch.metrics.bytesSent.Inc(int64(numBytes))
 
-	// numBytes, err = writer.Write(packetBytes)
 	return true, numBytes, err
 }
 
@@ -102,3 +199,14 @@ func (ch *Channel) hasPacketToSend() bool {
 	hasPacket := !ch.sendBuf.isEmpty()
 	return hasPacket
 }
+
+// isReadyToSend returns whether there are pending data in the sendBuffer, and the rate limit
+// of the channel allows sending them at the moment
+func (ch *Channel) isReadyToSend() bool {
+	return ch.hasPacketToSend() && ch.sendLimiter.allow()
+}
+
+// getQueueSize returns the number of the messages queued in the channel
+func (ch *Channel) getQueueSize() int {
+	return ch.sendBuf.getSize()
+}
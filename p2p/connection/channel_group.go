@@ -7,7 +7,8 @@ import (
 )
 
 const (
-	channelSelectionRoundRobinStrategy = 1
+	channelSelectionRoundRobinStrategy         = 1
+	channelSelectionWeightedRoundRobinStrategy = 2
 )
 
 //
@@ -42,6 +43,8 @@ func createChannelGroup(cgConfig ChannelGroupConfig, channels []*Channel) (bool,
 	var channelSelector ChannelSelector
 	if cgConfig.selectionStrategy == channelSelectionRoundRobinStrategy {
 		channelSelector = createRoundRobinChannelSelector()
+	} else if cgConfig.selectionStrategy == channelSelectionWeightedRoundRobinStrategy {
+		channelSelector = createWeightedRoundRobinChannelSelector()
 	} else {
 		logger.Errorf("Invalid channel selection strategy")
 		return false, ChannelGroup{}
@@ -63,7 +66,7 @@ func createChannelGroup(cgConfig ChannelGroupConfig, channels []*Channel) (bool,
 
 func getDefaultChannelGroupConfig() ChannelGroupConfig {
 	return ChannelGroupConfig{
-		selectionStrategy: channelSelectionRoundRobinStrategy,
+		selectionStrategy: channelSelectionWeightedRoundRobinStrategy,
 	}
 }
 
@@ -144,7 +147,7 @@ func (cg *ChannelGroup) nextChannelToSendPacket() (success bool, channel *Channe
 			return false, nil
 		}
 		selectedChannel := (*channels)[selectedChannelIndex]
-		if !selectedChannel.hasPacketToSend() {
+		if !selectedChannel.isReadyToSend() {
 			continue
 		}
 		return true, selectedChannel
@@ -152,6 +155,26 @@ func (cg *ChannelGroup) nextChannelToSendPacket() (success bool, channel *Channe
 	return true, nil
 }
 
+// hasPacketToSend returns whether any of the channels has pending data, including the data
+// held back by the rate limits
+func (cg *ChannelGroup) hasPacketToSend() bool {
+	for _, channel := range *(cg.getAllChannels()) {
+		if channel.hasPacketToSend() {
+			return true
+		}
+	}
+	return false
+}
+
+// getQueueSize returns the number of the messages queued in all the channels
+func (cg *ChannelGroup) getQueueSize() int {
+	size := 0
+	for _, channel := range *(cg.getAllChannels()) {
+		size += channel.getQueueSize()
+	}
+	return size
+}
+
 //
 // RoundRobinChannelSelector implments the ChannelSelector interface
 // with the round robin strategy
@@ -179,3 +202,42 @@ func (rrcs *RoundRobinChannelSelector) nextSelectedChannelIndex(cg *ChannelGroup
 	}
 	return true, rrcs.lastUsedChannelIndex
 }
+
+//
+// WeightedRoundRobinChannelSelector implments the ChannelSelector interface
+// with the weighted round robin strategy. In each round, a channel keeps being
+// selected while it is ready to send, up to its priority number of times, so
+// the channels with higher priorities get a larger share of the bandwidth,
+// while the channels with lower priorities are never starved.
+//
+type WeightedRoundRobinChannelSelector struct {
+	lastUsedChannelIndex int
+	remainingQuota       uint // the number of times the last used channel can still be selected in the current round
+}
+
+func createWeightedRoundRobinChannelSelector() ChannelSelector {
+	return &WeightedRoundRobinChannelSelector{
+		lastUsedChannelIndex: -1,
+	}
+}
+
+func (wrrcs *WeightedRoundRobinChannelSelector) nextSelectedChannelIndex(cg *ChannelGroup) (success bool, index int) {
+	channels := *(cg.getAllChannels())
+	totalNumberOfChannels := len(channels)
+	if totalNumberOfChannels == 0 {
+		logger.Errorf("The channel group contains no channel")
+		return false, -1
+	}
+	if wrrcs.lastUsedChannelIndex >= 0 && wrrcs.lastUsedChannelIndex < totalNumberOfChannels &&
+		wrrcs.remainingQuota > 0 && channels[wrrcs.lastUsedChannelIndex].isReadyToSend() {
+		wrrcs.remainingQuota--
+		return true, wrrcs.lastUsedChannelIndex
+	}
+	if wrrcs.lastUsedChannelIndex < totalNumberOfChannels-1 {
+		wrrcs.lastUsedChannelIndex = wrrcs.lastUsedChannelIndex + 1
+	} else {
+		wrrcs.lastUsedChannelIndex = 0
+	}
+	wrrcs.remainingQuota = channels[wrrcs.lastUsedChannelIndex].getPriority() - 1
+	return true, wrrcs.lastUsedChannelIndex
+}
@@ -253,6 +253,44 @@ func TestRoundRobinChannelSelector2(t *testing.T) {
 	assert.Equal(&ch5, ch)
 }
 
+func TestWeightedRoundRobinChannelSelector(t *testing.T) {
+	assert := assert.New(t)
+
+	cg := newTestEmptyChannelGroup()
+	ch1 := createChannelWithConfig(common.ChannelIDVote, ChannelConfig{Priority: 3})
+	ch2 := createChannelWithConfig(common.ChannelIDTransaction, ChannelConfig{Priority: 1})
+	ch3 := createChannelWithConfig(common.ChannelIDBlock, ChannelConfig{Priority: 2})
+
+	assert.True(cg.addChannel(&ch1))
+	assert.True(cg.addChannel(&ch2))
+	assert.True(cg.addChannel(&ch3))
+
+	assert.True(ch1.enqueueMessage([]byte("vote")))
+	assert.True(ch2.enqueueMessage([]byte("tx")))
+	assert.True(ch3.enqueueMessage([]byte("block")))
+
+	// Each channel is selected up to its priority number of times in a round
+	expected := []*Channel{&ch1, &ch1, &ch1, &ch2, &ch3, &ch3, &ch1, &ch1, &ch1, &ch2}
+	for _, expectedCh := range expected {
+		success, ch := cg.nextChannelToSendPacket()
+		assert.True(success)
+		assert.Equal(expectedCh, ch)
+	}
+
+	// The channels held back by the rate limits are skipped
+	ch4 := createChannelWithConfig(common.ChannelIDHeader, ChannelConfig{Priority: 5, SendRate: 100})
+	assert.True(cg.addChannel(&ch4))
+	assert.True(ch4.enqueueMessage([]byte("header")))
+	ch4.sendLimiter.consume(1000)
+	assert.False(ch4.isReadyToSend())
+	assert.True(cg.hasPacketToSend())
+	for i := 0; i < 20; i++ {
+		_, ch := cg.nextChannelToSendPacket()
+		assert.NotEqual(&ch4, ch)
+	}
+	assert.Equal(4, cg.getQueueSize())
+}
+
 // --------------- Test Utilities --------------- //
 
 func newTestEmptyChannelGroup() ChannelGroup {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/common/timer"
 	"github.com/dnerochain/dnero/p2p/connection/flowrate"
@@ -32,8 +33,9 @@ type Connection struct {
 	bufWriter   *bufio.Writer
 	sendMonitor *flowrate.Monitor
 
-	bufReader   *bufio.Reader
-	recvMonitor *flowrate.Monitor
+	bufReader        *bufio.Reader
+	recvMonitor      *flowrate.Monitor // limits the rate of the received packets
+	recvBytesMonitor *flowrate.Monitor // tracks the received bytes
 
 	bufConn io.ReadWriter
 
@@ -48,8 +50,9 @@ type Connection struct {
 	pongPulse chan bool
 	quitPulse chan bool
 
-	flushTimer *timer.ThrottleTimer // flush writes as necessary but throttled
-	pingTimer  *timer.RepeatTimer   // send pings periodically
+	flushTimer    *timer.ThrottleTimer // flush writes as necessary but throttled
+	pingTimer     *timer.RepeatTimer   // send pings periodically
+	throttleTimer *timer.ThrottleTimer // resume sending the data held back by the channel rate limits
 
 	pendingPings uint32
 
@@ -76,8 +79,12 @@ type ConnectionConfig struct {
 	FlushThrottle   time.Duration
 	PingTimeout     time.Duration
 	MaxPendingPings uint32
+	ChannelConfigs  map[common.ChannelIDEnum]ChannelConfig // the channels not listed use the default ChannelConfig
 }
 
+// throttleRetryInterval is the interval to retry sending the data held back by the channel rate limits
+const throttleRetryInterval = 20 * time.Millisecond
+
 // MessageParser parses the raw message bytes to type p2ptypes.Message
 type MessageParser func(channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (p2ptypes.Message, error)
 
@@ -100,31 +107,28 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 		logger.Debugf("Create connection, local: %v, remote: %v", netconn.LocalAddr(), netconn.RemoteAddr())
 	}
 
-	channelCheckpoint := createDefaultChannel(common.ChannelIDCheckpoint)
-	channelHeader := createDefaultChannel(common.ChannelIDHeader)
-	channelBlock := createDefaultChannel(common.ChannelIDBlock)
-	channelProposal := createDefaultChannel(common.ChannelIDProposal)
-	channelVote := createDefaultChannel(common.ChannelIDVote)
-	channelTransaction := createDefaultChannel(common.ChannelIDTransaction)
-	channelPeerDiscover := createDefaultChannel(common.ChannelIDPeerDiscovery)
-	channelPing := createDefaultChannel(common.ChannelIDPing)
-	channelSentry := createDefaultChannel(common.ChannelIDSentry)
-	channelNATMapping := createDefaultChannel(common.ChannelIDNATMapping)
-	channelEliteEdgeNodeVote := createDefaultChannel(common.ChannelIDEliteEdgeNodeVote)
-	channelEliteAggregatedEdgeNodeVotes := createDefaultChannel(common.ChannelIDAggregatedEliteEdgeNodeVotes)
-	channels := []*Channel{
-		&channelCheckpoint,
-		&channelHeader,
-		&channelBlock,
-		&channelProposal,
-		&channelVote,
-		&channelTransaction,
-		&channelPeerDiscover,
-		&channelPing,
-		&channelSentry,
-		&channelNATMapping,
-		&channelEliteEdgeNodeVote,
-		&channelEliteAggregatedEdgeNodeVotes,
+	channelIDs := []common.ChannelIDEnum{
+		common.ChannelIDCheckpoint,
+		common.ChannelIDHeader,
+		common.ChannelIDBlock,
+		common.ChannelIDProposal,
+		common.ChannelIDVote,
+		common.ChannelIDTransaction,
+		common.ChannelIDPeerDiscovery,
+		common.ChannelIDPing,
+		common.ChannelIDSentry,
+		common.ChannelIDNATMapping,
+		common.ChannelIDEliteEdgeNodeVote,
+		common.ChannelIDAggregatedEliteEdgeNodeVotes,
+		common.ChannelIDStateSync,
+		common.ChannelIDEvidence,
+		common.ChannelIDCompactBlock,
+		common.ChannelIDBlockTxs,
+	}
+	channels := []*Channel{}
+	for _, channelID := range channelIDs {
+		channel := createChannelWithConfig(channelID, config.getChannelConfig(channelID))
+		channels = append(channels, &channel)
 	}
 
 	success, channelGroup := createChannelGroup(getDefaultChannelGroupConfig(), channels)
@@ -133,19 +137,21 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 	}
 
 	conn := &Connection{
-		netconn:      netconn,
-		bufWriter:    bufio.NewWriter(netconn),
-		sendMonitor:  flowrate.New(0, 0),
-		bufReader:    bufio.NewReader(netconn),
-		recvMonitor:  flowrate.New(0, 0),
-		channelGroup: channelGroup,
-		sendPulse:    make(chan bool, 1),
-		pongPulse:    make(chan bool, 1),
-		quitPulse:    make(chan bool, 1),
-		flushTimer:   timer.NewThrottleTimer("flush", config.FlushThrottle),
-		pingTimer:    timer.NewRepeatTimer("ping", config.PingTimeout),
-		config:       config,
-		wg:           &sync.WaitGroup{},
+		netconn:          netconn,
+		bufWriter:        bufio.NewWriter(netconn),
+		sendMonitor:      flowrate.New(0, 0),
+		bufReader:        bufio.NewReader(netconn),
+		recvMonitor:      flowrate.New(0, 0),
+		recvBytesMonitor: flowrate.New(0, 0),
+		channelGroup:     channelGroup,
+		sendPulse:        make(chan bool, 1),
+		pongPulse:        make(chan bool, 1),
+		quitPulse:        make(chan bool, 1),
+		flushTimer:       timer.NewThrottleTimer("flush", config.FlushThrottle),
+		pingTimer:        timer.NewRepeatTimer("ping", config.PingTimeout),
+		throttleTimer:    timer.NewThrottleTimer("throttle", throttleRetryInterval),
+		config:           config,
+		wg:               &sync.WaitGroup{},
 
 		onEncode: defaultMessageEncoder,
 	}
@@ -156,18 +162,39 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 	return conn
 }
 
-// GetDefaultConnectionConfig returns the default ConnectionConfig
+// GetDefaultConnectionConfig returns the default ConnectionConfig, with the send rate and the
+// channel configs from the node configuration
 func GetDefaultConnectionConfig() ConnectionConfig {
+	channelConfigs := make(map[common.ChannelIDEnum]ChannelConfig)
+	for channelID, name := range channelNames {
+		channelConfig := getDefaultChannelConfig()
+		if priority := viper.GetInt(common.CfgP2PChannelPriorities + "." + name); priority > 0 {
+			channelConfig.Priority = uint(priority)
+		}
+		channelConfig.SendRate = viper.GetInt64(common.CfgP2PChannelSendRates + "." + name)
+		channelConfig.RecvRate = viper.GetInt64(common.CfgP2PChannelRecvRates + "." + name)
+		channelConfigs[channelID] = channelConfig
+	}
+
 	return ConnectionConfig{
-		SendRate:        int64(512000), // 500KB/s
+		SendRate:        viper.GetInt64(common.CfgP2PSendRate),
 		RecvRate:        int64(512000), // 500KB/s
 		PacketBatchSize: int64(10),
 		FlushThrottle:   100 * time.Millisecond,
 		PingTimeout:     40 * time.Second,
 		MaxPendingPings: 3,
+		ChannelConfigs:  channelConfigs,
 	}
 }
 
+// getChannelConfig returns the config of the given channel
+func (config ConnectionConfig) getChannelConfig(channelID common.ChannelIDEnum) ChannelConfig {
+	if channelConfig, ok := config.ChannelConfigs[channelID]; ok {
+		return channelConfig
+	}
+	return getDefaultChannelConfig()
+}
+
 // SetPingTimer for testing purpose
 func (conn *Connection) SetPingTimer(seconds time.Duration) {
 	conn.pingTimer = timer.NewRepeatTimer("ping", seconds*time.Second)
@@ -299,6 +326,7 @@ func (conn *Connection) sendRoutine() {
 	defer conn.recover()
 	defer conn.flushTimer.Stop()
 	defer conn.pingTimer.Stop()
+	defer conn.throttleTimer.Stop()
 	defer func() {
 		_ = conn.netconn.Close()
 	}()
@@ -319,6 +347,8 @@ func (conn *Connection) sendRoutine() {
 			err = conn.sendPongSignal()
 		case <-conn.sendPulse:
 			conn.sendPacketBatchAndScheduleSendPulse()
+		case <-conn.throttleTimer.Ch:
+			conn.sendPacketBatchAndScheduleSendPulse()
 		case <-conn.quitPulse:
 			return
 		}
@@ -371,6 +401,8 @@ func (conn *Connection) sendPacketBatchAndScheduleSendPulse() {
 	success, dataExhausted := conn.sendPacketBatch()
 	if !success || !dataExhausted {
 		conn.scheduleSendPulse()
+	} else if conn.channelGroup.hasPacketToSend() {
+		conn.throttleTimer.Set() // the remaining data is held back by the channel rate limits
 	}
 }
 
@@ -424,6 +456,7 @@ func (conn *Connection) recvRoutine() {
 			return
 		}
 		conn.recvMonitor.Update(int(1))
+		conn.recvBytesMonitor.Update(len(packet.Bytes))
 		switch packet.ChannelID {
 		case common.ChannelIDPing:
 			conn.handlePingPong(packet)
@@ -544,6 +577,25 @@ func (conn *Connection) sendPacket() (success bool, exhausted bool) {
 
 // --------------------- Utils --------------------- //
 
+// ConnectionStatus represents the traffic statistics of the connection
+type ConnectionStatus struct {
+	SendMonitor flowrate.Status
+	RecvMonitor flowrate.Status
+}
+
+// Status returns the number of bytes sent and received over the connection, and the transfer rates
+func (conn *Connection) Status() ConnectionStatus {
+	return ConnectionStatus{
+		SendMonitor: conn.sendMonitor.Status(),
+		RecvMonitor: conn.recvBytesMonitor.Status(),
+	}
+}
+
+// QueueSize returns the number of the messages queued in all the channels of the connection
+func (conn *Connection) QueueSize() int {
+	return conn.channelGroup.getQueueSize()
+}
+
 // GetNetconn returns the attached network connection
 func (conn *Connection) GetNetconn() net.Conn {
 	return conn.netconn
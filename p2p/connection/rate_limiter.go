@@ -0,0 +1,70 @@
+package connection
+
+import (
+	"sync"
+	"time"
+)
+
+//
+// RateLimiter is a token bucket limiting the number of bytes per second. The bucket holds up to one
+// second worth of tokens. A transfer is allowed as long as the bucket is not empty, and may take the
+// bucket into debt, so the messages larger than the bucket are not blocked forever. A RateLimiter with
+// a non-positive rate does not limit anything.
+//
+type RateLimiter struct {
+	mutex *sync.Mutex
+
+	rate      int64
+	tokens    float64
+	updatedAt time.Time
+}
+
+// createRateLimiter creates a RateLimiter for the given number of bytes per second
+func createRateLimiter(rate int64) RateLimiter {
+	return RateLimiter{
+		mutex:     &sync.Mutex{},
+		rate:      rate,
+		tokens:    float64(rate),
+		updatedAt: time.Now(),
+	}
+}
+
+// isUnlimited indicates whether the RateLimiter does not limit anything
+func (rl *RateLimiter) isUnlimited() bool {
+	return rl.rate <= 0
+}
+
+// allow returns whether a transfer is allowed at the moment. It is goroutine safe
+func (rl *RateLimiter) allow() bool {
+	if rl.isUnlimited() {
+		return true
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.refill()
+	return rl.tokens > 0
+}
+
+// consume takes the given number of bytes from the bucket. It is goroutine safe
+func (rl *RateLimiter) consume(numBytes int) {
+	if rl.isUnlimited() {
+		return
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.refill()
+	rl.tokens -= float64(numBytes)
+}
+
+func (rl *RateLimiter) refill() {
+	now := time.Now()
+	rl.tokens += now.Sub(rl.updatedAt).Seconds() * float64(rl.rate)
+	if rl.tokens > float64(rl.rate) {
+		rl.tokens = float64(rl.rate)
+	}
+	rl.updatedAt = now
+}
@@ -0,0 +1,64 @@
+package connection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+)
+
+func TestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	rl := createRateLimiter(1000)
+	assert.True(rl.allow())
+
+	// Messages larger than the bucket can still take it into debt
+	rl.consume(1500)
+	assert.False(rl.allow())
+
+	time.Sleep(600 * time.Millisecond)
+	assert.True(rl.allow())
+	rl.consume(500)
+	assert.False(rl.allow())
+
+	unlimited := createRateLimiter(0)
+	unlimited.consume(1000000)
+	assert.True(unlimited.allow())
+}
+
+func TestChannelRecvRateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	ch := createChannelWithConfig(common.ChannelIDTransaction, ChannelConfig{RecvRate: 10})
+
+	packet := &Packet{ChannelID: common.ChannelIDTransaction, Bytes: []byte("hello world"), IsEOF: byte(0x01)}
+	bytes, success := ch.receivePacket(packet)
+	assert.True(success)
+	assert.Equal([]byte("hello world"), bytes)
+
+	// Dropped since the rate limit is exceeded
+	bytes, success = ch.receivePacket(packet)
+	assert.True(success)
+	assert.Nil(bytes)
+}
+
+func TestConnectionChannelConfigs(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set(common.CfgP2PChannelSendRates+".transaction", 2048)
+	viper.Set(common.CfgP2PChannelPriorities+".state_sync", 4)
+	defer viper.Set(common.CfgP2PChannelSendRates+".transaction", 0)
+	defer viper.Set(common.CfgP2PChannelPriorities+".state_sync", 2)
+
+	config := GetDefaultConnectionConfig()
+	assert.Equal(int64(0), config.SendRate) // the connections are not throttled by default
+	assert.Equal(uint(10), config.getChannelConfig(common.ChannelIDVote).Priority)
+	assert.Equal(uint(1), config.getChannelConfig(common.ChannelIDTransaction).Priority)
+	assert.Equal(int64(2048), config.getChannelConfig(common.ChannelIDTransaction).SendRate)
+	assert.Equal(int64(0), config.getChannelConfig(common.ChannelIDVote).SendRate)
+	assert.Equal(uint(4), config.getChannelConfig(common.ChannelIDStateSync).Priority)
+	assert.Equal(uint(1), config.getChannelConfig(common.ChannelIDInvalid).Priority)
+}
@@ -11,6 +11,7 @@ type SendBuffer struct {
 	workspace []byte
 	queue     chan []byte
 	queueSize int32
+	waiting   int32 // number of the inserts waiting for the queue to have room
 
 	config  SendBufferConfig
 	chanSeq uint
@@ -43,6 +44,11 @@ func (sb *SendBuffer) getSize() int {
 	return int(atomic.LoadInt32(&sb.queueSize))
 }
 
+// getDepth returns the number of the messages queued or waiting to be queued. It is goroutine safe
+func (sb *SendBuffer) getDepth() int {
+	return int(atomic.LoadInt32(&sb.queueSize) + atomic.LoadInt32(&sb.waiting))
+}
+
 // isEmpty indicates whether the SendBuffer is empty
 func (sb *SendBuffer) isEmpty() bool {
 	return (len(sb.workspace) == 0 && len(sb.queue) == 0)
@@ -57,6 +63,9 @@ func (sb *SendBuffer) canInsert() bool {
 // Insert insert the bytes to queue, and times out after
 // the configured timeout. It is goroutine safe
 func (sb *SendBuffer) insert(bytes []byte) bool {
+	atomic.AddInt32(&sb.waiting, 1)
+	defer atomic.AddInt32(&sb.waiting, -1)
+
 	select {
 	case sb.queue <- bytes:
 		atomic.AddInt32(&sb.queueSize, 1)
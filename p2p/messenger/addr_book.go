@@ -23,6 +23,7 @@ import (
 	mm "github.com/dnerochain/dnero/common/math"
 	"github.com/dnerochain/dnero/crypto"
 	nu "github.com/dnerochain/dnero/p2p/netutil"
+	"github.com/dnerochain/dnero/p2p/scoring"
 )
 
 const (
@@ -75,6 +76,9 @@ const (
 	// max addresses returned by GetSelection
 	// NOTE: this must match "maxPexMessageSize"
 	maxGetSelection = 250
+
+	// duration an address marked bad stays banned.
+	badAddressBanDuration = time.Hour * 24
 )
 
 const (
@@ -82,7 +86,7 @@ const (
 	bucketTypeOld = 0x02
 )
 
-// AddrBook - concurrency safe peer address manager.
+// AddrBook - concurrency safe peer address manager. It also keeps the banned peers.
 type AddrBook struct {
 	mtx               sync.Mutex
 	filePath          string
@@ -93,6 +97,7 @@ type AddrBook struct {
 	addrLookup        map[string]*knownAddress // new & old
 	addrNew           []map[string]*knownAddress
 	addrOld           []map[string]*knownAddress
+	bans              map[string]*scoring.BannedPeer
 	wg                sync.WaitGroup
 	nOld              int
 	nNew              int
@@ -105,6 +110,7 @@ func NewAddrBook(filePath string, routabilityStrict bool) *AddrBook {
 		rand:              rand.New(rand.NewSource(time.Now().UnixNano())),
 		ourAddrs:          make(map[string]*nu.NetAddress),
 		addrLookup:        make(map[string]*knownAddress),
+		bans:              make(map[string]*scoring.BannedPeer),
 		filePath:          filePath,
 		routabilityStrict: routabilityStrict,
 	}
@@ -258,10 +264,14 @@ func (a *AddrBook) MarkAttempt(addr *nu.NetAddress) {
 	ka.markAttempt()
 }
 
-// MarkBad currently just ejects the address. In the future, consider
-// blacklisting.
+// MarkBad ejects the address, and bans it for badAddressBanDuration.
 func (a *AddrBook) MarkBad(addr *nu.NetAddress) {
 	a.RemoveAddress(addr)
+	a.Ban(&scoring.BannedPeer{
+		ID:     addr.String(),
+		Until:  time.Now().Add(badAddressBanDuration),
+		Reason: "marked bad",
+	})
 }
 
 // RemoveAddress removes the address from the book.
@@ -276,6 +286,48 @@ func (a *AddrBook) RemoveAddress(addr *nu.NetAddress) {
 	a.removeFromAllBuckets(ka)
 }
 
+/* Bans */
+
+var _ scoring.BanList = (*AddrBook)(nil)
+
+// Ban implements the scoring.BanList interface. The bans are saved right away.
+func (a *AddrBook) Ban(bannedPeer *scoring.BannedPeer) {
+	a.mtx.Lock()
+	a.bans[bannedPeer.ID] = bannedPeer
+	a.mtx.Unlock()
+
+	a.saveToFile(a.filePath)
+}
+
+// Unban implements the scoring.BanList interface.
+func (a *AddrBook) Unban(id string) bool {
+	a.mtx.Lock()
+	_, ok := a.bans[id]
+	delete(a.bans, id)
+	a.mtx.Unlock()
+
+	if ok {
+		a.saveToFile(a.filePath)
+	}
+	return ok
+}
+
+// GetBan implements the scoring.BanList interface.
+func (a *AddrBook) GetBan(id string) *scoring.BannedPeer {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return a.bans[id]
+}
+
+// BannedPeers implements the scoring.BanList interface.
+func (a *AddrBook) BannedPeers() []*scoring.BannedPeer {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return scoring.SortBannedPeers(a.bans)
+}
+
 /* Peer exchange */
 
 // GetSelection randomly selects some addresses (old & new). Suitable for peer-exchange protocols.
@@ -316,6 +368,7 @@ func (a *AddrBook) GetSelection() []*nu.NetAddress {
 type addrBookJSON struct {
 	Key   string
 	Addrs []*knownAddress
+	Bans  []*scoring.BannedPeer
 }
 
 func (a *AddrBook) saveToFile(filePath string) {
@@ -329,9 +382,19 @@ func (a *AddrBook) saveToFile(filePath string) {
 		addrs = append(addrs, ka)
 	}
 
+	// Compile the bans not expired yet
+	bans := []*scoring.BannedPeer{}
+	now := time.Now()
+	for _, ban := range scoring.SortBannedPeers(a.bans) {
+		if !ban.IsExpired(now) {
+			bans = append(bans, ban)
+		}
+	}
+
 	aJSON := &addrBookJSON{
 		Key:   a.key,
 		Addrs: addrs,
+		Bans:  bans,
 	}
 
 	jsonBytes, err := json.MarshalIndent(aJSON, "", "\t")
@@ -383,6 +446,10 @@ func (a *AddrBook) loadFromFile(filePath string) bool {
 			a.nOld++
 		}
 	}
+	// Restore the bans
+	for _, ban := range aJSON.Bans {
+		a.bans[ban.ID] = ban
+	}
 	return true
 }
 
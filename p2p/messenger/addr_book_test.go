@@ -7,9 +7,11 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/dnerochain/dnero/p2p/netutil"
+	"github.com/dnerochain/dnero/p2p/scoring"
 )
 
 func createTempFileName(prefix string) string {
@@ -167,3 +169,33 @@ func TestAddrBookRemoveAddress(t *testing.T) {
 	book.RemoveAddress(nonExistingAddr)
 	assert.Equal(t, 0, book.Size())
 }
+
+func TestAddrBookBans(t *testing.T) {
+	fname := createTempFileName("addrbook_test")
+	book := NewAddrBook(fname, true)
+
+	addr := randIPv4Address(t)
+	book.AddAddress(addr, addr)
+	book.MarkBad(addr)
+	assert.Equal(t, 0, book.Size())
+	assert.NotNil(t, book.GetBan(addr.String()))
+
+	book.Ban(&scoring.BannedPeer{ID: "peer1", Until: time.Now().Add(time.Hour), Reason: "invalid block"})
+	book.Ban(&scoring.BannedPeer{ID: "peer2", Until: time.Now().Add(-time.Hour), Reason: "invalid vote"})
+
+	// The bans are persisted, except the expired ones
+	book = NewAddrBook(fname, true)
+	book.loadFromFile(fname)
+	bans := book.BannedPeers()
+	assert.Equal(t, 2, len(bans))
+	assert.NotNil(t, book.GetBan(addr.String()))
+	assert.Equal(t, "invalid block", book.GetBan("peer1").Reason)
+	assert.Nil(t, book.GetBan("peer2"))
+
+	assert.True(t, book.Unban("peer1"))
+	assert.False(t, book.Unban("peer1"))
+
+	book = NewAddrBook(fname, true)
+	book.loadFromFile(fname)
+	assert.Nil(t, book.GetBan("peer1"))
+}
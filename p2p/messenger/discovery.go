@@ -232,11 +232,22 @@ func (discMgr *PeerDiscoveryManager) connectWithInboundPeer(netconn net.Conn, pe
 // handshakeAndAddPeer performs handshake with a peer. Upon successful handshake,
 // it save the peer to the peer table
 func (discMgr *PeerDiscoveryManager) handshakeAndAddPeer(peer *pr.Peer) error {
-	if err := peer.Handshake(discMgr.nodeInfo); err != nil {
+	var localInfo *p2ptypes.HandshakeInfo
+	if discMgr.messenger != nil {
+		localInfo = discMgr.messenger.localHandshakeInfo()
+	}
+	if err := peer.Handshake(discMgr.nodeInfo, localInfo); err != nil {
 		logger.Warnf("Failed to handshake with peer, error: %v", err)
 		return err
 	}
 
+	if discMgr.messenger != nil && discMgr.messenger.isBanned(peer) {
+		peer.Stop()
+		errMsg := "Refused banned peer"
+		logger.Debugf("%v: %v", errMsg, peer.ID())
+		return errors.New(errMsg)
+	}
+
 	isSeed := discMgr.seedPeerConnector.isASeedPeer(peer.NetAddress())
 	peer.SetSeed(isSeed)
 	if isSeed {
@@ -261,6 +272,10 @@ func (discMgr *PeerDiscoveryManager) handshakeAndAddPeer(peer *pr.Peer) error {
 		return errors.New(errMsg)
 	}
 
+	if discMgr.messenger != nil {
+		discMgr.messenger.notifyPeerConnected(peer.ID())
+	}
+
 	//discMgr.addrBook.AddAddress(peer.NetAddress(), peer.NetAddress())
 	//discMgr.addrBook.Save()
 
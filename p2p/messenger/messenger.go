@@ -2,6 +2,7 @@ package messenger
 
 import (
 	"context"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/dnerochain/dnero/crypto"
 	"github.com/dnerochain/dnero/p2p"
 	pr "github.com/dnerochain/dnero/p2p/peer"
+	"github.com/dnerochain/dnero/p2p/scoring"
 	p2ptypes "github.com/dnerochain/dnero/p2p/types"
 )
 
@@ -36,6 +38,12 @@ type Messenger struct {
 
 	config MessengerConfig
 
+	peerConnectedLock      sync.Mutex
+	peerConnectedListeners []func(peerID string)
+
+	scorer                *scoring.Scorer
+	handshakeInfoProvider p2ptypes.HandshakeInfoProvider
+
 	// Life cycle
 	wg      *sync.WaitGroup
 	quit    chan struct{}
@@ -74,6 +82,8 @@ func CreateMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string,
 		nodeInfo:      p2ptypes.CreateLocalNodeInfo(privKey, uint16(eport)),
 		config:        msgrConfig,
 		wg:            &sync.WaitGroup{},
+
+		handshakeInfoProvider: p2ptypes.CreateLocalHandshakeInfo,
 	}
 
 	localNetAddress := "0.0.0.0:" + strconv.Itoa(port)
@@ -119,6 +129,73 @@ func (msgr *Messenger) SetNATManager(natMgr *NATManager) {
 	msgr.natMgr = natMgr
 }
 
+// AddPeerConnectedListener registers a listener which is notified when a new peer is connected.
+// The listeners are called from the networking goroutines, so they must not block.
+func (msgr *Messenger) AddPeerConnectedListener(listener func(peerID string)) {
+	msgr.peerConnectedLock.Lock()
+	defer msgr.peerConnectedLock.Unlock()
+
+	msgr.peerConnectedListeners = append(msgr.peerConnectedListeners, listener)
+}
+
+func (msgr *Messenger) notifyPeerConnected(peerID string) {
+	msgr.peerConnectedLock.Lock()
+	defer msgr.peerConnectedLock.Unlock()
+
+	for _, listener := range msgr.peerConnectedListeners {
+		listener(peerID)
+	}
+}
+
+// SetPeerScorer sets the scorer the misbehaving peers are reported to. The messenger refuses the
+// banned peers, and disconnects a peer once it is banned.
+func (msgr *Messenger) SetPeerScorer(scorer *scoring.Scorer) {
+	msgr.scorer = scorer
+	scorer.AddBanListener(func(peerID string) {
+		peer := msgr.peerTable.GetPeer(peerID)
+		if peer == nil {
+			return
+		}
+		logger.Infof("Disconnecting banned peer %v", peerID)
+		msgr.peerTable.DeletePeer(peerID)
+		go peer.Stop()
+	})
+}
+
+// SetHandshakeInfoProvider sets the provider of the local HandshakeInfo sent to the peers in the handshakes
+func (msgr *Messenger) SetHandshakeInfoProvider(provider p2ptypes.HandshakeInfoProvider) {
+	msgr.handshakeInfoProvider = provider
+}
+
+// localHandshakeInfo returns the local HandshakeInfo, with the channels of the registered message handlers
+func (msgr *Messenger) localHandshakeInfo() *p2ptypes.HandshakeInfo {
+	info := msgr.handshakeInfoProvider()
+	info.ChannelIDs = []common.ChannelIDEnum{}
+	for channelID := range msgr.msgHandlerMap {
+		info.ChannelIDs = append(info.ChannelIDs, channelID)
+	}
+	sort.Slice(info.ChannelIDs, func(i, j int) bool { return info.ChannelIDs[i] < info.ChannelIDs[j] })
+	return info
+}
+
+// PeerHandshakeInfo returns the info the peer sent in the handshake, or nil if the peer is not
+// connected or runs a version without the chain-aware handshake
+func (msgr *Messenger) PeerHandshakeInfo(peerID string) *p2ptypes.HandshakeInfo {
+	peer := msgr.peerTable.GetPeer(peerID)
+	if peer == nil {
+		return nil
+	}
+	return peer.HandshakeInfo()
+}
+
+// isBanned indicates whether the peer or its network address is banned
+func (msgr *Messenger) isBanned(peer *pr.Peer) bool {
+	if msgr.scorer.IsBanned(peer.ID()) {
+		return true
+	}
+	return peer.NetAddress() != nil && msgr.scorer.IsBanned(peer.NetAddress().String())
+}
+
 // Start is called when the Messenger starts
 func (msgr *Messenger) Start(ctx context.Context) error {
 	c, cancel := context.WithCancel(ctx)
@@ -281,6 +358,9 @@ func (msgr *Messenger) AttachMessageHandlersToPeer(peer *pr.Peer) {
 			logger.Errorf("Failed to setup message parser for channelID %v", channelID)
 		}
 		message, err := msgHandler.ParseMessage(peerID, channelID, rawMessageBytes)
+		if err != nil {
+			msgr.scorer.Report(peerID, scoring.OffenseUndecodableMessage)
+		}
 		return message, err
 	}
 	peer.GetConnection().SetMessageParser(messageParser)
@@ -3,10 +3,14 @@ package messenger
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/crypto"
@@ -15,6 +19,18 @@ import (
 	"github.com/dnerochain/dnero/rlp"
 )
 
+// TestMain keeps the peer table database created by the tests out of the source tree
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "p2p-messenger")
+	if err != nil {
+		panic(err)
+	}
+	viper.SetConfigFile(filepath.Join(dir, "config.yaml"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestMessengerBroadcastMessages(t *testing.T) {
 	assert := assert.New(t)
 	ctx := context.Background()
@@ -97,7 +113,7 @@ func TestMessengerBroadcastMessages(t *testing.T) {
 			ChannelID: common.ChannelIDTransaction,
 			Content:   peerCMsg,
 		}
-		messenger.Broadcast(message)
+		messenger.Broadcast(message, false)
 	}
 
 	// ---------------- Check PeerA and PeerB both received the broadcasted messages ---------------- //
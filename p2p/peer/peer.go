@@ -36,9 +36,10 @@ type Peer struct {
 	isSeed       bool
 	netAddress   *nu.NetAddress
 
-	nodeInfo p2ptypes.NodeInfo // information of the blockchain node of the peer
-	nodeType cmn.NodeType
-	config   PeerConfig
+	nodeInfo      p2ptypes.NodeInfo // information of the blockchain node of the peer
+	nodeType      cmn.NodeType
+	handshakeInfo *p2ptypes.HandshakeInfo // nil if the peer runs a version without the chain-aware handshake
+	config        PeerConfig
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -113,9 +114,10 @@ func (peer *Peer) Stop() {
 	peer.connection.Stop()
 }
 
-// Handshake handles the initial signaling between two peers
+// Handshake handles the initial signaling between two peers. If the local HandshakeInfo is given, it is
+// exchanged with the peer, and the peer is refused if it is incompatible.
 // NOTE: need to call peer.Handshake() before peer.Start()
-func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
+func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo, localInfo *p2ptypes.HandshakeInfo) error {
 	remoteAddr := peer.connection.GetNetconn().RemoteAddr()
 	logger.Infof("Handshaking with %v...", remoteAddr)
 
@@ -151,10 +153,12 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 	targetPeerNodeInfo.PubKey = targetNodePubKey
 	peer.nodeInfo = targetPeerNodeInfo
 
-	// Forward compatibility.
+	// Forward compatibility. The extra info is a list of strings terminated by "EOH", the
+	// peers skip the strings they do not understand.
 	localChainID := viper.GetString(cmn.CfgGenesisChainID)
 	selfNodeType := viper.GetInt(cmn.CfgNodeType)
 	var peerType int
+	var peerInfo *p2ptypes.HandshakeInfo
 	cmn.Parallel(
 		func() {
 			sendError = rlp.Encode(peer.connection.GetBufNetconn(), localChainID)
@@ -165,6 +169,17 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 			if sendError != nil {
 				return
 			}
+			if localInfo != nil {
+				var infoBytes []byte
+				infoBytes, sendError = rlp.EncodeToBytes(localInfo)
+				if sendError != nil {
+					return
+				}
+				sendError = rlp.Encode(peer.connection.GetBufNetconn(), infoBytes)
+				if sendError != nil {
+					return
+				}
+			}
 			sendError = rlp.Encode(peer.connection.GetBufNetconn(), "EOH")
 		},
 		func() {
@@ -178,7 +193,7 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 			}
 			if msg != localChainID {
 				recvError = fmt.Errorf("ChainID mismatch: peer chainID: %v, local ChainID: %v", msg, localChainID)
-				//return
+				return
 			}
 			logger.Infof("Peer ChainID: %v", msg)
 
@@ -205,6 +220,12 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 				if msg == "EOH" {
 					return
 				}
+				if peerInfo == nil {
+					info := &p2ptypes.HandshakeInfo{}
+					if err := rlp.DecodeBytes([]byte(msg), info); err == nil {
+						peerInfo = info
+					}
+				}
 			}
 		},
 	)
@@ -219,6 +240,15 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 
 	peer.nodeType = common.NodeType(peerType)
 
+	if localInfo != nil && peerInfo != nil {
+		if err := peerInfo.CheckCompatibility(localInfo); err != nil {
+			logger.Warnf("Incompatible peer %v: %v", remoteAddr, err)
+			return err
+		}
+		logger.Infof("Peer handshake info: %v", peerInfo)
+	}
+	peer.handshakeInfo = peerInfo
+
 	remotePub, err := peer.connection.DoEncHandshake(
 		crypto.PrivKeyToECDSA(sourceNodeInfo.PrivKey), crypto.PubKeyToECDSA(targetNodePubKey))
 	if err != nil {
@@ -293,6 +323,12 @@ func (peer *Peer) NodeType() cmn.NodeType {
 	return peer.nodeType
 }
 
+// HandshakeInfo returns the info the peer sent in the handshake, or nil if the peer runs a version
+// without the chain-aware handshake
+func (peer *Peer) HandshakeInfo() *p2ptypes.HandshakeInfo {
+	return peer.handshakeInfo
+}
+
 // SetSeed sets the isSeed for the given peer
 func (peer *Peer) SetSeed(isSeed bool) {
 	peer.isSeed = isSeed
@@ -10,6 +10,7 @@ import (
 
 	"github.com/dnerochain/dnero/common"
 	mm "github.com/dnerochain/dnero/common/math"
+	"github.com/dnerochain/dnero/common/metrics"
 	nu "github.com/dnerochain/dnero/p2p/netutil"
 
 	"github.com/spf13/viper"
@@ -100,6 +101,8 @@ func (pt *PeerTable) AddPeer(peer *Peer) bool {
 	pt.peerMap[peer.ID()] = peer
 	pt.addrMap[peer.NetAddress().String()] = peer
 
+	registerPeerMetrics(peer)
+
 	pt.persistPeers()
 
 	return true
@@ -124,6 +127,8 @@ func (pt *PeerTable) DeletePeer(peerID string) {
 		}
 	}
 
+	unregisterPeerMetrics(peerID)
+
 	logger.Infof("Deleted peer %v from the peer table", peerID)
 
 	pt.persistPeers()
@@ -307,3 +312,33 @@ func (pt *PeerTable) writeToDB(key, value string) {
 		pt.db.Put([]byte(key), []byte(value), nil)
 	}
 }
+
+func peerMetricsPrefix(peerID string) string {
+	return "p2p/peer/" + peerID + "/"
+}
+
+// registerPeerMetrics exports the number of bytes sent to and received from the peer, and the
+// number of messages queued to be sent to the peer
+func registerPeerMetrics(peer *Peer) {
+	if peer.GetConnection() == nil {
+		return
+	}
+	unregisterPeerMetrics(peer.ID())
+	prefix := peerMetricsPrefix(peer.ID())
+	metrics.NewRegisteredFunctionalGauge(prefix+"bytes/sent", nil, func() int64 {
+		return peer.GetConnection().Status().SendMonitor.Bytes
+	})
+	metrics.NewRegisteredFunctionalGauge(prefix+"bytes/received", nil, func() int64 {
+		return peer.GetConnection().Status().RecvMonitor.Bytes
+	})
+	metrics.NewRegisteredFunctionalGauge(prefix+"queue", nil, func() int64 {
+		return int64(peer.GetConnection().QueueSize())
+	})
+}
+
+func unregisterPeerMetrics(peerID string) {
+	prefix := peerMetricsPrefix(peerID)
+	metrics.Unregister(prefix + "bytes/sent")
+	metrics.Unregister(prefix + "bytes/received")
+	metrics.Unregister(prefix + "queue")
+}
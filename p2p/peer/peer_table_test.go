@@ -2,16 +2,32 @@ package peer
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/dnerochain/dnero/crypto"
 	cn "github.com/dnerochain/dnero/p2p/connection"
 	p2ptypes "github.com/dnerochain/dnero/p2p/types"
 )
 
+// TestMain keeps the peer table database created by the tests out of the source tree
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "p2p-peer")
+	if err != nil {
+		panic(err)
+	}
+	viper.SetConfigFile(filepath.Join(dir, "config.yaml"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestDefaultPeerTableAddPeer(t *testing.T) {
 	assert := assert.New(t)
 
@@ -42,9 +42,14 @@ func TestPeerHandshakeAndCommunication(t *testing.T) {
 		outboundPeer := newOutboundPeer("127.0.0.1:" + strconv.Itoa(port))
 		randPeerPrivKey, _, _ := crypto.GenerateKeyPair()
 		peerANodeInfo := p2ptypes.CreateLocalNodeInfo(randPeerPrivKey, uint16(port))
-		err := outboundPeer.Handshake(&peerANodeInfo) // send out PeerA's node info
+		peerAInfo := p2ptypes.CreateLocalHandshakeInfo()
+		peerAInfo.ChannelIDs = []common.ChannelIDEnum{common.ChannelIDBlock, common.ChannelIDTransaction}
+		peerAInfo.FinalizedHeight = 100
+		err := outboundPeer.Handshake(&peerANodeInfo, peerAInfo) // send out PeerA's node info
 		assert.Nil(err)
 		assert.True(outboundPeer.IsOutbound())
+		assert.NotNil(outboundPeer.HandshakeInfo())
+		assert.Equal(p2ptypes.NodeRoleValidator, outboundPeer.HandshakeInfo().Role)
 
 		generatedPeerAAddr := peerANodeInfo.PubKey.Address().Hex()
 		receivedPeerBAddr := outboundPeer.nodeInfo.PubKey.Address().Hex()
@@ -77,10 +82,20 @@ func TestPeerHandshakeAndCommunication(t *testing.T) {
 	inboundPeer := newInboundPeer(netconn)
 	peerBPrivKey, _, _ := crypto.GenerateKeyPair()
 	peerBNodeInfo := p2ptypes.CreateLocalNodeInfo(peerBPrivKey, uint16(port))
-	err = inboundPeer.Handshake(&peerBNodeInfo) // send out PeerB's node info
+	peerBInfo := p2ptypes.CreateLocalHandshakeInfo()
+	peerBInfo.Role = p2ptypes.NodeRoleValidator
+	err = inboundPeer.Handshake(&peerBNodeInfo, peerBInfo) // send out PeerB's node info
 	assert.Nil(err)
 	assert.False(inboundPeer.IsOutbound())
 
+	// Handshake info checks
+	peerAInfo := inboundPeer.HandshakeInfo()
+	assert.NotNil(peerAInfo)
+	assert.Equal(uint64(p2ptypes.HandshakeProtocolVersion), peerAInfo.ProtocolVersion)
+	assert.Equal(uint64(100), peerAInfo.FinalizedHeight)
+	assert.True(peerAInfo.SupportsChannel(common.ChannelIDBlock))
+	assert.False(peerAInfo.SupportsChannel(common.ChannelIDVote))
+
 	receivedPeerAAddr := inboundPeer.nodeInfo.PubKey.Address().Hex()
 	generatedPeerBAddr := peerBNodeInfo.PubKey.Address().Hex()
 	log.Infof("Received  PeerA nodeInfo.Address: %v", receivedPeerAAddr)
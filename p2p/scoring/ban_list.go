@@ -0,0 +1,96 @@
+package scoring
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//
+// BannedPeer records a ban of a peer. The ID is either the peer ID or a network address.
+//
+type BannedPeer struct {
+	ID     string
+	Until  time.Time
+	Reason string
+}
+
+// IsExpired indicates whether the ban is lifted at the given time
+func (bp *BannedPeer) IsExpired(now time.Time) bool {
+	return !now.Before(bp.Until)
+}
+
+//
+// BanList keeps the banned peers. The AddrBook implements it to persist the bans.
+//
+type BanList interface {
+	Ban(bannedPeer *BannedPeer)
+	Unban(id string) bool
+	GetBan(id string) *BannedPeer
+	BannedPeers() []*BannedPeer
+}
+
+var _ BanList = (*MemBanList)(nil)
+
+//
+// MemBanList is a BanList kept in memory only
+//
+type MemBanList struct {
+	mtx  sync.Mutex
+	bans map[string]*BannedPeer
+}
+
+// NewMemBanList creates an empty in-memory ban list
+func NewMemBanList() *MemBanList {
+	return &MemBanList{
+		bans: make(map[string]*BannedPeer),
+	}
+}
+
+// Ban adds the ban, replacing the existing ban of the same peer if any
+func (bl *MemBanList) Ban(bannedPeer *BannedPeer) {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	bl.bans[bannedPeer.ID] = bannedPeer
+}
+
+// Unban removes the ban of the peer. It returns false if the peer is not banned.
+func (bl *MemBanList) Unban(id string) bool {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	if _, ok := bl.bans[id]; !ok {
+		return false
+	}
+	delete(bl.bans, id)
+	return true
+}
+
+// GetBan returns the ban of the peer, or nil if the peer is not banned
+func (bl *MemBanList) GetBan(id string) *BannedPeer {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	return bl.bans[id]
+}
+
+// BannedPeers returns all the bans, including the expired ones, ordered by the ID
+func (bl *MemBanList) BannedPeers() []*BannedPeer {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	return SortBannedPeers(bl.bans)
+}
+
+// SortBannedPeers returns the bans in the map ordered by the ID
+func SortBannedPeers(bans map[string]*BannedPeer) []*BannedPeer {
+	bannedPeers := make([]*BannedPeer, 0, len(bans))
+	for _, ban := range bans {
+		bannedPeers = append(bannedPeers, ban)
+	}
+	sort.Slice(bannedPeers, func(i, j int) bool {
+		return bannedPeers[i].ID < bannedPeers[j].ID
+	})
+	return bannedPeers
+}
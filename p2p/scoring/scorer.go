@@ -0,0 +1,257 @@
+package scoring
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/dnerochain/dnero/common"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "scoring"})
+
+const messageSourceCacheSize = 8192
+
+//
+// Offense is a kind of misbehavior a peer is penalized for
+//
+type Offense byte
+
+const (
+	OffenseUndecodableMessage Offense = iota
+	OffenseInvalidBlock
+	OffenseInvalidVote
+	OffenseInvalidTx
+)
+
+// offensePenalties are the scores added for the offenses. A single invalid block is severe, while
+// the invalid txs are penalized lightly since a peer might relay them in good faith.
+var offensePenalties = map[Offense]float64{
+	OffenseUndecodableMessage: 20,
+	OffenseInvalidBlock:       50,
+	OffenseInvalidVote:        20,
+	OffenseInvalidTx:          2,
+}
+
+func (o Offense) String() string {
+	switch o {
+	case OffenseUndecodableMessage:
+		return "undecodable message"
+	case OffenseInvalidBlock:
+		return "invalid block"
+	case OffenseInvalidVote:
+		return "invalid vote"
+	case OffenseInvalidTx:
+		return "invalid transaction"
+	default:
+		return "unknown offense"
+	}
+}
+
+// Penalty returns the score added for the offense
+func (o Offense) Penalty() float64 {
+	return offensePenalties[o]
+}
+
+//
+// ScorerConfig specifies the configuration for the Scorer
+//
+type ScorerConfig struct {
+	BanThreshold float64       // the score at which a peer is banned, zero disables the automatic banning
+	BanDuration  time.Duration // how long a peer stays banned
+	HalfLife     time.Duration // the time for a score to decay by half
+}
+
+// GetDefaultScorerConfig returns the scorer config from the node configuration
+func GetDefaultScorerConfig() ScorerConfig {
+	return ScorerConfig{
+		BanThreshold: viper.GetFloat64(common.CfgP2PBanThreshold),
+		BanDuration:  time.Duration(viper.GetInt64(common.CfgP2PBanDuration)) * time.Second,
+		HalfLife:     time.Duration(viper.GetInt64(common.CfgP2PScoreHalfLife)) * time.Second,
+	}
+}
+
+type peerScore struct {
+	score     float64
+	updatedAt time.Time
+}
+
+//
+// Scorer keeps the misbehavior scores of the peers, reported by the messengers and the message
+// handlers. The scores decay exponentially over time, and a peer whose score reaches the ban
+// threshold is banned for the ban duration. The Scorer is shared by both the p2p and the p2pl
+// messengers, which disconnect the peers once they are banned.
+//
+type Scorer struct {
+	mtx     sync.Mutex
+	config  ScorerConfig
+	banList BanList
+	scores  map[string]*peerScore
+
+	// sources records the peers the blocks and votes are received from, so the misbehavior
+	// found later, e.g. by the consensus engine, can be attributed
+	sources *lru.Cache
+
+	banListenersLock sync.Mutex
+	banListeners     []func(peerID string)
+
+	now func() time.Time
+}
+
+// NewScorer creates an instance of the Scorer. The bans are kept in the given ban list.
+func NewScorer(banList BanList, config ScorerConfig) *Scorer {
+	sources, _ := lru.New(messageSourceCacheSize)
+	return &Scorer{
+		config:  config,
+		banList: banList,
+		scores:  make(map[string]*peerScore),
+		sources: sources,
+		now:     time.Now,
+	}
+}
+
+// AddBanListener registers a listener which is notified when a peer is banned. The listeners are
+// called from the reporting goroutines, so they must not block.
+func (s *Scorer) AddBanListener(listener func(peerID string)) {
+	s.banListenersLock.Lock()
+	defer s.banListenersLock.Unlock()
+
+	s.banListeners = append(s.banListeners, listener)
+}
+
+func (s *Scorer) notifyBanned(peerID string) {
+	s.banListenersLock.Lock()
+	defer s.banListenersLock.Unlock()
+
+	for _, listener := range s.banListeners {
+		listener(peerID)
+	}
+}
+
+// Report penalizes the peer for the offense, and bans the peer if its score reaches the ban
+// threshold. It returns true if the peer gets banned.
+func (s *Scorer) Report(peerID string, offense Offense) bool {
+	if s == nil || peerID == "" {
+		return false
+	}
+
+	s.mtx.Lock()
+	now := s.now()
+	ps, ok := s.scores[peerID]
+	if !ok {
+		ps = &peerScore{updatedAt: now}
+		s.scores[peerID] = ps
+	}
+	ps.score = s.decay(ps, now) + offense.Penalty()
+	ps.updatedAt = now
+	score := ps.score
+
+	banned := s.config.BanThreshold > 0 && score >= s.config.BanThreshold
+	if banned {
+		delete(s.scores, peerID)
+	}
+	s.mtx.Unlock()
+
+	logger.Debugf("Peer %v reported for %v, score: %.2f", peerID, offense, score)
+
+	if banned {
+		s.Ban(peerID, s.config.BanDuration, offense.String())
+	}
+	return banned
+}
+
+// Score returns the current misbehavior score of the peer
+func (s *Scorer) Score(peerID string) float64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	ps, ok := s.scores[peerID]
+	if !ok {
+		return 0
+	}
+	return s.decay(ps, s.now())
+}
+
+// decay returns the score decayed since it was last updated
+func (s *Scorer) decay(ps *peerScore, now time.Time) float64 {
+	if s.config.HalfLife <= 0 {
+		return ps.score
+	}
+	elapsed := now.Sub(ps.updatedAt)
+	if elapsed <= 0 {
+		return ps.score
+	}
+	return ps.score * math.Pow(0.5, float64(elapsed)/float64(s.config.HalfLife))
+}
+
+// RecordSource records the peer a message, e.g. a block or a vote, is received from
+func (s *Scorer) RecordSource(hash common.Hash, peerID string) {
+	if s == nil || peerID == "" {
+		return
+	}
+	s.sources.Add(hash, peerID)
+}
+
+// ReportSource penalizes the peer the message is received from, if known. It returns true if the
+// peer gets banned.
+func (s *Scorer) ReportSource(hash common.Hash, offense Offense) bool {
+	if s == nil {
+		return false
+	}
+	peerID, ok := s.sources.Get(hash)
+	if !ok {
+		return false
+	}
+	s.sources.Remove(hash) // a message is penalized only once
+	return s.Report(peerID.(string), offense)
+}
+
+// Ban bans the peer for the given duration, and resets its score
+func (s *Scorer) Ban(peerID string, duration time.Duration, reason string) {
+	s.mtx.Lock()
+	delete(s.scores, peerID)
+	s.mtx.Unlock()
+
+	s.banList.Ban(&BannedPeer{
+		ID:     peerID,
+		Until:  s.now().Add(duration),
+		Reason: reason,
+	})
+	logger.Infof("Banned peer %v for %v, reason: %v", peerID, duration, reason)
+
+	s.notifyBanned(peerID)
+}
+
+// Unban lifts the ban of the peer. It returns false if the peer is not banned.
+func (s *Scorer) Unban(peerID string) bool {
+	if !s.banList.Unban(peerID) {
+		return false
+	}
+	logger.Infof("Unbanned peer %v", peerID)
+	return true
+}
+
+// IsBanned indicates whether the peer is currently banned
+func (s *Scorer) IsBanned(peerID string) bool {
+	if s == nil {
+		return false
+	}
+	ban := s.banList.GetBan(peerID)
+	return ban != nil && !ban.IsExpired(s.now())
+}
+
+// BannedPeers returns the peers currently banned
+func (s *Scorer) BannedPeers() []*BannedPeer {
+	now := s.now()
+	bannedPeers := []*BannedPeer{}
+	for _, ban := range s.banList.BannedPeers() {
+		if !ban.IsExpired(now) {
+			bannedPeers = append(bannedPeers, ban)
+		}
+	}
+	return bannedPeers
+}
@@ -0,0 +1,107 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/dnerochain/dnero/common"
+)
+
+func newTestScorer(now *time.Time) *Scorer {
+	scorer := NewScorer(NewMemBanList(), ScorerConfig{
+		BanThreshold: 100,
+		BanDuration:  time.Hour,
+		HalfLife:     10 * time.Minute,
+	})
+	scorer.now = func() time.Time { return *now }
+	return scorer
+}
+
+func TestScorerBanThreshold(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1600000000, 0)
+	scorer := newTestScorer(&now)
+
+	banned := []string{}
+	scorer.AddBanListener(func(peerID string) {
+		banned = append(banned, peerID)
+	})
+
+	assert.False(scorer.Report("peer1", OffenseInvalidBlock))
+	assert.Equal(50.0, scorer.Score("peer1"))
+	assert.False(scorer.IsBanned("peer1"))
+
+	// The second invalid block reaches the ban threshold
+	assert.True(scorer.Report("peer1", OffenseInvalidBlock))
+	assert.True(scorer.IsBanned("peer1"))
+	assert.Equal(0.0, scorer.Score("peer1"))
+	assert.Equal([]string{"peer1"}, banned)
+
+	bannedPeers := scorer.BannedPeers()
+	assert.Equal(1, len(bannedPeers))
+	assert.Equal("peer1", bannedPeers[0].ID)
+	assert.Equal(now.Add(time.Hour), bannedPeers[0].Until)
+	assert.Equal(OffenseInvalidBlock.String(), bannedPeers[0].Reason)
+
+	// The ban expires after the ban duration
+	now = now.Add(time.Hour)
+	assert.False(scorer.IsBanned("peer1"))
+	assert.Equal(0, len(scorer.BannedPeers()))
+}
+
+func TestScorerDecay(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1600000000, 0)
+	scorer := newTestScorer(&now)
+
+	scorer.Report("peer1", OffenseInvalidBlock)
+	now = now.Add(10 * time.Minute)
+	assert.InDelta(25.0, scorer.Score("peer1"), 1e-9)
+
+	// The decayed score does not reach the ban threshold
+	assert.False(scorer.Report("peer1", OffenseInvalidBlock))
+	assert.InDelta(75.0, scorer.Score("peer1"), 1e-9)
+	assert.False(scorer.IsBanned("peer1"))
+
+	// The peers are scored independently
+	assert.Equal(0.0, scorer.Score("peer2"))
+}
+
+func TestScorerReportSource(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1600000000, 0)
+	scorer := newTestScorer(&now)
+
+	hash := common.BytesToHash([]byte("block"))
+	assert.False(scorer.ReportSource(hash, OffenseInvalidBlock)) // unknown source
+
+	scorer.RecordSource(hash, "peer1")
+	scorer.ReportSource(hash, OffenseInvalidBlock)
+	assert.Equal(50.0, scorer.Score("peer1"))
+
+	// A message is penalized only once
+	scorer.ReportSource(hash, OffenseInvalidBlock)
+	assert.Equal(50.0, scorer.Score("peer1"))
+}
+
+func TestScorerBanUnban(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1600000000, 0)
+	scorer := newTestScorer(&now)
+
+	scorer.Ban("peer1", time.Minute, "banned by the operator")
+	assert.True(scorer.IsBanned("peer1"))
+	assert.True(scorer.Unban("peer1"))
+	assert.False(scorer.IsBanned("peer1"))
+	assert.False(scorer.Unban("peer1"))
+
+	// A nil scorer disables the scoring
+	var nilScorer *Scorer
+	assert.False(nilScorer.Report("peer1", OffenseInvalidBlock))
+	assert.False(nilScorer.IsBanned("peer1"))
+}
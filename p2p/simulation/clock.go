@@ -0,0 +1,52 @@
+package simulation
+
+import (
+	"sync"
+	"time"
+)
+
+// clockEpoch is the simulated time at which the clocks start
+var clockEpoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+//
+// Clock is the clock of a simulation. The simulated time starts at a fixed instant when the
+// simulation starts, and advances with the wall clock, so the scheduled events and the simulation
+// reports can refer to the time since the start. It is not a virtual clock: the message delivery and
+// the timers of the nodes run in real time, thus the runs cannot be replayed exactly.
+//
+type Clock struct {
+	mu     sync.Mutex
+	origin time.Time // the simulated time when the clock starts
+	start  time.Time // the wall time when the clock starts, zero if not started yet
+}
+
+// NewClock creates a clock for a simulation
+func NewClock() *Clock {
+	return &Clock{
+		origin: clockEpoch,
+	}
+}
+
+// Start starts the clock. Starting a clock that is already started is a no-op.
+func (c *Clock) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.start.IsZero() {
+		c.start = time.Now()
+	}
+}
+
+// Elapsed returns the time elapsed since the clock started
+func (c *Clock) Elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.start.IsZero() {
+		return 0
+	}
+	return time.Since(c.start)
+}
+
+// Now returns the current simulated time
+func (c *Clock) Now() time.Time {
+	return c.origin.Add(c.Elapsed())
+}
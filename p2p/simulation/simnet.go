@@ -2,29 +2,74 @@ package simulation
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
 	"sync"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/p2p"
 	p2ptypes "github.com/dnerochain/dnero/p2p/types"
 )
 
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "simnet"})
+
 // Envelope wraps a message with network information for delivery.
 type Envelope struct {
-	From    string
-	To      string
-	Content interface{}
+	From      string
+	To        string
+	ChannelID common.ChannelIDEnum
+	Content   interface{}
+	Encoded   bool // true if the Content is the message encoded by the sender's message handler
+}
+
+//
+// LinkConfig specifies the conditions of the link from one endpoint to another.
+//
+type LinkConfig struct {
+	Latency  time.Duration // the base delay of the messages
+	Jitter   time.Duration // the extra delay, drawn uniformly from [0, Jitter)
+	DropRate float64       // the probability a message is dropped, in [0, 1]
+}
+
+type link struct {
+	from string
+	to   string
+}
+
+// scheduledEvent is an action taken at a given time after the Simnet starts, e.g. a partition.
+type scheduledEvent struct {
+	at     time.Duration
+	action func()
 }
 
 // Simnet represents an instance of simulated network.
+//
+// The fate of each message, i.e. whether it is dropped and how long it is delayed, is drawn from
+// the seed, the link and the sequence number of the message on the link. Thus the n-th message on
+// a link meets the same fate in the runs with the same seed. The messages are delivered in real
+// time, and which message is the n-th one depends on the goroutine scheduling, so the runs as a
+// whole are not reproducible.
 type Simnet struct {
 	Endpoints  []*SimnetEndpoint
 	msgHandler p2p.MessageHandler
-	messages   chan Envelope
 	MsgLogs    []Envelope
 
+	seed  int64
+	Clock *Clock
+
+	linkLock    sync.Mutex
+	defaultLink LinkConfig
+	links       map[link]LinkConfig
+	linkSeqs    map[link]uint64
+	linkQueues  map[link]chan delivery
+	partitions  map[string]int // endpoint ID -> partition group, the endpoints not in the map are in group 0
+	schedule    []scheduledEvent
+	logMessages bool
+
 	// Life cycle.
 	wg      *sync.WaitGroup
 	mu      *sync.Mutex
@@ -35,46 +80,146 @@ type Simnet struct {
 
 // NewSimnet creates a new instance of Simnet.
 func NewSimnet() *Simnet {
-	return &Simnet{
-		messages: make(chan Envelope, viper.GetInt(common.CfgP2PMessageQueueSize)),
-		MsgLogs:  []Envelope{},
-		wg:       &sync.WaitGroup{},
-		mu:       &sync.Mutex{},
-	}
+	return NewSimnetWithSeed(nil, 0)
 }
 
 // NewSimnetWithHandler creates a new instance of Simnet with given MessageHandler as the default handler.
 func NewSimnetWithHandler(msgHandler p2p.MessageHandler) *Simnet {
+	return NewSimnetWithSeed(msgHandler, 0)
+}
+
+// NewSimnetWithSeed creates a new instance of Simnet whose random decisions are drawn from the given seed.
+// The msgHandler, if not nil, is the default handler receiving all the delivered messages.
+func NewSimnetWithSeed(msgHandler p2p.MessageHandler, seed int64) *Simnet {
 	return &Simnet{
-		msgHandler: msgHandler,
-		messages:   make(chan Envelope, viper.GetInt(common.CfgP2PMessageQueueSize)),
-		wg:         &sync.WaitGroup{},
-		mu:         &sync.Mutex{},
+		msgHandler:  msgHandler,
+		MsgLogs:     []Envelope{},
+		seed:        seed,
+		Clock:       NewClock(),
+		links:       make(map[link]LinkConfig),
+		linkSeqs:    make(map[link]uint64),
+		linkQueues:  make(map[link]chan delivery),
+		partitions:  make(map[string]int),
+		logMessages: true,
+		wg:          &sync.WaitGroup{},
+		mu:          &sync.Mutex{},
 	}
 }
 
 // AddEndpoint adds an endpoint with given ID to the Simnet instance.
 func (sn *Simnet) AddEndpoint(id string) *SimnetEndpoint {
 	endpoint := &SimnetEndpoint{
-		id:       id,
-		network:  sn,
-		incoming: make(chan Envelope, viper.GetInt(common.CfgP2PMessageQueueSize)),
-		outgoing: make(chan Envelope, viper.GetInt(common.CfgP2PMessageQueueSize)),
+		id:         id,
+		network:    sn,
+		handlerMap: make(map[common.ChannelIDEnum]p2p.MessageHandler),
+		incoming:   make(chan Envelope, viper.GetInt(common.CfgP2PMessageQueueSize)),
 	}
 	sn.Endpoints = append(sn.Endpoints, endpoint)
 	return endpoint
 }
 
-// Start is the main entry point for Simnet. It starts all endpoints and start a goroutine to handle message dlivery.
+// GetEndpoint returns the endpoint with the given ID, or nil if not found.
+func (sn *Simnet) GetEndpoint(id string) *SimnetEndpoint {
+	for _, endpoint := range sn.Endpoints {
+		if endpoint.ID() == id {
+			return endpoint
+		}
+	}
+	return nil
+}
+
+// SetMessageLogging sets whether the sent messages are recorded in MsgLogs. It is on by default,
+// long running simulations should turn it off to bound the memory usage.
+func (sn *Simnet) SetMessageLogging(enabled bool) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	sn.logMessages = enabled
+}
+
+// SetDefaultLinkConfig sets the conditions of the links without a specific config.
+func (sn *Simnet) SetDefaultLinkConfig(config LinkConfig) {
+	sn.linkLock.Lock()
+	defer sn.linkLock.Unlock()
+	sn.defaultLink = config
+}
+
+// SetLinkConfig sets the conditions of the link from one endpoint to the other.
+func (sn *Simnet) SetLinkConfig(from, to string, config LinkConfig) {
+	sn.linkLock.Lock()
+	defer sn.linkLock.Unlock()
+	sn.links[link{from: from, to: to}] = config
+}
+
+// SetBidirectionalLinkConfig sets the conditions of the links in both directions between two endpoints.
+func (sn *Simnet) SetBidirectionalLinkConfig(a, b string, config LinkConfig) {
+	sn.SetLinkConfig(a, b, config)
+	sn.SetLinkConfig(b, a, config)
+}
+
+// Partition splits the network into the given groups of endpoint IDs. The messages across the
+// groups are dropped. The endpoints not listed form a group of their own.
+func (sn *Simnet) Partition(groups ...[]string) {
+	sn.linkLock.Lock()
+	defer sn.linkLock.Unlock()
+
+	sn.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, id := range group {
+			sn.partitions[id] = i + 1
+		}
+	}
+	logger.Infof("Network partitioned at %v: %v", sn.Clock.Elapsed(), groups)
+}
+
+// Heal removes the partitions.
+func (sn *Simnet) Heal() {
+	sn.linkLock.Lock()
+	defer sn.linkLock.Unlock()
+
+	sn.partitions = make(map[string]int)
+	logger.Infof("Network partitions healed at %v", sn.Clock.Elapsed())
+}
+
+// Connected indicates whether the messages from one endpoint can reach the other, i.e. they are not
+// separated by a partition, and neither of them is stopped.
+func (sn *Simnet) Connected(from, to string) bool {
+	sn.linkLock.Lock()
+	defer sn.linkLock.Unlock()
+	return sn.connected(from, to)
+}
+
+func (sn *Simnet) connected(from, to string) bool {
+	if sn.partitions[from] != sn.partitions[to] {
+		return false
+	}
+	for _, endpoint := range sn.Endpoints {
+		if (endpoint.ID() == from || endpoint.ID() == to) && endpoint.isStopped() {
+			return false
+		}
+	}
+	return true
+}
+
+// Schedule schedules the action, e.g. a partition or a link config change, to be taken at the given
+// time after the Simnet starts. It must be called before the Simnet starts.
+func (sn *Simnet) Schedule(at time.Duration, action func()) {
+	sn.schedule = append(sn.schedule, scheduledEvent{at: at, action: action})
+}
+
+// Start is the main entry point for Simnet. It starts all endpoints and start a goroutine to run the schedule.
 func (sn *Simnet) Start(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
+	sn.mu.Lock()
 	sn.ctx = c
 	sn.cancel = cancel
+	sn.mu.Unlock()
 
+	sn.Clock.Start()
 	for _, endpoint := range sn.Endpoints {
-		endpoint.Start(ctx)
+		endpoint.Start(c)
 	}
 
+	sn.wg.Add(1)
 	go sn.mainLoop()
 }
 
@@ -88,79 +233,177 @@ func (sn *Simnet) Wait() {
 	sn.wg.Wait()
 }
 
+// mainLoop takes the scheduled actions in time order.
 func (sn *Simnet) mainLoop() {
-	sn.wg.Add(1)
 	defer sn.wg.Done()
 
-	for {
+	schedule := make([]scheduledEvent, len(sn.schedule))
+	copy(schedule, sn.schedule)
+	sort.SliceStable(schedule, func(i, j int) bool { return schedule[i].at < schedule[j].at })
+
+	for _, event := range schedule {
+		timer := time.NewTimer(event.at - sn.Clock.Elapsed())
 		select {
 		case <-sn.ctx.Done():
-			sn.mu.Lock()
-			sn.stopped = true
-			sn.mu.Unlock()
+			timer.Stop()
+			sn.markStopped()
 			return
-		case envelope := <-sn.messages:
-			time.Sleep(1 * time.Microsecond)
-			for _, endpoint := range sn.Endpoints {
-				if (envelope.To == "" && envelope.From != endpoint.ID()) || envelope.To == endpoint.ID() {
-					go func(endpoint *SimnetEndpoint, envelope Envelope) {
-						// Simulate network delay except for messages to self.
-						if envelope.From != endpoint.ID() {
-							// time.Sleep(100 * time.Millisecond)
-						}
-						endpoint.incoming <- envelope
-
-					}(endpoint, envelope)
-				}
-			}
+		case <-timer.C:
+			event.action()
 		}
 	}
+
+	<-sn.ctx.Done()
+	sn.markStopped()
+}
+
+func (sn *Simnet) markStopped() {
+	sn.mu.Lock()
+	sn.stopped = true
+	sn.mu.Unlock()
 }
 
 // AddMessage send a message through the network.
 func (sn *Simnet) AddMessage(msg Envelope) {
 	sn.mu.Lock()
-	defer sn.mu.Unlock()
-
 	if sn.stopped {
+		sn.mu.Unlock()
+		return
+	}
+	if sn.logMessages {
+		sn.MsgLogs = append(sn.MsgLogs, msg)
+	}
+	ctx := sn.ctx
+	sn.mu.Unlock()
+
+	if ctx == nil { // not started
+		return
+	}
+
+	for _, endpoint := range sn.Endpoints {
+		if (msg.To == "" && msg.From != endpoint.ID()) || msg.To == endpoint.ID() {
+			sn.route(ctx, msg, endpoint)
+		}
+	}
+}
+
+type delivery struct {
+	envelope  Envelope
+	deliverAt time.Time
+}
+
+// route decides the fate of the message to the given endpoint, and queues it for the delivery.
+func (sn *Simnet) route(ctx context.Context, msg Envelope, endpoint *SimnetEndpoint) {
+	l := link{from: msg.From, to: endpoint.ID()}
+
+	sn.linkLock.Lock()
+	if msg.From != endpoint.ID() && !sn.connected(msg.From, endpoint.ID()) {
+		sn.linkLock.Unlock()
 		return
 	}
+	config, ok := sn.links[l]
+	if !ok {
+		config = sn.defaultLink
+	}
+	seq := sn.linkSeqs[l]
+	sn.linkSeqs[l] = seq + 1
+	queue, ok := sn.linkQueues[l]
+	if !ok {
+		queue = make(chan delivery, viper.GetInt(common.CfgP2PMessageQueueSize))
+		sn.linkQueues[l] = queue
+		sn.wg.Add(1)
+		go sn.deliveryLoop(ctx, queue, endpoint)
+	}
+	sn.linkLock.Unlock()
 
-	sn.MsgLogs = append(sn.MsgLogs, msg)
-	sn.messages <- msg
+	var delay time.Duration
+	if msg.From != endpoint.ID() { // no delay or drop for messages to self
+		dropDraw, jitterDraw := sn.draw(l, seq)
+		if dropDraw < config.DropRate {
+			return
+		}
+		delay = config.Latency
+		if config.Jitter > 0 {
+			delay += time.Duration(jitterDraw * float64(config.Jitter))
+		}
+	}
+
+	select {
+	case queue <- delivery{envelope: msg, deliverAt: time.Now().Add(delay)}:
+	case <-ctx.Done():
+	}
+}
+
+// deliveryLoop delivers the messages of a link in order, like a stream connection does.
+func (sn *Simnet) deliveryLoop(ctx context.Context, queue chan delivery, endpoint *SimnetEndpoint) {
+	defer sn.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-queue:
+			if wait := time.Until(d.deliverAt); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+			select {
+			case endpoint.incoming <- d.envelope:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// draw returns two numbers uniformly distributed in [0, 1) for the message with the given
+// sequence number on the link, derived from the seed only.
+func (sn *Simnet) draw(l link, seq uint64) (float64, float64) {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], uint64(sn.seed))
+	binary.BigEndian.PutUint64(buf[8:], seq)
+	hash := sha256.Sum256(append(append(append(buf, l.from...), 0), l.to...))
+	u1 := binary.BigEndian.Uint64(hash[:8]) >> 11
+	u2 := binary.BigEndian.Uint64(hash[8:16]) >> 11
+	return float64(u1) / (1 << 53), float64(u2) / (1 << 53)
 }
 
 // SimnetEndpoint is the implementation of Network interface for Simnet.
 type SimnetEndpoint struct {
-	id       string
-	network  *Simnet
-	handlers []p2p.MessageHandler
-	incoming chan Envelope
-	outgoing chan Envelope
+	id         string
+	network    *Simnet
+	handlerMap map[common.ChannelIDEnum]p2p.MessageHandler
+	incoming   chan Envelope
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
 }
 
 var _ p2p.Network = &SimnetEndpoint{}
 
-// Start implements the Network interface. It starts goroutines to receive/send message from network.
+// Start implements the Network interface. It starts a goroutine to receive messages from network.
+// Starting an endpoint that is already started is a no-op.
 func (se *SimnetEndpoint) Start(ctx context.Context) error {
-	go func() {
-		for {
-			select {
-			case envelope := <-se.incoming:
-				message := p2ptypes.Message{
-					PeerID:  envelope.From,
-					Content: envelope.Content,
-				}
-				se.HandleMessage(message)
-			}
-		}
-	}()
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	if se.started {
+		return nil
+	}
+	se.started = true
 
 	go func() {
 		for {
 			select {
-			case envelope := <-se.outgoing:
-				se.network.messages <- envelope
+			case <-ctx.Done():
+				return
+			case envelope := <-se.incoming:
+				se.receive(envelope)
 			}
 		}
 	}()
@@ -168,8 +411,25 @@ func (se *SimnetEndpoint) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop implements the Network interface.
+// Stop implements the Network interface. A stopped endpoint is disconnected from the network, the
+// messages from and to it are dropped.
 func (se *SimnetEndpoint) Stop() {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.stopped = true
+}
+
+// Restart reconnects the stopped endpoint to the network.
+func (se *SimnetEndpoint) Restart() {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.stopped = false
+}
+
+func (se *SimnetEndpoint) isStopped() bool {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	return se.stopped
 }
 
 // Wait blocks until all goroutines have stopped.
@@ -180,17 +440,17 @@ func (se *SimnetEndpoint) Wait() {
 func (se *SimnetEndpoint) Broadcast(message p2ptypes.Message, skipEdgeNode bool) (successes chan bool) {
 	successes = make(chan bool, 10)
 	go func() {
-		se.network.AddMessage(Envelope{From: se.ID(), Content: message.Content})
+		se.send("", message)
 		successes <- true
 	}()
 	return successes
 }
 
-// BroadcastToNeighbors implements the Network interface.
+// BroadcastToNeighbors implements the Network interface. All the endpoints are neighbors in the Simnet.
 func (se *SimnetEndpoint) BroadcastToNeighbors(message p2ptypes.Message, maxNumPeersToBroadcast int, skipEdgeNode bool) (successes chan bool) {
 	successes = make(chan bool, 10)
 	go func() {
-		se.network.AddMessage(Envelope{From: se.ID(), Content: message.Content})
+		se.send("", message)
 		successes <- true
 	}()
 	return successes
@@ -199,29 +459,65 @@ func (se *SimnetEndpoint) BroadcastToNeighbors(message p2ptypes.Message, maxNumP
 // Send implements the Network interface.
 func (se *SimnetEndpoint) Send(id string, message p2ptypes.Message) bool {
 	go func() {
-		se.network.AddMessage(Envelope{From: se.ID(), To: id, Content: message.Content})
+		se.send(id, message)
 	}()
 	return true
 }
 
+// send encodes the message with the message handler of the channel if any, so the receivers get
+// their own copy of the message, and adds it to the network.
+func (se *SimnetEndpoint) send(to string, message p2ptypes.Message) {
+	if se.isStopped() {
+		return
+	}
+	envelope := Envelope{From: se.ID(), To: to, ChannelID: message.ChannelID, Content: message.Content}
+	if handler := se.getHandler(message.ChannelID); handler != nil {
+		raw, err := handler.EncodeMessage(message.Content)
+		if err != nil {
+			logger.Errorf("Failed to encode message on channel %v: %v", message.ChannelID, err)
+			return
+		}
+		envelope.Content = raw
+		envelope.Encoded = true
+	}
+	se.network.AddMessage(envelope)
+}
+
 // Peers returns the IDs of all peers
 func (se *SimnetEndpoint) Peers(skipEdgeNode bool) []string {
-	return []string{}
+	peerIDs := []string{}
+	for _, endpoint := range se.network.Endpoints {
+		if endpoint.ID() != se.ID() && se.network.Connected(se.ID(), endpoint.ID()) {
+			peerIDs = append(peerIDs, endpoint.ID())
+		}
+	}
+	return peerIDs
 }
 
 // PeerURLs returns the URLs of all peers
 func (se *SimnetEndpoint) PeerURLs(skipEdgeNode bool) []string {
-	return []string{}
+	return se.Peers(skipEdgeNode)
 }
 
 // PeerExists indicates if the given peerID is a neighboring peer
 func (se *SimnetEndpoint) PeerExists(peerID string) bool {
-	return false
+	return peerID != se.ID() && se.network.GetEndpoint(peerID) != nil && se.network.Connected(se.ID(), peerID)
 }
 
 // RegisterMessageHandler implements the Network interface.
+// A handler registered for a channel replaces the previous one, e.g. when the node is restarted.
 func (se *SimnetEndpoint) RegisterMessageHandler(handler p2p.MessageHandler) {
-	se.handlers = append(se.handlers, handler)
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	for _, channelID := range handler.GetChannelIDs() {
+		se.handlerMap[channelID] = handler
+	}
+}
+
+func (se *SimnetEndpoint) getHandler(channelID common.ChannelIDEnum) p2p.MessageHandler {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	return se.handlerMap[channelID]
 }
 
 // ID implements the Network interface.
@@ -229,9 +525,36 @@ func (se *SimnetEndpoint) ID() string {
 	return se.id
 }
 
+// receive decodes the message with the message handler of the channel if it is encoded, and
+// hands it to the message handlers.
+func (se *SimnetEndpoint) receive(envelope Envelope) {
+	if se.isStopped() {
+		return
+	}
+
+	message := p2ptypes.Message{
+		PeerID:    envelope.From,
+		ChannelID: envelope.ChannelID,
+		Content:   envelope.Content,
+	}
+	if envelope.Encoded {
+		handler := se.getHandler(envelope.ChannelID)
+		if handler == nil {
+			return
+		}
+		parsed, err := handler.ParseMessage(envelope.From, envelope.ChannelID, envelope.Content.(common.Bytes))
+		if err != nil {
+			logger.Warnf("Failed to parse message from %v on channel %v: %v", envelope.From, envelope.ChannelID, err)
+			return
+		}
+		message = parsed
+	}
+	se.HandleMessage(message)
+}
+
 // HandleMessage implements the MessageHandler interface.
 func (se *SimnetEndpoint) HandleMessage(message p2ptypes.Message) error {
-	for _, handler := range se.handlers {
+	if handler := se.getHandler(message.ChannelID); handler != nil {
 		handler.HandleMessage(message)
 	}
 	if se.network.msgHandler != nil {
@@ -65,7 +65,7 @@ func TestSimnetBroadcast(t *testing.T) {
 	simnet.AddEndpoint("e3")
 	simnet.Start(context.Background())
 
-	e2.Broadcast(createBlockMessage("hello!"))
+	e2.Broadcast(createBlockMessage("hello!"), false)
 	time.Sleep(1 * time.Second)
 	msgHandler.lock.Lock()
 	sort.Strings(msgHandler.ReceivedMessages)
@@ -74,7 +74,7 @@ func TestSimnetBroadcast(t *testing.T) {
 	assert.EqualValues([]string{"e2 -> hello!", "e2 -> hello!"}, msgHandler.ReceivedMessages)
 
 	msgHandler.ReceivedMessages = make([]string, 0)
-	e1.Broadcast(createBlockMessage("world!"))
+	e1.Broadcast(createBlockMessage("world!"), false)
 	time.Sleep(1 * time.Second)
 	msgHandler.lock.Lock()
 	sort.Strings(msgHandler.ReceivedMessages)
@@ -106,3 +106,59 @@ func TestSimnetSend(t *testing.T) {
 	msgHandler.lock.Unlock()
 	assert.EqualValues([]string{"e1 -> world!"}, msgHandler.ReceivedMessages)
 }
+
+func TestSimnetPartition(t *testing.T) {
+	assert := assert.New(t)
+	msgHandler := &SimMessageHandler{lock: &sync.Mutex{}}
+	simnet := NewSimnetWithHandler(msgHandler)
+	e1 := simnet.AddEndpoint("e1")
+	e2 := simnet.AddEndpoint("e2")
+	simnet.AddEndpoint("e3")
+	simnet.Start(context.Background())
+
+	simnet.Partition([]string{"e1", "e2"}, []string{"e3"})
+	assert.False(simnet.Connected("e1", "e3"))
+	assert.EqualValues([]string{"e2"}, e1.Peers(false))
+
+	e1.Broadcast(createBlockMessage("hello!"), false)
+	time.Sleep(1 * time.Second)
+	msgHandler.lock.Lock()
+	assert.EqualValues([]string{"e1 -> hello!"}, msgHandler.ReceivedMessages)
+	msgHandler.ReceivedMessages = make([]string, 0)
+	msgHandler.lock.Unlock()
+
+	simnet.Heal()
+	e2.Send("e3", createBlockMessage("world!"))
+	time.Sleep(1 * time.Second)
+	msgHandler.lock.Lock()
+	assert.EqualValues([]string{"e2 -> world!"}, msgHandler.ReceivedMessages)
+	msgHandler.lock.Unlock()
+}
+
+func TestSimnetSeededDrops(t *testing.T) {
+	assert := assert.New(t)
+
+	run := func(seed int64) []string {
+		msgHandler := &SimMessageHandler{lock: &sync.Mutex{}}
+		simnet := NewSimnetWithSeed(msgHandler, seed)
+		e1 := simnet.AddEndpoint("e1")
+		simnet.AddEndpoint("e2")
+		simnet.SetDefaultLinkConfig(LinkConfig{DropRate: 0.5})
+		simnet.Start(context.Background())
+		defer simnet.Stop()
+
+		for i := 0; i < 40; i++ {
+			e1.Send("e2", createBlockMessage(fmt.Sprintf("%02d", i)))
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(1 * time.Second)
+		msgHandler.lock.Lock()
+		defer msgHandler.lock.Unlock()
+		sort.Strings(msgHandler.ReceivedMessages)
+		return msgHandler.ReceivedMessages
+	}
+
+	received := run(7)
+	assert.True(len(received) > 0 && len(received) < 40)
+	assert.EqualValues(received, run(7))
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/version"
+)
+
+// HandshakeProtocolVersion is the version of the chain-aware handshake
+const HandshakeProtocolVersion = 1
+
+//
+// NodeRole is the role of a node advertised in the handshake
+//
+type NodeRole byte
+
+const (
+	NodeRoleUnknown   NodeRole = iota
+	NodeRoleValidator          // a blockchain node in the validator set
+	NodeRoleSentry             // a blockchain node not in the validator set
+	NodeRoleEdge               // an edge node
+)
+
+func (r NodeRole) String() string {
+	switch r {
+	case NodeRoleValidator:
+		return "validator"
+	case NodeRoleSentry:
+		return "sentry"
+	case NodeRoleEdge:
+		return "edge"
+	default:
+		return "unknown"
+	}
+}
+
+//
+// HandshakeInfo describes the chain a node is on and what it is capable of. It is exchanged in the
+// handshake, so the peers on a different chain are refused before any bandwidth is wasted on them.
+//
+type HandshakeInfo struct {
+	ProtocolVersion uint64
+	ChainID         string
+	GenesisHash     common.Hash // empty if unknown to the node
+	Role            NodeRole
+	SoftwareVersion string
+	ChannelIDs      []common.ChannelIDEnum // the channels the node handles messages for
+	FinalizedHeight uint64                 // the height of the latest finalized block at the handshake
+}
+
+//
+// HandshakeInfoProvider provides the up-to-date local HandshakeInfo for each handshake
+//
+type HandshakeInfoProvider func() *HandshakeInfo
+
+// CreateLocalHandshakeInfo creates the HandshakeInfo of the local node from the node configuration.
+// The node fills in the fields that change over time, e.g. the finalized height, once it is started.
+func CreateLocalHandshakeInfo() *HandshakeInfo {
+	role := NodeRoleSentry
+	if common.NodeType(viper.GetInt(common.CfgNodeType)) == common.NodeTypeEdgeNode {
+		role = NodeRoleEdge
+	}
+	var genesisHash common.Hash
+	if hash := viper.GetString(common.CfgGenesisHash); hash != "" {
+		genesisHash = common.HexToHash(hash)
+	}
+	return &HandshakeInfo{
+		ProtocolVersion: HandshakeProtocolVersion,
+		ChainID:         viper.GetString(common.CfgGenesisChainID),
+		GenesisHash:     genesisHash,
+		Role:            role,
+		SoftwareVersion: version.Version,
+	}
+}
+
+// CheckCompatibility returns an error if the peer with the info is incompatible with the local node
+func (info *HandshakeInfo) CheckCompatibility(local *HandshakeInfo) error {
+	if info.ProtocolVersion == 0 {
+		return fmt.Errorf("Invalid handshake protocol version 0")
+	}
+	if info.ChainID != local.ChainID {
+		return fmt.Errorf("ChainID mismatch: peer chainID: %v, local ChainID: %v", info.ChainID, local.ChainID)
+	}
+	if !info.GenesisHash.IsEmpty() && !local.GenesisHash.IsEmpty() && info.GenesisHash != local.GenesisHash {
+		return fmt.Errorf("Genesis hash mismatch: peer genesis hash: %v, local genesis hash: %v",
+			info.GenesisHash.Hex(), local.GenesisHash.Hex())
+	}
+	return nil
+}
+
+// SupportsChannel indicates whether the node handles the messages of the channel
+func (info *HandshakeInfo) SupportsChannel(channelID common.ChannelIDEnum) bool {
+	for _, cid := range info.ChannelIDs {
+		if cid == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+func (info *HandshakeInfo) String() string {
+	return fmt.Sprintf("HandshakeInfo{ProtocolVersion: %v, ChainID: %v, GenesisHash: %v, Role: %v, SoftwareVersion: %v, ChannelIDs: %v, FinalizedHeight: %v}",
+		info.ProtocolVersion, info.ChainID, info.GenesisHash.Hex(), info.Role, info.SoftwareVersion, info.ChannelIDs, info.FinalizedHeight)
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/rlp"
+)
+
+func newTestHandshakeInfo() *HandshakeInfo {
+	return &HandshakeInfo{
+		ProtocolVersion: HandshakeProtocolVersion,
+		ChainID:         "testnet",
+		GenesisHash:     common.BytesToHash([]byte("genesis")),
+		Role:            NodeRoleValidator,
+		SoftwareVersion: "1.0.0",
+		ChannelIDs:      []common.ChannelIDEnum{common.ChannelIDBlock, common.ChannelIDVote},
+		FinalizedHeight: 1024,
+	}
+}
+
+func TestHandshakeInfoRLPEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	info := newTestHandshakeInfo()
+	encodedInfoBytes, err := rlp.EncodeToBytes(info)
+	assert.Nil(err)
+
+	var decodedInfo HandshakeInfo
+	assert.Nil(rlp.DecodeBytes(encodedInfoBytes, &decodedInfo))
+	assert.Equal(*info, decodedInfo)
+	assert.True(decodedInfo.SupportsChannel(common.ChannelIDVote))
+	assert.False(decodedInfo.SupportsChannel(common.ChannelIDTransaction))
+}
+
+func TestHandshakeInfoCheckCompatibility(t *testing.T) {
+	assert := assert.New(t)
+
+	local := newTestHandshakeInfo()
+
+	peerInfo := newTestHandshakeInfo()
+	peerInfo.Role = NodeRoleEdge
+	peerInfo.SoftwareVersion = "2.0.0"
+	assert.Nil(peerInfo.CheckCompatibility(local))
+
+	// A peer not knowing the genesis hash is not refused
+	peerInfo.GenesisHash = common.Hash{}
+	assert.Nil(peerInfo.CheckCompatibility(local))
+
+	peerInfo.GenesisHash = common.BytesToHash([]byte("another genesis"))
+	assert.NotNil(peerInfo.CheckCompatibility(local))
+
+	peerInfo = newTestHandshakeInfo()
+	peerInfo.ChainID = "mainnet"
+	assert.NotNil(peerInfo.CheckCompatibility(local))
+
+	peerInfo = newTestHandshakeInfo()
+	peerInfo.ProtocolVersion = 0
+	assert.NotNil(peerInfo.CheckCompatibility(local))
+}
@@ -3,21 +3,26 @@ package messenger
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/common/util"
 	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/p2p/scoring"
 	p2ptypes "github.com/dnerochain/dnero/p2p/types"
 	p2pcmn "github.com/dnerochain/dnero/p2pl/common"
+	"github.com/dnerochain/dnero/rlp"
 
 	"github.com/dnerochain/dnero/p2pl/peer"
 
@@ -59,6 +64,14 @@ const (
 	connectInterval                   = 1000 // 1 sec
 	lowConnectivityCheckInterval      = 60
 	highConnectivityCheckInterval     = 10
+
+	handshakeProtocol     = "handshake"
+	maxHandshakeInfoSize  = 4096
+	handshakeTimeout      = 10 * time.Second
+	handshakeInfoStoreKey = "dnero/handshake"
+
+	// maxNumPubsubRelayers is the max number of pubsub messages whose relaying peers are tracked
+	maxNumPubsubRelayers = 4096
 )
 
 type Messenger struct {
@@ -86,6 +99,14 @@ type Messenger struct {
 	statsLock    sync.Mutex
 	statsCounter map[common.ChannelIDEnum]uint64
 
+	peerConnectedLock      sync.Mutex
+	peerConnectedListeners []func(peerID string)
+
+	scorer                *scoring.Scorer
+	handshakeInfoProvider p2ptypes.HandshakeInfoProvider
+
+	pubsubRelayers *lru.Cache // pubsub message -> ID of the peer which relayed the message to us
+
 	// Life cycle
 	wg      *sync.WaitGroup
 	quit    chan struct{}
@@ -159,7 +180,10 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 		statsCounter:        make(map[common.ChannelIDEnum]uint64),
 		wg:                  &sync.WaitGroup{},
 		ctx:                 ctx,
+
+		handshakeInfoProvider: p2ptypes.CreateLocalHandshakeInfo,
 	}
+	messenger.pubsubRelayers, _ = lru.New(maxNumPubsubRelayers)
 
 	for i := 0; i < bufferPoolSize; i++ {
 		messenger.msgBlockBufferPool <- make([]byte, p2pcmn.MaxBlockMessageSize)
@@ -260,6 +284,7 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 	messenger.pubsub = pubsub
 
 	host.Network().Notify((*PeerNotif)(messenger))
+	messenger.host.SetStreamHandler(protocol.ID(protocolPrefix+handshakeProtocol), messenger.handleHandshakeStream)
 
 	logger.Infof("Created node %v, %v, seedPeerOnly: %v", host.ID(), host.Addrs(), seedPeerOnly)
 	return messenger, nil
@@ -288,6 +313,12 @@ func (msgr *Messenger) processLoop(ctx context.Context) {
 				continue
 			}
 
+			if msgr.scorer.IsBanned(pid.Pretty()) {
+				logger.Debugf("Refused banned peer %v", pid)
+				msgr.host.Network().ClosePeer(pid)
+				continue
+			}
+
 			if msgr.seedPeerOnly {
 				if !msgr.isSeedPeer(pid) {
 					msgr.host.Network().ClosePeer(pid)
@@ -312,6 +343,8 @@ func (msgr *Messenger) processLoop(ctx context.Context) {
 			peer.Start(msgr.ctx)
 			peer.OpenStreams()
 			logger.Infof("Peer connected, id: %v, addrs: %v", pr.ID, pr.Addrs)
+			msgr.notifyPeerConnected(peer.ID().Pretty())
+			go msgr.sendHandshakeInfo(pid)
 		case pid := <-msgr.newPeerError:
 			peer := msgr.peerTable.GetPeer(pid)
 			if peer == nil {
@@ -414,7 +447,7 @@ func (msgr *Messenger) maintainSufficientConnections(ctx context.Context) {
 			prevPeers, err := msgr.peerTable.RetrievePreviousPeers()
 			if err == nil {
 				for _, prevPeer := range prevPeers {
-					if msgr.peerTable.PeerExists(prevPeer.ID) {
+					if msgr.peerTable.PeerExists(prevPeer.ID) || msgr.scorer.IsBanned(prevPeer.ID.Pretty()) {
 						continue
 					}
 
@@ -450,6 +483,128 @@ func (msgr *Messenger) maintainSufficientConnections(ctx context.Context) {
 	}
 }
 
+// AddPeerConnectedListener registers a listener which is notified when a new peer is connected.
+// The listeners are called from the networking goroutines, so they must not block.
+func (msgr *Messenger) AddPeerConnectedListener(listener func(peerID string)) {
+	msgr.peerConnectedLock.Lock()
+	defer msgr.peerConnectedLock.Unlock()
+
+	msgr.peerConnectedListeners = append(msgr.peerConnectedListeners, listener)
+}
+
+func (msgr *Messenger) notifyPeerConnected(peerID string) {
+	msgr.peerConnectedLock.Lock()
+	defer msgr.peerConnectedLock.Unlock()
+
+	for _, listener := range msgr.peerConnectedListeners {
+		listener(peerID)
+	}
+}
+
+// SetPeerScorer sets the scorer the misbehaving peers are reported to. The messenger refuses the
+// banned peers, and disconnects a peer once it is banned.
+func (msgr *Messenger) SetPeerScorer(scorer *scoring.Scorer) {
+	msgr.scorer = scorer
+	scorer.AddBanListener(func(peerID string) {
+		pid, err := pr.IDB58Decode(peerID)
+		if err != nil {
+			return
+		}
+		if msgr.peerTable.PeerExists(pid) {
+			logger.Infof("Disconnecting banned peer %v", pid)
+			go msgr.host.Network().ClosePeer(pid)
+		}
+	})
+}
+
+// reportUndecodableMessage reports the peer which sent a message failed to parse
+func (msgr *Messenger) reportUndecodableMessage(peerID string) {
+	msgr.scorer.Report(peerID, scoring.OffenseUndecodableMessage)
+}
+
+// SetHandshakeInfoProvider sets the provider of the local HandshakeInfo sent to the peers in the handshakes
+func (msgr *Messenger) SetHandshakeInfoProvider(provider p2ptypes.HandshakeInfoProvider) {
+	msgr.handshakeInfoProvider = provider
+}
+
+// localHandshakeInfo returns the local HandshakeInfo, with the channels of the registered message handlers
+func (msgr *Messenger) localHandshakeInfo() *p2ptypes.HandshakeInfo {
+	info := msgr.handshakeInfoProvider()
+	info.ChannelIDs = []common.ChannelIDEnum{}
+	for channelID := range msgr.msgHandlerMap {
+		info.ChannelIDs = append(info.ChannelIDs, channelID)
+	}
+	sort.Slice(info.ChannelIDs, func(i, j int) bool { return info.ChannelIDs[i] < info.ChannelIDs[j] })
+	return info
+}
+
+// sendHandshakeInfo sends the local HandshakeInfo to the newly connected peer. The peers running a
+// version without the chain-aware handshake do not support the protocol, and are kept.
+func (msgr *Messenger) sendHandshakeInfo(pid pr.ID) {
+	strm, err := msgr.host.NewStream(msgr.ctx, pid, protocol.ID(msgr.protocolPrefix+handshakeProtocol))
+	if err != nil {
+		logger.Debugf("Peer %v does not support the handshake protocol: %v", pid, err)
+		return
+	}
+	defer strm.Close()
+
+	infoBytes, err := rlp.EncodeToBytes(msgr.localHandshakeInfo())
+	if err != nil {
+		logger.Errorf("Failed to encode the handshake info: %v", err)
+		return
+	}
+	strm.SetWriteDeadline(time.Now().Add(handshakeTimeout))
+	if _, err := strm.Write(infoBytes); err != nil {
+		logger.Debugf("Failed to send the handshake info to peer %v: %v", pid, err)
+	}
+}
+
+// handleHandshakeStream receives the HandshakeInfo of a peer, and disconnects the peer if it is incompatible
+func (msgr *Messenger) handleHandshakeStream(strm network.Stream) {
+	defer strm.Close()
+	pid := strm.Conn().RemotePeer()
+
+	strm.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	infoBytes, err := ioutil.ReadAll(io.LimitReader(strm, maxHandshakeInfoSize))
+	if err != nil {
+		logger.Debugf("Failed to read the handshake info from peer %v: %v", pid, err)
+		return
+	}
+	info := &p2ptypes.HandshakeInfo{}
+	if err := rlp.DecodeBytes(infoBytes, info); err != nil {
+		logger.Warnf("Failed to decode the handshake info from peer %v: %v", pid, err)
+		msgr.reportUndecodableMessage(pid.Pretty())
+		return
+	}
+	if err := info.CheckCompatibility(msgr.localHandshakeInfo()); err != nil {
+		logger.Warnf("Disconnecting incompatible peer %v: %v", pid, err)
+		msgr.host.Network().ClosePeer(pid)
+		return
+	}
+	logger.Infof("Peer %v handshake info: %v", pid, info)
+
+	// The info is kept in the peerstore, since it might arrive before the peer is added to the peer table
+	msgr.host.Peerstore().Put(pid, handshakeInfoStoreKey, info)
+}
+
+// PeerHandshakeInfo returns the info the peer sent in the handshake, or nil if the peer is not
+// connected or runs a version without the chain-aware handshake
+func (msgr *Messenger) PeerHandshakeInfo(peerID string) *p2ptypes.HandshakeInfo {
+	pid, err := pr.IDB58Decode(peerID)
+	if err != nil || !msgr.peerTable.PeerExists(pid) {
+		return nil
+	}
+	val, err := msgr.host.Peerstore().Get(pid, handshakeInfoStoreKey)
+	if err != nil {
+		return nil
+	}
+	info, ok := val.(*p2ptypes.HandshakeInfo)
+	if !ok {
+		return nil
+	}
+	return info
+}
+
 // Start is called when the Messenger starts
 func (msgr *Messenger) Start(ctx context.Context) error {
 	c, cancel := context.WithCancel(ctx)
@@ -697,7 +852,7 @@ func (msgr *Messenger) printStats() {
 	defer msgr.statsLock.Unlock()
 
 	ret := "Received bytes:"
-	for k := byte(0); k <= byte(common.ChannelIDAggregatedEliteEdgeNodeVotes); k++ {
+	for k := byte(0); k <= byte(common.ChannelIDBlockTxs); k++ {
 		v, ok := msgr.statsCounter[common.ChannelIDEnum(k)]
 		if !ok {
 			continue
@@ -719,7 +874,12 @@ func (msgr *Messenger) RegisterMessageHandler(msgHandler p2pl.MessageHandler) {
 
 		msgr.registerStreamHandler(channelID)
 
-		sub, err := msgr.pubsub.Subscribe(msgr.protocolPrefix + strconv.Itoa(int(channelID)))
+		topic := msgr.protocolPrefix + strconv.Itoa(int(channelID))
+		err := msgr.pubsub.RegisterTopicValidator(topic, msgr.recordPubsubRelayer, ps.WithValidatorInline(true))
+		if err != nil {
+			logger.Warnf("Failed to register validator for channel %v, %v", channelID, err)
+		}
+		sub, err := msgr.pubsub.Subscribe(topic)
 		if err != nil {
 			logger.Errorf("Failed to subscribe to channel %v, %v", channelID, err)
 			continue
@@ -746,10 +906,14 @@ func (msgr *Messenger) RegisterMessageHandler(msgHandler p2pl.MessageHandler) {
 					continue
 				}
 
-				message, err := msgHandler.ParseMessage(msg.GetFrom().String(), channelID, msg.Data)
+				// The original publisher of the message might not even be connected, so the
+				// peer which relayed the message is held responsible for it
+				relayer := msgr.pubsubRelayerOf(msg)
+				message, err := msgHandler.ParseMessage(relayer, channelID, msg.Data)
 				if err != nil {
 					logger.Errorf("Failed to parse message, %v", err)
-					return
+					msgr.reportUndecodableMessage(relayer)
+					continue
 				}
 
 				msgr.recordReceivedBytes(channelID, len(msg.Data))
@@ -760,6 +924,21 @@ func (msgr *Messenger) RegisterMessageHandler(msgHandler p2pl.MessageHandler) {
 	}
 }
 
+// recordPubsubRelayer is the pubsub topic validator recording the peer which relayed the message
+func (msgr *Messenger) recordPubsubRelayer(ctx context.Context, from pr.ID, msg *ps.Message) bool {
+	msgr.pubsubRelayers.Add(msg.Message, from)
+	return true
+}
+
+// pubsubRelayerOf returns the ID of the peer which relayed the pubsub message to us
+func (msgr *Messenger) pubsubRelayerOf(msg *ps.Message) string {
+	if from, ok := msgr.pubsubRelayers.Get(msg.Message); ok {
+		msgr.pubsubRelayers.Remove(msg.Message)
+		return from.(pr.ID).String()
+	}
+	return msg.GetFrom().String()
+}
+
 func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 	logger.Debugf("Registered stream handler for channel %v", channelID)
 	msgr.host.SetStreamHandler(protocol.ID(msgr.protocolPrefix+strconv.Itoa(int(channelID))), func(strm network.Stream) {
@@ -772,6 +951,11 @@ func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 			}
 		}
 
+		if msgr.scorer.IsBanned(peerID.Pretty()) {
+			msgr.host.Network().ClosePeer(peerID)
+			return
+		}
+
 		if strings.Compare(msgr.host.ID().String(), peerID.String()) > 0 {
 			logger.Warnf("Received stream from an outbound peer")
 			return
@@ -788,6 +972,8 @@ func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 			remotePeer.Start(msgr.ctx)
 
 			logger.Infof("Peer connected (via stream), id: %v, addrs: %v", remotePeer.ID, remotePeer.Addrs)
+			msgr.notifyPeerConnected(remotePeer.ID().Pretty())
+			go msgr.sendHandshakeInfo(peerID)
 		}
 
 		reuseStream := viper.GetBool(common.CfgP2PReuseStream)
@@ -810,6 +996,7 @@ func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 			message, err := msgHandler.ParseMessage(peerID.String(), channelID, rawPeerMsg)
 			if err != nil {
 				logger.Errorf("Failed to parse message, %v. len(): %v, channel: %v, peer: %v, msg: %v", err, len(rawPeerMsg), channelID, peerID, rawPeerMsg)
+				msgr.reportUndecodableMessage(peerID.String())
 				return
 			}
 
@@ -869,6 +1056,7 @@ func (msgr *Messenger) readPeerMessageRoutine(stream *transport.BufferedStream,
 		bufferPool <- msgBuffer
 		if err != nil {
 			logger.Errorf("Failed to parse message, %v. msgSize: %v, len(): %v, channel: %v, peer: %v, msg: %v", err, msgSize, len(rawPeerMsg), channelID, peerID, rawPeerMsg)
+			msgr.reportUndecodableMessage(peerID)
 			return
 		}
 
@@ -887,6 +1075,9 @@ func (msgr *Messenger) attachHandlersToPeer(peer *peer.Peer) {
 			logger.Errorf("Failed to setup message parser for channelID %v", channelID)
 		}
 		message, err := msgHandler.ParseMessage(peerID.String(), channelID, rawMessageBytes)
+		if err != nil {
+			msgr.reportUndecodableMessage(peerID.String())
+		}
 
 		msgr.recordReceivedBytes(channelID, len(rawMessageBytes))
 
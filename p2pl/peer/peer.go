@@ -32,6 +32,10 @@ var Channels = []cmn.ChannelIDEnum{
 	cmn.ChannelIDSentry,
 	cmn.ChannelIDEliteEdgeNodeVote,
 	cmn.ChannelIDAggregatedEliteEdgeNodeVotes,
+	cmn.ChannelIDStateSync,
+	cmn.ChannelIDEvidence,
+	cmn.ChannelIDCompactBlock,
+	cmn.ChannelIDBlockTxs,
 }
 
 //
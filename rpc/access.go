@@ -0,0 +1,553 @@
+package rpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"golang.org/x/net/websocket"
+)
+
+// Method namespaces
+const (
+	NamespacePublic = "public"
+	NamespaceAdmin  = "admin"
+)
+
+// Error codes returned when a call is rejected by the access control
+const (
+	ErrCodeUnauthorized     = -32020
+	ErrCodeMethodNotAllowed = -32021
+	ErrCodeRateLimited      = -32022
+	ErrCodeRequestTooLarge  = -32023
+	ErrCodeInvalidBatch     = -32024
+)
+
+const (
+	apiKeyHeader     = "X-API-Key"
+	apiKeyQueryParam = "api_key"
+	batchMethod      = "JSONRPC2.Batch"
+
+	rateLimiterIdleTimeout = 10 * time.Minute
+)
+
+//
+// accessController splits the RPC methods into namespaces, and decides whether a client can call
+// a method based on the API key it presents. It also enforces the per-IP and per-key rate limits
+// and the request size limit, and records the admin calls to the audit log.
+//
+type accessController struct {
+	adminMethods        map[string]bool
+	anonymousNamespaces map[string]bool
+	apiKeys             map[string]map[string]bool // API key -> namespaces
+
+	ipLimiter       *rateLimiter
+	keyLimiter      *rateLimiter
+	maxRequestBytes int64
+
+	audit *auditLogger
+}
+
+// newAccessController creates the access controller from the RPC configuration
+func newAccessController() *accessController {
+	ac := &accessController{
+		adminMethods:        make(map[string]bool),
+		anonymousNamespaces: make(map[string]bool),
+		apiKeys:             make(map[string]map[string]bool),
+		maxRequestBytes:     viper.GetInt64(common.CfgRPCMaxRequestBytes),
+		audit:               newAuditLogger(viper.GetString(common.CfgRPCAuditLogPath)),
+	}
+	if ac.maxRequestBytes <= 0 || ac.maxRequestBytes > math.MaxInt32 {
+		ac.maxRequestBytes = math.MaxInt32
+	}
+	for _, method := range splitConfigList(viper.GetString(common.CfgRPCAdminMethods)) {
+		ac.adminMethods[method] = true
+	}
+	for _, ns := range splitConfigList(viper.GetString(common.CfgRPCAnonymousNamespaces)) {
+		ac.anonymousNamespaces[ns] = true
+	}
+	for _, entry := range splitConfigList(viper.GetString(common.CfgRPCAPIKeys)) {
+		idx := strings.LastIndex(entry, ":")
+		if idx <= 0 {
+			logger.Warnf("Ignoring the malformed API key entry, expecting <key>:<namespace>|<namespace>")
+			continue
+		}
+		namespaces := make(map[string]bool)
+		for _, ns := range strings.Split(entry[idx+1:], "|") {
+			namespaces[strings.TrimSpace(ns)] = true
+		}
+		ac.apiKeys[entry[:idx]] = namespaces
+	}
+
+	burst := viper.GetInt(common.CfgRPCRateLimitBurst)
+	if rate := viper.GetFloat64(common.CfgRPCRateLimitPerIP); rate > 0 {
+		ac.ipLimiter = newRateLimiter(rate, burst)
+	}
+	if rate := viper.GetFloat64(common.CfgRPCRateLimitPerKey); rate > 0 {
+		ac.keyLimiter = newRateLimiter(rate, burst)
+	}
+	return ac
+}
+
+// namespaceOf returns the namespace of the method, e.g. "dnero.BackupChain". The method name is
+// normalized the same way as the JSON-RPC codec does, so "dnero_backupChain" and "dnero_BackupChain"
+// are in the same namespace as "dnero.BackupChain".
+func (ac *accessController) namespaceOf(method string) string {
+	name := method
+	if idx := strings.Index(method, "."); idx >= 0 {
+		name = method[idx+1:]
+	} else if idx := strings.Index(method, "_"); idx > 0 {
+		name = method[idx+1:]
+	}
+	if len(name) > 0 {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	if ac.adminMethods[name] {
+		return NamespaceAdmin
+	}
+	return NamespacePublic
+}
+
+// accessClient identifies the client of a connection or an HTTP request
+type accessClient struct {
+	ip      string
+	key     string
+	hasKey  bool
+	keyHash string // identifies the key in the logs without revealing it
+}
+
+// authenticate extracts the API key from the request, either as a bearer token, in the
+// X-API-Key header, or in the api_key query parameter, which is meant for the browser
+// WebSocket clients that cannot set headers
+func (ac *accessController) authenticate(r *http.Request) (*accessClient, *accessError) {
+	client := &accessClient{ip: remoteIP(r)}
+
+	key := r.Header.Get(apiKeyHeader)
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if key == "" {
+		key = r.URL.Query().Get(apiKeyQueryParam)
+	}
+	if key == "" {
+		return client, nil
+	}
+
+	if !ac.isValidKey(key) {
+		return nil, &accessError{http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid API key"}
+	}
+	hash := sha256.Sum256([]byte(key))
+	client.key = key
+	client.hasKey = true
+	client.keyHash = hex.EncodeToString(hash[:4])
+	return client, nil
+}
+
+func (ac *accessController) isValidKey(key string) bool {
+	valid := false
+	for k := range ac.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// authorize checks whether the client can make the call now. The admin calls are recorded to
+// the audit log whether they are allowed or not.
+func (ac *accessController) authorize(client *accessClient, call *rpcCall, transport string) *accessError {
+	namespace := ac.namespaceOf(call.Method)
+
+	aerr := ac.authorizeNamespace(client, namespace)
+	if aerr == nil {
+		aerr = ac.rateLimit(client)
+	}
+
+	if namespace == NamespaceAdmin {
+		ac.audit.record(client, call, transport, aerr)
+	}
+	return aerr
+}
+
+func (ac *accessController) authorizeNamespace(client *accessClient, namespace string) *accessError {
+	if ac.anonymousNamespaces[namespace] {
+		return nil
+	}
+	if client.hasKey && ac.apiKeys[client.key][namespace] {
+		return nil
+	}
+	if !client.hasKey {
+		return &accessError{http.StatusUnauthorized, ErrCodeUnauthorized, "API key required for the " + namespace + " methods"}
+	}
+	return &accessError{http.StatusForbidden, ErrCodeMethodNotAllowed, "API key not allowed to call the " + namespace + " methods"}
+}
+
+func (ac *accessController) rateLimit(client *accessClient) *accessError {
+	if ac.ipLimiter != nil && !ac.ipLimiter.allow(client.ip) {
+		return &accessError{http.StatusTooManyRequests, ErrCodeRateLimited, "Rate limit exceeded"}
+	}
+	if client.hasKey && ac.keyLimiter != nil && !ac.keyLimiter.allow(client.key) {
+		return &accessError{http.StatusTooManyRequests, ErrCodeRateLimited, "Rate limit exceeded"}
+	}
+	return nil
+}
+
+// checkCalls authorizes all the calls in the request body, which is either a single call or a
+// batch of calls. A malformed single call is passed through for the codec to report, while a
+// batch with any malformed element is rejected as a whole, since the codec would still run the
+// well-formed elements of the batch.
+func (ac *accessController) checkCalls(client *accessClient, body []byte, transport string) (*rpcCall, *accessError) {
+	calls, err := parseRPCCalls(body)
+	if err != nil {
+		return nil, &accessError{http.StatusBadRequest, ErrCodeInvalidBatch, err.Error()}
+	}
+	for _, call := range calls {
+		if aerr := ac.authorize(client, call, transport); aerr != nil {
+			return call, aerr
+		}
+	}
+	return nil, nil
+}
+
+// httpMiddleware enforces the access control on the JSON-RPC over HTTP requests
+func (ac *accessController) httpMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		client, aerr := ac.authenticate(r)
+		if aerr != nil {
+			writeAccessError(w, nil, aerr)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, ac.maxRequestBytes+1))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) > ac.maxRequestBytes {
+			writeAccessError(w, nil, &accessError{http.StatusRequestEntityTooLarge, ErrCodeRequestTooLarge, "Request too large"})
+			return
+		}
+
+		if call, aerr := ac.checkCalls(client, body, "http"); aerr != nil {
+			writeAccessError(w, call.id(), aerr)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// wsHandshake rejects the WebSocket connections with invalid API keys before the upgrade
+func (ac *accessController) wsHandshake(handler func(ws *websocket.Conn, client *accessClient)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, aerr := ac.authenticate(r)
+		if aerr != nil {
+			writeAccessError(w, nil, aerr)
+			return
+		}
+		websocket.Handler(func(ws *websocket.Conn) {
+			handler(ws, client)
+		}).ServeHTTP(w, r)
+	})
+}
+
+//
+// accessConn enforces the access control on each message received over a WebSocket connection.
+// The rejected calls are answered directly, and the accepted ones are passed to the codec.
+//
+type accessConn struct {
+	*websocket.Conn
+	ac     *accessController
+	client *accessClient
+	buf    bytes.Reader
+}
+
+func newAccessConn(ws *websocket.Conn, ac *accessController, client *accessClient) *accessConn {
+	ws.MaxPayloadBytes = int(ac.maxRequestBytes)
+	return &accessConn{
+		Conn:   ws,
+		ac:     ac,
+		client: client,
+	}
+}
+
+func (c *accessConn) Read(p []byte) (int, error) {
+	for c.buf.Len() == 0 {
+		var msg []byte
+		if err := websocket.Message.Receive(c.Conn, &msg); err != nil {
+			if err == websocket.ErrFrameTooLarge {
+				logger.Warnf("Closing the WebSocket connection from %v, message too large", c.client.ip)
+			}
+			return 0, err
+		}
+		if call, aerr := c.ac.checkCalls(c.client, msg, "ws"); aerr != nil {
+			resp, err := json.Marshal(newAccessErrorResponse(call.id(), aerr))
+			if err == nil {
+				c.Conn.Write(resp)
+			}
+			continue
+		}
+		c.buf.Reset(msg)
+	}
+	return c.buf.Read(p)
+}
+
+// ------------------------------ Errors -----------------------------------
+
+type accessError struct {
+	status  int
+	code    int
+	message string
+}
+
+type accessErrorResponse struct {
+	Version string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   accessErrorBody  `json:"error"`
+}
+
+type accessErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func newAccessErrorResponse(id *json.RawMessage, aerr *accessError) accessErrorResponse {
+	return accessErrorResponse{
+		Version: "2.0",
+		ID:      id,
+		Error:   accessErrorBody{Code: aerr.code, Message: aerr.message},
+	}
+}
+
+func writeAccessError(w http.ResponseWriter, id *json.RawMessage, aerr *accessError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(aerr.status)
+	json.NewEncoder(w).Encode(newAccessErrorResponse(id, aerr))
+}
+
+// ------------------------------ Rate Limiter -----------------------------------
+
+// rateLimiter keeps a token bucket for each client, e.g. each IP address or API key
+type rateLimiter struct {
+	mu        *sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastUpdate time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		mu:        &sync.Mutex{},
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// allow takes a token from the bucket of the client, and returns false if the bucket is empty
+func (rl *rateLimiter) allow(id string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	if now.Sub(rl.lastSweep) > rateLimiterIdleTimeout {
+		rl.sweep(now)
+	}
+
+	bucket, ok := rl.buckets[id]
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, lastUpdate: now}
+		rl.buckets[id] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.lastUpdate).Seconds() * rl.rate
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.lastUpdate = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// sweep removes the buckets of the idle clients, which would have been refilled anyway
+func (rl *rateLimiter) sweep(now time.Time) {
+	for id, bucket := range rl.buckets {
+		if now.Sub(bucket.lastUpdate) > rateLimiterIdleTimeout {
+			delete(rl.buckets, id)
+		}
+	}
+	rl.lastSweep = now
+}
+
+// ------------------------------ Audit Log -----------------------------------
+
+// auditLogger records the admin calls, as one JSON object per line
+type auditLogger struct {
+	mu   *sync.Mutex
+	file *os.File
+}
+
+type auditEntry struct {
+	Time      string           `json:"time"`
+	RemoteIP  string           `json:"remote_ip"`
+	APIKey    string           `json:"api_key,omitempty"` // hash prefix of the key
+	Transport string           `json:"transport"`
+	Method    string           `json:"method"`
+	Params    *json.RawMessage `json:"params,omitempty"`
+	Allowed   bool             `json:"allowed"`
+	Error     string           `json:"error,omitempty"`
+}
+
+func newAuditLogger(path string) *auditLogger {
+	al := &auditLogger{mu: &sync.Mutex{}}
+	if path == "" {
+		return al
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		logger.Errorf("Failed to open the RPC audit log %v, logging the admin calls instead: %v", path, err)
+		return al
+	}
+	al.file = file
+	return al
+}
+
+func (al *auditLogger) record(client *accessClient, call *rpcCall, transport string, aerr *accessError) {
+	entry := auditEntry{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		RemoteIP:  client.ip,
+		APIKey:    client.keyHash,
+		Transport: transport,
+		Method:    call.Method,
+		Params:    call.Params,
+		Allowed:   aerr == nil,
+	}
+	if aerr != nil {
+		entry.Error = aerr.message
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if al.file == nil {
+		logger.Infof("RPC audit: %s", line)
+		return
+	}
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if _, err := al.file.Write(append(line, '\n')); err != nil {
+		logger.Errorf("Failed to write the RPC audit log: %v", err)
+	}
+}
+
+// ------------------------------ Utils -----------------------------------
+
+type rpcCall struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params *json.RawMessage `json:"params"`
+}
+
+func (call *rpcCall) id() *json.RawMessage {
+	if call == nil {
+		return nil
+	}
+	return call.ID
+}
+
+// parseRPCCalls parses the calls in a JSON-RPC request, which is either a single call or a batch.
+// A malformed single call is returned as no calls, and a malformed batch as an error.
+func parseRPCCalls(body []byte) ([]*rpcCall, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		return parseRPCBatch(body)
+	}
+	call := &rpcCall{}
+	if err := json.Unmarshal(body, call); err != nil {
+		return nil, nil
+	}
+	if call.Method == batchMethod && call.Params != nil {
+		// The calls of a batch can also be sent as the params of the batch method
+		calls, err := parseRPCBatch(bytes.TrimSpace(*call.Params))
+		if err != nil {
+			return nil, err
+		}
+		return append(calls, call), nil
+	}
+	return []*rpcCall{call}, nil
+}
+
+// parseRPCBatch parses each element of a batch on its own, and fails if any of them is not a call
+func parseRPCBatch(body []byte) ([]*rpcCall, error) {
+	elems := []json.RawMessage{}
+	if err := json.Unmarshal(body, &elems); err != nil {
+		return nil, errors.New("Invalid batch request")
+	}
+	calls := []*rpcCall{}
+	for _, elem := range elems {
+		call := &rpcCall{}
+		elem = bytes.TrimSpace(elem)
+		if len(elem) == 0 || elem[0] != '{' {
+			return nil, errors.New("Invalid call in the batch request")
+		}
+		if err := json.Unmarshal(elem, call); err != nil || call.Method == "" {
+			return nil, errors.New("Invalid call in the batch request")
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func splitConfigList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,258 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/util"
+)
+
+// setAccessConfig overrides the config, and returns a function restoring the original config
+func setAccessConfig(values map[string]interface{}) func() {
+	originals := make(map[string]interface{})
+	for key, value := range values {
+		originals[key] = viper.Get(key)
+		viper.Set(key, value)
+	}
+	return func() {
+		for key, original := range originals {
+			viper.Set(key, original)
+		}
+	}
+}
+
+func newTestAccessServer() (*httptest.Server, *[]string) {
+	ac := newAccessController()
+	served := &[]string{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*served = append(*served, string(body))
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	})
+	server := httptest.NewServer(ac.httpMiddleware(next))
+	return server, served
+}
+
+func postRPC(t *testing.T, url string, body string, headers map[string]string) (int, *accessErrorResponse) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	errResp := &accessErrorResponse{}
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(errResp))
+	return resp.StatusCode, errResp
+}
+
+func TestAccessControlNamespaces(t *testing.T) {
+	assert := assert.New(t)
+	logger = util.GetLoggerForModule("rpc")
+
+	dir, err := ioutil.TempDir("", "rpc-access")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	auditLog := filepath.Join(dir, "audit.log")
+	// Only the public methods are open to the anonymous clients by default
+	assert.Equal("public", viper.GetString(common.CfgRPCAnonymousNamespaces))
+	defer setAccessConfig(map[string]interface{}{
+		common.CfgRPCAPIKeys:      "adminkey:public|admin, readkey:public",
+		common.CfgRPCAuditLogPath: auditLog,
+	})()
+	server, served := newTestAccessServer()
+	defer server.Close()
+
+	publicCall := `{"jsonrpc":"2.0","id":1,"method":"dnero.GetStatus","params":[{}]}`
+	adminCall := `{"jsonrpc":"2.0","id":2,"method":"dnero.BackupChain","params":[{"start":1,"end":2}]}`
+
+	status, _ := postRPC(t, server.URL, publicCall, nil)
+	assert.Equal(http.StatusOK, status)
+
+	status, errResp := postRPC(t, server.URL, adminCall, nil)
+	assert.Equal(http.StatusUnauthorized, status)
+	assert.Equal(ErrCodeUnauthorized, errResp.Error.Code)
+	assert.Equal("2", string(*errResp.ID))
+
+	status, errResp = postRPC(t, server.URL, adminCall, map[string]string{"X-API-Key": "readkey"})
+	assert.Equal(http.StatusForbidden, status)
+	assert.Equal(ErrCodeMethodNotAllowed, errResp.Error.Code)
+
+	status, _ = postRPC(t, server.URL, adminCall, map[string]string{"Authorization": "Bearer adminkey"})
+	assert.Equal(http.StatusOK, status)
+
+	status, errResp = postRPC(t, server.URL, publicCall, map[string]string{"X-API-Key": "wrongkey"})
+	assert.Equal(http.StatusUnauthorized, status)
+	assert.Equal(ErrCodeUnauthorized, errResp.Error.Code)
+
+	// An admin call in a batch is not let through either
+	status, _ = postRPC(t, server.URL, "["+publicCall+","+adminCall+"]", nil)
+	assert.Equal(http.StatusUnauthorized, status)
+	status, _ = postRPC(t, server.URL, `{"jsonrpc":"2.0","id":3,"method":"JSONRPC2.Batch","params":[`+adminCall+`]}`, nil)
+	assert.Equal(http.StatusUnauthorized, status)
+
+	// The admin calls in the underscore forms are not let through either
+	for _, method := range []string{"dnero_backupChain", "dnero_BackupChain"} {
+		status, errResp = postRPC(t, server.URL, `{"jsonrpc":"2.0","id":4,"method":"`+method+`","params":[{"start":1,"end":2}]}`, nil)
+		assert.Equal(http.StatusUnauthorized, status)
+		assert.Equal(ErrCodeUnauthorized, errResp.Error.Code)
+	}
+
+	// Neither are the batches mixing the calls with other values
+	for _, batch := range []string{"[" + adminCall + ",1]", "[" + adminCall + ",null]", "[null]"} {
+		status, errResp = postRPC(t, server.URL, batch, nil)
+		assert.Equal(http.StatusBadRequest, status)
+		assert.Equal(ErrCodeInvalidBatch, errResp.Error.Code)
+	}
+
+	assert.Equal([]string{publicCall, adminCall}, *served)
+
+	// The admin calls are audited, without revealing the API keys
+	data, err := ioutil.ReadFile(auditLog)
+	require.Nil(t, err)
+	assert.False(bytes.Contains(data, []byte("adminkey")))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(7, len(lines))
+	entries := []auditEntry{}
+	for _, line := range lines {
+		entry := auditEntry{}
+		require.Nil(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	for _, entry := range entries[:5] {
+		assert.Equal("dnero.BackupChain", entry.Method)
+	}
+	assert.Equal("dnero_backupChain", entries[5].Method)
+	assert.Equal("dnero_BackupChain", entries[6].Method)
+	assert.False(entries[5].Allowed)
+	assert.False(entries[6].Allowed)
+	assert.False(entries[0].Allowed)
+	assert.False(entries[1].Allowed)
+	assert.True(entries[2].Allowed)
+	assert.NotEqual("", entries[2].APIKey)
+	assert.Equal("127.0.0.1", entries[2].RemoteIP)
+}
+
+func TestAccessControlRequestSize(t *testing.T) {
+	assert := assert.New(t)
+	logger = util.GetLoggerForModule("rpc")
+
+	defer setAccessConfig(map[string]interface{}{
+		common.CfgRPCMaxRequestBytes: 128,
+	})()
+	server, served := newTestAccessServer()
+	defer server.Close()
+
+	status, errResp := postRPC(t, server.URL, `{"jsonrpc":"2.0","id":1,"method":"dnero.BroadcastRawTransaction","params":[{"tx_bytes":"`+
+		strings.Repeat("ab", 100)+`"}]}`, nil)
+	assert.Equal(http.StatusRequestEntityTooLarge, status)
+	assert.Equal(ErrCodeRequestTooLarge, errResp.Error.Code)
+	assert.Equal(0, len(*served))
+
+	status, _ = postRPC(t, server.URL, `{"jsonrpc":"2.0","id":1,"method":"dnero.GetStatus","params":[{}]}`, nil)
+	assert.Equal(http.StatusOK, status)
+}
+
+func TestAccessControlRateLimit(t *testing.T) {
+	assert := assert.New(t)
+	logger = util.GetLoggerForModule("rpc")
+
+	defer setAccessConfig(map[string]interface{}{
+		common.CfgRPCAPIKeys:         "key1:public,key2:public",
+		common.CfgRPCRateLimitPerIP:  0,
+		common.CfgRPCRateLimitPerKey: 0.001,
+		common.CfgRPCRateLimitBurst:  2,
+	})()
+	server, _ := newTestAccessServer()
+	defer server.Close()
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"dnero.GetStatus","params":[{}]}`
+	for i := 0; i < 2; i++ {
+		status, _ := postRPC(t, server.URL, call, map[string]string{"X-API-Key": "key1"})
+		assert.Equal(http.StatusOK, status)
+	}
+	status, errResp := postRPC(t, server.URL, call, map[string]string{"X-API-Key": "key1"})
+	assert.Equal(http.StatusTooManyRequests, status)
+	assert.Equal(ErrCodeRateLimited, errResp.Error.Code)
+
+	// Each key has its own bucket
+	status, _ = postRPC(t, server.URL, call, map[string]string{"X-API-Key": "key2"})
+	assert.Equal(http.StatusOK, status)
+}
+
+func TestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	rl := newRateLimiter(2, 3)
+	rl.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.True(rl.allow("a"))
+	}
+	assert.False(rl.allow("a"))
+	assert.True(rl.allow("b"))
+
+	// Refilled at 2 tokens per second, up to the burst
+	now = now.Add(500 * time.Millisecond)
+	assert.True(rl.allow("a"))
+	assert.False(rl.allow("a"))
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(rl.allow("a"))
+	}
+	assert.False(rl.allow("a"))
+
+	// The idle clients are swept
+	assert.Equal(1, len(rl.buckets))
+}
+
+func TestParseRPCCalls(t *testing.T) {
+	assert := assert.New(t)
+
+	calls, err := parseRPCCalls([]byte(` [{"id":1,"method":"dnero.GetStatus"},{"id":2,"method":"dnero.BackupChain"}]`))
+	assert.Nil(err)
+	assert.Equal(2, len(calls))
+	assert.Equal("dnero.BackupChain", calls[1].Method)
+
+	calls, err = parseRPCCalls([]byte(`{"id":3,"method":"JSONRPC2.Batch","params":[{"id":1,"method":"dnero.BackupChain"}]}`))
+	assert.Nil(err)
+	assert.Equal(2, len(calls))
+	assert.Equal("dnero.BackupChain", calls[0].Method)
+
+	calls, err = parseRPCCalls([]byte(`not json`))
+	assert.Nil(err)
+	assert.Equal(0, len(calls))
+
+	// A batch with any element that is not a call is rejected as a whole
+	for _, body := range []string{
+		`[{"id":1,"method":"dnero.BackupChain"}, 1]`,
+		`[{"id":1,"method":"dnero.BackupChain"}, null]`,
+		`[null]`,
+		`[{"id":1,"method":"dnero.BackupChain"}, {"id":2}]`,
+		`[{"id":1,"method":"dnero.BackupChain"}, {"id":2,"method":3}]`,
+		`{"id":3,"method":"JSONRPC2.Batch","params":[{"id":1,"method":"dnero.BackupChain"}, "x"]}`,
+	} {
+		_, err = parseRPCCalls([]byte(body))
+		assert.NotNil(err, body)
+	}
+}
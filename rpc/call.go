@@ -3,9 +3,10 @@ package rpc
 import (
 	"encoding/hex"
 	"fmt"
+	"math/big"
+	"sort"
 
 	"github.com/dnerochain/dnero/common"
-	"github.com/dnerochain/dnero/ledger/state"
 	"github.com/dnerochain/dnero/ledger/types"
 	"github.com/dnerochain/dnero/ledger/vm"
 )
@@ -13,7 +14,8 @@ import (
 // ------------------------------- CallSmartContract -----------------------------------
 
 type CallSmartContractArgs struct {
-	SctxBytes string `json:"sctx_bytes"`
+	SctxBytes string        `json:"sctx_bytes"`
+	Block     BlockSelector `json:"block"` // the latest block by default
 }
 
 type CallSmartContractResult struct {
@@ -23,19 +25,19 @@ type CallSmartContractResult struct {
 	VmError         string            `json:"vm_error"`
 }
 
-// CallSmartContract calls the smart contract. However, calling a smart contract does NOT modify
-// the globally consensus state. It can be used for dry run, or for retrieving info from smart contracts
-// without actually spending gas.
+// CallSmartContract calls the smart contract on top of the state of the selected block. However, calling
+// a smart contract does NOT modify the globally consensus state. It can be used for dry run, or for
+// retrieving info from smart contracts without actually spending gas.
 func (t *DneroRPCService) CallSmartContract(args *CallSmartContractArgs, result *CallSmartContractResult) (err error) {
-	var ledgerState *state.StoreView
-	ledgerState, err = t.ledger.GetDeliveredSnapshot()
+	block, ledgerState, err := t.getStateAt(args.Block.orDefault(BlockTagLatest))
 	if err != nil {
 		return err
 	}
 
-	blockHeight := ledgerState.Height() + 1 // the view points to the parent of the current block
-	if blockHeight < common.HeightEnableSmartContract {
-		return fmt.Errorf("Smart contract feature not enabled until block height %v.", common.HeightEnableSmartContract)
+	blockHeight := block.Height + 1 // the call is executed as if in the next block
+	chainParams := common.GetChainParams(t.chain.ChainID)
+	if blockHeight < chainParams.HeightEnableSmartContract {
+		return fmt.Errorf("Smart contract feature not enabled until block height %v.", chainParams.HeightEnableSmartContract)
 	}
 
 	sctxBytes, err := hex.DecodeString(args.SctxBytes)
@@ -52,8 +54,7 @@ func (t *DneroRPCService) CallSmartContract(args *CallSmartContractArgs, result
 		return fmt.Errorf("Failed to parse SmartContractTx: %v", args.SctxBytes)
 	}
 
-	parentBlock := t.ledger.State().ParentBlock()
-	vmRet, contractAddr, gasUsed, vmErr := vm.Execute(parentBlock, sctx, ledgerState)
+	vmRet, contractAddr, gasUsed, vmErr := vm.Execute(block.Block, sctx, ledgerState)
 	ledgerState.Save()
 
 	result.VmReturn = hex.EncodeToString(vmRet)
@@ -65,3 +66,254 @@ func (t *DneroRPCService) CallSmartContract(args *CallSmartContractArgs, result
 
 	return nil
 }
+
+// ------------------------------- EstimateGas -----------------------------------
+
+const (
+	gasPriceSampleBlocks = 20 // number of the recent finalized blocks to sample the gas prices from
+	gasPricePercentile   = 60 // percentile of the sampled gas prices to suggest
+)
+
+type EstimateGasArgs struct {
+	SctxBytes string        `json:"sctx_bytes"`
+	Preview   bool          `json:"preview"` // estimate against the ScreenedView instead of the DeliveredView, superseded by Block
+	Block     BlockSelector `json:"block"`   // the latest block by default
+}
+
+type EstimateGasResult struct {
+	GasLimit        common.JSONUint64 `json:"gas_limit"`
+	GasUsed         common.JSONUint64 `json:"gas_used"`
+	GasPrice        *common.JSONBig   `json:"gas_price"`
+	MinimumGasPrice *common.JSONBig   `json:"minimum_gas_price"`
+	VmReturn        string            `json:"vm_return"`
+	ContractAddress common.Address    `json:"contract_address"`
+}
+
+// EstimateGas searches for the minimum gas limit with which the smart contract transaction executes
+// successfully, and suggests a gas price based on the effective gas prices of the transactions in the
+// recent finalized blocks. The gas limit of the given transaction is ignored. Similar to
+// CallSmartContract, it does NOT modify the globally consensus state.
+func (t *DneroRPCService) EstimateGas(args *EstimateGasArgs, result *EstimateGasResult) (err error) {
+	selector := args.Block
+	if args.Preview {
+		selector = selector.orDefault(BlockTagPending)
+	}
+	block, ledgerState, err := t.getStateAt(selector.orDefault(BlockTagLatest))
+	if err != nil {
+		return err
+	}
+
+	blockHeight := block.Height + 1 // the transaction is executed as if in the next block
+	chainParams := common.GetChainParams(t.chain.ChainID)
+	if blockHeight < chainParams.HeightEnableSmartContract {
+		return fmt.Errorf("Smart contract feature not enabled until block height %v.", chainParams.HeightEnableSmartContract)
+	}
+
+	sctxBytes, err := hex.DecodeString(args.SctxBytes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := types.TxFromBytes(sctxBytes)
+	if err != nil {
+		return fmt.Errorf("Failed to parse SmartContractTx, error: %v", err)
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return fmt.Errorf("Failed to parse SmartContractTx: %v", args.SctxBytes)
+	}
+
+	parentBlock := block.Block
+	maxGasLimit := types.GetMaxGasLimit(chainParams, blockHeight).Uint64()
+
+	// Each run executes the transaction on a fresh copy of the view
+	var vmRet common.Bytes
+	var contractAddr common.Address
+	var gasUsed uint64
+	execute := func(gasLimit uint64) (bool, error) {
+		view, err := ledgerState.Copy()
+		if err != nil {
+			return false, err
+		}
+		sctx.GasLimit = gasLimit
+		ret, addr, used, vmErr := vm.Execute(parentBlock, sctx, view)
+		if vmErr != nil {
+			if gasLimit == maxGasLimit {
+				if vm.IsExecutionReverted(vmErr) {
+					if reason, ok := vm.UnpackRevertReason(ret); ok {
+						return false, fmt.Errorf("Execution failed with the maximum gas limit %v: %v: %v", maxGasLimit, vmErr, reason)
+					}
+				}
+				return false, fmt.Errorf("Execution failed with the maximum gas limit %v: %v", maxGasLimit, vmErr)
+			}
+			return false, nil
+		}
+		vmRet, contractAddr, gasUsed = ret, addr, used
+		return true, nil
+	}
+
+	// The execution with the maximum gas limit tells whether the transaction can succeed at all,
+	// and the gas it consumes is a lower bound of the gas limit required
+	if _, err = execute(maxGasLimit); err != nil {
+		return err
+	}
+	lowerBound := gasUsed
+	if lowerBound > 0 {
+		lowerBound--
+	}
+	gasLimit, err := searchGasLimit(lowerBound, maxGasLimit, execute)
+	if err != nil {
+		return err
+	}
+	// Re-execute with the gas limit found, so the results reflect the estimation
+	if _, err = execute(gasLimit); err != nil {
+		return err
+	}
+
+	result.GasLimit = common.JSONUint64(gasLimit)
+	result.GasUsed = common.JSONUint64(gasUsed)
+	result.VmReturn = hex.EncodeToString(vmRet)
+	result.ContractAddress = contractAddr
+
+	minGasPrice := types.GetMinimumGasPrice(chainParams, blockHeight)
+	gasPrice := suggestGasPrice(t.recentGasPrices(gasPriceSampleBlocks), gasPricePercentile, minGasPrice)
+	result.GasPrice = (*common.JSONBig)(gasPrice)
+	result.MinimumGasPrice = (*common.JSONBig)(minGasPrice)
+
+	return nil
+}
+
+// recentGasPrices collects the non-zero effective gas prices of the transactions in the given number
+// of the latest finalized blocks
+func (t *DneroRPCService) recentGasPrices(numBlocks int) []*big.Int {
+	gasPrices := []*big.Int{}
+	block := t.consensus.GetLastFinalizedBlock()
+	for i := 0; i < numBlocks && block != nil; i++ {
+		for _, rawTx := range block.Txs {
+			txInfo, res := t.ledger.GetTxInfo(rawTx)
+			if res.IsError() || txInfo == nil || txInfo.EffectiveGasPrice == nil {
+				continue
+			}
+			if txInfo.EffectiveGasPrice.Sign() > 0 { // e.g. the coinbase and slash txs
+				gasPrices = append(gasPrices, txInfo.EffectiveGasPrice)
+			}
+		}
+		if block.Height == 0 {
+			break
+		}
+		parent, err := t.chain.FindBlock(block.Parent)
+		if err != nil {
+			break
+		}
+		block = parent
+	}
+	return gasPrices
+}
+
+// ------------------------------- SimulateTransaction -----------------------------------
+
+type SimulateTransactionArgs struct {
+	TxBytes string        `json:"tx_bytes"` // signed or unsigned
+	Block   BlockSelector `json:"block"`    // the pending state by default
+}
+
+type SimulatedAccount struct {
+	Address common.Address `json:"address"`
+	Account *types.Account `json:"account"`
+}
+
+type SimulateTransactionResult struct {
+	Hash            common.Hash            `json:"hash"`
+	BlockHeight     common.JSONUint64      `json:"block_height"` // the height the transaction is simulated at
+	Code            int                    `json:"code"`         // zero if the transaction succeeds
+	Error           string                 `json:"error"`
+	GasUsed         common.JSONUint64      `json:"gas_used"`
+	Logs            []*types.Log           `json:"logs"`
+	BalanceChanges  []*types.BalanceChange `json:"balance_changes"`
+	Accounts        []SimulatedAccount     `json:"accounts"` // the resulting states of the accounts involved
+	VmReturn        string                 `json:"vm_return"`
+	VmError         string                 `json:"vm_error"`
+	ContractAddress common.Address         `json:"contract_address"`
+}
+
+// SimulateTransaction executes a transaction of any type on top of the state of the selected block, as
+// if it is included in the next block, and returns its effect. Like CallSmartContract, it does NOT
+// modify the globally consensus state. The signatures are verified only if present, so the effect can
+// be previewed before the transaction is signed. A transaction rejected by the checks is reported by
+// the result code, not as an RPC error.
+func (t *DneroRPCService) SimulateTransaction(args *SimulateTransactionArgs, result *SimulateTransactionResult) (err error) {
+	txBytes, err := decodeTxHexBytes(args.TxBytes)
+	if err != nil {
+		return err
+	}
+	block, ledgerState, err := t.getStateAt(args.Block.orDefault(BlockTagPending))
+	if err != nil {
+		return err
+	}
+
+	sim, err := t.ledger.SimulateTx(block.Block, ledgerState, txBytes)
+	if err != nil {
+		return err
+	}
+
+	result.Hash = sim.TxHash
+	result.BlockHeight = common.JSONUint64(block.Height + 1)
+	result.Code = int(sim.Result.Code)
+	if sim.Result.IsError() {
+		result.Error = sim.Result.Message
+		return nil
+	}
+	result.GasUsed = common.JSONUint64(sim.GasUsed)
+	result.Logs = sim.Logs
+	result.BalanceChanges = sim.BalanceChanges
+	for _, account := range sim.Accounts {
+		result.Accounts = append(result.Accounts, SimulatedAccount{Address: account.Address, Account: account})
+	}
+	result.VmReturn = hex.EncodeToString(sim.EvmReturn)
+	if sim.EvmError != nil {
+		result.VmError = sim.EvmError.Error()
+	}
+	result.ContractAddress = sim.ContractAddress
+
+	return nil
+}
+
+// ------------------------------ Utils ------------------------------
+
+// searchGasLimit binary searches the minimum gas limit in (lowerBound, upperBound] with which the
+// execution succeeds. The execution is expected to succeed with upperBound.
+func searchGasLimit(lowerBound, upperBound uint64, execute func(gasLimit uint64) (bool, error)) (uint64, error) {
+	for lowerBound+1 < upperBound {
+		mid := lowerBound + (upperBound-lowerBound)/2
+		succeeded, err := execute(mid)
+		if err != nil {
+			return 0, err
+		}
+		if succeeded {
+			upperBound = mid
+		} else {
+			lowerBound = mid
+		}
+	}
+	return upperBound, nil
+}
+
+// suggestGasPrice returns the given percentile of the gas prices, and no less than the minimum
+// gas price. The minimum gas price is suggested if there is no gas price sampled.
+func suggestGasPrice(gasPrices []*big.Int, percentile int, minGasPrice *big.Int) *big.Int {
+	if len(gasPrices) == 0 {
+		return new(big.Int).Set(minGasPrice)
+	}
+
+	sorted := make([]*big.Int, len(gasPrices))
+	copy(sorted, gasPrices)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+
+	gasPrice := sorted[(len(sorted)-1)*percentile/100]
+	if gasPrice.Cmp(minGasPrice) < 0 {
+		return new(big.Int).Set(minGasPrice)
+	}
+	return new(big.Int).Set(gasPrice)
+}
@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchGasLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	// e.g. the refunds or the 63/64 rule make the required gas limit higher than the gas used
+	required := uint64(53421)
+	numRuns := 0
+	execute := func(gasLimit uint64) (bool, error) {
+		numRuns++
+		return gasLimit >= required, nil
+	}
+
+	gasLimit, err := searchGasLimit(41000, 10000000, execute)
+	assert.Nil(err)
+	assert.Equal(required, gasLimit)
+	assert.True(numRuns <= 24)
+
+	// The upper bound is returned if it is the only candidate
+	gasLimit, err = searchGasLimit(required-1, required, execute)
+	assert.Nil(err)
+	assert.Equal(required, gasLimit)
+
+	_, err = searchGasLimit(0, 10000000, func(gasLimit uint64) (bool, error) {
+		return false, errors.New("failed to copy the view")
+	})
+	assert.NotNil(err)
+}
+
+func TestSuggestGasPrice(t *testing.T) {
+	assert := assert.New(t)
+
+	minGasPrice := big.NewInt(100)
+
+	assert.Equal(int64(100), suggestGasPrice([]*big.Int{}, 60, minGasPrice).Int64())
+
+	gasPrices := []*big.Int{}
+	for _, price := range []int64{500, 100, 900, 300, 700, 200, 1000, 400, 800, 600} {
+		gasPrices = append(gasPrices, big.NewInt(price))
+	}
+	assert.Equal(int64(600), suggestGasPrice(gasPrices, 60, minGasPrice).Int64())
+	assert.Equal(int64(100), suggestGasPrice(gasPrices, 0, minGasPrice).Int64())
+	assert.Equal(int64(1000), suggestGasPrice(gasPrices, 100, minGasPrice).Int64())
+
+	// The input is not modified
+	assert.Equal(int64(500), gasPrices[0].Int64())
+
+	// Never lower than the minimum gas price
+	assert.Equal(int64(100), suggestGasPrice([]*big.Int{big.NewInt(10), big.NewInt(20)}, 60, minGasPrice).Int64())
+}
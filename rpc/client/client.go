@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "rpcclient"})
+
+// Config specifies how the client talks to the node
+type Config struct {
+	// Timeout is the timeout of each HTTP request. Zero means no timeout. It should be longer than
+	// the time the node waits for a tx broadcasted by BroadcastRawTransaction to be included.
+	Timeout time.Duration
+
+	// MaxRetries is the number of times a failed call is retried. Only the transport errors, e.g.
+	// the connection is refused, are retried. The errors returned by the node, the tx broadcasts
+	// and the subscriptions are never retried.
+	MaxRetries int
+
+	// RetryInterval is the interval before the first retry, which doubles after each retry
+	RetryInterval time.Duration
+}
+
+// DefaultConfig returns the default client configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       90 * time.Second,
+		MaxRetries:    3,
+		RetryInterval: 500 * time.Millisecond,
+	}
+}
+
+//
+// Client is a typed client of the RPC endpoints of a Dnero node. It talks to the node over
+// WebSocket if the endpoint URL starts with "ws://" or "wss://", and over HTTP otherwise.
+//
+type Client struct {
+	transport transport
+	config    Config
+
+	// pinnedHeight, if non-zero, is the default height of the state queries
+	pinnedHeight uint64
+
+	chainIDMu *sync.Mutex
+	chainID   *string
+}
+
+// NewClient creates a client with the default configuration, e.g. NewClient("http://localhost:16888/rpc")
+// or NewClient("ws://localhost:16888/ws")
+func NewClient(endpoint string) (*Client, error) {
+	return NewClientWithConfig(endpoint, DefaultConfig())
+}
+
+// NewClientWithConfig creates a client with the given configuration
+func NewClientWithConfig(endpoint string, config Config) (*Client, error) {
+	var t transport
+	if strings.HasPrefix(endpoint, "ws://") || strings.HasPrefix(endpoint, "wss://") {
+		ws, err := newWSTransport(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		t = ws
+	} else {
+		t = newHTTPTransport(endpoint, config.Timeout)
+	}
+	return &Client{
+		transport: t,
+		config:    config,
+		chainIDMu: &sync.Mutex{},
+		chainID:   new(string),
+	}, nil
+}
+
+// Close closes the underlying connection. The clients derived with AtHeight share the
+// connection, and are closed as well.
+func (c *Client) Close() error {
+	return c.transport.close()
+}
+
+// AtHeight returns a client which shares the connection, and queries the state at the given
+// height unless a height is specified explicitly. It allows multiple queries to see a consistent
+// state while new blocks are being finalized. Zero unpins the height.
+func (c *Client) AtHeight(height uint64) *Client {
+	pinned := *c
+	pinned.pinnedHeight = height
+	return &pinned
+}
+
+// AtFinalizedHeight returns a client pinned at the latest finalized height of the node
+func (c *Client) AtFinalizedHeight(ctx context.Context) (*Client, error) {
+	status, err := c.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.AtHeight(uint64(status.LatestFinalizedBlockHeight)), nil
+}
+
+// PinnedHeight returns the height the client is pinned at, zero if not pinned
+func (c *Client) PinnedHeight() uint64 {
+	return c.pinnedHeight
+}
+
+// ChainID returns the chain ID of the node, which is queried once and cached
+func (c *Client) ChainID(ctx context.Context) (string, error) {
+	c.chainIDMu.Lock()
+	defer c.chainIDMu.Unlock()
+
+	if *c.chainID != "" {
+		return *c.chainID, nil
+	}
+	status, err := c.GetStatus(ctx)
+	if err != nil {
+		return "", err
+	}
+	*c.chainID = status.ChainID
+	return status.ChainID, nil
+}
+
+// call invokes the method, and retries on the transport errors
+func (c *Client) call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	interval := c.config.RetryInterval
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.transport.call(ctx, method, args, result)
+		if err == nil || !isRetryable(err) || attempt >= c.config.MaxRetries {
+			return err
+		}
+		logger.Debugf("Call to %v failed, retrying in %v: %v", method, interval, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
+// callOnce invokes the method without retries, e.g. for the tx broadcasts
+func (c *Client) callOnce(ctx context.Context, method string, args interface{}, result interface{}) error {
+	return c.transport.call(ctx, method, args, result)
+}
+
+func isRetryable(err error) bool {
+	if _, ok := err.(*RPCError); ok {
+		return false
+	}
+	return err != context.Canceled && err != context.DeadlineExceeded && err != ErrClientClosed
+}
+
+// heightOrPinned returns the given height, or the pinned height if the given height is zero
+func (c *Client) heightOrPinned(height uint64) uint64 {
+	if height == 0 {
+		return c.pinnedHeight
+	}
+	return height
+}
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/types"
+	trpc "github.com/dnerochain/dnero/rpc"
+)
+
+// testNode serves the JSON-RPC calls of the tests over both HTTP and WebSocket
+type testNode struct {
+	heights  chan uint64
+	sequence uint64
+}
+
+type testRequest struct {
+	ID     uint64            `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func (n *testNode) handle(data []byte) []byte {
+	req := &testRequest{}
+	if err := json.Unmarshal(data, req); err != nil {
+		panic(err)
+	}
+
+	var result interface{}
+	var rpcErr *RPCError
+	switch req.Method {
+	case "dnero.GetStatus":
+		result = trpc.GetStatusResult{ChainID: "testchain", LatestFinalizedBlockHeight: common.JSONUint64(120)}
+	case "dnero.GetAccount":
+		args := trpc.GetAccountArgs{}
+		if err := json.Unmarshal(req.Params[0], &args); err != nil {
+			panic(err)
+		}
+		if args.Address == "" {
+			rpcErr = &RPCError{Code: -32000, Message: "Address must be specified"}
+			break
+		}
+		if !args.Preview {
+			n.heights <- uint64(args.Height)
+		}
+		result = trpc.GetAccountResult{Address: args.Address, Account: &types.Account{Sequence: n.sequence}}
+	case "dnero.Subscribe":
+		result = trpc.SubscribeResult{Subscription: "0x01"}
+	case "dnero.Unsubscribe":
+		result = trpc.UnsubscribeResult{Unsubscribed: true}
+	default:
+		rpcErr = &RPCError{Code: -32601, Message: "Method not found"}
+	}
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if rpcErr != nil {
+		resp["error"] = rpcErr
+	} else {
+		resp["result"] = result
+	}
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		panic(err)
+	}
+	return msg
+}
+
+func (n *testNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(n.handle(data))
+}
+
+func (n *testNode) serveWS(ws *websocket.Conn) {
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			return
+		}
+		if err := websocket.Message.Send(ws, n.handle(data)); err != nil {
+			return
+		}
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	service := &testNode{heights: make(chan uint64, 10), sequence: 7}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.Nil(err)
+	ctx := context.Background()
+
+	chainID, err := client.ChainID(ctx)
+	require.Nil(err)
+	assert.Equal("testchain", chainID)
+
+	address := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	_, err = client.GetAccount(ctx, trpc.GetAccountArgs{Address: address.Hex()})
+	require.Nil(err)
+	assert.Equal(uint64(0), <-service.heights)
+
+	// Height pinning
+	pinned, err := client.AtFinalizedHeight(ctx)
+	require.Nil(err)
+	assert.Equal(uint64(120), pinned.PinnedHeight())
+	_, err = pinned.GetAccount(ctx, trpc.GetAccountArgs{Address: address.Hex()})
+	require.Nil(err)
+	assert.Equal(uint64(120), <-service.heights)
+	_, err = pinned.GetAccount(ctx, trpc.GetAccountArgs{Address: address.Hex(), Height: 100})
+	require.Nil(err)
+	assert.Equal(uint64(100), <-service.heights)
+	assert.Equal(uint64(0), client.PinnedHeight())
+
+	sequence, err := client.NextSequence(ctx, address)
+	require.Nil(err)
+	assert.Equal(uint64(8), sequence)
+
+	// Errors returned by the node
+	_, err = client.GetAccount(ctx, trpc.GetAccountArgs{})
+	require.NotNil(err)
+	_, ok := err.(*RPCError)
+	assert.True(ok)
+	assert.True(strings.Contains(err.Error(), "Address must be specified"))
+}
+
+func TestHTTPClientRetries(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	service := &testNode{heights: make(chan uint64, 10)}
+	var numRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&numRequests, 1) <= 2 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		service.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RetryInterval = time.Millisecond
+	client, err := NewClientWithConfig(server.URL, config)
+	require.Nil(err)
+
+	status, err := client.GetStatus(context.Background())
+	require.Nil(err)
+	assert.Equal("testchain", status.ChainID)
+	assert.Equal(int32(3), atomic.LoadInt32(&numRequests))
+
+	// The errors returned by the node are not retried
+	_, err = client.GetAccount(context.Background(), trpc.GetAccountArgs{})
+	assert.NotNil(err)
+	assert.Equal(int32(4), atomic.LoadInt32(&numRequests))
+
+	// Give up after the maximum number of retries
+	atomic.StoreInt32(&numRequests, 0)
+	config.MaxRetries = 1
+	client, err = NewClientWithConfig(server.URL, config)
+	require.Nil(err)
+	_, err = client.GetStatus(context.Background())
+	assert.NotNil(err)
+	assert.Equal(int32(2), atomic.LoadInt32(&numRequests))
+}
+
+func TestWSClientSubscription(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	service := &testNode{heights: make(chan uint64, 10)}
+	serverConns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		serverConns <- ws
+		service.serveWS(ws)
+	}))
+	defer server.Close()
+
+	client, err := NewClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	require.Nil(err)
+	ctx := context.Background()
+	serverConn := <-serverConns
+
+	status, err := client.GetStatus(ctx)
+	require.Nil(err)
+	assert.Equal("testchain", status.ChainID)
+
+	sub, err := client.SubscribeFinalizedBlocks(ctx, false)
+	require.Nil(err)
+	assert.Equal("0x01", sub.ID())
+
+	notification := `{"jsonrpc":"2.0","method":"dnero.subscription","params":{"subscription":"0x01","result":{"height":"12"}}}`
+	require.Nil(websocket.Message.Send(serverConn, []byte(notification)))
+	select {
+	case result := <-sub.Notifications():
+		block := &trpc.GetBlockResultInner{}
+		require.Nil(json.Unmarshal(result, block))
+		assert.Equal(common.JSONUint64(12), block.Height)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the notification")
+	}
+
+	require.Nil(sub.Unsubscribe(ctx))
+	_, ok := <-sub.Notifications()
+	assert.False(ok)
+
+	// Subscriptions terminate once the connection is closed
+	sub, err = client.SubscribeNewHeads(ctx, false)
+	require.Nil(err)
+	client.Close()
+	select {
+	case err := <-sub.Err():
+		assert.NotNil(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the subscription to terminate")
+	}
+	_, err = client.GetStatus(ctx)
+	assert.Equal(ErrClientClosed, err)
+}
+
+func TestSubscribeRequiresWebSocket(t *testing.T) {
+	client, err := NewClient("http://localhost:16888/rpc")
+	require.Nil(t, err)
+	_, err = client.SubscribeNewHeads(context.Background(), false)
+	assert.NotNil(t, err)
+}
+
+func TestSignTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	privKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	signer := NewPrivateKeySigner(privKey)
+	recipient := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	sendTx := &types.SendTx{
+		Fee:     types.NewCoins(0, 1000000000000),
+		Inputs:  []types.TxInput{{Address: signer.Address(), Coins: types.NewCoins(10, 1000000000000), Sequence: 1}},
+		Outputs: []types.TxOutput{{Address: recipient, Coins: types.NewCoins(10, 0)}},
+	}
+	require.Nil(SignTx("testchain", sendTx, signer))
+	assert.True(sendTx.Inputs[0].Signature.Verify(sendTx.SignBytes("testchain"), signer.Address()))
+
+	// Only the accounts involved can sign
+	otherKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	assert.NotNil(SignTx("testchain", sendTx, NewPrivateKeySigner(otherKey)))
+
+	// The source and the target sign the ServicePaymentTx separately
+	targetSigner := NewPrivateKeySigner(otherKey)
+	paymentTx := &types.ServicePaymentTx{
+		Fee:    types.NewCoins(0, 1000000000000),
+		Source: types.TxInput{Address: signer.Address(), Coins: types.NewCoins(0, 100)},
+		Target: types.TxInput{Address: targetSigner.Address(), Sequence: 1},
+	}
+	require.Nil(SignTx("testchain", paymentTx, signer))
+	require.Nil(SignTx("testchain", paymentTx, targetSigner))
+	assert.True(paymentTx.Source.Signature.Verify(paymentTx.SourceSignBytes("testchain"), signer.Address()))
+	assert.True(paymentTx.Target.Signature.Verify(paymentTx.TargetSignBytes("testchain"), targetSigner.Address()))
+}
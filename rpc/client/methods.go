@@ -0,0 +1,403 @@
+package client
+
+import (
+	"context"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/rpc"
+)
+
+// ------------------------------- Node -----------------------------------
+
+// GetVersion returns the version of the node
+func (c *Client) GetVersion(ctx context.Context) (*rpc.GetVersionResult, error) {
+	result := &rpc.GetVersionResult{}
+	err := c.call(ctx, "dnero.GetVersion", rpc.GetVersionArgs{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStatus returns the status of the node, e.g. the latest finalized block
+func (c *Client) GetStatus(ctx context.Context) (*rpc.GetStatusResult, error) {
+	result := &rpc.GetStatusResult{}
+	err := c.call(ctx, "dnero.GetStatus", rpc.GetStatusArgs{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPeers returns the IDs of the peers connected to the node
+func (c *Client) GetPeers(ctx context.Context, args rpc.GetPeersArgs) (*rpc.GetPeersResult, error) {
+	result := &rpc.GetPeersResult{}
+	err := c.call(ctx, "dnero.GetPeers", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPeerURLs returns the URLs of the peers connected to the node
+func (c *Client) GetPeerURLs(ctx context.Context, args rpc.GetPeersArgs) (*rpc.GetPeerURLsResult, error) {
+	result := &rpc.GetPeerURLsResult{}
+	err := c.call(ctx, "dnero.GetPeerURLs", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSentryInfo returns the BLS key and the proof of possession of the node for staking as a sentry
+func (c *Client) GetSentryInfo(ctx context.Context) (*rpc.GetSentryInfoResult, error) {
+	result := &rpc.GetSentryInfoResult{}
+	err := c.call(ctx, "dnero.GetSentryInfo", rpc.GetSentryInfoArgs{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStateAvailability returns the lowest height whose full state is present on the node
+func (c *Client) GetStateAvailability(ctx context.Context) (*rpc.GetStateAvailabilityResult, error) {
+	result := &rpc.GetStateAvailabilityResult{}
+	err := c.call(ctx, "dnero.GetStateAvailability", rpc.GetStateAvailabilityArgs{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ------------------------------- Accounts -----------------------------------
+
+// GetAccount returns the account at the given height, the pinned height, or the latest finalized height
+func (c *Client) GetAccount(ctx context.Context, args rpc.GetAccountArgs) (*rpc.GetAccountResult, error) {
+	if !args.Preview {
+		args.Height = common.JSONUint64(c.heightOrPinned(uint64(args.Height)))
+	}
+	// The result embeds the account, whose JSON decoder is promoted to the result and fills
+	// the account only
+	result := &rpc.GetAccountResult{Account: &types.Account{}}
+	err := c.call(ctx, "dnero.GetAccount", args, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Address = args.Address
+	return result, nil
+}
+
+// GetSplitRule returns the split rule of the resource
+func (c *Client) GetSplitRule(ctx context.Context, args rpc.GetSplitRuleArgs) (*rpc.GetSplitRuleResult, error) {
+	result := &rpc.GetSplitRuleResult{}
+	err := c.call(ctx, "dnero.GetSplitRule", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCode returns the code of the smart contract at the given height, the pinned height, or the latest height
+func (c *Client) GetCode(ctx context.Context, args rpc.GetCodeArgs) (*rpc.GetCodeResult, error) {
+	args.Height = common.JSONUint64(c.heightOrPinned(uint64(args.Height)))
+	result := &rpc.GetCodeResult{}
+	err := c.call(ctx, "dnero.GetCode", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStorageAt returns the value of the contract storage slot at the given height, the pinned height, or the latest height
+func (c *Client) GetStorageAt(ctx context.Context, args rpc.GetStorageAtArgs) (*rpc.GetStorageAtResult, error) {
+	args.Height = common.JSONUint64(c.heightOrPinned(uint64(args.Height)))
+	result := &rpc.GetStorageAtResult{}
+	err := c.call(ctx, "dnero.GetStorageAt", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAccountProof returns the account and its merkle proof at the given height, the pinned height, or the latest finalized height
+func (c *Client) GetAccountProof(ctx context.Context, args rpc.GetAccountProofArgs) (*rpc.GetAccountProofResult, error) {
+	args.Height = common.JSONUint64(c.heightOrPinned(uint64(args.Height)))
+	result := &rpc.GetAccountProofResult{}
+	err := c.call(ctx, "dnero.GetAccountProof", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStorageProof returns the contract storage slots and their merkle proofs at the given height, the pinned height, or the latest finalized height
+func (c *Client) GetStorageProof(ctx context.Context, args rpc.GetStorageProofArgs) (*rpc.GetStorageProofResult, error) {
+	args.Height = common.JSONUint64(c.heightOrPinned(uint64(args.Height)))
+	result := &rpc.GetStorageProofResult{}
+	err := c.call(ctx, "dnero.GetStorageProof", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetVCPProof returns the merkle proof of the validator candidate pool of the block
+func (c *Client) GetVCPProof(ctx context.Context, args rpc.GetVCPProofArgs) (*rpc.GetVCPProofResult, error) {
+	result := &rpc.GetVCPProofResult{}
+	err := c.call(ctx, "dnero.GetVCPProof", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ------------------------------- Blocks and transactions -----------------------------------
+
+// GetBlock returns the block with the given hash
+func (c *Client) GetBlock(ctx context.Context, args rpc.GetBlockArgs) (*rpc.GetBlockResult, error) {
+	result := &rpc.GetBlockResult{}
+	err := c.call(ctx, "dnero.GetBlock", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlockByHeight returns the block at the given height
+func (c *Client) GetBlockByHeight(ctx context.Context, args rpc.GetBlockByHeightArgs) (*rpc.GetBlockResult, error) {
+	result := &rpc.GetBlockResult{}
+	err := c.call(ctx, "dnero.GetBlockByHeight", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlocksByRange returns the blocks within the height range
+func (c *Client) GetBlocksByRange(ctx context.Context, args rpc.GetBlocksByRangeArgs) (*rpc.GetBlocksResult, error) {
+	result := &rpc.GetBlocksResult{}
+	err := c.call(ctx, "dnero.GetBlocksByRange", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransaction returns the transaction with the given hash
+func (c *Client) GetTransaction(ctx context.Context, args rpc.GetTransactionArgs) (*rpc.GetTransactionResult, error) {
+	result := &rpc.GetTransactionResult{}
+	err := c.call(ctx, "dnero.GetTransaction", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPendingTransactions returns the hashes of the transactions in the mempool
+func (c *Client) GetPendingTransactions(ctx context.Context) (*rpc.GetPendingTransactionsResult, error) {
+	result := &rpc.GetPendingTransactionsResult{}
+	err := c.call(ctx, "dnero.GetPendingTransactions", rpc.GetPendingTransactionsArgs{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAccountTransactions returns a page of the finalized transactions which involve the account
+func (c *Client) GetAccountTransactions(ctx context.Context, args rpc.GetAccountTransactionsArgs) (*rpc.GetAccountTransactionsResult, error) {
+	result := &rpc.GetAccountTransactionsResult{}
+	err := c.call(ctx, "dnero.GetAccountTransactions", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetLogs returns the logs emitted by the finalized transactions which match the filter
+func (c *Client) GetLogs(ctx context.Context, args rpc.GetLogsArgs) (*rpc.GetLogsResult, error) {
+	result := &rpc.GetLogsResult{}
+	err := c.call(ctx, "dnero.GetLogs", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ------------------------------- Validators and stakes -----------------------------------
+
+// GetVcpByHeight returns the validator candidate pool at the given height
+func (c *Client) GetVcpByHeight(ctx context.Context, args rpc.GetVcpByHeightArgs) (*rpc.GetVcpResult, error) {
+	result := &rpc.GetVcpResult{}
+	err := c.call(ctx, "dnero.GetVcpByHeight", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetScpByHeight returns the sentry candidate pool at the given height
+func (c *Client) GetScpByHeight(ctx context.Context, args rpc.GetScpByHeightArgs) (*rpc.GetScpResult, error) {
+	result := &rpc.GetScpResult{}
+	err := c.call(ctx, "dnero.GetScpByHeight", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEenpByHeight returns the elite edge node pool at the given height
+func (c *Client) GetEenpByHeight(ctx context.Context, args rpc.GetEenpByHeightArgs) (*rpc.GetEenpResult, error) {
+	result := &rpc.GetEenpResult{}
+	err := c.call(ctx, "dnero.GetEenpByHeight", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStakeRewardDistributionByHeight returns the stake reward distribution rules at the given height
+func (c *Client) GetStakeRewardDistributionByHeight(ctx context.Context, args rpc.GetStakeRewardDistributionRuleSetByHeightArgs) (*rpc.GetStakeRewardDistributionRuleSetResult, error) {
+	result := &rpc.GetStakeRewardDistributionRuleSetResult{}
+	err := c.call(ctx, "dnero.GetStakeRewardDistributionByHeight", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEliteEdgeNodeStakeReturnsByHeight returns the elite edge node stakes returned at the given height
+func (c *Client) GetEliteEdgeNodeStakeReturnsByHeight(ctx context.Context, args rpc.GetEliteEdgeNodeStakeReturnsByHeightArgs) (*rpc.GetEliteEdgeNodeStakeReturnsByHeightResult, error) {
+	result := &rpc.GetEliteEdgeNodeStakeReturnsByHeightResult{}
+	err := c.call(ctx, "dnero.GetEliteEdgeNodeStakeReturnsByHeight", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAllPendingEliteEdgeNodeStakeReturns returns all the pending elite edge node stake returns
+func (c *Client) GetAllPendingEliteEdgeNodeStakeReturns(ctx context.Context) (*rpc.GetAllPendingEliteEdgeNodeStakeReturnsResult, error) {
+	result := &rpc.GetAllPendingEliteEdgeNodeStakeReturnsResult{}
+	err := c.call(ctx, "dnero.GetAllPendingEliteEdgeNodeStakeReturns", rpc.GetAllPendingEliteEdgeNodeStakeReturnsArgs{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ------------------------------- Smart contracts -----------------------------------
+
+// CallSmartContract executes the smart contract transaction without modifying the state
+func (c *Client) CallSmartContract(ctx context.Context, args rpc.CallSmartContractArgs) (*rpc.CallSmartContractResult, error) {
+	result := &rpc.CallSmartContractResult{}
+	err := c.call(ctx, "dnero.CallSmartContract", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EstimateGas returns the minimum gas limit of the smart contract transaction and a suggested gas price
+func (c *Client) EstimateGas(ctx context.Context, args rpc.EstimateGasArgs) (*rpc.EstimateGasResult, error) {
+	result := &rpc.EstimateGasResult{}
+	err := c.call(ctx, "dnero.EstimateGas", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SimulateTransaction executes the transaction of any type without modifying the state, and returns its effect
+func (c *Client) SimulateTransaction(ctx context.Context, args rpc.SimulateTransactionArgs) (*rpc.SimulateTransactionResult, error) {
+	result := &rpc.SimulateTransactionResult{}
+	err := c.call(ctx, "dnero.SimulateTransaction", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TraceTransaction replays the committed smart contract transaction and returns the execution traces
+func (c *Client) TraceTransaction(ctx context.Context, args rpc.TraceTransactionArgs) (*rpc.TraceResult, error) {
+	result := &rpc.TraceResult{}
+	err := c.call(ctx, "dnero.TraceTransaction", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TraceCall executes the smart contract transaction without modifying the state, and returns the execution traces
+func (c *Client) TraceCall(ctx context.Context, args rpc.TraceCallArgs) (*rpc.TraceResult, error) {
+	result := &rpc.TraceResult{}
+	err := c.call(ctx, "dnero.TraceCall", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ------------------------------- Backups -----------------------------------
+
+// BackupSnapshot dumps a snapshot of the state on the node
+func (c *Client) BackupSnapshot(ctx context.Context, args rpc.BackupSnapshotArgs) (*rpc.BackupSnapshotResult, error) {
+	result := &rpc.BackupSnapshotResult{}
+	err := c.callOnce(ctx, "dnero.BackupSnapshot", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BackupChain dumps the blocks within the height range on the node
+func (c *Client) BackupChain(ctx context.Context, args rpc.BackupChainArgs) (*rpc.BackupChainResult, error) {
+	result := &rpc.BackupChainResult{}
+	err := c.callOnce(ctx, "dnero.BackupChain", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BackupChainCorrection dumps the chain correction data on the node
+func (c *Client) BackupChainCorrection(ctx context.Context, args rpc.BackupChainCorrectionArgs) (*rpc.BackupChainCorrectionResult, error) {
+	result := &rpc.BackupChainCorrectionResult{}
+	err := c.callOnce(ctx, "dnero.BackupChainCorrection", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ------------------------------- Peer Bans -----------------------------------
+
+// ListBannedPeers returns the peers currently banned by the node
+func (c *Client) ListBannedPeers(ctx context.Context) (*rpc.ListBannedPeersResult, error) {
+	result := &rpc.ListBannedPeersResult{}
+	err := c.call(ctx, "dnero.ListBannedPeers", rpc.ListBannedPeersArgs{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BanPeer bans the peer on the node
+func (c *Client) BanPeer(ctx context.Context, args rpc.BanPeerArgs) (*rpc.BanPeerResult, error) {
+	result := &rpc.BanPeerResult{}
+	err := c.callOnce(ctx, "dnero.BanPeer", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UnbanPeer lifts the ban of the peer on the node
+func (c *Client) UnbanPeer(ctx context.Context, args rpc.UnbanPeerArgs) (*rpc.UnbanPeerResult, error) {
+	result := &rpc.UnbanPeerResult{}
+	err := c.callOnce(ctx, "dnero.UnbanPeer", args, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
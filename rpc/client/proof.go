@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/rlp"
+	"github.com/dnerochain/dnero/rpc"
+	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/dnerochain/dnero/store/trie"
+)
+
+// VerifyAccountProof verifies the merkle proof of the account against the given state root, and returns
+// the proven account. It returns nil if the proof shows that the account does not exist.
+func VerifyAccountProof(stateHash common.Hash, address common.Address, proof []hexutil.Bytes) (*types.Account, error) {
+	account, _, err := verifyAccountProof(stateHash, address, proof)
+	return account, err
+}
+
+// VerifyStorageProof verifies the merkle proof of the storage slot against the given storage root
+// of an account, and returns the proven value of the slot.
+func VerifyStorageProof(storageRoot common.Hash, slot common.Hash, proof []hexutil.Bytes) (common.Hash, error) {
+	if storageRoot == (common.Hash{}) || storageRoot == core.EmptyRootHash {
+		return common.Hash{}, nil // empty storage
+	}
+
+	enc, err := verifyProof(storageRoot, slot[:], proof)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(enc) == 0 {
+		return common.Hash{}, nil
+	}
+	_, content, _, err := rlp.Split(enc)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("Failed to decode the proven storage value: %v", err)
+	}
+	return common.BytesToHash(content), nil
+}
+
+// VerifyGetAccountProofResult verifies the result of the GetAccountProof RPC call against the trusted
+// state root, and returns the proven account.
+func VerifyGetAccountProofResult(stateHash common.Hash, result *rpc.GetAccountProofResult) (*types.Account, error) {
+	if result.StateHash != stateHash {
+		return nil, fmt.Errorf("State hash mismatch, expected: %v, returned: %v", stateHash.Hex(), result.StateHash.Hex())
+	}
+	account, raw, err := verifyAccountProof(stateHash, result.Address, result.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(raw, result.Account) {
+		return nil, errors.New("Returned account does not match the proof")
+	}
+	return account, nil
+}
+
+// VerifyGetStorageProofResult verifies the result of the GetStorageProof RPC call against the trusted
+// state root, and returns the proven values of the storage slots.
+func VerifyGetStorageProofResult(stateHash common.Hash, result *rpc.GetStorageProofResult) (map[common.Hash]common.Hash, error) {
+	account, err := VerifyGetAccountProofResult(stateHash, &result.GetAccountProofResult)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("Account with address %v does not exist", result.Address.Hex())
+	}
+	if account.Root != result.StorageRoot {
+		return nil, fmt.Errorf("Storage root mismatch, proven: %v, returned: %v", account.Root.Hex(), result.StorageRoot.Hex())
+	}
+
+	values := make(map[common.Hash]common.Hash)
+	for _, sp := range result.StorageProofs {
+		value, err := VerifyStorageProof(account.Root, sp.Slot, sp.Proof)
+		if err != nil {
+			return nil, err
+		}
+		if value != sp.Value {
+			return nil, fmt.Errorf("Returned value of slot %v does not match the proof", sp.Slot.Hex())
+		}
+		values[sp.Slot] = value
+	}
+	return values, nil
+}
+
+func verifyAccountProof(stateHash common.Hash, address common.Address, proof []hexutil.Bytes) (*types.Account, []byte, error) {
+	raw, err := verifyProof(stateHash, state.AccountKey(address), proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil, nil
+	}
+
+	account := &types.Account{}
+	if err := types.FromBytes(raw, account); err != nil {
+		return nil, nil, fmt.Errorf("Failed to decode the proven account: %v", err)
+	}
+	return account, raw, nil
+}
+
+func verifyProof(root common.Hash, key []byte, proof []hexutil.Bytes) ([]byte, error) {
+	proofDb := backend.NewMemDatabase()
+	for _, node := range proof {
+		proofDb.Put(crypto.Keccak256(node), node)
+	}
+	value, _, err := trie.VerifyProof(root, key, proofDb)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid merkle proof: %v", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/store/database/backend"
+)
+
+type testProof []hexutil.Bytes
+
+func (p *testProof) Put(key []byte, value []byte) error {
+	*p = append(*p, value)
+	return nil
+}
+
+func TestVerifyProofs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	contractAddr := common.HexToAddress("0x00000000000000000000000000000000000000ab")
+	otherAddr := common.HexToAddress("0x00000000000000000000000000000000000000cd")
+	slot1, value1 := common.HexToHash("0x01"), common.HexToHash("0x1234")
+	slot2 := common.HexToHash("0x02")
+
+	db := backend.NewMemDatabase()
+	sv := state.NewStoreView(1, common.Hash{}, db)
+	account := types.NewAccount(contractAddr)
+	account.Balance = types.NewCoins(100, 200)
+	sv.SetAccount(contractAddr, account)
+	sv.SetState(contractAddr, slot1, value1)
+	stateHash := sv.Save()
+
+	sv = state.NewStoreView(1, stateHash, db)
+	account = sv.GetAccount(contractAddr)
+
+	accountProof := &testProof{}
+	require.Nil(sv.ProveAccount(contractAddr, accountProof))
+	provenAccount, err := VerifyAccountProof(stateHash, contractAddr, *accountProof)
+	require.Nil(err)
+	require.NotNil(provenAccount)
+	assert.Equal(account.Root, provenAccount.Root)
+	assert.Equal(big.NewInt(200), provenAccount.Balance.DTokenWei)
+
+	// proof of absence
+	absenceProof := &testProof{}
+	require.Nil(sv.ProveAccount(otherAddr, absenceProof))
+	provenAccount, err = VerifyAccountProof(stateHash, otherAddr, *absenceProof)
+	assert.Nil(err)
+	assert.Nil(provenAccount)
+
+	// the proof does not match the state root
+	_, err = VerifyAccountProof(common.HexToHash("0xff"), contractAddr, *accountProof)
+	assert.NotNil(err)
+
+	storageProof := &testProof{}
+	require.Nil(sv.ProveStorage(contractAddr, slot1, storageProof))
+	value, err := VerifyStorageProof(account.Root, slot1, *storageProof)
+	assert.Nil(err)
+	assert.Equal(value1, value)
+
+	storageProof = &testProof{}
+	require.Nil(sv.ProveStorage(contractAddr, slot2, storageProof))
+	value, err = VerifyStorageProof(account.Root, slot2, *storageProof)
+	assert.Nil(err)
+	assert.Equal(common.Hash{}, value)
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/dnerochain/dnero/rpc"
+)
+
+// subscriptionBufferSize is the number of notifications buffered for a subscription. The
+// notifications are dropped if the subscriber falls behind.
+const subscriptionBufferSize = 256
+
+// Subscription receives the notifications of a WebSocket subscription. The notification results
+// decode into rpc.GetBlockResultInner for the "newHeads" and "finalizedBlocks" subscriptions, into
+// rpc.Tx for the "pendingTransactions" subscriptions, and into rpc.LogEntry for the "logs"
+// subscriptions.
+type Subscription struct {
+	id        string
+	transport *wsTransport
+
+	notifications chan json.RawMessage
+	err           chan error
+	once          *sync.Once
+}
+
+func newSubscription(id string, transport *wsTransport) *Subscription {
+	return &Subscription{
+		id:            id,
+		transport:     transport,
+		notifications: make(chan json.RawMessage, subscriptionBufferSize),
+		err:           make(chan error, 1),
+		once:          &sync.Once{},
+	}
+}
+
+// ID returns the subscription ID assigned by the node
+func (sub *Subscription) ID() string {
+	return sub.id
+}
+
+// Notifications returns the channel of the notification results. The channel is closed once the
+// subscription terminates.
+func (sub *Subscription) Notifications() <-chan json.RawMessage {
+	return sub.notifications
+}
+
+// Err returns the channel which receives the error that terminates the subscription, e.g. the
+// WebSocket connection is lost. It receives nothing if the subscription is unsubscribed.
+func (sub *Subscription) Err() <-chan error {
+	return sub.err
+}
+
+// Unsubscribe cancels the subscription on the node
+func (sub *Subscription) Unsubscribe(ctx context.Context) error {
+	sub.transport.removeSubscription(sub.id)
+	result := &rpc.UnsubscribeResult{}
+	err := sub.transport.call(ctx, "dnero.Unsubscribe", rpc.UnsubscribeArgs{Subscription: sub.id}, result)
+	sub.terminate(nil)
+	return err
+}
+
+func (sub *Subscription) deliver(result json.RawMessage) {
+	select {
+	case sub.notifications <- result:
+	default:
+		logger.Warnf("Notification buffer of subscription %v is full, dropping the notification", sub.id)
+	}
+}
+
+func (sub *Subscription) terminate(err error) {
+	sub.once.Do(func() {
+		if err != nil {
+			sub.err <- err
+		}
+		close(sub.notifications)
+	})
+}
+
+// ------------------------------- Subscribe -----------------------------------
+
+// Subscribe subscribes to the given type of notifications. Subscriptions are only supported
+// over WebSocket.
+func (c *Client) Subscribe(ctx context.Context, typ string, filter rpc.SubscriptionFilter) (*Subscription, error) {
+	transport, ok := c.transport.(*wsTransport)
+	if !ok {
+		return nil, errors.New("Subscriptions are only supported over WebSocket")
+	}
+
+	result := &rpc.SubscribeResult{}
+	err := transport.call(ctx, "dnero.Subscribe", rpc.SubscribeArgs{Type: typ, Filter: filter}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// A notification may arrive before the subscription is registered, in which case it is dropped
+	sub := newSubscription(result.Subscription, transport)
+	transport.addSubscription(sub)
+	return sub, nil
+}
+
+// SubscribeNewHeads subscribes to the newly proposed blocks
+func (c *Client) SubscribeNewHeads(ctx context.Context, includeTxs bool) (*Subscription, error) {
+	return c.Subscribe(ctx, rpc.SubscriptionNewHeads, rpc.SubscriptionFilter{IncludeTransactions: includeTxs})
+}
+
+// SubscribeFinalizedBlocks subscribes to the finalized blocks
+func (c *Client) SubscribeFinalizedBlocks(ctx context.Context, includeTxs bool) (*Subscription, error) {
+	return c.Subscribe(ctx, rpc.SubscriptionFinalizedBlocks, rpc.SubscriptionFilter{IncludeTransactions: includeTxs})
+}
+
+// SubscribePendingTransactions subscribes to the transactions added to the mempool
+func (c *Client) SubscribePendingTransactions(ctx context.Context) (*Subscription, error) {
+	return c.Subscribe(ctx, rpc.SubscriptionPendingTransactions, rpc.SubscriptionFilter{})
+}
+
+// SubscribeLogs subscribes to the logs emitted by the transactions in the finalized blocks
+func (c *Client) SubscribeLogs(ctx context.Context, addresses []string, topics [][]string) (*Subscription, error) {
+	return c.Subscribe(ctx, rpc.SubscriptionLogs, rpc.SubscriptionFilter{Addresses: addresses, Topics: topics})
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	jsonRPCVersion                 = "2.0"
+	subscriptionNotificationMethod = "dnero.subscription"
+)
+
+// ErrClientClosed is returned for the calls made after the client is closed, or after the
+// WebSocket connection is lost
+var ErrClientClosed = errors.New("Client is closed")
+
+// RPCError is the error returned by the node for a call. Unlike the transport errors, it is
+// never retried.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %v: %v", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	Version string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcMessage is either the response to a call, or a subscription notification
+type rpcMessage struct {
+	Version string          `json:"jsonrpc"`
+	ID      *uint64         `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type subscriptionParams struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+func newRequest(id uint64, method string, args interface{}) ([]byte, error) {
+	return json.Marshal(rpcRequest{
+		Version: jsonRPCVersion,
+		ID:      id,
+		Method:  method,
+		Params:  []interface{}{args},
+	})
+}
+
+func decodeResult(msg *rpcMessage, result interface{}) error {
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if result == nil || len(msg.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(msg.Result, result)
+}
+
+// transport sends the JSON-RPC calls to the node
+type transport interface {
+	call(ctx context.Context, method string, args interface{}, result interface{}) error
+	close() error
+}
+
+//
+// --------------------- HTTP transport -------------------------
+//
+
+type httpTransport struct {
+	url    string
+	client *http.Client
+	nextID uint64 // accessed atomically
+}
+
+func newHTTPTransport(url string, timeout time.Duration) *httpTransport {
+	return &httpTransport{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (t *httpTransport) call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	body, err := newRequest(atomic.AddUint64(&t.nextID, 1), method, args)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	msg := &rpcMessage{}
+	if err := json.Unmarshal(respBody, msg); err != nil {
+		return fmt.Errorf("Failed to decode the response, status: %v, error: %v", resp.Status, err)
+	}
+	return decodeResult(msg, result)
+}
+
+func (t *httpTransport) close() error {
+	return nil
+}
+
+//
+// --------------------- WebSocket transport -------------------------
+//
+
+// wsTransport multiplexes the calls over a single WebSocket connection, and routes the
+// subscription notifications to the subscriptions
+type wsTransport struct {
+	ws     *websocket.Conn
+	nextID uint64 // accessed atomically
+
+	mu            *sync.Mutex
+	pending       map[uint64]chan *rpcMessage
+	subscriptions map[string]*Subscription
+	err           error
+
+	sendMu *sync.Mutex
+	done   chan struct{}
+}
+
+func newWSTransport(url string) (*wsTransport, error) {
+	ws, err := websocket.Dial(url, "", url)
+	if err != nil {
+		return nil, err
+	}
+	t := &wsTransport{
+		ws:            ws,
+		mu:            &sync.Mutex{},
+		pending:       make(map[uint64]chan *rpcMessage),
+		subscriptions: make(map[string]*Subscription),
+		sendMu:        &sync.Mutex{},
+		done:          make(chan struct{}),
+	}
+	go t.readLoop()
+	return t, nil
+}
+
+func (t *wsTransport) call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	id := atomic.AddUint64(&t.nextID, 1)
+	req, err := newRequest(id, method, args)
+	if err != nil {
+		return err
+	}
+
+	respCh := make(chan *rpcMessage, 1)
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return t.err
+	}
+	t.pending[id] = respCh
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	if err := t.send(req); err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-respCh:
+		return decodeResult(msg, result)
+	case <-t.done:
+		return t.closeErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *wsTransport) send(msg []byte) error {
+	t.sendMu.Lock()
+	defer t.sendMu.Unlock()
+	return websocket.Message.Send(t.ws, msg)
+}
+
+func (t *wsTransport) readLoop() {
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(t.ws, &data); err != nil {
+			t.shutdown(err)
+			return
+		}
+		msg := &rpcMessage{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			logger.Warnf("Failed to decode the WebSocket message: %v", err)
+			continue
+		}
+		if msg.Method == subscriptionNotificationMethod {
+			t.dispatchNotification(msg)
+			continue
+		}
+		if msg.ID == nil {
+			continue
+		}
+		t.mu.Lock()
+		respCh, ok := t.pending[*msg.ID]
+		t.mu.Unlock()
+		if ok {
+			respCh <- msg
+		}
+	}
+}
+
+func (t *wsTransport) dispatchNotification(msg *rpcMessage) {
+	params := &subscriptionParams{}
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		logger.Warnf("Failed to decode the subscription notification: %v", err)
+		return
+	}
+	t.mu.Lock()
+	sub, ok := t.subscriptions[params.Subscription]
+	t.mu.Unlock()
+	if ok {
+		sub.deliver(params.Result)
+	}
+}
+
+func (t *wsTransport) addSubscription(sub *Subscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subscriptions[sub.id] = sub
+}
+
+func (t *wsTransport) removeSubscription(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.subscriptions, id)
+}
+
+// shutdown fails the pending calls and terminates the subscriptions once the connection is lost
+func (t *wsTransport) shutdown(err error) {
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return
+	}
+	t.err = ErrClientClosed
+	subs := t.subscriptions
+	t.subscriptions = make(map[string]*Subscription)
+	close(t.done)
+	t.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.terminate(err)
+	}
+}
+
+func (t *wsTransport) closeErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func (t *wsTransport) close() error {
+	err := t.ws.Close()
+	t.shutdown(ErrClientClosed)
+	return err
+}
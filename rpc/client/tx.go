@@ -0,0 +1,371 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/rpc"
+)
+
+// Signer signs the transactions on behalf of an account
+type Signer interface {
+	Address() common.Address
+	Sign(msg common.Bytes) (*crypto.Signature, error)
+}
+
+// PrivateKeySigner signs the transactions with a private key held in memory
+type PrivateKeySigner struct {
+	privKey *crypto.PrivateKey
+}
+
+var _ Signer = (*PrivateKeySigner)(nil)
+
+func NewPrivateKeySigner(privKey *crypto.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{privKey: privKey}
+}
+
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.privKey.PublicKey().Address()
+}
+
+func (s *PrivateKeySigner) Sign(msg common.Bytes) (*crypto.Signature, error) {
+	return s.privKey.Sign(msg)
+}
+
+// ------------------------------- Broadcast -----------------------------------
+
+// BroadcastRawTransaction broadcasts the signed transaction, and waits until it is included in
+// a block. The broadcasts are never retried.
+func (c *Client) BroadcastRawTransaction(ctx context.Context, rawTx common.Bytes) (*rpc.BroadcastRawTransactionResult, error) {
+	result := &rpc.BroadcastRawTransactionResult{}
+	err := c.callOnce(ctx, "dnero.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: hex.EncodeToString(rawTx)}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BroadcastRawTransactionAsync broadcasts the signed transaction without waiting for it to be
+// included in a block
+func (c *Client) BroadcastRawTransactionAsync(ctx context.Context, rawTx common.Bytes) (*rpc.BroadcastRawTransactionAsyncResult, error) {
+	result := &rpc.BroadcastRawTransactionAsyncResult{}
+	err := c.callOnce(ctx, "dnero.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionAsyncArgs{TxBytes: hex.EncodeToString(rawTx)}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BroadcastRawEthTransaction broadcasts the signed Ethereum transaction, and waits until it is
+// included in a block
+func (c *Client) BroadcastRawEthTransaction(ctx context.Context, rawEthTx common.Bytes) (*rpc.BroadcastRawTransactionResult, error) {
+	result := &rpc.BroadcastRawTransactionResult{}
+	err := c.callOnce(ctx, "dnero.BroadcastRawEthTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: hexutil.Encode(rawEthTx)}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BroadcastRawEthTransactionAsync broadcasts the signed Ethereum transaction without waiting for
+// it to be included in a block
+func (c *Client) BroadcastRawEthTransactionAsync(ctx context.Context, rawEthTx common.Bytes) (*rpc.BroadcastRawTransactionAsyncResult, error) {
+	result := &rpc.BroadcastRawTransactionAsyncResult{}
+	err := c.callOnce(ctx, "dnero.BroadcastRawEthTransactionAsync", rpc.BroadcastRawTransactionAsyncArgs{TxBytes: hexutil.Encode(rawEthTx)}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SignAndBroadcastTx signs the transaction with the given signers, and broadcasts it. If async is
+// false, it waits until the transaction is included in a block. It returns the hash of the
+// transaction.
+func (c *Client) SignAndBroadcastTx(ctx context.Context, tx types.Tx, async bool, signers ...Signer) (common.Hash, error) {
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	for _, signer := range signers {
+		if err := SignTx(chainID, tx, signer); err != nil {
+			return common.Hash{}, err
+		}
+	}
+
+	rawTx, err := types.TxToBytes(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if async {
+		_, err = c.BroadcastRawTransactionAsync(ctx, rawTx)
+	} else {
+		_, err = c.BroadcastRawTransaction(ctx, rawTx)
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(rawTx), nil
+}
+
+// ------------------------------- Signing -----------------------------------
+
+type signableTx interface {
+	SignBytes(chainID string) []byte
+	SetSignature(addr common.Address, sig *crypto.Signature) bool
+}
+
+// SignTx signs the transaction on behalf of the signer. For a ServicePaymentTx, the signer
+// signs as the source and/or the target, depending on its address.
+func SignTx(chainID string, tx types.Tx, signer Signer) error {
+	address := signer.Address()
+
+	if sptx, ok := tx.(*types.ServicePaymentTx); ok {
+		signed := false
+		if sptx.Source.Address == address {
+			sig, err := signer.Sign(sptx.SourceSignBytes(chainID))
+			if err != nil {
+				return err
+			}
+			sptx.SetSourceSignature(sig)
+			signed = true
+		}
+		if sptx.Target.Address == address {
+			sig, err := signer.Sign(sptx.TargetSignBytes(chainID))
+			if err != nil {
+				return err
+			}
+			sptx.SetTargetSignature(sig)
+			signed = true
+		}
+		if !signed {
+			return fmt.Errorf("%v is neither the source nor the target of the ServicePaymentTx", address.Hex())
+		}
+		return nil
+	}
+
+	stx, ok := tx.(signableTx)
+	if !ok {
+		return fmt.Errorf("Unsupported transaction type: %T", tx)
+	}
+	sig, err := signer.Sign(stx.SignBytes(chainID))
+	if err != nil {
+		return err
+	}
+	if !stx.SetSignature(address, sig) {
+		return fmt.Errorf("%v is not a signer of the transaction", address.Hex())
+	}
+	return nil
+}
+
+// ------------------------------- Builders -----------------------------------
+
+// The builders below create the unsigned transactions with the sequence of the sending account
+// filled in. The sequence is the one following the account sequence in the screened view, i.e.
+// it takes the pending transactions in the mempool into account. The coinbase and slash
+// transactions are created by the block proposers, and hence have no builders.
+
+// NextSequence returns the sequence for the next transaction of the account
+func (c *Client) NextSequence(ctx context.Context, address common.Address) (uint64, error) {
+	result, err := c.GetAccount(ctx, rpc.GetAccountArgs{Address: address.Hex(), Preview: true})
+	if err != nil {
+		return 0, err
+	}
+	if result.Account == nil {
+		return 0, fmt.Errorf("Account %v is not found", address.Hex())
+	}
+	return result.Account.Sequence + 1, nil
+}
+
+func (c *Client) newTxInput(ctx context.Context, address common.Address, coins types.Coins) (types.TxInput, error) {
+	sequence, err := c.NextSequence(ctx, address)
+	if err != nil {
+		return types.TxInput{}, err
+	}
+	return types.TxInput{
+		Address:  address,
+		Coins:    coins.NoNil(),
+		Sequence: sequence,
+	}, nil
+}
+
+// NewSendTx creates a SendTx which transfers the coins from the sender to the outputs. The sender
+// pays the total amount of the outputs plus the fee.
+func (c *Client) NewSendTx(ctx context.Context, from common.Address, outputs []types.TxOutput, fee types.Coins) (*types.SendTx, error) {
+	total := fee.NoNil()
+	for _, output := range outputs {
+		total = total.Plus(output.Coins.NoNil())
+	}
+	input, err := c.newTxInput(ctx, from, total)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SendTx{
+		Fee:     fee.NoNil(),
+		Inputs:  []types.TxInput{input},
+		Outputs: outputs,
+	}, nil
+}
+
+// NewReserveFundTx creates a ReserveFundTx which reserves the fund of the source account for the
+// off-chain micropayments of the resources
+func (c *Client) NewReserveFundTx(ctx context.Context, source common.Address, fund, collateral types.Coins,
+	resourceIDs []string, duration uint64, fee types.Coins) (*types.ReserveFundTx, error) {
+	input, err := c.newTxInput(ctx, source, fund)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ReserveFundTx{
+		Fee:         fee.NoNil(),
+		Source:      input,
+		Collateral:  collateral.NoNil(),
+		ResourceIDs: resourceIDs,
+		Duration:    duration,
+	}, nil
+}
+
+// NewReleaseFundTx creates a ReleaseFundTx which releases the expired reserved fund
+func (c *Client) NewReleaseFundTx(ctx context.Context, source common.Address, reserveSequence uint64,
+	fee types.Coins) (*types.ReleaseFundTx, error) {
+	input, err := c.newTxInput(ctx, source, types.NewCoins(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &types.ReleaseFundTx{
+		Fee:             fee.NoNil(),
+		Source:          input,
+		ReserveSequence: reserveSequence,
+	}, nil
+}
+
+// NewServicePaymentTx creates a ServicePaymentTx which settles the off-chain payment from the source
+// to the target on-chain. The source signs the payment off-chain, and the target signs and submits
+// the transaction, see SignTx.
+func (c *Client) NewServicePaymentTx(ctx context.Context, source, target common.Address, payment types.Coins,
+	paymentSequence, reserveSequence uint64, resourceID string, fee types.Coins) (*types.ServicePaymentTx, error) {
+	targetInput, err := c.newTxInput(ctx, target, types.NewCoins(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &types.ServicePaymentTx{
+		Fee: fee.NoNil(),
+		Source: types.TxInput{
+			Address: source,
+			Coins:   payment.NoNil(),
+		},
+		Target:          targetInput,
+		PaymentSequence: paymentSequence,
+		ReserveSequence: reserveSequence,
+		ResourceID:      resourceID,
+	}, nil
+}
+
+// NewSplitRuleTx creates a SplitRuleTx which specifies how the payments of the resource are split
+func (c *Client) NewSplitRuleTx(ctx context.Context, initiator common.Address, resourceID string,
+	splits []types.Split, duration uint64, fee types.Coins) (*types.SplitRuleTx, error) {
+	input, err := c.newTxInput(ctx, initiator, types.NewCoins(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &types.SplitRuleTx{
+		Fee:        fee.NoNil(),
+		ResourceID: resourceID,
+		Initiator:  input,
+		Splits:     splits,
+		Duration:   duration,
+	}, nil
+}
+
+// NewSmartContractTx creates a SmartContractTx which calls the contract, or deploys the contract if
+// the to address is empty. If the gas limit is zero, it is estimated with EstimateGas. If the gas
+// price is nil, the gas price suggested by EstimateGas is used.
+func (c *Client) NewSmartContractTx(ctx context.Context, from, to common.Address, value types.Coins,
+	data common.Bytes, gasLimit uint64, gasPrice *big.Int) (*types.SmartContractTx, error) {
+	input, err := c.newTxInput(ctx, from, value)
+	if err != nil {
+		return nil, err
+	}
+	tx := &types.SmartContractTx{
+		From:     input,
+		To:       types.TxOutput{Address: to},
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		Data:     data,
+	}
+	if gasLimit != 0 && gasPrice != nil {
+		return tx, nil
+	}
+
+	if tx.GasPrice == nil {
+		tx.GasPrice = big.NewInt(0)
+	}
+	rawTx, err := types.TxToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	estimation, err := c.EstimateGas(ctx, rpc.EstimateGasArgs{SctxBytes: hex.EncodeToString(rawTx), Preview: true})
+	if err != nil {
+		return nil, err
+	}
+	if gasLimit == 0 {
+		tx.GasLimit = uint64(estimation.GasLimit)
+	}
+	if gasPrice == nil {
+		tx.GasPrice = (*big.Int)(estimation.GasPrice)
+	}
+	return tx, nil
+}
+
+// NewDepositStakeTx creates a DepositStakeTxV1 which deposits the stake from the source to the
+// holder, i.e. a validator, a sentry or an elite edge node, depending on the purpose. For the
+// sentry and elite edge node stakes, the BLS fields need to be filled in with the GetSentryInfo
+// result of the holder before signing.
+func (c *Client) NewDepositStakeTx(ctx context.Context, source, holder common.Address, stake types.Coins,
+	purpose uint8, fee types.Coins) (*types.DepositStakeTxV1, error) {
+	input, err := c.newTxInput(ctx, source, stake)
+	if err != nil {
+		return nil, err
+	}
+	return &types.DepositStakeTxV1{
+		Fee:     fee.NoNil(),
+		Source:  input,
+		Holder:  types.TxOutput{Address: holder},
+		Purpose: purpose,
+	}, nil
+}
+
+// NewWithdrawStakeTx creates a WithdrawStakeTx which withdraws the stake of the source from the holder
+func (c *Client) NewWithdrawStakeTx(ctx context.Context, source, holder common.Address, purpose uint8,
+	fee types.Coins) (*types.WithdrawStakeTx, error) {
+	input, err := c.newTxInput(ctx, source, types.NewCoins(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &types.WithdrawStakeTx{
+		Fee:     fee.NoNil(),
+		Source:  input,
+		Holder:  types.TxOutput{Address: holder},
+		Purpose: purpose,
+	}, nil
+}
+
+// NewStakeRewardDistributionTx creates a StakeRewardDistributionTx which shares the stake rewards
+// of the holder with the beneficiary, in terms of basis points
+func (c *Client) NewStakeRewardDistributionTx(ctx context.Context, holder, beneficiary common.Address,
+	splitBasisPoint uint, fee types.Coins) (*types.StakeRewardDistributionTx, error) {
+	input, err := c.newTxInput(ctx, holder, types.NewCoins(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &types.StakeRewardDistributionTx{
+		Fee:             fee.NoNil(),
+		Holder:          input,
+		Beneficiary:     types.TxOutput{Address: beneficiary},
+		SplitBasisPoint: splitBasisPoint,
+	}, nil
+}
@@ -0,0 +1,724 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/ledger/vm"
+	"github.com/dnerochain/dnero/rpc/lib/rpc-codec/jsonrpc2"
+	"github.com/dnerochain/dnero/version"
+)
+
+// ErrCodeExecutionReverted is returned by eth_call when the execution is reverted, the same code
+// as go-ethereum, so the Ethereum tooling can decode the revert reason from the error data
+const ErrCodeExecutionReverted = 3
+
+// Block tags accepted in place of the block numbers. Since blocks are final once finalized,
+// "latest", "safe" and "finalized" all refer to the latest finalized block.
+const (
+	ethBlockEarliest  = "earliest"
+	ethBlockLatest    = "latest"
+	ethBlockSafe      = "safe"
+	ethBlockFinalized = "finalized"
+	ethBlockPending   = "pending"
+)
+
+var (
+	// ethEmptyUncleHash is the hash of an empty list of uncles, i.e. keccak256(rlp([]))
+	ethEmptyUncleHash = common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")
+
+	// ethEmptyRootHash is the root hash of an empty trie
+	ethEmptyRootHash = common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+)
+
+//
+// EthRPCService serves the Ethereum JSON-RPC methods in the "eth" namespace, e.g. eth_chainId,
+// so wallets and tools like MetaMask, Hardhat and ethers can talk to the node directly. The
+// methods map onto the same ledger state, chain index and tx receipts as the DneroRPCService.
+//
+type EthRPCService struct {
+	service *DneroRPCService
+}
+
+// NetRPCService serves the Ethereum JSON-RPC methods in the "net" namespace
+type NetRPCService struct {
+	service *DneroRPCService
+}
+
+// Web3RPCService serves the Ethereum JSON-RPC methods in the "web3" namespace
+type Web3RPCService struct {
+	service *DneroRPCService
+}
+
+// EthArgs holds the positional params of an Ethereum JSON-RPC call, e.g. ["0x...", "latest"]
+type EthArgs []json.RawMessage
+
+type EthCallObject struct {
+	From     *common.Address `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"` // takes precedence over Data
+}
+
+type EthFilter struct {
+	FromBlock string        `json:"fromBlock"`
+	ToBlock   string        `json:"toBlock"`
+	BlockHash *common.Hash  `json:"blockHash"` // if set, FromBlock and ToBlock are ignored
+	Address   ethStringList `json:"address"`
+	Topics    ethTopics     `json:"topics"`
+}
+
+type EthBlock struct {
+	Number           hexutil.Uint64 `json:"number"`
+	Hash             common.Hash    `json:"hash"`
+	ParentHash       common.Hash    `json:"parentHash"`
+	Nonce            hexutil.Bytes  `json:"nonce"`
+	Sha3Uncles       common.Hash    `json:"sha3Uncles"`
+	LogsBloom        core.Bloom     `json:"logsBloom"`
+	TransactionsRoot common.Hash    `json:"transactionsRoot"`
+	StateRoot        common.Hash    `json:"stateRoot"`
+	ReceiptsRoot     common.Hash    `json:"receiptsRoot"`
+	Miner            common.Address `json:"miner"`
+	Difficulty       hexutil.Uint64 `json:"difficulty"`
+	TotalDifficulty  hexutil.Uint64 `json:"totalDifficulty"`
+	ExtraData        hexutil.Bytes  `json:"extraData"`
+	GasLimit         hexutil.Uint64 `json:"gasLimit"`
+	GasUsed          hexutil.Uint64 `json:"gasUsed"`
+	Timestamp        hexutil.Uint64 `json:"timestamp"`
+	Transactions     []interface{}  `json:"transactions"` // tx hashes, or EthTransactions if requested
+	Uncles           []common.Hash  `json:"uncles"`
+}
+
+type EthTransaction struct {
+	Hash             common.Hash     `json:"hash"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	BlockHash        common.Hash     `json:"blockHash"`
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+	From             common.Address  `json:"from"`
+	To               *common.Address `json:"to"` // nil for contract creation
+	Value            *hexutil.Big    `json:"value"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Input            hexutil.Bytes   `json:"input"`
+	Type             hexutil.Uint64  `json:"type"`
+	ChainID          *hexutil.Big    `json:"chainId"`
+	V                *hexutil.Big    `json:"v,omitempty"` // only for the txs signed by Ethereum wallets
+	R                *hexutil.Big    `json:"r,omitempty"`
+	S                *hexutil.Big    `json:"s,omitempty"`
+}
+
+type EthReceipt struct {
+	TransactionHash   common.Hash     `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint64  `json:"transactionIndex"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	From              common.Address  `json:"from"`
+	To                *common.Address `json:"to"`
+	CumulativeGasUsed hexutil.Uint64  `json:"cumulativeGasUsed"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	Logs              []*EthLog       `json:"logs"`
+	LogsBloom         core.Bloom      `json:"logsBloom"`
+	Status            hexutil.Uint64  `json:"status"`
+	Type              hexutil.Uint64  `json:"type"`
+}
+
+type EthLog struct {
+	Address          common.Address `json:"address"`
+	Topics           []common.Hash  `json:"topics"`
+	Data             hexutil.Bytes  `json:"data"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	LogIndex         hexutil.Uint64 `json:"logIndex"`
+	Removed          bool           `json:"removed"`
+}
+
+// ------------------------------- eth_chainId -----------------------------------
+
+func (e *EthRPCService) ChainId(args *EthArgs, result *hexutil.Big) (err error) {
+	*result = hexutil.Big(*e.service.ethChainID())
+	return nil
+}
+
+// ------------------------------- eth_blockNumber -----------------------------------
+
+func (e *EthRPCService) BlockNumber(args *EthArgs, result *hexutil.Uint64) (err error) {
+	*result = hexutil.Uint64(e.service.consensus.GetLastFinalizedBlock().Height)
+	return nil
+}
+
+// ------------------------------- eth_getBalance -----------------------------------
+
+// GetBalance returns the DToken balance of the account, which is the coin transferred as the
+// value of the Ethereum txs
+func (e *EthRPCService) GetBalance(args *EthArgs, result *hexutil.Big) (err error) {
+	var address common.Address
+	if err = args.param(0, &address, true); err != nil {
+		return err
+	}
+	blockNumber := ethBlockLatest
+	if err = args.param(1, &blockNumber, false); err != nil {
+		return err
+	}
+
+	_, ledgerState, err := e.service.ethStateAt(blockNumber)
+	if err != nil {
+		return err
+	}
+	balance := big.NewInt(0)
+	if account := ledgerState.GetAccount(address); account != nil && account.Balance.DTokenWei != nil {
+		balance = account.Balance.DTokenWei
+	}
+	*result = hexutil.Big(*balance)
+	return nil
+}
+
+// ------------------------------- eth_call -----------------------------------
+
+// Call executes the call on the state at the given block without creating a tx. It does NOT
+// modify the globally consensus state.
+func (e *EthRPCService) Call(args *EthArgs, result *hexutil.Bytes) (err error) {
+	call := &EthCallObject{}
+	if err = args.param(0, call, true); err != nil {
+		return err
+	}
+	blockNumber := ethBlockLatest
+	if err = args.param(1, &blockNumber, false); err != nil {
+		return err
+	}
+
+	parentBlock, ledgerState, err := e.service.ethStateAt(blockNumber)
+	if err != nil {
+		return err
+	}
+	blockHeight := parentBlock.Height + 1
+	chainParams := common.GetChainParams(e.service.chain.ChainID)
+	if blockHeight < chainParams.HeightEnableSmartContract {
+		return fmt.Errorf("Smart contract feature not enabled until block height %v.", chainParams.HeightEnableSmartContract)
+	}
+
+	sctx := call.toSmartContractTx(types.GetMaxGasLimit(chainParams, blockHeight).Uint64(),
+		types.GetMinimumGasPrice(chainParams, blockHeight))
+	vmRet, _, _, vmErr := vm.Execute(parentBlock, sctx, ledgerState)
+	if vmErr != nil {
+		if vm.IsExecutionReverted(vmErr) {
+			message := vmErr.Error()
+			if reason, ok := vm.UnpackRevertReason(vmRet); ok {
+				message = fmt.Sprintf("%v: %v", message, reason)
+			}
+			return &jsonrpc2.Error{Code: ErrCodeExecutionReverted, Message: message, Data: hexutil.Encode(vmRet)}
+		}
+		return vmErr
+	}
+
+	*result = hexutil.Bytes(vmRet)
+	return nil
+}
+
+// ------------------------------- eth_estimateGas -----------------------------------
+
+// EstimateGas returns the minimum gas limit with which the call succeeds. The estimation is
+// made against the state at the given block, same as Call.
+func (e *EthRPCService) EstimateGas(args *EthArgs, result *hexutil.Uint64) (err error) {
+	call := &EthCallObject{}
+	if err = args.param(0, call, true); err != nil {
+		return err
+	}
+	blockNumber := ethBlockLatest
+	if err = args.param(1, &blockNumber, false); err != nil {
+		return err
+	}
+
+	// The gas limit is searched by EstimateGas, the gas price only affects the GASPRICE opcode
+	gasPrice := types.GetMinimumGasPrice(common.GetChainParams(e.service.chain.ChainID),
+		e.service.consensus.GetLastFinalizedBlock().Height+1)
+	sctxBytes, err := types.TxToBytes(call.toSmartContractTx(0, gasPrice))
+	if err != nil {
+		return err
+	}
+
+	estimation := &EstimateGasResult{}
+	err = e.service.EstimateGas(&EstimateGasArgs{
+		SctxBytes: hex.EncodeToString(sctxBytes),
+		Block:     ethBlockSelector(blockNumber),
+	}, estimation)
+	if err != nil {
+		return err
+	}
+
+	*result = hexutil.Uint64(estimation.GasLimit)
+	return nil
+}
+
+// ------------------------------- eth_sendRawTransaction -----------------------------------
+
+// SendRawTransaction submits the RLP encoded and signed Ethereum tx, and returns its Ethereum tx
+// hash without waiting for it to be included in a block
+func (e *EthRPCService) SendRawTransaction(args *EthArgs, result *common.Hash) (err error) {
+	var rawTx hexutil.Bytes
+	if err = args.param(0, &rawTx, true); err != nil {
+		return err
+	}
+
+	broadcastResult := &BroadcastRawTransactionAsyncResult{}
+	err = e.service.BroadcastRawEthTransactionAsync(&BroadcastRawTransactionAsyncArgs{
+		TxBytes: hexutil.Encode(rawTx),
+	}, broadcastResult)
+	if err != nil {
+		return err
+	}
+
+	*result = common.HexToHash(broadcastResult.TxHash)
+	return nil
+}
+
+// ------------------------------- eth_getTransactionReceipt -----------------------------------
+
+// GetTransactionReceipt returns the receipt of the smart contract tx with the given Ethereum or
+// native tx hash, or null if the tx is not finalized yet. The other types of txs do not have
+// receipts.
+func (e *EthRPCService) GetTransactionReceipt(args *EthArgs, result **EthReceipt) (err error) {
+	var hash common.Hash
+	if err = args.param(0, &hash, true); err != nil {
+		return err
+	}
+
+	raw, block, found := e.service.chain.FindTxByHash(hash)
+	if !found || !block.Status.IsFinalized() {
+		return nil
+	}
+	txIndex := -1
+	for i, rawTx := range block.Txs {
+		if bytes.Equal(rawTx, raw) {
+			txIndex = i
+			break
+		}
+	}
+	if txIndex < 0 {
+		return nil
+	}
+
+	*result = e.service.ethReceipt(block, txIndex)
+	return nil
+}
+
+// ------------------------------- eth_getLogs -----------------------------------
+
+// GetLogs returns the logs of the finalized blocks matching the filter. It is subject to the same
+// block range limit as GetLogs.
+func (e *EthRPCService) GetLogs(args *EthArgs, result *[]*EthLog) (err error) {
+	filter := &EthFilter{}
+	if err = args.param(0, filter, true); err != nil {
+		return err
+	}
+
+	logs := []*LogEntry{}
+	if filter.BlockHash != nil {
+		block, err := e.service.chain.FindBlock(*filter.BlockHash)
+		if err != nil || !block.Status.IsFinalized() {
+			return fmt.Errorf("Finalized block %v is not found", filter.BlockHash.Hex())
+		}
+		e.service.gatherLogs(block, newLogFilter(filter.Address, filter.Topics.toStrings()), &logs)
+	} else {
+		fromHeight, err := e.service.ethBlockHeight(filter.FromBlock)
+		if err != nil {
+			return err
+		}
+		toHeight, err := e.service.ethBlockHeight(filter.ToBlock)
+		if err != nil {
+			return err
+		}
+		logsResult := &GetLogsResult{}
+		err = e.service.GetLogs(&GetLogsArgs{
+			FromHeight: common.JSONUint64(fromHeight),
+			ToHeight:   common.JSONUint64(toHeight),
+			Addresses:  filter.Address,
+			Topics:     filter.Topics.toStrings(),
+		}, logsResult)
+		if err != nil {
+			return err
+		}
+		logs = logsResult.Logs
+	}
+
+	// The logs refer to the txs by their Ethereum tx hashes, the same as the receipts
+	blocks := make(map[common.Hash]*core.ExtendedBlock)
+	*result = []*EthLog{}
+	for _, entry := range logs {
+		block, ok := blocks[entry.BlockHash]
+		if !ok {
+			if block, err = e.service.chain.FindBlock(entry.BlockHash); err != nil {
+				return err
+			}
+			blocks[entry.BlockHash] = block
+		}
+		*result = append(*result, &EthLog{
+			Address:          entry.Address,
+			Topics:           nonNilTopics(entry.Topics),
+			Data:             entry.Data,
+			BlockNumber:      hexutil.Uint64(entry.BlockHeight),
+			TransactionHash:  ethTxHash(block, block.Txs[entry.TxIndex]),
+			TransactionIndex: hexutil.Uint64(entry.TxIndex),
+			BlockHash:        entry.BlockHash,
+			LogIndex:         hexutil.Uint64(entry.LogIndex),
+		})
+	}
+	return nil
+}
+
+// ------------------------------- eth_getBlockByNumber -----------------------------------
+
+// GetBlockByNumber returns the finalized block at the given height, or null if not found. Only
+// the smart contract txs are listed, since the other types of txs have no Ethereum counterparts.
+func (e *EthRPCService) GetBlockByNumber(args *EthArgs, result **EthBlock) (err error) {
+	var blockNumber string
+	if err = args.param(0, &blockNumber, true); err != nil {
+		return err
+	}
+	fullTxs := false
+	if err = args.param(1, &fullTxs, false); err != nil {
+		return err
+	}
+
+	height, err := e.service.ethBlockHeight(blockNumber)
+	if err != nil {
+		return err
+	}
+	block := e.service.findFinalizedBlockByHeight(height)
+	if block == nil {
+		return nil
+	}
+
+	*result = e.service.ethBlock(block, fullTxs)
+	return nil
+}
+
+// ------------------------------- net_version -----------------------------------
+
+func (n *NetRPCService) Version(args *EthArgs, result *string) (err error) {
+	*result = n.service.ethChainID().String()
+	return nil
+}
+
+// ------------------------------- net_listening -----------------------------------
+
+func (n *NetRPCService) Listening(args *EthArgs, result *bool) (err error) {
+	*result = true
+	return nil
+}
+
+// ------------------------------- net_peerCount -----------------------------------
+
+func (n *NetRPCService) PeerCount(args *EthArgs, result *hexutil.Uint64) (err error) {
+	*result = hexutil.Uint64(len(n.service.dispatcher.Peers(false)))
+	return nil
+}
+
+// ------------------------------- web3_clientVersion -----------------------------------
+
+func (w *Web3RPCService) ClientVersion(args *EthArgs, result *string) (err error) {
+	*result = fmt.Sprintf("dnero/v%v-%v", version.Version, version.GitHash)
+	return nil
+}
+
+// ------------------------------ Utils ------------------------------
+
+// param decodes the idx-th positional param into v. An optional param which is missing or null
+// leaves v untouched.
+func (args EthArgs) param(idx int, v interface{}, required bool) error {
+	if idx >= len(args) || string(args[idx]) == "null" {
+		if required {
+			return fmt.Errorf("Missing value for required argument %v", idx)
+		}
+		return nil
+	}
+	if err := json.Unmarshal(args[idx], v); err != nil {
+		return fmt.Errorf("Invalid argument %v: %v", idx, err)
+	}
+	return nil
+}
+
+// ethStringList is a list of strings which can also be given as a single string or null, e.g.
+// the contract addresses and the topics of a filter
+type ethStringList []string
+
+func (l *ethStringList) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]string)(l))
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*l = ethStringList{s}
+	return nil
+}
+
+type ethTopics []ethStringList
+
+func (topics ethTopics) toStrings() [][]string {
+	res := [][]string{}
+	for _, position := range topics {
+		res = append(res, []string(position))
+	}
+	return res
+}
+
+func (c *EthCallObject) toSmartContractTx(gasLimit uint64, gasPrice *big.Int) *types.SmartContractTx {
+	sctx := &types.SmartContractTx{
+		From:     types.TxInput{Coins: types.NewCoins(0, 0)},
+		To:       types.TxOutput{Coins: types.NewCoins(0, 0)},
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+	}
+	if c.From != nil {
+		sctx.From.Address = *c.From
+	}
+	if c.To != nil {
+		sctx.To.Address = *c.To
+	}
+	if c.Gas != nil && uint64(*c.Gas) != 0 {
+		sctx.GasLimit = uint64(*c.Gas)
+	}
+	if c.GasPrice != nil {
+		sctx.GasPrice = c.GasPrice.ToInt()
+	}
+	if c.Value != nil {
+		sctx.From.Coins.DTokenWei = c.Value.ToInt()
+	}
+	if c.Input != nil {
+		sctx.Data = common.Bytes(*c.Input)
+	} else if c.Data != nil {
+		sctx.Data = common.Bytes(*c.Data)
+	}
+	return sctx
+}
+
+// ethChainID returns the Ethereum chain ID of the next block
+func (t *DneroRPCService) ethChainID() *big.Int {
+	return types.MapChainID(t.chain.ChainID, t.consensus.GetLastFinalizedBlock().Height+1)
+}
+
+// ethBlockHeight resolves the block number, either a hex number or a block tag, to a height.
+// An empty block number means "latest".
+func (t *DneroRPCService) ethBlockHeight(blockNumber string) (uint64, error) {
+	switch blockNumber {
+	case "", ethBlockLatest, ethBlockSafe, ethBlockFinalized, ethBlockPending:
+		return t.consensus.GetLastFinalizedBlock().Height, nil
+	case ethBlockEarliest:
+		return 0, nil
+	}
+	height, err := hexutil.DecodeUint64(blockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid block number %v: %v", blockNumber, err)
+	}
+	return height, nil
+}
+
+// ethBlockSelector converts the block number, a hex number, a block hash or a block tag, to the
+// selector of the state. The Ethereum "latest" block is the latest finalized block.
+func ethBlockSelector(blockNumber string) BlockSelector {
+	switch blockNumber {
+	case "", ethBlockLatest, ethBlockSafe, ethBlockFinalized:
+		return BlockTagFinalized
+	case ethBlockPending:
+		return BlockTagPending
+	case ethBlockEarliest:
+		return "0"
+	}
+	return BlockSelector(blockNumber)
+}
+
+// ethStateAt returns the state at the given block, and the block the state is derived from, on
+// top of which the calls are executed. The "pending" state includes the txs screened by the mempool.
+func (t *DneroRPCService) ethStateAt(blockNumber string) (*core.Block, *state.StoreView, error) {
+	block, ledgerState, err := t.getStateAt(ethBlockSelector(blockNumber))
+	if err != nil {
+		return nil, nil, err
+	}
+	return block.Block, ledgerState, nil
+}
+
+// ethTxHash returns the Ethereum tx hash of the tx if it is signed by an Ethereum wallet, and the
+// native tx hash otherwise
+func ethTxHash(block *core.ExtendedBlock, rawTx common.Bytes) common.Hash {
+	if hash, err := blockchain.CalcEthTxHash(block, rawTx); err == nil {
+		return hash
+	}
+	return crypto.Keccak256Hash(rawTx)
+}
+
+// ethBlock converts the block, the gas used is summed up from the tx receipts
+func (t *DneroRPCService) ethBlock(block *core.ExtendedBlock, fullTxs bool) *EthBlock {
+	chainParams := common.GetChainParams(block.ChainID)
+	res := &EthBlock{
+		Number:           hexutil.Uint64(block.Height),
+		Hash:             block.Hash(),
+		ParentHash:       block.Parent,
+		Nonce:            make(hexutil.Bytes, 8),
+		Sha3Uncles:       ethEmptyUncleHash,
+		LogsBloom:        t.chain.GetBlockBloom(block),
+		TransactionsRoot: block.TxHash,
+		StateRoot:        block.StateHash,
+		ReceiptsRoot:     ethEmptyRootHash,
+		Miner:            block.Proposer,
+		ExtraData:        hexutil.Bytes{},
+		GasLimit:         hexutil.Uint64(types.GetMaxGasLimit(chainParams, block.Height).Uint64()),
+		Transactions:     []interface{}{},
+		Uncles:           []common.Hash{},
+	}
+	if block.Timestamp != nil {
+		res.Timestamp = hexutil.Uint64(block.Timestamp.Uint64())
+	}
+
+	blockHash := block.Hash()
+	gasUsed := uint64(0)
+	for txIndex, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			continue
+		}
+		sctx, ok := tx.(*types.SmartContractTx)
+		if !ok {
+			continue
+		}
+		if receipt, found := t.chain.FindTxReceiptByHash(blockHash, crypto.Keccak256Hash(rawTx)); found {
+			gasUsed += receipt.GasUsed
+		}
+
+		if fullTxs {
+			res.Transactions = append(res.Transactions, ethTransaction(block, txIndex, sctx))
+		} else {
+			res.Transactions = append(res.Transactions, ethTxHash(block, rawTx))
+		}
+	}
+	res.GasUsed = hexutil.Uint64(gasUsed)
+
+	return res
+}
+
+func ethTransaction(block *core.ExtendedBlock, txIndex int, sctx *types.SmartContractTx) *EthTransaction {
+	rawTx := block.Txs[txIndex]
+	chainID := types.MapChainID(block.ChainID, block.Height)
+	res := &EthTransaction{
+		Hash:             ethTxHash(block, rawTx),
+		BlockHash:        block.Hash(),
+		BlockNumber:      hexutil.Uint64(block.Height),
+		TransactionIndex: hexutil.Uint64(txIndex),
+		From:             sctx.From.Address,
+		Value:            (*hexutil.Big)(sctx.From.Coins.NoNil().DTokenWei),
+		Gas:              hexutil.Uint64(sctx.GasLimit),
+		GasPrice:         (*hexutil.Big)(sctx.GasPrice),
+		Input:            hexutil.Bytes(sctx.Data),
+		ChainID:          (*hexutil.Big)(chainID),
+	}
+	if sctx.From.Sequence > 0 {
+		res.Nonce = hexutil.Uint64(sctx.From.Sequence - 1) // ETH tx nonce starts from 0, while Dnero tx sequence starts from 1
+	}
+	if (sctx.To.Address != common.Address{}) {
+		to := sctx.To.Address
+		res.To = &to
+	}
+	if res.Hash != crypto.Keccak256Hash(rawTx) && sctx.From.Signature != nil {
+		// Signed by an Ethereum wallet, the signature is presented the same way as EIP-155
+		r, s, v := crypto.DecodeSignature(sctx.From.Signature)
+		vPrime := new(big.Int).Mul(chainID, big.NewInt(2))
+		vPrime.Add(vPrime, big.NewInt(8))
+		vPrime.Add(vPrime, v)
+		res.V, res.R, res.S = (*hexutil.Big)(vPrime), (*hexutil.Big)(r), (*hexutil.Big)(s)
+	}
+	return res
+}
+
+// ethReceipt returns the receipt of the txIndex-th tx of the block, or nil if the tx is not a
+// smart contract tx. The cumulative gas used and the log indices count the preceding txs in the block.
+func (t *DneroRPCService) ethReceipt(block *core.ExtendedBlock, txIndex int) *EthReceipt {
+	tx, err := types.TxFromBytes(block.Txs[txIndex])
+	if err != nil {
+		return nil
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return nil
+	}
+
+	blockHash := block.Hash()
+	cumulativeGasUsed := uint64(0)
+	logIndex := uint64(0)
+	for i := 0; i < txIndex; i++ {
+		if receipt, found := t.chain.FindTxReceiptByHash(blockHash, crypto.Keccak256Hash(block.Txs[i])); found {
+			cumulativeGasUsed += receipt.GasUsed
+			logIndex += uint64(len(receipt.Logs))
+		}
+	}
+	receipt, found := t.chain.FindTxReceiptByHash(blockHash, crypto.Keccak256Hash(block.Txs[txIndex]))
+	if !found {
+		return nil
+	}
+
+	txHash := ethTxHash(block, block.Txs[txIndex])
+	res := &EthReceipt{
+		TransactionHash:   txHash,
+		TransactionIndex:  hexutil.Uint64(txIndex),
+		BlockHash:         blockHash,
+		BlockNumber:       hexutil.Uint64(block.Height),
+		From:              sctx.From.Address,
+		CumulativeGasUsed: hexutil.Uint64(cumulativeGasUsed + receipt.GasUsed),
+		GasUsed:           hexutil.Uint64(receipt.GasUsed),
+		EffectiveGasPrice: (*hexutil.Big)(sctx.GasPrice),
+		Logs:              []*EthLog{},
+		LogsBloom:         types.LogsBloom(receipt.Logs),
+	}
+	if (sctx.To.Address == common.Address{}) {
+		contractAddress := receipt.ContractAddress
+		res.ContractAddress = &contractAddress
+	} else {
+		to := sctx.To.Address
+		res.To = &to
+	}
+	if receipt.EvmErr == "" {
+		res.Status = 1
+	}
+	for _, log := range receipt.Logs {
+		res.Logs = append(res.Logs, &EthLog{
+			Address:          log.Address,
+			Topics:           nonNilTopics(log.Topics),
+			Data:             log.Data,
+			BlockNumber:      hexutil.Uint64(block.Height),
+			TransactionHash:  txHash,
+			TransactionIndex: hexutil.Uint64(txIndex),
+			BlockHash:        blockHash,
+			LogIndex:         hexutil.Uint64(logIndex),
+		})
+		logIndex++
+	}
+	return res
+}
+
+func nonNilTopics(topics []common.Hash) []common.Hash {
+	if topics == nil {
+		return []common.Hash{}
+	}
+	return topics
+}
@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/rpc"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/version"
+)
+
+// testServerCodec serves a single request with the given params to the net/rpc server
+type testServerCodec struct {
+	method string
+	params json.RawMessage
+	read   bool
+
+	resp  *rpc.Response
+	reply interface{}
+}
+
+func (c *testServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	if c.read {
+		return io.EOF
+	}
+	c.read = true
+	r.ServiceMethod = c.method
+	r.Seq = 1
+	return nil
+}
+
+func (c *testServerCodec) ReadRequestBody(x interface{}) error {
+	if x == nil || c.params == nil {
+		return nil
+	}
+	return json.Unmarshal(c.params, x)
+}
+
+func (c *testServerCodec) WriteResponse(r *rpc.Response, x interface{}) error {
+	c.resp = r
+	c.reply = x
+	return nil
+}
+
+func (c *testServerCodec) Close() error {
+	return nil
+}
+
+func TestEthServices(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := rpc.NewServer()
+	service := &DneroRPCService{}
+	require.Nil(s.RegisterName("eth", &EthRPCService{service: service}))
+	require.Nil(s.RegisterName("net", &NetRPCService{service: service}))
+	require.Nil(s.RegisterName("web3", &Web3RPCService{service: service}))
+
+	codec := &testServerCodec{method: "web3.ClientVersion", params: json.RawMessage(`[]`)}
+	require.Nil(s.ServeRequest(codec))
+	assert.Equal("", codec.resp.Error)
+	assert.Contains(*codec.reply.(*string), version.Version)
+
+	// The methods are registered, and validate the positional params
+	for _, method := range []string{"eth.GetBalance", "eth.Call", "eth.EstimateGas", "eth.SendRawTransaction",
+		"eth.GetTransactionReceipt", "eth.GetLogs", "eth.GetBlockByNumber"} {
+		codec := &testServerCodec{method: method, params: json.RawMessage(`[]`)}
+		require.Nil(s.ServeRequest(codec))
+		assert.Equal("Missing value for required argument 0", codec.resp.Error, method)
+	}
+}
+
+func TestEthArgs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	args := EthArgs{}
+	require.Nil(json.Unmarshal([]byte(`["0x2e833968e5bb786ae419c4d13189fb081cc43bab", null]`), &args))
+
+	var address common.Address
+	require.Nil(args.param(0, &address, true))
+	assert.Equal(common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab"), address)
+
+	// Optional params default to the value given
+	blockNumber := ethBlockLatest
+	require.Nil(args.param(1, &blockNumber, false))
+	assert.Equal(ethBlockLatest, blockNumber)
+	require.Nil(args.param(2, &blockNumber, false))
+	assert.Equal(ethBlockLatest, blockNumber)
+	assert.NotNil(args.param(2, &blockNumber, true))
+
+	var fullTxs bool
+	assert.NotNil(args.param(0, &fullTxs, false))
+}
+
+func TestEthFilter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	filter := &EthFilter{}
+	require.Nil(json.Unmarshal([]byte(`{
+		"fromBlock": "0x10",
+		"address": "0x2e833968e5bb786ae419c4d13189fb081cc43bab",
+		"topics": [null, "0x01", ["0x02", "0x03"]]
+	}`), filter))
+	assert.Equal("0x10", filter.FromBlock)
+	assert.Equal("", filter.ToBlock)
+	assert.Nil(filter.BlockHash)
+	assert.Equal([]string{"0x2e833968e5bb786ae419c4d13189fb081cc43bab"}, []string(filter.Address))
+	assert.Equal([][]string{nil, {"0x01"}, {"0x02", "0x03"}}, filter.Topics.toStrings())
+
+	filter = &EthFilter{}
+	require.Nil(json.Unmarshal([]byte(`{"address": ["0x01", "0x02"]}`), filter))
+	assert.Equal([]string{"0x01", "0x02"}, []string(filter.Address))
+	assert.Equal([][]string{}, filter.Topics.toStrings())
+}
+
+func TestEthCallObject(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	call := &EthCallObject{}
+	require.Nil(json.Unmarshal([]byte(`{
+		"to": "0x2e833968e5bb786ae419c4d13189fb081cc43bab",
+		"value": "0x64",
+		"data": "0x01",
+		"input": "0xa9059cbb"
+	}`), call))
+
+	sctx := call.toSmartContractTx(1000000, big.NewInt(4000))
+	assert.Equal(common.Address{}, sctx.From.Address)
+	assert.Equal(common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab"), sctx.To.Address)
+	assert.Equal(uint64(1000000), sctx.GasLimit)
+	assert.Equal(big.NewInt(4000), sctx.GasPrice)
+	assert.Equal(big.NewInt(100), sctx.From.Coins.DTokenWei)
+	assert.Equal(common.Bytes(hexutil.MustDecode("0xa9059cbb")), sctx.Data)
+
+	gas := hexutil.Uint64(21000)
+	call = &EthCallObject{Gas: &gas, GasPrice: (*hexutil.Big)(big.NewInt(1))}
+	sctx = call.toSmartContractTx(1000000, big.NewInt(4000))
+	assert.Equal(uint64(21000), sctx.GasLimit)
+	assert.Equal(big.NewInt(1), sctx.GasPrice)
+	assert.Equal(big.NewInt(0), sctx.From.Coins.DTokenWei)
+}
@@ -249,6 +249,22 @@ func TestUnexpectedError(t *testing.T) {
 	ServeConn(srv)                                                   // must return, not loop
 }
 
+func TestServiceMethod(t *testing.T) {
+	cases := []struct{ method, want string }{
+		{"Arith.Add", "Arith.Add"},
+		{"eth_chainId", "eth.ChainId"},
+		{"web3_clientVersion", "web3.ClientVersion"},
+		{"eth_", "eth_"},
+		{"_chainId", "_chainId"},
+		{"Arith.Add_2", "Arith.Add_2"},
+	}
+	for _, c := range cases {
+		if got := serviceMethod(c.method); got != c.want {
+			t.Errorf("serviceMethod(%q) = %q, want %q", c.method, got, c.want)
+		}
+	}
+}
+
 // Copied from package net.
 func myPipe() (*pipe, *pipe) {
 	r1, w1 := io.Pipe()
@@ -24,6 +24,15 @@ used. To define custom error code (and optionally extra error data) method
 should return jsonrpc2.Error.
 
 
+Method names
+
+Methods are called as "Service.Method", the same as with net/rpc. Method
+names in the "namespace_method" form used by Ethereum JSON-RPC, e.g.
+"eth_chainId", are mapped to "Namespace.Method" with the first letter of the
+method capitalized, e.g. "eth.ChainId", so such methods can be served by a
+service registered under the namespace.
+
+
 Using positional parameters of different types
 
 If you'll have to provide method which should be called using positional
@@ -0,0 +1,136 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+)
+
+// maxLogQueryBlockRange is the maximum number of heights a GetLogs call can scan
+const maxLogQueryBlockRange = uint64(5000)
+
+// ------------------------------- GetLogs -----------------------------------
+
+type GetLogsArgs struct {
+	FromHeight common.JSONUint64 `json:"from_height"`
+	ToHeight   common.JSONUint64 `json:"to_height"` // zero means the latest finalized block
+	Addresses  []string          `json:"addresses"` // empty means any contract
+	Topics     [][]string        `json:"topics"`    // matched by position, empty means any topic
+}
+
+type LogEntry struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	TxHash      common.Hash       `json:"tx_hash"`
+	TxIndex     common.JSONUint64 `json:"tx_index"`
+	LogIndex    common.JSONUint64 `json:"log_index"` // index of the log in the block
+	Address     common.Address    `json:"address"`
+	Topics      []common.Hash     `json:"topics"`
+	Data        hexutil.Bytes     `json:"data"`
+}
+
+type GetLogsResult struct {
+	Logs []*LogEntry `json:"logs"`
+}
+
+// GetLogs returns the event logs emitted by the finalized blocks between FromHeight and ToHeight
+// (inclusive) that match the given contract addresses and topics. Blocks and sections of blocks
+// are skipped using the log bloom index.
+func (t *DneroRPCService) GetLogs(args *GetLogsArgs, result *GetLogsResult) (err error) {
+	filter := newLogFilter(args.Addresses, args.Topics)
+
+	lastFinalizedHeight := t.consensus.GetLastFinalizedBlock().Height
+	fromHeight := uint64(args.FromHeight)
+	toHeight := uint64(args.ToHeight)
+	if toHeight == 0 || toHeight > lastFinalizedHeight {
+		toHeight = lastFinalizedHeight
+	}
+	if fromHeight > toHeight {
+		return errors.New("Starting block must be less than ending block")
+	}
+	if toHeight-fromHeight >= maxLogQueryBlockRange {
+		return fmt.Errorf("Can't query logs for more than %v blocks at a time", maxLogQueryBlockRange)
+	}
+
+	result.Logs = []*LogEntry{}
+	for height := fromHeight; height <= toHeight; height++ {
+		if height%blockchain.BloomSectionSize == 0 || height == fromHeight {
+			section := blockchain.BloomSection(height)
+			sectionBloom, complete := t.chain.GetSectionBloom(section)
+			if complete && !filter.MatchBloom(sectionBloom) {
+				height = (section+1)*blockchain.BloomSectionSize - 1 // skip the rest of the section
+				continue
+			}
+		}
+
+		block := t.findFinalizedBlockByHeight(height)
+		if block == nil {
+			continue
+		}
+		if !filter.MatchBloom(t.chain.GetBlockBloom(block)) {
+			continue
+		}
+		t.gatherLogs(block, filter, &result.Logs)
+	}
+
+	return nil
+}
+
+// ------------------------------ Utils ------------------------------
+
+func newLogFilter(addresses []string, topics [][]string) *blockchain.LogFilter {
+	filter := &blockchain.LogFilter{}
+	for _, addr := range addresses {
+		filter.Addresses = append(filter.Addresses, common.HexToAddress(addr))
+	}
+	for _, position := range topics {
+		hashes := []common.Hash{}
+		for _, topic := range position {
+			hashes = append(hashes, common.HexToHash(topic))
+		}
+		filter.Topics = append(filter.Topics, hashes)
+	}
+	return filter
+}
+
+func (t *DneroRPCService) findFinalizedBlockByHeight(height uint64) *core.ExtendedBlock {
+	blocks := t.chain.FindBlocksByHeight(height)
+	for _, b := range blocks {
+		if b.Status.IsFinalized() {
+			return b
+		}
+	}
+	return nil
+}
+
+func (t *DneroRPCService) gatherLogs(block *core.ExtendedBlock, filter *blockchain.LogFilter, logs *[]*LogEntry) {
+	blockHash := block.Hash()
+	logIndex := uint64(0)
+	for txIndex, rawTx := range block.Txs {
+		txHash := crypto.Keccak256Hash(rawTx)
+		receipt, found := t.chain.FindTxReceiptByHash(blockHash, txHash)
+		if !found {
+			continue
+		}
+		for _, log := range receipt.Logs {
+			if filter.MatchLog(log) {
+				*logs = append(*logs, &LogEntry{
+					BlockHash:   blockHash,
+					BlockHeight: common.JSONUint64(block.Height),
+					TxHash:      txHash,
+					TxIndex:     common.JSONUint64(txIndex),
+					LogIndex:    common.JSONUint64(logIndex),
+					Address:     log.Address,
+					Topics:      log.Topics,
+					Data:        log.Data,
+				})
+			}
+			logIndex++
+		}
+	}
+}
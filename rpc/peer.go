@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/p2p/scoring"
+)
+
+type BannedPeerInfo struct {
+	PeerID string            `json:"peer_id"` // the peer ID, or the network address of the peer
+	Until  common.JSONUint64 `json:"until"`   // unix timestamp in seconds
+	Reason string            `json:"reason"`
+}
+
+func newBannedPeerInfo(ban *scoring.BannedPeer) BannedPeerInfo {
+	return BannedPeerInfo{
+		PeerID: ban.ID,
+		Until:  common.JSONUint64(ban.Until.Unix()),
+		Reason: ban.Reason,
+	}
+}
+
+// ------------------------------- ListBannedPeers -----------------------------------
+
+type ListBannedPeersArgs struct{}
+
+type ListBannedPeersResult struct {
+	BannedPeers []BannedPeerInfo `json:"banned_peers"`
+}
+
+// ListBannedPeers returns the peers currently banned, either by the peer scoring or by the operators
+func (t *DneroRPCService) ListBannedPeers(args *ListBannedPeersArgs, result *ListBannedPeersResult) (err error) {
+	result.BannedPeers = []BannedPeerInfo{}
+	for _, ban := range t.dispatcher.BannedPeers() {
+		result.BannedPeers = append(result.BannedPeers, newBannedPeerInfo(ban))
+	}
+	return nil
+}
+
+// ------------------------------- BanPeer -----------------------------------
+
+type BanPeerArgs struct {
+	PeerID   string            `json:"peer_id"`
+	Duration common.JSONUint64 `json:"duration"` // in seconds, the configured ban duration by default
+	Reason   string            `json:"reason"`
+}
+
+type BanPeerResult struct {
+	BannedPeer BannedPeerInfo `json:"banned_peer"`
+}
+
+// BanPeer bans the peer, and disconnects it if connected
+func (t *DneroRPCService) BanPeer(args *BanPeerArgs, result *BanPeerResult) (err error) {
+	if args.PeerID == "" {
+		return fmt.Errorf("Peer ID must be specified")
+	}
+	duration := time.Duration(args.Duration) * time.Second
+	if args.Duration == 0 {
+		duration = time.Duration(viper.GetInt64(common.CfgP2PBanDuration)) * time.Second
+	}
+	reason := args.Reason
+	if reason == "" {
+		reason = "banned by the operator"
+	}
+
+	if err = t.dispatcher.BanPeer(args.PeerID, duration, reason); err != nil {
+		return err
+	}
+	result.BannedPeer = BannedPeerInfo{
+		PeerID: args.PeerID,
+		Until:  common.JSONUint64(time.Now().Add(duration).Unix()),
+		Reason: reason,
+	}
+	return nil
+}
+
+// ------------------------------- UnbanPeer -----------------------------------
+
+type UnbanPeerArgs struct {
+	PeerID string `json:"peer_id"`
+}
+
+type UnbanPeerResult struct {
+	Unbanned bool `json:"unbanned"` // false if the peer is not banned
+}
+
+// UnbanPeer lifts the ban of the peer
+func (t *DneroRPCService) UnbanPeer(args *UnbanPeerArgs, result *UnbanPeerResult) (err error) {
+	if args.PeerID == "" {
+		return fmt.Errorf("Peer ID must be specified")
+	}
+	result.Unbanned, err = t.dispatcher.UnbanPeer(args.PeerID)
+	return err
+}
@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+)
+
+// maxStorageProofSlots is the maximum number of storage slots a GetStorageProof call can prove
+const maxStorageProofSlots = 256
+
+// ------------------------------- GetAccountProof -----------------------------------
+
+type GetAccountProofArgs struct {
+	Address string            `json:"address"`
+	Height  common.JSONUint64 `json:"height"` // zero means the latest finalized block, superseded by Block
+	Block   BlockSelector     `json:"block"`
+}
+
+type GetAccountProofResult struct {
+	Address     common.Address    `json:"address"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	StateHash   common.Hash       `json:"state_hash"`
+	Account     hexutil.Bytes     `json:"account"` // RLP encoded account, empty if the account does not exist
+	Proof       []hexutil.Bytes   `json:"proof"`   // RLP encoded trie nodes from the state root to the account
+}
+
+// GetAccountProof returns the account together with its merkle proof against the StateHash of the
+// selected block, by default the latest finalized block. The proof also proves the absence of the
+// account if it does not exist.
+func (t *DneroRPCService) GetAccountProof(args *GetAccountProofArgs, result *GetAccountProofResult) (err error) {
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+
+	block, ledgerState, err := t.getProvableStateAt(args.Block.orDefault(heightSelector(args.Height)))
+	if err != nil {
+		return err
+	}
+
+	return proveAccount(ledgerState, block, address, result)
+}
+
+// ------------------------------- GetStorageProof -----------------------------------
+
+type GetStorageProofArgs struct {
+	Address string            `json:"address"`
+	Slots   []string          `json:"slots"`
+	Height  common.JSONUint64 `json:"height"` // zero means the latest finalized block, superseded by Block
+	Block   BlockSelector     `json:"block"`
+}
+
+type StorageProof struct {
+	Slot  common.Hash     `json:"slot"`
+	Value common.Hash     `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"` // RLP encoded trie nodes from the storage root to the slot
+}
+
+type GetStorageProofResult struct {
+	GetAccountProofResult
+	StorageRoot   common.Hash     `json:"storage_root"`
+	StorageProofs []*StorageProof `json:"storage_proofs"`
+}
+
+// GetStorageProof returns the values of the given storage slots of a contract, together with the merkle
+// proofs of the account against the StateHash of the selected block, by default the latest finalized block,
+// and of the slots against the storage root.
+func (t *DneroRPCService) GetStorageProof(args *GetStorageProofArgs, result *GetStorageProofResult) (err error) {
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	if len(args.Slots) > maxStorageProofSlots {
+		return fmt.Errorf("Can't prove more than %v storage slots at a time", maxStorageProofSlots)
+	}
+	address := common.HexToAddress(args.Address)
+
+	block, ledgerState, err := t.getProvableStateAt(args.Block.orDefault(heightSelector(args.Height)))
+	if err != nil {
+		return err
+	}
+
+	account := ledgerState.GetAccount(address)
+	if account == nil {
+		return fmt.Errorf("Account with address %v is not found", address.Hex())
+	}
+	if err = proveAccount(ledgerState, block, address, &result.GetAccountProofResult); err != nil {
+		return err
+	}
+
+	result.StorageRoot = account.Root
+	result.StorageProofs = []*StorageProof{}
+	for _, slotStr := range args.Slots {
+		slot := common.HexToHash(slotStr)
+		proof := &proofList{}
+		if err = ledgerState.ProveStorage(address, slot, proof); err != nil {
+			return err
+		}
+		result.StorageProofs = append(result.StorageProofs, &StorageProof{
+			Slot:  slot,
+			Value: ledgerState.GetState(address, slot),
+			Proof: *proof,
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------- GetVCPProof -----------------------------------
+
+type GetVCPProofArgs struct {
+	BlockHash common.Hash `json:"block_hash"`
+}
+
+type GetVCPProofResult struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	StateHash   common.Hash       `json:"state_hash"`
+	Proof       []hexutil.Bytes   `json:"proof"` // RLP encoded trie nodes from the state root to the validator candidate pool
+}
+
+// GetVCPProof returns the merkle proof of the validator candidate pool against the StateHash of the given block.
+// Light clients use it to follow the validator set changes.
+func (t *DneroRPCService) GetVCPProof(args *GetVCPProofArgs, result *GetVCPProofResult) (err error) {
+	block, err := t.chain.FindBlock(args.BlockHash)
+	if err != nil {
+		return fmt.Errorf("Block %v is not found", args.BlockHash.Hex())
+	}
+
+	ledgerState, err := t.getStoreViewAt(block)
+	if err != nil {
+		return err
+	}
+
+	proof := &proofList{}
+	if err = ledgerState.GetStore().Prove(state.ValidatorCandidatePoolKey(), proof); err != nil {
+		return err
+	}
+
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.StateHash = block.StateHash
+	result.Proof = *proof
+	return nil
+}
+
+// ------------------------------ Utils ------------------------------
+
+// proofList collects the trie nodes of a merkle proof in the order from the root to the leaf.
+type proofList []hexutil.Bytes
+
+func (p *proofList) Put(key []byte, value []byte) error {
+	*p = append(*p, value)
+	return nil
+}
+
+func proveAccount(ledgerState *state.StoreView, block *core.ExtendedBlock, address common.Address, result *GetAccountProofResult) error {
+	proof := &proofList{}
+	if err := ledgerState.ProveAccount(address, proof); err != nil {
+		return err
+	}
+
+	result.Address = address
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.StateHash = block.StateHash
+	result.Proof = *proof
+	if account := ledgerState.GetAccount(address); account != nil {
+		raw, err := types.ToBytes(account)
+		if err != nil {
+			return err
+		}
+		result.Account = raw
+	}
+	return nil
+}
+
+// getProvableStateAt returns the selected block and its state, which the proofs are made against.
+// An empty selector selects the latest finalized block.
+func (t *DneroRPCService) getProvableStateAt(selector BlockSelector) (*core.ExtendedBlock, *state.StoreView, error) {
+	selector = selector.orDefault(BlockTagFinalized)
+	if selector == BlockTagPending {
+		return nil, nil, errors.New("The pending state can't be proved")
+	}
+	block, ledgerState, err := t.getStateAt(selector)
+	if err != nil {
+		return nil, nil, err
+	}
+	if selector == BlockTagLatest {
+		// The proofs are made against the StateHash of the block rather than the delivered view
+		ledgerState, err = t.getStoreViewAt(block)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return block, ledgerState, nil
+}
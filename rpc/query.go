@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/big"
 	"math/rand"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,8 +49,9 @@ func (t *DneroRPCService) GetVersion(args *GetVersionArgs, result *GetVersionRes
 type GetAccountArgs struct {
 	Name    string            `json:"name"`
 	Address string            `json:"address"`
-	Height  common.JSONUint64 `json:"height"`
-	Preview bool              `json:"preview"` // preview the account balance from the ScreenedView
+	Height  common.JSONUint64 `json:"height"`  // superseded by Block
+	Preview bool              `json:"preview"` // preview the account balance from the ScreenedView, superseded by Block
+	Block   BlockSelector     `json:"block"`   // the latest finalized block by default
 }
 
 type GetAccountResult struct {
@@ -62,64 +65,35 @@ func (t *DneroRPCService) GetAccount(args *GetAccountArgs, result *GetAccountRes
 	}
 	address := common.HexToAddress(args.Address)
 	result.Address = args.Address
-	height := uint64(args.Height)
-
-	if height == 0 { // get the latest
-		var ledgerState *state.StoreView
-		if args.Preview {
-			ledgerState, err = t.ledger.GetScreenedSnapshot()
-		} else {
-			ledgerState, err = t.ledger.GetFinalizedSnapshot()
-		}
-		if err != nil {
-			return err
-		}
-
-		account := ledgerState.GetAccount(address)
-		if account == nil {
-			return fmt.Errorf("Account with address %s is not found", address.Hex())
-		}
-		account.UpdateToHeight(ledgerState.Height())
-
-		result.Account = account
-	} else {
-		blocks := t.chain.FindBlocksByHeight(height)
-		if len(blocks) == 0 {
-			result.Account = nil
-			return nil
-		}
+	if t.isLegacyHeightNotFinalized(args.Block, args.Height) {
+		result.Account = nil
+		return nil
+	}
 
-		deliveredView, err := t.ledger.GetDeliveredSnapshot()
-		if err != nil {
-			return err
-		}
-		db := deliveredView.GetDB()
-
-		for _, b := range blocks {
-			if b.Status.IsFinalized() {
-				stateRoot := b.StateHash
-				ledgerState := state.NewStoreView(height, stateRoot, db)
-				if ledgerState == nil { // might have been pruned
-					return fmt.Errorf("the account details for height %v is not available, it might have been pruned", height)
-				}
-				account := ledgerState.GetAccount(address)
-				if account == nil {
-					return fmt.Errorf("Account with address %v is not found", address.Hex())
-				}
-				result.Account = account
-				break
-			}
-		}
+	selector := args.Block.orDefault(heightSelector(args.Height))
+	if args.Preview {
+		selector = selector.orDefault(BlockTagPending)
+	}
+	_, ledgerState, err := t.getStateAt(selector.orDefault(BlockTagFinalized))
+	if err != nil {
+		return err
+	}
 
+	account := ledgerState.GetAccount(address)
+	if account == nil {
+		return fmt.Errorf("Account with address %s is not found", address.Hex())
 	}
+	account.UpdateToHeight(ledgerState.Height())
 
+	result.Account = account
 	return nil
 }
 
 // ------------------------------- GetSplitRule -----------------------------------
 
 type GetSplitRuleArgs struct {
-	ResourceID string `json:"resource_id"`
+	ResourceID string        `json:"resource_id"`
+	Block      BlockSelector `json:"block"` // the latest block by default
 }
 
 type GetSplitRuleResult struct {
@@ -131,7 +105,7 @@ func (t *DneroRPCService) GetSplitRule(args *GetSplitRuleArgs, result *GetSplitR
 		return errors.New("ResourceID must be specified")
 	}
 	resourceID := args.ResourceID
-	ledgerState, err := t.ledger.GetDeliveredSnapshot()
+	_, ledgerState, err := t.getStateAt(args.Block.orDefault(BlockTagLatest))
 	if err != nil {
 		return err
 	}
@@ -222,6 +196,86 @@ func (t *DneroRPCService) GetTransaction(args *GetTransactionArgs, result *GetTr
 	return nil
 }
 
+// ------------------------------ GetAccountTransactions -----------------------------------
+
+const (
+	defaultAccountTxsLimit = 50
+	maxAccountTxsLimit     = 1000
+)
+
+type GetAccountTransactionsArgs struct {
+	Address    string            `json:"address"`
+	FromHeight common.JSONUint64 `json:"from_height"`
+	ToHeight   common.JSONUint64 `json:"to_height"` // 0 for the latest height
+	Limit      common.JSONUint64 `json:"limit"`
+	Cursor     string            `json:"cursor"` // next_cursor of the previous page, empty for the first page
+}
+
+type AccountTransaction struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	TxHash      common.Hash       `json:"tx_hash"`
+	Role        string            `json:"role"`
+}
+
+type GetAccountTransactionsResult struct {
+	Transactions []AccountTransaction `json:"transactions"` // from the newest to the oldest
+	NextCursor   string               `json:"next_cursor"`  // empty if there are no more transactions
+}
+
+func (t *DneroRPCService) GetAccountTransactions(args *GetAccountTransactionsArgs, result *GetAccountTransactionsResult) (err error) {
+	if !t.chain.AccountTxIndexEnabled() {
+		return fmt.Errorf("Account transaction index is not enabled, please set %v to true", common.CfgStorageIndexAccountTxs)
+	}
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	if !common.IsHexAddress(args.Address) {
+		return fmt.Errorf("Invalid address: %v", args.Address)
+	}
+	address := common.HexToAddress(args.Address)
+
+	toHeight := uint64(args.ToHeight)
+	if toHeight == 0 {
+		toHeight = math.MaxUint64
+	}
+	if uint64(args.FromHeight) > toHeight {
+		return errors.New("from_height must be less than or equal to to_height")
+	}
+
+	limit := int(args.Limit)
+	if limit == 0 {
+		limit = defaultAccountTxsLimit
+	}
+	if limit > maxAccountTxsLimit {
+		return fmt.Errorf("limit must not exceed %v", maxAccountTxsLimit)
+	}
+
+	cursor := uint64(0)
+	if args.Cursor != "" {
+		cursor, err = strconv.ParseUint(args.Cursor, 10, 64)
+		if err != nil || cursor == 0 {
+			return fmt.Errorf("Invalid cursor: %v", args.Cursor)
+		}
+	}
+
+	entries, next := t.chain.FindAccountTxs(address, uint64(args.FromHeight), toHeight, cursor, limit)
+	result.Transactions = []AccountTransaction{}
+	for _, entry := range entries {
+		result.Transactions = append(result.Transactions, AccountTransaction{
+			BlockHash:   entry.BlockHash,
+			BlockHeight: common.JSONUint64(entry.Height),
+			TxHash:      entry.TxHash,
+			Role:        entry.Role,
+		})
+	}
+	if next != 0 {
+		result.NextCursor = strconv.FormatUint(next, 10)
+	}
+
+	return nil
+}
+
 // ------------------------------ GetPendingTransactions -----------------------------------
 
 type GetPendingTransactionsArgs struct {
@@ -302,6 +356,7 @@ const (
 	TxTypeWithdrawStake
 	TxTypeDepositStakeTxV1
 	TxTypeStakeRewardDistributionTx
+	TxTypeEquivocationSlashTx
 )
 
 func (t *DneroRPCService) GetBlock(args *GetBlockArgs, result *GetBlockResult) (err error) {
@@ -907,7 +962,8 @@ func (t *DneroRPCService) GetAllPendingEliteEdgeNodeStakeReturns(
 
 type GetCodeArgs struct {
 	Address string            `json:"address"`
-	Height  common.JSONUint64 `json:"height"`
+	Height  common.JSONUint64 `json:"height"` // superseded by Block
+	Block   BlockSelector     `json:"block"`  // the latest finalized block by default
 }
 
 type GetCodeResult struct {
@@ -921,43 +977,17 @@ func (t *DneroRPCService) GetCode(args *GetCodeArgs, result *GetCodeResult) (err
 	}
 	address := common.HexToAddress(args.Address)
 	result.Address = args.Address
-	height := uint64(args.Height)
-
-	if height == 0 { // get the latest
-		var ledgerState *state.StoreView
-		ledgerState, err = t.ledger.GetFinalizedSnapshot()
-		if err != nil {
-			return err
-		}
-		codeBytes := ledgerState.GetCode(address)
-		result.Code = hex.EncodeToString(codeBytes)
-	} else {
-		blocks := t.chain.FindBlocksByHeight(height)
-		if len(blocks) == 0 {
-			result.Code = ""
-			return nil
-		}
-
-		deliveredView, err := t.ledger.GetDeliveredSnapshot()
-		if err != nil {
-			return err
-		}
-		db := deliveredView.GetDB()
-
-		for _, b := range blocks {
-			if b.Status.IsFinalized() {
-				stateRoot := b.StateHash
-				ledgerState := state.NewStoreView(height, stateRoot, db)
-				if ledgerState == nil { // might have been pruned
-					return fmt.Errorf("the account details for height %v is not available, it might have been pruned", height)
-				}
-				codeBytes := ledgerState.GetCode(address)
-				result.Code = hex.EncodeToString(codeBytes)
-				break
-			}
-		}
+	if t.isLegacyHeightNotFinalized(args.Block, args.Height) {
+		result.Code = ""
+		return nil
+	}
 
+	_, ledgerState, err := t.getStateAt(args.Block.orDefault(heightSelector(args.Height)).orDefault(BlockTagFinalized))
+	if err != nil {
+		return err
 	}
+	codeBytes := ledgerState.GetCode(address)
+	result.Code = hex.EncodeToString(codeBytes)
 
 	return nil
 }
@@ -967,7 +997,8 @@ func (t *DneroRPCService) GetCode(args *GetCodeArgs, result *GetCodeResult) (err
 type GetStorageAtArgs struct {
 	Address         string            `json:"address"`
 	StoragePosition string            `json:"storage_positon"`
-	Height          common.JSONUint64 `json:"height"`
+	Height          common.JSONUint64 `json:"height"` // superseded by Block
+	Block           BlockSelector     `json:"block"`  // the latest finalized block by default
 }
 
 type GetStorageAtResult struct {
@@ -980,42 +1011,17 @@ func (t *DneroRPCService) GetStorageAt(args *GetStorageAtArgs, result *GetStorag
 	}
 	address := common.HexToAddress(args.Address)
 	key := common.HexToHash(args.StoragePosition)
-	height := uint64(args.Height)
-
-	if height == 0 { // get the latest
-		var ledgerState *state.StoreView
-		ledgerState, err = t.ledger.GetFinalizedSnapshot()
-		if err != nil {
-			return err
-		}
-		value := ledgerState.GetState(address, key)
-		result.Value = hex.EncodeToString(value.Bytes())
-	} else {
-		blocks := t.chain.FindBlocksByHeight(height)
-		if len(blocks) == 0 {
-			result.Value = ""
-			return nil
-		}
+	if t.isLegacyHeightNotFinalized(args.Block, args.Height) {
+		result.Value = ""
+		return nil
+	}
 
-		deliveredView, err := t.ledger.GetDeliveredSnapshot()
-		if err != nil {
-			return err
-		}
-		db := deliveredView.GetDB()
-
-		for _, b := range blocks {
-			if b.Status.IsFinalized() {
-				stateRoot := b.StateHash
-				ledgerState := state.NewStoreView(height, stateRoot, db)
-				if ledgerState == nil { // might have been pruned
-					return fmt.Errorf("the account details for height %v is not available, it might have been pruned", height)
-				}
-				value := ledgerState.GetState(address, key)
-				result.Value = hex.EncodeToString(value.Bytes())
-				break
-			}
-		}
+	_, ledgerState, err := t.getStateAt(args.Block.orDefault(heightSelector(args.Height)).orDefault(BlockTagFinalized))
+	if err != nil {
+		return err
 	}
+	value := ledgerState.GetState(address, key)
+	result.Value = hex.EncodeToString(value.Bytes())
 
 	return nil
 }
@@ -1097,6 +1103,8 @@ func getTxType(tx types.Tx) byte {
 		t = TxTypeDepositStakeTxV1
 	case *types.StakeRewardDistributionTx:
 		t = TxTypeStakeRewardDistributionTx
+	case *types.EquivocationSlashTx:
+		t = TxTypeEquivocationSlashTx
 	}
 
 	return t
@@ -22,6 +22,7 @@ import (
 	"github.com/dnerochain/dnero/common/util"
 	"github.com/dnerochain/dnero/consensus"
 	"github.com/dnerochain/dnero/dispatcher"
+	"github.com/dnerochain/dnero/eventbus"
 	"github.com/dnerochain/dnero/ledger"
 	"github.com/dnerochain/dnero/mempool"
 	"github.com/dnerochain/dnero/rpc/lib/rpc-codec/jsonrpc2"
@@ -38,6 +39,10 @@ type DneroRPCService struct {
 	chain      *blockchain.Chain
 	consensus  *consensus.ConsensusEngine
 
+	finalizedBlocks    *eventbus.Subscription
+	subscriptionEvents *eventbus.Subscription
+	subscriptions      *subscriptionManager
+
 	// Life cycle
 	wg      *sync.WaitGroup
 	ctx     context.Context
@@ -53,14 +58,18 @@ type DneroRPCServer struct {
 	handler  *rpc.Server
 	router   *mux.Router
 	listener net.Listener
+	access   *accessController
 }
 
 // NewDneroRPCServer creates a new instance of DneroRPCServer.
 func NewDneroRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatcher *dispatcher.Dispatcher,
-	chain *blockchain.Chain, consensus *consensus.ConsensusEngine) *DneroRPCServer {
+	chain *blockchain.Chain, consensus *consensus.ConsensusEngine, eventBus *eventbus.EventBus) *DneroRPCServer {
+	logger = util.GetLoggerForModule("rpc")
+
 	t := &DneroRPCServer{
 		DneroRPCService: &DneroRPCService{
-			wg: &sync.WaitGroup{},
+			subscriptions: newSubscriptionManager(),
+			wg:            &sync.WaitGroup{},
 		},
 	}
 
@@ -70,24 +79,38 @@ func NewDneroRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 	t.chain = chain
 	t.consensus = consensus
 
+	t.finalizedBlocks = eventBus.Subscribe("rpc/txcallback", eventbus.DefaultBufferSize, eventbus.TopicBlockFinalized)
+	t.subscriptionEvents = eventBus.Subscribe("rpc/subscriptions", eventbus.DefaultBufferSize,
+		eventbus.TopicBlockProposed, eventbus.TopicBlockFinalized, eventbus.TopicTxAddedToMempool)
+
 	s := rpc.NewServer()
 	s.RegisterName("dnero", t.DneroRPCService)
+	s.RegisterName("eth", &EthRPCService{service: t.DneroRPCService})
+	s.RegisterName("net", &NetRPCService{service: t.DneroRPCService})
+	s.RegisterName("web3", &Web3RPCService{service: t.DneroRPCService})
 
 	t.handler = s
+	t.access = newAccessController()
 
 	t.router = mux.NewRouter()
 	t.router.Handle("/", &defaultHTTPHandler{})
-	t.router.Handle("/rpc", corsMiddleware(TimeoutHandler(jsonrpc2.HTTPHandler(s), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, "")))
-	t.router.Handle("/ws", websocket.Handler(func(ws *websocket.Conn) {
-		s.ServeCodec(jsonrpc2.NewServerCodec(ws, s))
+	t.router.Handle("/rpc", corsMiddleware(t.access.httpMiddleware(
+		TimeoutHandler(jsonrpc2.HTTPHandler(s), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, ""))))
+	t.router.Handle("/ws", t.access.wsHandshake(func(ws *websocket.Conn, client *accessClient) {
+		conn := newWSConnection(ws)
+		defer func() {
+			t.subscriptions.removeConnection(conn)
+			conn.close()
+		}()
+
+		ctx := context.WithValue(context.Background(), wsConnectionKey{}, conn)
+		s.ServeCodec(jsonrpc2.NewServerCodecContext(ctx, newAccessConn(ws, t.access, client), s))
 	}))
 
 	t.server = &http.Server{
 		Handler: t.router,
 	}
 
-	logger = util.GetLoggerForModule("rpc")
-
 	return t
 }
 
@@ -102,6 +125,9 @@ func (t *DneroRPCServer) Start(ctx context.Context) {
 
 	t.wg.Add(1)
 	go t.txCallback()
+
+	t.wg.Add(1)
+	go t.subscriptionLoop()
 }
 
 func (t *DneroRPCServer) mainLoop() {
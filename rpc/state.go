@@ -0,0 +1,169 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/viper"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/common/hexutil"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+// Block tags accepted by the BlockSelector
+const (
+	BlockTagLatest    = "latest"    // the state after the latest block processed, which might not be finalized yet
+	BlockTagFinalized = "finalized" // the state after the latest finalized block
+	BlockTagPending   = "pending"   // the latest state plus the txs screened by the mempool
+)
+
+// ErrCodeStatePruned is returned when the state of the selected block is no longer available, e.g.
+// removed by the state pruning or with a rolled away rollingdb layer
+const ErrCodeStatePruned = -32030
+
+//
+// BlockSelector selects the block whose state a query reads. It is either a block height, e.g.
+// 1024, "1024" or "0x400", a block hash, or one of the block tags. A height selects the finalized
+// block at the height, while a hash selects any block validated by the node. An empty selector
+// selects the default of the RPC.
+//
+type BlockSelector string
+
+func (s *BlockSelector) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = BlockSelector(str)
+		return nil
+	}
+	var height uint64
+	if err := json.Unmarshal(data, &height); err != nil {
+		return fmt.Errorf("Invalid block selector %s, expecting a height, a block hash or a block tag", data)
+	}
+	*s = BlockSelector(strconv.FormatUint(height, 10))
+	return nil
+}
+
+// heightSelector converts the legacy height argument, where zero means the default of the RPC
+func heightSelector(height common.JSONUint64) BlockSelector {
+	if height == 0 {
+		return ""
+	}
+	return BlockSelector(strconv.FormatUint(uint64(height), 10))
+}
+
+// orDefault returns the selector, or the given default if the selector is empty
+func (s BlockSelector) orDefault(defaultSelector BlockSelector) BlockSelector {
+	if s == "" {
+		return defaultSelector
+	}
+	return s
+}
+
+// isLegacyHeightNotFinalized tells whether the state is selected with the legacy height argument, and
+// there is no finalized block at the height. The RPCs return an empty result instead of an error in this
+// case, the same as before the block selector was introduced.
+func (t *DneroRPCService) isLegacyHeightNotFinalized(block BlockSelector, height common.JSONUint64) bool {
+	return block == "" && height != 0 && t.findFinalizedBlockByHeight(uint64(height)) == nil
+}
+
+// getStateAt returns the state selected, and the block the state results from. For the pending
+// state, the block is the latest block processed, on top of which the screened txs are applied.
+func (t *DneroRPCService) getStateAt(selector BlockSelector) (*core.ExtendedBlock, *state.StoreView, error) {
+	switch selector {
+	case BlockTagLatest, BlockTagPending:
+		block, err := t.chain.FindBlock(t.ledger.State().ParentBlock().Hash())
+		if err != nil {
+			return nil, nil, err
+		}
+		var ledgerState *state.StoreView
+		if selector == BlockTagPending {
+			ledgerState, err = t.ledger.GetScreenedSnapshot()
+		} else {
+			ledgerState, err = t.ledger.GetDeliveredSnapshot()
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return block, ledgerState, nil
+	case BlockTagFinalized:
+		block := t.consensus.GetLastFinalizedBlock()
+		ledgerState, err := t.getStoreViewAt(block)
+		if err != nil {
+			return nil, nil, err
+		}
+		return block, ledgerState, nil
+	case "":
+		return nil, nil, fmt.Errorf("Block selector must be specified")
+	}
+
+	str := string(selector)
+	var block *core.ExtendedBlock
+	if hexutil.Has0xPrefix(str) && len(str) == 2+2*common.HashLength {
+		hash := common.HexToHash(str)
+		var err error
+		block, err = t.chain.FindBlock(hash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Block %v is not found", hash.Hex())
+		}
+		if !block.Status.IsValid() {
+			return nil, nil, fmt.Errorf("The state of block %v is not available, the block has not been validated", hash.Hex())
+		}
+	} else {
+		var height uint64
+		var err error
+		if hexutil.Has0xPrefix(str) {
+			height, err = hexutil.DecodeUint64(str)
+		} else {
+			height, err = strconv.ParseUint(str, 10, 64)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid block selector %v, expecting a height, a block hash or a block tag", str)
+		}
+		block = t.findFinalizedBlockByHeight(height)
+		if block == nil {
+			return nil, nil, fmt.Errorf("Finalized block for height %v is not found", height)
+		}
+	}
+
+	ledgerState, err := t.getStoreViewAt(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, ledgerState, nil
+}
+
+// getStoreViewAt returns the state after the given block is applied
+func (t *DneroRPCService) getStoreViewAt(block *core.ExtendedBlock) (*state.StoreView, error) {
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	ledgerState := state.NewStoreView(block.Height, block.StateHash, deliveredView.GetDB())
+	if ledgerState == nil {
+		return nil, jsonrpc2.NewError(ErrCodeStatePruned, fmt.Sprintf(
+			"The state of block %v at height %v is not available, it has been pruned", block.Hash().Hex(), block.Height))
+	}
+	return ledgerState, nil
+}
+
+// ------------------------------- GetStateAvailability -----------------------------------
+
+type GetStateAvailabilityArgs struct{}
+
+type GetStateAvailabilityResult struct {
+	ArchiveMode           bool              `json:"archive_mode"`
+	LowestHeight          common.JSONUint64 `json:"lowest_height"` // the full state of every finalized block since the height is present
+	LatestFinalizedHeight common.JSONUint64 `json:"latest_finalized_height"`
+}
+
+// GetStateAvailability returns the range of heights whose state can be queried. In the archive mode,
+// the state since the lowest height is never pruned.
+func (t *DneroRPCService) GetStateAvailability(args *GetStateAvailabilityArgs, result *GetStateAvailabilityResult) (err error) {
+	result.ArchiveMode = viper.GetBool(common.CfgStorageArchiveMode)
+	result.LowestHeight = common.JSONUint64(t.ledger.GetLowestStateHeight())
+	result.LatestFinalizedHeight = common.JSONUint64(t.consensus.GetLastFinalizedBlock().Height)
+	return nil
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/dnerochain/dnero/store/kvstore"
+)
+
+func TestBlockSelector(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	args := &GetAccountArgs{}
+	require.Nil(json.Unmarshal([]byte(`{"address": "0x01", "block": 1024}`), args))
+	assert.Equal(BlockSelector("1024"), args.Block)
+
+	args = &GetAccountArgs{}
+	require.Nil(json.Unmarshal([]byte(`{"address": "0x01", "block": "0x400"}`), args))
+	assert.Equal(BlockSelector("0x400"), args.Block)
+
+	args = &GetAccountArgs{}
+	require.Nil(json.Unmarshal([]byte(`{"address": "0x01", "block": "finalized"}`), args))
+	assert.Equal(BlockSelector(BlockTagFinalized), args.Block)
+
+	args = &GetAccountArgs{}
+	assert.NotNil(json.Unmarshal([]byte(`{"address": "0x01", "block": {}}`), args))
+
+	// The legacy height argument is superseded by the block selector
+	assert.Equal(BlockSelector(""), heightSelector(common.JSONUint64(0)))
+	assert.Equal(BlockSelector("12"), heightSelector(common.JSONUint64(12)))
+	assert.Equal(BlockSelector(BlockTagLatest), heightSelector(0).orDefault(BlockTagLatest))
+	assert.Equal(BlockSelector("12"), heightSelector(12).orDefault(BlockTagLatest))
+}
+
+func TestEthBlockSelector(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(BlockSelector(BlockTagFinalized), ethBlockSelector(""))
+	assert.Equal(BlockSelector(BlockTagFinalized), ethBlockSelector(ethBlockLatest))
+	assert.Equal(BlockSelector(BlockTagPending), ethBlockSelector(ethBlockPending))
+	assert.Equal(BlockSelector("0"), ethBlockSelector(ethBlockEarliest))
+	assert.Equal(BlockSelector("0x10"), ethBlockSelector("0x10"))
+}
+
+func TestLegacyHeightWithoutFinalizedBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	root := core.CreateTestBlock("a0", "")
+	root.ChainID = "testchain"
+	chain := blockchain.NewChain("testchain", kvstore.NewKVStore(backend.NewMemDatabase()), root)
+	service := &DneroRPCService{chain: chain}
+	address := "0x2e833968e5bb786ae419c4d13189fb081cc43bab"
+
+	// The legacy height argument returns an empty result
+	accountResult := &GetAccountResult{}
+	assert.Nil(service.GetAccount(&GetAccountArgs{Address: address, Height: 5}, accountResult))
+	assert.Nil(accountResult.Account)
+	codeResult := &GetCodeResult{}
+	assert.Nil(service.GetCode(&GetCodeArgs{Address: address, Height: 5}, codeResult))
+	assert.Equal("", codeResult.Code)
+	storageResult := &GetStorageAtResult{}
+	assert.Nil(service.GetStorageAt(&GetStorageAtArgs{Address: address, StoragePosition: "0x1", Height: 5}, storageResult))
+	assert.Equal("", storageResult.Value)
+
+	// The block selector returns an error
+	assert.NotNil(service.GetAccount(&GetAccountArgs{Address: address, Block: "5"}, &GetAccountResult{}))
+	assert.NotNil(service.GetCode(&GetCodeArgs{Address: address, Block: "5"}, &GetCodeResult{}))
+	assert.NotNil(service.GetStorageAt(&GetStorageAtArgs{Address: address, StoragePosition: "0x1", Block: "5"}, &GetStorageAtResult{}))
+}
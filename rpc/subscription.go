@@ -0,0 +1,410 @@
+package rpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/eventbus"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+const (
+	SubscriptionNewHeads            = "newHeads"
+	SubscriptionFinalizedBlocks     = "finalizedBlocks"
+	SubscriptionPendingTransactions = "pendingTransactions"
+	SubscriptionLogs                = "logs"
+
+	subscriptionNotificationMethod = "dnero.subscription"
+
+	maxSubscriptionsPerConnection = 64
+	wsNotificationQueueSize       = 1024
+	wsWriteTimeout                = 10 * time.Second
+)
+
+type wsConnectionKey struct{}
+
+// ------------------------------- Subscribe -----------------------------------
+
+// SubscriptionFilter narrows down the notifications of a subscription. Addresses and Topics
+// only apply to the "logs" subscriptions, and IncludeTransactions only applies to the
+// "newHeads" and "finalizedBlocks" subscriptions.
+type SubscriptionFilter struct {
+	Addresses           []string   `json:"addresses"`
+	Topics              [][]string `json:"topics"`
+	IncludeTransactions bool       `json:"include_transactions"`
+}
+
+type SubscribeArgs struct {
+	jsonrpc2.Ctx
+	Type   string             `json:"type"`
+	Filter SubscriptionFilter `json:"filter"`
+}
+
+type SubscribeResult struct {
+	Subscription string `json:"subscription"`
+}
+
+func (t *DneroRPCService) Subscribe(args *SubscribeArgs, result *SubscribeResult) (err error) {
+	conn, err := wsConnectionFromArgs(&args.Ctx)
+	if err != nil {
+		return err
+	}
+
+	switch args.Type {
+	case SubscriptionNewHeads, SubscriptionFinalizedBlocks, SubscriptionPendingTransactions, SubscriptionLogs:
+	default:
+		return fmt.Errorf("Unsupported subscription type: %v", args.Type)
+	}
+
+	id, err := t.subscriptions.subscribe(conn, args.Type, args.Filter)
+	if err != nil {
+		return err
+	}
+	result.Subscription = id
+
+	return nil
+}
+
+// ------------------------------- Unsubscribe -----------------------------------
+
+type UnsubscribeArgs struct {
+	jsonrpc2.Ctx
+	Subscription string `json:"subscription"`
+}
+
+type UnsubscribeResult struct {
+	Unsubscribed bool `json:"unsubscribed"`
+}
+
+func (t *DneroRPCService) Unsubscribe(args *UnsubscribeArgs, result *UnsubscribeResult) (err error) {
+	conn, err := wsConnectionFromArgs(&args.Ctx)
+	if err != nil {
+		return err
+	}
+
+	result.Unsubscribed = t.subscriptions.unsubscribe(conn, args.Subscription)
+
+	return nil
+}
+
+// ------------------------------ Notifications -----------------------------------
+
+// subscriptionLoop dispatches the events received from the event bus to the subscribers, so slow
+// subscribers never block block processing
+func (t *DneroRPCService) subscriptionLoop() {
+	defer t.wg.Done()
+	defer t.subscriptionEvents.Unsubscribe()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case event := <-t.subscriptionEvents.Events():
+			t.dispatchSubscriptionEvent(event)
+		}
+	}
+}
+
+func (t *DneroRPCService) dispatchSubscriptionEvent(event eventbus.Event) {
+	switch data := event.Data.(type) {
+	case *eventbus.BlockProposedEvent:
+		t.dispatchBlock(SubscriptionNewHeads, data.Block)
+	case *eventbus.BlockFinalizedEvent:
+		t.dispatchBlock(SubscriptionFinalizedBlocks, data.Block)
+		t.dispatchLogs(data.Block)
+	case *eventbus.TxAddedToMempoolEvent:
+		t.dispatchPendingTransaction(data.RawTx)
+	}
+}
+
+func (t *DneroRPCService) dispatchBlock(typ string, block *core.ExtendedBlock) {
+	var header, headerWithTxs *GetBlockResultInner
+	for _, sub := range t.subscriptions.subscribers(typ) {
+		if !sub.filter.IncludeTransactions {
+			if header == nil {
+				header = newBlockResult(block)
+			}
+			sub.notify(header)
+			continue
+		}
+		if headerWithTxs == nil {
+			headerWithTxs = newBlockResult(block)
+			t.gatherTxs(block, &headerWithTxs.Txs, false)
+		}
+		sub.notify(headerWithTxs)
+	}
+}
+
+func (t *DneroRPCService) dispatchPendingTransaction(rawTx common.Bytes) {
+	subs := t.subscriptions.subscribers(SubscriptionPendingTransactions)
+	if len(subs) == 0 {
+		return
+	}
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		logger.Warnf("Failed to parse the pending transaction: %v", err)
+		return
+	}
+	pendingTx := Tx{
+		Tx:   tx,
+		Type: getTxType(tx),
+		Hash: crypto.Keccak256Hash(rawTx),
+	}
+	for _, sub := range subs {
+		sub.notify(pendingTx)
+	}
+}
+
+// dispatchLogs sends the logs emitted by the transactions in the finalized block to the
+// matching "logs" subscriptions.
+func (t *DneroRPCService) dispatchLogs(block *core.ExtendedBlock) {
+	subs := t.subscriptions.subscribers(SubscriptionLogs)
+	if len(subs) == 0 {
+		return
+	}
+
+	bloom := t.chain.GetBlockBloom(block)
+	for _, sub := range subs {
+		if !sub.logFilter.MatchBloom(bloom) {
+			continue
+		}
+		logs := []*LogEntry{}
+		t.gatherLogs(block, sub.logFilter, &logs)
+		for _, logEntry := range logs {
+			sub.notify(logEntry)
+		}
+	}
+}
+
+// ------------------------------ Subscription Manager -----------------------------------
+
+type subscription struct {
+	id        string
+	typ       string
+	filter    SubscriptionFilter
+	logFilter *blockchain.LogFilter
+	conn      *wsConnection
+}
+
+type subscriptionNotification struct {
+	Version string                         `json:"jsonrpc"`
+	Method  string                         `json:"method"`
+	Params  subscriptionNotificationParams `json:"params"`
+}
+
+type subscriptionNotificationParams struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+func (sub *subscription) notify(result interface{}) {
+	msg, err := json.Marshal(subscriptionNotification{
+		Version: "2.0",
+		Method:  subscriptionNotificationMethod,
+		Params: subscriptionNotificationParams{
+			Subscription: sub.id,
+			Result:       result,
+		},
+	})
+	if err != nil {
+		logger.Warnf("Failed to encode the subscription notification: %v", err)
+		return
+	}
+	sub.conn.send(msg)
+}
+
+// subscriptionManager keeps track of the subscriptions of all the WebSocket connections
+type subscriptionManager struct {
+	mu            *sync.Mutex
+	subscriptions map[string]*subscription
+	numSubs       map[*wsConnection]int
+}
+
+func newSubscriptionManager() *subscriptionManager {
+	return &subscriptionManager{
+		mu:            &sync.Mutex{},
+		subscriptions: make(map[string]*subscription),
+		numSubs:       make(map[*wsConnection]int),
+	}
+}
+
+func (sm *subscriptionManager) subscribe(conn *wsConnection, typ string, filter SubscriptionFilter) (string, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.numSubs[conn] >= maxSubscriptionsPerConnection {
+		return "", fmt.Errorf("Too many subscriptions, at most %v subscriptions are allowed per connection", maxSubscriptionsPerConnection)
+	}
+
+	id, err := newSubscriptionID()
+	if err != nil {
+		return "", err
+	}
+	sub := &subscription{
+		id:     id,
+		typ:    typ,
+		filter: filter,
+		conn:   conn,
+	}
+	if typ == SubscriptionLogs {
+		sub.logFilter = newLogFilter(filter.Addresses, filter.Topics)
+	}
+	sm.subscriptions[id] = sub
+	sm.numSubs[conn]++
+
+	return id, nil
+}
+
+// unsubscribe removes the subscription. A connection can only remove its own subscriptions.
+func (sm *subscriptionManager) unsubscribe(conn *wsConnection, id string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sub, ok := sm.subscriptions[id]
+	if !ok || sub.conn != conn {
+		return false
+	}
+	sm.removeSubscriptionUnsafe(sub)
+	return true
+}
+
+// removeConnection removes all the subscriptions of the closed connection
+func (sm *subscriptionManager) removeConnection(conn *wsConnection) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, sub := range sm.subscriptions {
+		if sub.conn == conn {
+			sm.removeSubscriptionUnsafe(sub)
+		}
+	}
+}
+
+func (sm *subscriptionManager) removeSubscriptionUnsafe(sub *subscription) {
+	delete(sm.subscriptions, sub.id)
+	sm.numSubs[sub.conn]--
+	if sm.numSubs[sub.conn] <= 0 {
+		delete(sm.numSubs, sub.conn)
+	}
+}
+
+func (sm *subscriptionManager) subscribers(typ string) []*subscription {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	subs := []*subscription{}
+	for _, sub := range sm.subscriptions {
+		if sub.typ == typ {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+func newSubscriptionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(buf), nil
+}
+
+// ------------------------------ WebSocket Connection -----------------------------------
+
+// wsConnection queues the subscription notifications of a WebSocket connection. The
+// notifications are written by a separate goroutine. A client which cannot keep up with
+// the notifications is disconnected once its queue is full.
+type wsConnection struct {
+	conn  io.WriteCloser
+	queue chan []byte
+
+	done      chan struct{}
+	closeOnce *sync.Once
+}
+
+func newWSConnection(conn io.WriteCloser) *wsConnection {
+	c := &wsConnection{
+		conn:      conn,
+		queue:     make(chan []byte, wsNotificationQueueSize),
+		done:      make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+	go c.writeLoop()
+	return c
+}
+
+func (c *wsConnection) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.queue:
+			if conn, ok := c.conn.(interface{ SetWriteDeadline(time.Time) error }); ok {
+				conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			}
+			if _, err := c.conn.Write(msg); err != nil {
+				logger.Debugf("Failed to write the subscription notification: %v", err)
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConnection) send(msg []byte) {
+	select {
+	case <-c.done:
+	case c.queue <- msg:
+	default:
+		logger.Warnf("Closing the WebSocket connection of a slow subscriber")
+		c.close()
+	}
+}
+
+func (c *wsConnection) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func wsConnectionFromArgs(args jsonrpc2.WithContext) (*wsConnection, error) {
+	ctx := args.Context()
+	if ctx != nil {
+		if conn, ok := ctx.Value(wsConnectionKey{}).(*wsConnection); ok {
+			return conn, nil
+		}
+	}
+	return nil, errors.New("Subscriptions are only supported over WebSocket")
+}
+
+// ------------------------------ Utils -----------------------------------
+
+func newBlockResult(block *core.ExtendedBlock) *GetBlockResultInner {
+	return &GetBlockResultInner{
+		ChainID:     block.ChainID,
+		Epoch:       common.JSONUint64(block.Epoch),
+		Height:      common.JSONUint64(block.Height),
+		Parent:      block.Parent,
+		TxHash:      block.TxHash,
+		StateHash:   block.StateHash,
+		Timestamp:   (*common.JSONBig)(block.Timestamp),
+		Proposer:    block.Proposer,
+		Children:    block.Children,
+		Status:      block.Status,
+		HCC:         block.HCC,
+		SentryVotes: block.SentryVotes,
+		Hash:        block.Hash(),
+	}
+}
@@ -0,0 +1,184 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common/util"
+)
+
+func init() {
+	logger = util.GetLoggerForModule("rpc")
+}
+
+func TestSubscriptionManager(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	sm := newSubscriptionManager()
+	conn1 := newWSConnection(&mockWSConn{})
+	conn2 := newWSConnection(&mockWSConn{})
+	defer conn1.close()
+	defer conn2.close()
+
+	id1, err := sm.subscribe(conn1, SubscriptionNewHeads, SubscriptionFilter{})
+	require.Nil(err)
+	id2, err := sm.subscribe(conn1, SubscriptionLogs, SubscriptionFilter{Addresses: []string{"0x0000000000000000000000000000000000000001"}})
+	require.Nil(err)
+	_, err = sm.subscribe(conn2, SubscriptionNewHeads, SubscriptionFilter{})
+	require.Nil(err)
+	assert.NotEqual(id1, id2)
+
+	assert.Equal(2, len(sm.subscribers(SubscriptionNewHeads)))
+	assert.Equal(0, len(sm.subscribers(SubscriptionFinalizedBlocks)))
+
+	logSubs := sm.subscribers(SubscriptionLogs)
+	require.Equal(1, len(logSubs))
+	require.NotNil(logSubs[0].logFilter)
+	assert.Equal(1, len(logSubs[0].logFilter.Addresses))
+
+	// A connection cannot remove the subscriptions of other connections
+	assert.False(sm.unsubscribe(conn2, id1))
+	assert.True(sm.unsubscribe(conn1, id1))
+	assert.False(sm.unsubscribe(conn1, id1))
+	assert.Equal(1, len(sm.subscribers(SubscriptionNewHeads)))
+
+	sm.removeConnection(conn1)
+	assert.Equal(0, len(sm.subscribers(SubscriptionLogs)))
+	assert.Equal(1, len(sm.subscribers(SubscriptionNewHeads)))
+	assert.Equal(0, sm.numSubs[conn1])
+
+	// Number of subscriptions per connection is capped
+	for i := 1; i < maxSubscriptionsPerConnection; i++ {
+		_, err = sm.subscribe(conn2, SubscriptionPendingTransactions, SubscriptionFilter{})
+		require.Nil(err)
+	}
+	_, err = sm.subscribe(conn2, SubscriptionPendingTransactions, SubscriptionFilter{})
+	assert.NotNil(err)
+}
+
+func TestSubscriptionNotification(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ws := &mockWSConn{}
+	sub := &subscription{id: "0x01", typ: SubscriptionNewHeads, conn: newWSConnection(ws)}
+	defer sub.conn.close()
+
+	sub.notify(map[string]string{"hash": "0xabc"})
+
+	var written [][]byte
+	for i := 0; i < 100; i++ {
+		if written = ws.messages(); len(written) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(1, len(written))
+
+	var msg struct {
+		Version string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  struct {
+			Subscription string            `json:"subscription"`
+			Result       map[string]string `json:"result"`
+		} `json:"params"`
+	}
+	require.Nil(json.Unmarshal(written[0], &msg))
+	assert.Equal("2.0", msg.Version)
+	assert.Equal("dnero.subscription", msg.Method)
+	assert.Equal("0x01", msg.Params.Subscription)
+	assert.Equal("0xabc", msg.Params.Result["hash"])
+}
+
+func TestSlowSubscriberDisconnected(t *testing.T) {
+	assert := assert.New(t)
+
+	ws := &mockWSConn{block: make(chan struct{})}
+	conn := newWSConnection(ws)
+
+	for i := 0; i < wsNotificationQueueSize+2; i++ {
+		conn.send([]byte("{}"))
+	}
+
+	select {
+	case <-conn.done:
+	default:
+		t.Fatal("slow subscriber should be disconnected")
+	}
+	assert.True(ws.isClosed())
+	close(ws.block)
+}
+
+func TestSubscribeRequiresWebSocket(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &DneroRPCService{subscriptions: newSubscriptionManager()}
+
+	args := &SubscribeArgs{Type: SubscriptionNewHeads}
+	args.SetContext(context.Background())
+	assert.NotNil(service.Subscribe(args, &SubscribeResult{}))
+
+	conn := newWSConnection(&mockWSConn{})
+	defer conn.close()
+	ctx := context.WithValue(context.Background(), wsConnectionKey{}, conn)
+
+	args = &SubscribeArgs{Type: "unknown"}
+	args.SetContext(ctx)
+	assert.NotNil(service.Subscribe(args, &SubscribeResult{}))
+
+	args = &SubscribeArgs{Type: SubscriptionFinalizedBlocks}
+	args.SetContext(ctx)
+	result := &SubscribeResult{}
+	assert.Nil(service.Subscribe(args, result))
+	assert.NotEmpty(result.Subscription)
+
+	unsubArgs := &UnsubscribeArgs{Subscription: result.Subscription}
+	unsubArgs.SetContext(ctx)
+	unsubResult := &UnsubscribeResult{}
+	assert.Nil(service.Unsubscribe(unsubArgs, unsubResult))
+	assert.True(unsubResult.Unsubscribed)
+}
+
+// --------------- Test Utilities --------------- //
+
+type mockWSConn struct {
+	mu      sync.Mutex
+	written [][]byte
+	closed  bool
+	block   chan struct{}
+}
+
+func (c *mockWSConn) Write(p []byte) (int, error) {
+	if c.block != nil {
+		<-c.block
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.written = append(c.written, append([]byte{}, p...))
+	return len(p), nil
+}
+
+func (c *mockWSConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *mockWSConn) messages() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.written
+}
+
+func (c *mockWSConn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
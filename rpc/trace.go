@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/ledger/vm"
+)
+
+// TraceConfig specifies what to collect while tracing a smart contract execution
+type TraceConfig struct {
+	DisableStructLogs bool `json:"disable_struct_logs"`
+	DisableMemory     bool `json:"disable_memory"`
+	DisableStack      bool `json:"disable_stack"`
+	DisableStorage    bool `json:"disable_storage"`
+	Limit             int  `json:"limit"` // maximum number of struct logs, zero means unlimited
+}
+
+type TraceResult struct {
+	VmReturn        string            `json:"vm_return"`
+	ContractAddress common.Address    `json:"contract_address"`
+	GasUsed         common.JSONUint64 `json:"gas_used"`
+	VmError         string            `json:"vm_error"`
+	RevertReason    string            `json:"revert_reason"`
+	StructLogs      []vm.StructLog    `json:"struct_logs"`
+	CallTrace       *vm.CallFrame     `json:"call_trace"`
+}
+
+// ------------------------------- TraceTransaction -----------------------------------
+
+type TraceTransactionArgs struct {
+	Hash string `json:"hash"`
+	TraceConfig
+}
+
+// TraceTransaction replays a committed smart contract transaction on top of the state of its
+// parent block (with the preceding transactions in the same block applied), and returns the
+// execution traces. The replay does NOT modify the globally consensus state.
+func (t *DneroRPCService) TraceTransaction(args *TraceTransactionArgs, result *TraceResult) (err error) {
+	if args.Hash == "" {
+		return errors.New("Transanction hash must be specified")
+	}
+	hash := common.HexToHash(args.Hash)
+
+	raw, block, found := t.chain.FindTxByHash(hash)
+	if !found {
+		return fmt.Errorf("Transaction %v is not found", args.Hash)
+	}
+	if block.Status.IsPending() || block.Status.IsInvalid() {
+		return fmt.Errorf("Transaction %v has not been executed yet", args.Hash)
+	}
+
+	tx, err := types.TxFromBytes(raw)
+	if err != nil {
+		return err
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return fmt.Errorf("Transaction %v is not a SmartContractTx", args.Hash)
+	}
+
+	txIndex := -1
+	for idx, blockTx := range block.Txs {
+		if bytes.Equal(blockTx, raw) {
+			txIndex = idx
+			break
+		}
+	}
+	if txIndex < 0 { // should not happen
+		return fmt.Errorf("Transaction %v is not found in block %v", args.Hash, block.Hash().Hex())
+	}
+
+	ledgerState, parentBlock, err := t.ledger.ReplayBlockTxs(block, txIndex)
+	if err != nil {
+		return err
+	}
+
+	traceSmartContractTx(parentBlock, sctx, ledgerState, &args.TraceConfig, result)
+
+	return nil
+}
+
+// ------------------------------- TraceCall -----------------------------------
+
+type TraceCallArgs struct {
+	SctxBytes string        `json:"sctx_bytes"`
+	Block     BlockSelector `json:"block"` // the latest block by default
+	TraceConfig
+}
+
+// TraceCall calls the smart contract the same way as CallSmartContract, and returns the
+// execution traces. It does NOT modify the globally consensus state.
+func (t *DneroRPCService) TraceCall(args *TraceCallArgs, result *TraceResult) (err error) {
+	block, ledgerState, err := t.getStateAt(args.Block.orDefault(BlockTagLatest))
+	if err != nil {
+		return err
+	}
+
+	blockHeight := block.Height + 1 // the call is executed as if in the next block
+	chainParams := common.GetChainParams(t.chain.ChainID)
+	if blockHeight < chainParams.HeightEnableSmartContract {
+		return fmt.Errorf("Smart contract feature not enabled until block height %v.", chainParams.HeightEnableSmartContract)
+	}
+
+	sctxBytes, err := hex.DecodeString(args.SctxBytes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := types.TxFromBytes(sctxBytes)
+	if err != nil {
+		return fmt.Errorf("Failed to parse SmartContractTx, error: %v", err)
+	}
+	sctx, ok := tx.(*types.SmartContractTx)
+	if !ok {
+		return fmt.Errorf("Failed to parse SmartContractTx: %v", args.SctxBytes)
+	}
+
+	traceSmartContractTx(block.Block, sctx, ledgerState, &args.TraceConfig, result)
+
+	return nil
+}
+
+// ------------------------------ Utils ------------------------------
+
+func traceSmartContractTx(parentBlock *core.Block, sctx *types.SmartContractTx, ledgerState *state.StoreView,
+	cfg *TraceConfig, result *TraceResult) {
+	callTracer := vm.NewCallTracer()
+	var tracer vm.Tracer = callTracer
+	var structLogger *vm.StructLogger
+	if !cfg.DisableStructLogs {
+		structLogger = vm.NewStructLogger(&vm.LogConfig{
+			DisableMemory:  cfg.DisableMemory,
+			DisableStack:   cfg.DisableStack,
+			DisableStorage: cfg.DisableStorage,
+			Limit:          cfg.Limit,
+		})
+		tracer = vm.NewMultiTracer(structLogger, callTracer)
+	}
+
+	config := vm.Config{
+		Debug:  true,
+		Tracer: tracer,
+	}
+	vmRet, contractAddr, gasUsed, vmErr := vm.ExecuteWithConfig(parentBlock, sctx, ledgerState, config)
+
+	result.VmReturn = hex.EncodeToString(vmRet)
+	result.ContractAddress = contractAddr
+	result.GasUsed = common.JSONUint64(gasUsed)
+	if vmErr != nil {
+		result.VmError = vmErr.Error()
+		if vm.IsExecutionReverted(vmErr) {
+			result.RevertReason, _ = vm.UnpackRevertReason(vmRet)
+		}
+	}
+	if structLogger != nil {
+		result.StructLogs = structLogger.StructLogs()
+	}
+	result.CallTrace = callTracer.CallFrame()
+}
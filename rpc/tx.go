@@ -13,12 +13,23 @@ import (
 	"github.com/dnerochain/dnero/common/hexutil"
 	"github.com/dnerochain/dnero/core"
 	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/eventbus"
 	"github.com/dnerochain/dnero/ledger/types"
 	"github.com/dnerochain/dnero/mempool"
+	"github.com/dnerochain/dnero/rpc/lib/rpc-codec/jsonrpc2"
 )
 
 const txTimeout = 60 * time.Second
 
+// Error codes returned when the mempool rejects a transaction, so the clients can tell the
+// rejection reasons apart without parsing the error messages.
+const (
+	ErrCodeTxAlreadySeen            = -32010
+	ErrCodeMempoolFull              = -32011
+	ErrCodeAccountTxQuotaExceeded   = -32012
+	ErrCodeReplacementTxUnderpriced = -32013
+)
+
 type Callback struct {
 	txHash   string
 	created  time.Time
@@ -92,6 +103,7 @@ var txCallbackManager = NewTxCallbackManager()
 
 func (t *DneroRPCService) txCallback() {
 	defer t.wg.Done()
+	defer t.finalizedBlocks.Unsubscribe()
 
 	timer := time.NewTicker(1 * time.Second)
 	defer timer.Stop()
@@ -101,7 +113,8 @@ func (t *DneroRPCService) txCallback() {
 		case <-t.ctx.Done():
 			logger.Infof("ctx.Done()")
 			return
-		case block := <-t.consensus.FinalizedBlocks():
+		case event := <-t.finalizedBlocks.Events():
+			block := event.Data.(*eventbus.BlockFinalizedEvent).Block.Block
 			logger.Infof("Processing finalized block, height=%v", block.Height)
 
 			for _, tx := range block.Txs {
@@ -152,7 +165,7 @@ func (t *DneroRPCService) BroadcastRawTransaction(
 		logger.Infof("Broadcasted raw transaction (sync): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
 	} else {
 		logger.Warnf("Failed to broadcast raw transaction (sync): %v, hash: %v, err: %v", hex.EncodeToString(txBytes), hash.Hex(), err)
-		return err
+		return toMempoolRPCError(err)
 	}
 
 	finalized := make(chan *core.Block)
@@ -210,7 +223,7 @@ func (t *DneroRPCService) BroadcastRawTransactionAsync(
 
 	logger.Warnf("Failed to broadcast raw transaction (async): %v, hash: %v, err: %v", hex.EncodeToString(txBytes), hash.Hex(), err)
 
-	return err
+	return toMempoolRPCError(err)
 }
 
 // ------------------------------- BroadcastRawEthTransaction -----------------------------------
@@ -291,3 +304,19 @@ func decodeTxHexBytes(txBytes string) ([]byte, error) {
 	}
 	return hex.DecodeString(txBytes)
 }
+
+// toMempoolRPCError attaches a distinct error code to the mempool rejections.
+func toMempoolRPCError(err error) error {
+	switch err {
+	case mempool.DuplicateTxError:
+		return jsonrpc2.NewError(ErrCodeTxAlreadySeen, err.Error())
+	case mempool.MempoolFullError:
+		return jsonrpc2.NewError(ErrCodeMempoolFull, err.Error())
+	case mempool.AccountTxQuotaExceededError:
+		return jsonrpc2.NewError(ErrCodeAccountTxQuotaExceeded, err.Error())
+	case mempool.ReplacementTxUnderpricedError:
+		return jsonrpc2.NewError(ErrCodeReplacementTxUnderpriced, err.Error())
+	default:
+		return err
+	}
+}
@@ -285,25 +285,11 @@ func ExportSnapshotV3(db database.Database, consensus *cns.ConsensusEngine, chai
 
 	// ------------ Export the Last Checkpoint Section ------------- //
 
-	lastFinalizedBlockHeight := lastFinalizedBlock.Height
-	lastCheckpointHeight := common.LastCheckPointHeight(lastFinalizedBlockHeight)
-	lastCheckpoint := &core.LastCheckpoint{}
-
-	currHeight := lastFinalizedBlockHeight
-	currBlock := lastFinalizedBlock
-	for currHeight > lastCheckpointHeight {
-		parentHash := currBlock.Parent
-		currBlock, err = chain.FindBlock(parentHash)
-		if err != nil {
-			logger.Errorf("Failed to get intermediate block %v, %v", parentHash.Hex(), err)
-			return "", err
-		}
-		lastCheckpoint.IntermediateHeaders = append(lastCheckpoint.IntermediateHeaders, currBlock.Block.BlockHeader)
-		currHeight = currBlock.Height
+	lastCheckpoint, err := exportLastCheckpoint(chain, lastFinalizedBlock)
+	if err != nil {
+		return "", err
 	}
-
-	lastCheckpointBlock := currBlock
-	lastCheckpoint.CheckpointHeader = lastCheckpointBlock.BlockHeader
+	lastCheckpointHeader := lastCheckpoint.CheckpointHeader
 
 	err = core.WriteLastCheckpoint(writer, lastCheckpoint)
 	if err != nil {
@@ -314,120 +300,16 @@ func ExportSnapshotV3(db database.Database, consensus *cns.ConsensusEngine, chai
 
 	metadata := &core.SnapshotMetadata{}
 	var genesisBlockHeader *core.BlockHeader
-	kvStore := kvstore.NewKVStore(db)
-	hl := sv.GetStakeTransactionHeightList().Heights
-	for _, height := range hl {
-		// check kvstore first
-		blockTrio := &core.SnapshotBlockTrio{}
-		blockTrioKey := []byte(core.BlockTrioStoreKeyPrefix + strconv.FormatUint(height, 10))
-		err := kvStore.Get(blockTrioKey, blockTrio)
-		if err == nil {
-			metadata.ProofTrios = append(metadata.ProofTrios, *blockTrio)
-			if height == core.GenesisBlockHeight {
-				genesisBlockHeader = blockTrio.Second.Header
-			}
-			continue
-		}
-
-		if height == core.GenesisBlockHeight {
-			blocks := chain.FindBlocksByHeight(core.GenesisBlockHeight)
-			genesisBlock := blocks[0]
-			genesisBlockHeader = genesisBlock.BlockHeader
-			metadata.ProofTrios = append(metadata.ProofTrios,
-				core.SnapshotBlockTrio{
-					First:  core.SnapshotFirstBlock{},
-					Second: core.SnapshotSecondBlock{Header: genesisBlock.BlockHeader},
-					Third:  core.SnapshotThirdBlock{},
-				})
-		} else {
-			blocks := chain.FindBlocksByHeight(height)
-			foundDirectlyFinalizedBlock := false
-			for _, block := range blocks {
-				if block.Status.IsDirectlyFinalized() {
-					var child, grandChild core.BlockHeader
-					b, err := getFinalizedChild(block, chain)
-					if err != nil {
-						return "", err
-					}
-					if b != nil {
-						child = *b.BlockHeader
-						b, err = getFinalizedChild(b, chain)
-						if err != nil {
-							return "", err
-						}
-						if b != nil {
-							grandChild = *b.BlockHeader
-						} else {
-							return "", fmt.Errorf("Can't find finalized grandchild block. " +
-								"Likely the last finalized block also contains stake change transactions. " +
-								"Please try again in 30 seconds.")
-						}
-					} else {
-						return "", fmt.Errorf("Can't find finalized child block. " +
-							"Likely the last finalized block also contains stake change transactions. " +
-							"Please try again in 30 seconds.")
-					}
-
-					if child.HCC.BlockHash != block.Hash() || grandChild.HCC.BlockHash != child.Hash() {
-						return "", fmt.Errorf("Invalid block HCC link for validator set changes")
-					}
-					if grandChild.HCC.Votes.IsEmpty() {
-						return "", fmt.Errorf("Missing block HCC votes for validator set changes")
-					}
-					for _, vote := range grandChild.HCC.Votes.Votes() {
-						if vote.Block != child.Hash() {
-							return "", fmt.Errorf("Invalid block HCC votes for validator set changes")
-						}
-					}
-
-					vcpProof, err := proveVCP(block, db)
-					if err != nil {
-						return "", fmt.Errorf("Failed to get VCP Proof")
-					}
-					metadata.ProofTrios = append(metadata.ProofTrios,
-						core.SnapshotBlockTrio{
-							First:  core.SnapshotFirstBlock{Header: block.BlockHeader, Proof: *vcpProof},
-							Second: core.SnapshotSecondBlock{Header: &child},
-							Third:  core.SnapshotThirdBlock{Header: &grandChild},
-						})
-					foundDirectlyFinalizedBlock = true
-					break
-				}
-			}
-			if !foundDirectlyFinalizedBlock {
-				return "", fmt.Errorf("Finalized block not found for height %v", height)
-			}
-		}
-	}
-
-	parentBlock, err := chain.FindBlock(lastFinalizedBlock.Parent)
+	metadata.ProofTrios, genesisBlockHeader, err = exportProofTrios(sv, chain, db)
 	if err != nil {
-		return "", fmt.Errorf("Failed to find last finalized block's parent, %v", err)
-	}
-	childBlock, err := getAtLeastCommittedChild(lastFinalizedBlock, chain)
-	if err != nil {
-		return "", fmt.Errorf("Failed to find last finalized block's committed child, %v", err)
-	}
-
-	if lastFinalizedBlock.HCC.BlockHash != parentBlock.Hash() {
-		return "", fmt.Errorf("Parent block hash mismatch: %v vs %v", lastFinalizedBlock.HCC.BlockHash, parentBlock.Hash())
-	}
-
-	if childBlock.HCC.BlockHash != lastFinalizedBlock.Hash() {
-		return "", fmt.Errorf("Finalized block hash mismatch: %v vs %v", childBlock.HCC.BlockHash, lastFinalizedBlock.Hash())
+		return "", err
 	}
-
-	childVoteSet := chain.FindVotesByHash(childBlock.Hash())
-
-	vcpProof, err := proveVCP(parentBlock, db)
+	tailTrio, err := exportTailTrio(lastFinalizedBlock, chain, db)
 	if err != nil {
-		return "", fmt.Errorf("Failed to get VCP Proof")
-	}
-	metadata.TailTrio = core.SnapshotBlockTrio{
-		First:  core.SnapshotFirstBlock{Header: parentBlock.BlockHeader, Proof: *vcpProof},
-		Second: core.SnapshotSecondBlock{Header: lastFinalizedBlock.BlockHeader},
-		Third:  core.SnapshotThirdBlock{Header: childBlock.BlockHeader, VoteSet: childVoteSet},
+		return "", err
 	}
+	metadata.TailTrio = *tailTrio
+	parentBlockHeader := tailTrio.First.Header
 
 	err = core.WriteMetadata(writer, metadata)
 	if err != nil {
@@ -441,13 +323,13 @@ func ExportSnapshotV3(db database.Database, consensus *cns.ConsensusEngine, chai
 	writeStoreViewV3(genesisSV, false, writer, db, common.Hash{})
 
 	// Last checkpoint storeview
-	if lastFinalizedBlock.Height != lastCheckpointHeight {
-		lastCheckpointSV := state.NewStoreView(lastCheckpointBlock.Height, lastCheckpointBlock.StateHash, db)
+	if lastFinalizedBlock.Height != lastCheckpointHeader.Height {
+		lastCheckpointSV := state.NewStoreView(lastCheckpointHeader.Height, lastCheckpointHeader.StateHash, db)
 		writeStoreViewV3(lastCheckpointSV, false, writer, db, genesisSV.Hash())
 	}
 
 	// Parent block storeview
-	parentSV := state.NewStoreView(parentBlock.Height, parentBlock.StateHash, db)
+	parentSV := state.NewStoreView(parentBlockHeader.Height, parentBlockHeader.StateHash, db)
 	writeStoreViewV3(parentSV, false, writer, db, genesisSV.Hash())
 	writeStoreViewV3(sv, true, writer, db, parentSV.Hash())
 
@@ -501,84 +383,203 @@ func ExportSnapshotV4(db database.Database, consensus *cns.ConsensusEngine, chai
 
 	// ------------ Export the Last Checkpoint Section ------------- //
 
-	lastFinalizedBlockHeight := lastFinalizedBlock.Height
-	lastCheckpointHeight := common.LastCheckPointHeight(lastFinalizedBlockHeight)
+	lastCheckpoint, err := exportLastCheckpoint(chain, lastFinalizedBlock)
+	if err != nil {
+		return "", err
+	}
+	lastCheckpointHeader := lastCheckpoint.CheckpointHeader
+
+	err = core.WriteLastCheckpoint(writer, lastCheckpoint)
+	if err != nil {
+		return "", err
+	}
+
+	// -------------- Export the Metadata Section -------------- //
+
+	metadata := &core.SnapshotMetadata{}
+	tailTrio, err := exportTailTrio(lastFinalizedBlock, chain, db)
+	if err != nil {
+		return "", err
+	}
+	metadata.TailTrio = *tailTrio
+	parentBlockHeader := tailTrio.First.Header
+
+	err = core.WriteMetadata(writer, metadata)
+	if err != nil {
+		return "", err
+	}
+
+	// -------------- Export the StoreView Section -------------- //
+	// Last checkpoint storeview
+	if lastFinalizedBlock.Height != lastCheckpointHeader.Height {
+		lastCheckpointSV := state.NewStoreView(lastCheckpointHeader.Height, lastCheckpointHeader.StateHash, db)
+		writeStoreViewV3(lastCheckpointSV, false, writer, db, common.Hash{})
+	}
+
+	// Parent block storeview
+	parentSV := state.NewStoreView(parentBlockHeader.Height, parentBlockHeader.StateHash, db)
+	writeStoreViewV3(parentSV, false, writer, db, common.Hash{})
+
+	writeStoreViewV3(sv, true, writer, db, parentSV.Hash())
+
+	return filename, nil
+}
+
+// exportLastCheckpoint collects the last checkpoint at or below the given finalized block, and the
+// headers of the intermediate blocks between them.
+func exportLastCheckpoint(chain *blockchain.Chain, lastFinalizedBlock *core.ExtendedBlock) (*core.LastCheckpoint, error) {
+	var err error
+	lastCheckpointHeight := common.LastCheckPointHeight(lastFinalizedBlock.Height)
 	lastCheckpoint := &core.LastCheckpoint{}
 
-	currHeight := lastFinalizedBlockHeight
+	currHeight := lastFinalizedBlock.Height
 	currBlock := lastFinalizedBlock
 	for currHeight > lastCheckpointHeight {
 		parentHash := currBlock.Parent
 		currBlock, err = chain.FindBlock(parentHash)
 		if err != nil {
 			logger.Errorf("Failed to get intermediate block %v, %v", parentHash.Hex(), err)
-			return "", err
+			return nil, err
 		}
 		lastCheckpoint.IntermediateHeaders = append(lastCheckpoint.IntermediateHeaders, currBlock.Block.BlockHeader)
 		currHeight = currBlock.Height
 	}
 
-	lastCheckpointBlock := currBlock
+	lastCheckpoint.CheckpointHeader = currBlock.BlockHeader
+	return lastCheckpoint, nil
+}
 
-	lastCheckpoint.CheckpointHeader = lastCheckpointBlock.BlockHeader
+// exportProofTrios collects the block trios proving the validator set changes from the genesis block up to
+// the given storeview. It also returns the genesis block header.
+func exportProofTrios(sv *state.StoreView, chain *blockchain.Chain, db database.Database) ([]core.SnapshotBlockTrio, *core.BlockHeader, error) {
+	proofTrios := []core.SnapshotBlockTrio{}
+	var genesisBlockHeader *core.BlockHeader
+	kvStore := kvstore.NewKVStore(db)
+	hl := sv.GetStakeTransactionHeightList().Heights
+	for _, height := range hl {
+		// check kvstore first
+		blockTrio := &core.SnapshotBlockTrio{}
+		blockTrioKey := []byte(core.BlockTrioStoreKeyPrefix + strconv.FormatUint(height, 10))
+		err := kvStore.Get(blockTrioKey, blockTrio)
+		if err == nil {
+			proofTrios = append(proofTrios, *blockTrio)
+			if height == core.GenesisBlockHeight {
+				genesisBlockHeader = blockTrio.Second.Header
+			}
+			continue
+		}
 
-	err = core.WriteLastCheckpoint(writer, lastCheckpoint)
-	if err != nil {
-		return "", err
-	}
+		if height == core.GenesisBlockHeight {
+			blocks := chain.FindBlocksByHeight(core.GenesisBlockHeight)
+			genesisBlock := blocks[0]
+			genesisBlockHeader = genesisBlock.BlockHeader
+			proofTrios = append(proofTrios,
+				core.SnapshotBlockTrio{
+					First:  core.SnapshotFirstBlock{},
+					Second: core.SnapshotSecondBlock{Header: genesisBlock.BlockHeader},
+					Third:  core.SnapshotThirdBlock{},
+				})
+		} else {
+			blocks := chain.FindBlocksByHeight(height)
+			foundDirectlyFinalizedBlock := false
+			for _, block := range blocks {
+				if block.Status.IsDirectlyFinalized() {
+					var child, grandChild core.BlockHeader
+					b, err := getFinalizedChild(block, chain)
+					if err != nil {
+						return nil, nil, err
+					}
+					if b != nil {
+						child = *b.BlockHeader
+						b, err = getFinalizedChild(b, chain)
+						if err != nil {
+							return nil, nil, err
+						}
+						if b != nil {
+							grandChild = *b.BlockHeader
+						} else {
+							return nil, nil, fmt.Errorf("Can't find finalized grandchild block. " +
+								"Likely the last finalized block also contains stake change transactions. " +
+								"Please try again in 30 seconds.")
+						}
+					} else {
+						return nil, nil, fmt.Errorf("Can't find finalized child block. " +
+							"Likely the last finalized block also contains stake change transactions. " +
+							"Please try again in 30 seconds.")
+					}
 
-	// -------------- Export the Metadata Section -------------- //
+					if child.HCC.BlockHash != block.Hash() || grandChild.HCC.BlockHash != child.Hash() {
+						return nil, nil, fmt.Errorf("Invalid block HCC link for validator set changes")
+					}
+					if grandChild.HCC.Votes.IsEmpty() {
+						return nil, nil, fmt.Errorf("Missing block HCC votes for validator set changes")
+					}
+					for _, vote := range grandChild.HCC.Votes.Votes() {
+						if vote.Block != child.Hash() {
+							return nil, nil, fmt.Errorf("Invalid block HCC votes for validator set changes")
+						}
+					}
 
-	metadata := &core.SnapshotMetadata{}
+					vcpProof, err := proveVCP(block, db)
+					if err != nil {
+						return nil, nil, fmt.Errorf("Failed to get VCP Proof")
+					}
+					proofTrios = append(proofTrios,
+						core.SnapshotBlockTrio{
+							First:  core.SnapshotFirstBlock{Header: block.BlockHeader, Proof: *vcpProof},
+							Second: core.SnapshotSecondBlock{Header: &child},
+							Third:  core.SnapshotThirdBlock{Header: &grandChild},
+						})
+					foundDirectlyFinalizedBlock = true
+					break
+				}
+			}
+			if !foundDirectlyFinalizedBlock {
+				return nil, nil, fmt.Errorf("Finalized block not found for height %v", height)
+			}
+		}
+	}
 
+	if genesisBlockHeader == nil {
+		return nil, nil, fmt.Errorf("Genesis block not found in the validator set change proofs")
+	}
+	return proofTrios, genesisBlockHeader, nil
+}
+
+// exportTailTrio collects the parent and a committed child of the given finalized block, together with
+// the VCP proof of the parent and the votes of the child.
+func exportTailTrio(lastFinalizedBlock *core.ExtendedBlock, chain *blockchain.Chain, db database.Database) (*core.SnapshotBlockTrio, error) {
 	parentBlock, err := chain.FindBlock(lastFinalizedBlock.Parent)
 	if err != nil {
-		return "", fmt.Errorf("Failed to find last finalized block's parent, %v", err)
+		return nil, fmt.Errorf("Failed to find last finalized block's parent, %v", err)
 	}
 	childBlock, err := getAtLeastCommittedChild(lastFinalizedBlock, chain)
 	if err != nil {
-		return "", fmt.Errorf("Failed to find last finalized block's committed child, %v", err)
+		return nil, fmt.Errorf("Failed to find last finalized block's committed child, %v", err)
+	}
+	if childBlock == nil {
+		return nil, fmt.Errorf("Last finalized block %v has no committed child yet", lastFinalizedBlock.Hash().Hex())
 	}
 
 	if lastFinalizedBlock.HCC.BlockHash != parentBlock.Hash() {
-		return "", fmt.Errorf("Parent block hash mismatch: %v vs %v", lastFinalizedBlock.HCC.BlockHash, parentBlock.Hash())
+		return nil, fmt.Errorf("Parent block hash mismatch: %v vs %v", lastFinalizedBlock.HCC.BlockHash, parentBlock.Hash())
 	}
 
 	if childBlock.HCC.BlockHash != lastFinalizedBlock.Hash() {
-		return "", fmt.Errorf("Finalized block hash mismatch: %v vs %v", childBlock.HCC.BlockHash, lastFinalizedBlock.Hash())
+		return nil, fmt.Errorf("Finalized block hash mismatch: %v vs %v", childBlock.HCC.BlockHash, lastFinalizedBlock.Hash())
 	}
 
 	childVoteSet := chain.FindVotesByHash(childBlock.Hash())
 
 	vcpProof, err := proveVCP(parentBlock, db)
 	if err != nil {
-		return "", fmt.Errorf("Failed to get VCP Proof")
+		return nil, fmt.Errorf("Failed to get VCP Proof")
 	}
-	metadata.TailTrio = core.SnapshotBlockTrio{
+	return &core.SnapshotBlockTrio{
 		First:  core.SnapshotFirstBlock{Header: parentBlock.BlockHeader, Proof: *vcpProof},
 		Second: core.SnapshotSecondBlock{Header: lastFinalizedBlock.BlockHeader},
 		Third:  core.SnapshotThirdBlock{Header: childBlock.BlockHeader, VoteSet: childVoteSet},
-	}
-
-	err = core.WriteMetadata(writer, metadata)
-	if err != nil {
-		return "", err
-	}
-
-	// -------------- Export the StoreView Section -------------- //
-	// Last checkpoint storeview
-	if lastFinalizedBlock.Height != lastCheckpointHeight {
-		lastCheckpointSV := state.NewStoreView(lastCheckpointBlock.Height, lastCheckpointBlock.StateHash, db)
-		writeStoreViewV3(lastCheckpointSV, false, writer, db, common.Hash{})
-	}
-
-	// Parent block storeview
-	parentSV := state.NewStoreView(parentBlock.Height, parentBlock.StateHash, db)
-	writeStoreViewV3(parentSV, false, writer, db, common.Hash{})
-
-	writeStoreViewV3(sv, true, writer, db, parentSV.Hash())
-
-	return filename, nil
+	}, nil
 }
 
 func proveVCP(block *core.ExtendedBlock, db database.Database) (*core.VCPProof, error) {
@@ -201,38 +201,7 @@ func loadSnapshot(snapshotFilePath string, db database.Database, logStr string)
 			return nil, nil, fmt.Errorf("Failed to load snapshot last checkpoint, %v", err)
 		}
 
-		ckb := core.Block{
-			BlockHeader: lastCheckpoint.CheckpointHeader,
-		}
-		eckb := core.ExtendedBlock{
-			Block:  &ckb,
-			Status: core.BlockStatusTrusted, // HCC links between all three blocks
-		}
-		ckbHash := ckb.BlockHeader.Hash()
-
-		existingCkbExt := core.ExtendedBlock{}
-		if kvstore.Get(ckbHash[:], &existingCkbExt) != nil {
-			logger.Infof("Saving the last checkpoint block: %v", ckbHash.Hex())
-			err = kvstore.Put(ckbHash[:], &eckb)
-			if err != nil {
-				logger.Panicf("Failed to save the last checkpoint: %v, err: %v", ckbHash.Hex(), err)
-			}
-		}
-
-		for _, intermediateHeader := range lastCheckpoint.IntermediateHeaders {
-			ibHash := intermediateHeader.Hash()
-			eib := core.ExtendedBlock{
-				Block: &core.Block{BlockHeader: intermediateHeader},
-			}
-			existingEib := core.ExtendedBlock{}
-			if kvstore.Get(ibHash[:], &existingEib) != nil {
-				logger.Debugf("Saving intermediate blocks: %v", ibHash.Hex())
-				err = kvstore.Put(ibHash[:], &eib)
-				if err != nil {
-					logger.Panicf("Failed to save ntermediate block: %v, err: %v", ibHash.Hex(), err)
-				}
-			}
-		}
+		saveLastCheckpoint(&lastCheckpoint, kvstore)
 	}
 
 	metadata := core.SnapshotMetadata{}
@@ -277,14 +246,7 @@ func loadSnapshot(snapshotFilePath string, db database.Database, logStr string)
 
 	// --------------------- Save Proofs and Tail Blocks  --------------------- //
 
-	for _, blockTrio := range metadata.ProofTrios {
-		blockTrioKey := []byte(core.BlockTrioStoreKeyPrefix + strconv.FormatUint(blockTrio.First.Header.Height, 10))
-		err = kvstore.Put(blockTrioKey, blockTrio)
-		if err != nil {
-			logger.Panicf("Failed to save ProofTrios: err: %v", err)
-		}
-	}
-
+	saveProofTrios(&metadata, kvstore)
 	secondBlockHeader := saveTailBlocks(&metadata, sv, kvstore)
 
 	// ----------------------------- More Validity Checks -------------------------- //
@@ -513,7 +475,7 @@ func loadChainSegment(filePath string, start, end uint64, prevBlock *core.Extend
 				if proofTrio.First.Header.Height == core.GenesisBlockHeight {
 					provenValSet, err = checkGenesisBlock(proofTrio.Second.Header, db)
 				} else {
-					provenValSet, err = getValidatorSetFromVCPProof(proofTrio.First.Header.StateHash, &proofTrio.First.Proof)
+					provenValSet, err = GetValidatorSetFromVCPProof(proofTrio.First.Header.StateHash, &proofTrio.First.Proof)
 				}
 				if err != nil {
 					return nil, fmt.Errorf("Failed to retrieve validator set from VCP proof: %v", err)
@@ -522,7 +484,7 @@ func loadChainSegment(filePath string, start, end uint64, prevBlock *core.Extend
 		}
 
 		// check votes
-		if err := validateVotes(provenValSet, block.BlockHeader, backupBlock.Votes); err != nil {
+		if err := ValidateVotes(provenValSet, block.BlockHeader, backupBlock.Votes); err != nil {
 			return nil, fmt.Errorf("Failed to validate voteSet, %v", err)
 		}
 
@@ -810,7 +772,7 @@ func checkSnapshotV4(sv *state.StoreView, metadata *core.SnapshotMetadata, db da
 	var err error
 
 	first := tailTrio.First
-	valSet, err = getValidatorSetFromVCPProof(first.Header.StateHash, &first.Proof)
+	valSet, err = GetValidatorSetFromVCPProof(first.Header.StateHash, &first.Proof)
 	if err != nil {
 		return fmt.Errorf("Failed to retrieve validator set from VCP proof: %v", err)
 	}
@@ -851,10 +813,10 @@ func checkProofTrios(proofTrios []core.SnapshotBlockTrio, db database.Database)
 			}
 
 			// third.Header.HCC.Votes contains the votes for the second block in the trio
-			if err := validateVotes(provenValSet, second.Header, third.Header.HCC.Votes); err != nil {
+			if err := ValidateVotes(provenValSet, second.Header, third.Header.HCC.Votes); err != nil {
 				return nil, fmt.Errorf("Failed to validate voteSet, %v", err)
 			}
-			provenValSet, err = getValidatorSetFromVCPProof(first.Header.StateHash, &first.Proof)
+			provenValSet, err = GetValidatorSetFromVCPProof(first.Header.StateHash, &first.Proof)
 			if err != nil {
 				return nil, fmt.Errorf("Failed to retrieve validator set from VCP proof: %v", err)
 			}
@@ -876,7 +838,7 @@ func checkTailTrio(sv *state.StoreView, provenValSet *core.ValidatorSet, tailTri
 			return err
 		}
 	} else {
-		validateVotes(provenValSet, third.Header, third.VoteSet)
+		ValidateVotes(provenValSet, third.Header, third.VoteSet)
 		retrievedValSet := getValidatorSetFromSV(sv)
 		if !provenValSet.Equals(retrievedValSet) {
 			return fmt.Errorf("The latest proven and retrieved validator set does not match")
@@ -887,8 +849,23 @@ func checkTailTrio(sv *state.StoreView, provenValSet *core.ValidatorSet, tailTri
 }
 
 func checkGenesisBlock(block *core.BlockHeader, db database.Database) (*core.ValidatorSet, error) {
+	if err := checkGenesisBlockHash(block); err != nil {
+		return nil, err
+	}
+
+	// now that the block hash matches with the expected genesis block hash,
+	// the block and its state trie is considerred valid. We can retrieve the
+	// genesis validator set from its state trie
+	gsv := state.NewStoreView(block.Height, block.StateHash, db)
+
+	genesisValidatorSet := getValidatorSetFromSV(gsv)
+
+	return genesisValidatorSet, nil
+}
+
+func checkGenesisBlockHash(block *core.BlockHeader) error {
 	if block.Height != core.GenesisBlockHeight {
-		return nil, fmt.Errorf("Invalid genesis block height: %v", block.Height)
+		return fmt.Errorf("Invalid genesis block height: %v", block.Height)
 	}
 
 	var expectedGenesisHash string
@@ -902,21 +879,16 @@ func checkGenesisBlock(block *core.BlockHeader, db database.Database) (*core.Val
 	// logger.Infof("Acutal   genesis hash: %v", block.Hash().Hex())
 
 	if block.Hash() != common.HexToHash(expectedGenesisHash) {
-		return nil, fmt.Errorf("Genesis block hash mismatch, expected: %v, calculated: %v",
+		return fmt.Errorf("Genesis block hash mismatch, expected: %v, calculated: %v",
 			expectedGenesisHash, block.Hash().Hex())
 	}
 
-	// now that the block hash matches with the expected genesis block hash,
-	// the block and its state trie is considerred valid. We can retrieve the
-	// genesis validator set from its state trie
-	gsv := state.NewStoreView(block.Height, block.StateHash, db)
-
-	genesisValidatorSet := getValidatorSetFromSV(gsv)
-
-	return genesisValidatorSet, nil
+	return nil
 }
 
-func getValidatorSetFromVCPProof(stateHash common.Hash, recoverredVp *core.VCPProof) (*core.ValidatorSet, error) {
+// GetValidatorSetFromVCPProof verifies the validator candidate pool proof against the given state root, and
+// returns the validator set selected from the proven pool.
+func GetValidatorSetFromVCPProof(stateHash common.Hash, recoverredVp *core.VCPProof) (*core.ValidatorSet, error) {
 	serializedVCP, _, err := trie.VerifyProof(stateHash, state.ValidatorCandidatePoolKey(), recoverredVp)
 	if err != nil {
 		return nil, err
@@ -935,7 +907,9 @@ func getValidatorSetFromSV(sv *state.StoreView) *core.ValidatorSet {
 	return consensus.SelectTopStakeHoldersAsValidators(vcp)
 }
 
-func validateVotes(validatorSet *core.ValidatorSet, block *core.BlockHeader, voteSet *core.VoteSet) error {
+// ValidateVotes checks that the vote set is a valid commit certificate of the block, i.e. the votes are
+// signed by the validators and carry the majority of the stake.
+func ValidateVotes(validatorSet *core.ValidatorSet, block *core.BlockHeader, voteSet *core.VoteSet) error {
 	if !validatorSet.HasMajority(voteSet) {
 		return fmt.Errorf("block doesn't have majority votes")
 	}
@@ -955,6 +929,51 @@ func validateVotes(validatorSet *core.ValidatorSet, block *core.BlockHeader, vot
 	return nil
 }
 
+func saveLastCheckpoint(lastCheckpoint *core.LastCheckpoint, kvstore store.Store) {
+	ckb := core.Block{
+		BlockHeader: lastCheckpoint.CheckpointHeader,
+	}
+	eckb := core.ExtendedBlock{
+		Block:  &ckb,
+		Status: core.BlockStatusTrusted, // HCC links between all three blocks
+	}
+	ckbHash := ckb.BlockHeader.Hash()
+
+	existingCkbExt := core.ExtendedBlock{}
+	if kvstore.Get(ckbHash[:], &existingCkbExt) != nil {
+		logger.Infof("Saving the last checkpoint block: %v", ckbHash.Hex())
+		err := kvstore.Put(ckbHash[:], &eckb)
+		if err != nil {
+			logger.Panicf("Failed to save the last checkpoint: %v, err: %v", ckbHash.Hex(), err)
+		}
+	}
+
+	for _, intermediateHeader := range lastCheckpoint.IntermediateHeaders {
+		ibHash := intermediateHeader.Hash()
+		eib := core.ExtendedBlock{
+			Block: &core.Block{BlockHeader: intermediateHeader},
+		}
+		existingEib := core.ExtendedBlock{}
+		if kvstore.Get(ibHash[:], &existingEib) != nil {
+			logger.Debugf("Saving intermediate blocks: %v", ibHash.Hex())
+			err := kvstore.Put(ibHash[:], &eib)
+			if err != nil {
+				logger.Panicf("Failed to save ntermediate block: %v, err: %v", ibHash.Hex(), err)
+			}
+		}
+	}
+}
+
+func saveProofTrios(metadata *core.SnapshotMetadata, kvstore store.Store) {
+	for _, blockTrio := range metadata.ProofTrios {
+		blockTrioKey := []byte(core.BlockTrioStoreKeyPrefix + strconv.FormatUint(blockTrio.First.Header.Height, 10))
+		err := kvstore.Put(blockTrioKey, blockTrio)
+		if err != nil {
+			logger.Panicf("Failed to save ProofTrios: err: %v", err)
+		}
+	}
+}
+
 func saveTailBlocks(metadata *core.SnapshotMetadata, sv *state.StoreView, kvstore store.Store) *core.BlockHeader {
 	tailBlockTrio := &metadata.TailTrio
 	firstBlock := core.Block{BlockHeader: tailBlockTrio.First.Header}
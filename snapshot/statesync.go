@@ -0,0 +1,275 @@
+package snapshot
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/dnerochain/dnero/blockchain"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/crypto"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/store/database"
+	"github.com/dnerochain/dnero/store/kvstore"
+	"github.com/dnerochain/dnero/store/trie"
+	"github.com/spf13/viper"
+)
+
+// StateSyncBatchSize is the max number of missing trie nodes handed to the NodeFetcher at a time
+const StateSyncBatchSize = 1024
+
+const stateSyncLogInterval = 100000
+
+// NodeFetcher retrieves the trie nodes with the given hashes, e.g. from the peers. It may return only
+// a subset of the requested nodes. The nodes are verified against their hashes by the caller, so the
+// source of the nodes does not need to be trusted.
+type NodeFetcher func(hashes []common.Hash) (map[common.Hash]common.Bytes, error)
+
+// BuildStateSyncCheckpoint collects the metadata to sync the state of the given finalized block, i.e. the
+// last checkpoint, the proofs of the validator set changes and the tail trio, the same as ExportSnapshotV3.
+func BuildStateSyncCheckpoint(db database.Database, chain *blockchain.Chain, lastFinalizedBlock *core.ExtendedBlock) (*core.StateSyncCheckpoint, error) {
+	sv := state.NewStoreView(lastFinalizedBlock.Height, lastFinalizedBlock.StateHash, db)
+	if sv == nil {
+		return nil, fmt.Errorf("State of block %v is not available", lastFinalizedBlock.Hash().Hex())
+	}
+
+	lastCheckpoint, err := exportLastCheckpoint(chain, lastFinalizedBlock)
+	if err != nil {
+		return nil, err
+	}
+	proofTrios, _, err := exportProofTrios(sv, chain, db)
+	if err != nil {
+		return nil, err
+	}
+	tailTrio, err := exportTailTrio(lastFinalizedBlock, chain, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.StateSyncCheckpoint{
+		LastCheckpoint: *lastCheckpoint,
+		Metadata: core.SnapshotMetadata{
+			ProofTrios: proofTrios,
+			TailTrio:   *tailTrio,
+		},
+	}, nil
+}
+
+// ImportStateSyncCheckpoint verifies the checkpoint against the validator set changes proven from the genesis
+// block, downloads the state tries of the checkpoint with the fetcher, and saves the proofs and the tail blocks
+// the same way as ImportSnapshot does. It returns the header of the block to be used as the root of the chain.
+func ImportStateSyncCheckpoint(checkpoint *core.StateSyncCheckpoint, db database.Database, fetch NodeFetcher) (*core.BlockHeader, error) {
+	metadata := &checkpoint.Metadata
+	lastCheckpoint := &checkpoint.LastCheckpoint
+	tailTrio := &metadata.TailTrio
+	if lastCheckpoint.CheckpointHeader == nil || tailTrio.Second.Header == nil {
+		return nil, fmt.Errorf("Incomplete state sync checkpoint")
+	}
+	secondBlock := tailTrio.Second.Header
+	chainID := viper.GetString(common.CfgGenesisChainID)
+	if secondBlock.ChainID != chainID {
+		return nil, fmt.Errorf("ChainID mismatch: block.ChainID(%s) != %s", secondBlock.ChainID, chainID)
+	}
+
+	// The genesis block is trusted by its hash, and its state is needed to verify the validator set changes
+	var genesisBlock *core.BlockHeader
+	if secondBlock.Height == core.GenesisBlockHeight {
+		genesisBlock = secondBlock
+	} else if len(metadata.ProofTrios) > 0 {
+		genesisBlock = metadata.ProofTrios[0].Second.Header
+	}
+	if genesisBlock == nil {
+		return nil, fmt.Errorf("Genesis block not found in the validator set change proofs")
+	}
+	if genesisBlock.ChainID != chainID {
+		return nil, fmt.Errorf("ChainID mismatch: genesis block.ChainID(%s) != %s", genesisBlock.ChainID, chainID)
+	}
+	if err := checkGenesisBlockHash(genesisBlock); err != nil {
+		return nil, err
+	}
+	logger.Infof("Syncing the genesis state, root: %v", genesisBlock.StateHash.Hex())
+	if err := SyncStateTrie(genesisBlock.StateHash, false, db, fetch); err != nil {
+		return nil, err
+	}
+
+	var provenValSet *core.ValidatorSet
+	var err error
+	if secondBlock.Height != core.GenesisBlockHeight {
+		provenValSet, err = checkProofTrios(metadata.ProofTrios, db)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkTailTrioVotes(provenValSet, tailTrio); err != nil {
+			return nil, err
+		}
+
+		// Now that the tail blocks are proven, sync the same state tries as a V4 snapshot contains
+		lastCheckpointHeader := lastCheckpoint.CheckpointHeader
+		if lastCheckpointHeader.Height != secondBlock.Height {
+			logger.Infof("Syncing the last checkpoint state, height: %v, root: %v", lastCheckpointHeader.Height, lastCheckpointHeader.StateHash.Hex())
+			if err = SyncStateTrie(lastCheckpointHeader.StateHash, false, db, fetch); err != nil {
+				return nil, err
+			}
+		}
+		firstBlock := tailTrio.First.Header
+		logger.Infof("Syncing the parent block state, height: %v, root: %v", firstBlock.Height, firstBlock.StateHash.Hex())
+		if err = SyncStateTrie(firstBlock.StateHash, false, db, fetch); err != nil {
+			return nil, err
+		}
+	}
+	logger.Infof("Syncing the state, height: %v, root: %v", secondBlock.Height, secondBlock.StateHash.Hex())
+	if err = SyncStateTrie(secondBlock.StateHash, true, db, fetch); err != nil {
+		return nil, err
+	}
+
+	sv := state.NewStoreView(secondBlock.Height, secondBlock.StateHash, db)
+	if sv == nil {
+		return nil, fmt.Errorf("Failed to load the synced state, root: %v", secondBlock.StateHash.Hex())
+	}
+	if err = checkTailTrio(sv, provenValSet, tailTrio); err != nil {
+		return nil, err
+	}
+
+	kvstore := kvstore.NewKVStore(db)
+	saveLastCheckpoint(lastCheckpoint, kvstore)
+	saveProofTrios(metadata, kvstore)
+	secondBlockHeader := saveTailBlocks(metadata, sv, kvstore)
+
+	if err = checkLastCheckpoint(sv, secondBlockHeader, lastCheckpoint, db); err != nil {
+		return nil, err
+	}
+	return secondBlockHeader, nil
+}
+
+// SyncStateTrie downloads the trie with the given root into the database, together with the storage tries
+// of the accounts in it if withAccountStorage is set. The nodes already in the database are skipped.
+func SyncStateTrie(root common.Hash, withAccountStorage bool, db database.Database, fetch NodeFetcher) error {
+	if err := syncTries([]common.Hash{root}, db, fetch); err != nil {
+		return err
+	}
+	if !withAccountStorage {
+		return nil
+	}
+
+	// The subtries already in the database are skipped by the scheduler, e.g. the ones shared with a state
+	// synced without the account storage, so the storage roots are collected from the complete trie instead
+	storageRoots := []common.Hash{}
+	seen := make(map[common.Hash]bool)
+	var err error
+	sv := state.NewStoreView(0, root, db)
+	sv.GetStore().Traverse([]byte("ls/a"), func(k, v common.Bytes) bool {
+		if err != nil {
+			return false
+		}
+		account := &types.Account{}
+		if err = types.FromBytes(v, account); err != nil {
+			err = fmt.Errorf("Failed to decode account %v: %v", hex.EncodeToString(k), err)
+			return false
+		}
+		if account.Root.IsEmpty() || seen[account.Root] {
+			return true
+		}
+		seen[account.Root] = true
+		if has, _ := db.Has(account.Root.Bytes()); !has {
+			storageRoots = append(storageRoots, account.Root)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if len(storageRoots) == 0 {
+		return nil
+	}
+	logger.Infof("Syncing the storage of %v accounts of root %v", len(storageRoots), root.Hex())
+	return syncTries(storageRoots, db, fetch)
+}
+
+// syncTries downloads the tries with the given roots, scheduled together so that the requests are batched.
+func syncTries(roots []common.Hash, db database.Database, fetch NodeFetcher) error {
+	sched := trie.NewSync(roots[0], db, nil)
+	for _, root := range roots[1:] {
+		sched.AddSubTrie(root, 0, common.Hash{}, nil)
+	}
+
+	var synced uint64
+	batch := db.NewBatch()
+	for sched.Pending() > 0 {
+		hashes := sched.Missing(StateSyncBatchSize)
+		for len(hashes) > 0 {
+			nodes, err := fetch(hashes)
+			if err != nil {
+				return err
+			}
+			results := []trie.SyncResult{}
+			missing := []common.Hash{}
+			for _, hash := range hashes {
+				data, ok := nodes[hash]
+				if !ok || crypto.Keccak256Hash(data) != hash {
+					missing = append(missing, hash)
+					continue
+				}
+				results = append(results, trie.SyncResult{Hash: hash, Data: data})
+			}
+			if _, index, err := sched.Process(results); err != nil {
+				return fmt.Errorf("Failed to process trie node %v: %v", results[index].Hash.Hex(), err)
+			}
+			hashes = missing
+		}
+
+		// Flush every round, so that the nodes shared by different parts of the trie are not requested again
+		written, err := sched.Commit(stateSyncBatch{batch})
+		if err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+
+		synced += uint64(written)
+		if synced/stateSyncLogInterval != (synced-uint64(written))/stateSyncLogInterval {
+			logger.Infof("Synced %v trie nodes, %v pending", synced, sched.Pending())
+		}
+	}
+	return nil
+}
+
+// checkTailTrioVotes checks that the tail blocks are linked by both the Parent and the HCC, and that
+// the third block is committed by the proven validator set, which finalizes the second block.
+func checkTailTrioVotes(provenValSet *core.ValidatorSet, tailTrio *core.SnapshotBlockTrio) error {
+	first := tailTrio.First.Header
+	second := tailTrio.Second.Header
+	third := tailTrio.Third.Header
+	if first == nil || second == nil || third == nil || tailTrio.Third.VoteSet == nil {
+		return fmt.Errorf("Incomplete tail trio")
+	}
+	if second.Parent != first.Hash() || third.Parent != second.Hash() {
+		return fmt.Errorf("Tail trio has invalid Parent link")
+	}
+	if second.HCC.BlockHash != first.Hash() || third.HCC.BlockHash != second.Hash() {
+		return fmt.Errorf("Tail trio has invalid HCC link")
+	}
+	if err := ValidateVotes(provenValSet, third, tailTrio.Third.VoteSet); err != nil {
+		return fmt.Errorf("Failed to validate the votes of the tail trio, %v", err)
+	}
+	return nil
+}
+
+// stateSyncBatch writes the synced trie nodes with the same conservative reference count as loadStateV3.
+type stateSyncBatch struct {
+	database.Batch
+}
+
+func (b stateSyncBatch) Put(key []byte, value []byte) error {
+	if err := b.Batch.Put(key, value); err != nil {
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		if err := b.Batch.Reference(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
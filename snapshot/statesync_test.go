@@ -0,0 +1,120 @@
+package snapshot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/dnerochain/dnero/common"
+	"github.com/dnerochain/dnero/core"
+	"github.com/dnerochain/dnero/ledger/state"
+	"github.com/dnerochain/dnero/ledger/types"
+	"github.com/dnerochain/dnero/store/database"
+	"github.com/dnerochain/dnero/store/database/backend"
+	"github.com/spf13/viper"
+)
+
+func newTestNodeFetcher(db database.Database, calls *int) NodeFetcher {
+	return func(hashes []common.Hash) (map[common.Hash]common.Bytes, error) {
+		*calls++
+		nodes := make(map[common.Hash]common.Bytes)
+		for i, hash := range hashes {
+			data, err := db.Get(hash.Bytes())
+			if err != nil {
+				continue
+			}
+			if *calls == 1 && i == 0 {
+				// a forged node is rejected and requested again
+				nodes[hash] = common.Bytes("forged")
+				continue
+			}
+			if *calls == 2 && i%2 == 1 {
+				// nodes not returned are requested again
+				continue
+			}
+			nodes[hash] = data
+		}
+		return nodes, nil
+	}
+}
+
+func TestSyncStateTrie(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srcdb := backend.NewMemDatabase()
+	sv := state.NewStoreView(1, common.Hash{}, srcdb)
+	addrs := []common.Address{}
+	for i := 0; i < 64; i++ {
+		addr := common.BytesToAddress([]byte{byte(i + 1)})
+		addrs = append(addrs, addr)
+		sv.SetAccount(addr, &types.Account{
+			Address:  addr,
+			Sequence: uint64(i),
+			Balance:  types.NewCoins(int64(i), int64(i*1000)),
+		})
+		if i%4 == 0 {
+			for j := 0; j < 8; j++ {
+				sv.SetState(addr, common.BytesToHash([]byte{byte(j)}), common.BytesToHash([]byte{byte(i), byte(j + 1)}))
+			}
+		}
+	}
+	root := sv.Save()
+
+	// without the account storage
+	dstdb := backend.NewMemDatabase()
+	calls := 0
+	require.Nil(SyncStateTrie(root, false, dstdb, newTestNodeFetcher(srcdb, &calls)))
+	synced := state.NewStoreView(1, root, dstdb)
+	require.NotNil(synced)
+	for i, addr := range addrs {
+		account := synced.GetAccount(addr)
+		require.NotNil(account)
+		assert.Equal(uint64(i), account.Sequence)
+		if i%4 == 0 {
+			has, _ := dstdb.Has(account.Root.Bytes())
+			assert.False(has)
+		}
+	}
+
+	// with the account storage, the nodes already synced are not requested again
+	calls = 0
+	require.Nil(SyncStateTrie(root, true, dstdb, newTestNodeFetcher(srcdb, &calls)))
+	synced = state.NewStoreView(1, root, dstdb)
+	for i, addr := range addrs {
+		if i%4 != 0 {
+			continue
+		}
+		for j := 0; j < 8; j++ {
+			assert.Equal(common.BytesToHash([]byte{byte(i), byte(j + 1)}), synced.GetState(addr, common.BytesToHash([]byte{byte(j)})))
+		}
+	}
+	assert.Equal(big.NewInt(63000), synced.GetAccount(addrs[63]).Balance.DTokenWei)
+
+	calls = 0
+	require.Nil(SyncStateTrie(root, true, dstdb, newTestNodeFetcher(srcdb, &calls)))
+	assert.Equal(0, calls)
+}
+
+func TestImportStateSyncCheckpointChainID(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID := viper.GetString(common.CfgGenesisChainID)
+	defer viper.Set(common.CfgGenesisChainID, chainID)
+	viper.Set(common.CfgGenesisChainID, "privatenet")
+
+	genesis := core.NewBlock().BlockHeader
+	genesis.ChainID = "othernet"
+	genesis.Height = core.GenesisBlockHeight
+	checkpoint := &core.StateSyncCheckpoint{}
+	checkpoint.LastCheckpoint.CheckpointHeader = genesis
+	checkpoint.Metadata.TailTrio.Second.Header = genesis
+
+	calls := 0
+	db := backend.NewMemDatabase()
+	_, err := ImportStateSyncCheckpoint(checkpoint, db, newTestNodeFetcher(db, &calls))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "ChainID mismatch")
+	assert.Equal(0, calls)
+}
@@ -56,6 +56,8 @@ type LDBDatabase struct {
 	writeDelayMeter  metrics.Meter // Meter for measuring the write delay duration due to database compaction
 	diskReadMeter    metrics.Meter // Meter for measuring the effective amount of data read
 	diskWriteMeter   metrics.Meter // Meter for measuring the effective amount of data written
+	readTimer        metrics.Timer // Timer for measuring the latency of the reads
+	writeTimer       metrics.Timer // Timer for measuring the latency of the writes, including the batch writes
 
 	quitLock sync.Mutex      // Mutex protecting the quit channel access
 	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
@@ -103,9 +105,11 @@ func NewLDBDatabase(file string, reffile string, cache int, handles int) (*LDBDa
 	}
 
 	return &LDBDatabase{
-		fn:    file,
-		db:    db,
-		refdb: refdb,
+		fn:         file,
+		db:         db,
+		refdb:      refdb,
+		readTimer:  metrics.NilTimer{},
+		writeTimer: metrics.NilTimer{},
 	}, nil
 }
 
@@ -116,6 +120,7 @@ func (db *LDBDatabase) Path() string {
 
 // Put puts the given key / value to the queue
 func (db *LDBDatabase) Put(key []byte, value []byte) error {
+	defer db.writeTimer.UpdateSince(time.Now())
 	return db.db.Put(key, value, nil)
 }
 
@@ -125,6 +130,7 @@ func (db *LDBDatabase) Has(key []byte) (bool, error) {
 
 // Get returns the given key if it's present.
 func (db *LDBDatabase) Get(key []byte) ([]byte, error) {
+	defer db.readTimer.UpdateSince(time.Now())
 	dat, err := db.db.Get(key, nil)
 	if err != nil {
 		if err == leveldb.ErrNotFound {
@@ -280,6 +286,8 @@ func (db *LDBDatabase) Meter(prefix string) {
 		db.compWriteMeter = metrics.NewRegisteredMeter(prefix+"compact/output", nil)
 		db.diskReadMeter = metrics.NewRegisteredMeter(prefix+"disk/read", nil)
 		db.diskWriteMeter = metrics.NewRegisteredMeter(prefix+"disk/write", nil)
+		db.readTimer = metrics.NewRegisteredTimer(prefix+"read", nil)
+		db.writeTimer = metrics.NewRegisteredTimer(prefix+"write", nil)
 	}
 	// Initialize write delay metrics no matter we are in metric mode or not.
 	db.writeDelayMeter = metrics.NewRegisteredMeter(prefix+"compact/writedelay/duration", nil)
@@ -468,7 +476,7 @@ func (db *LDBDatabase) meter(refresh time.Duration) {
 }
 
 func (db *LDBDatabase) NewBatch() database.Batch {
-	return &ldbBatch{db: db.db, refdb: db.refdb, b: new(leveldb.Batch), references: make(map[string]int)}
+	return &ldbBatch{db: db.db, refdb: db.refdb, b: new(leveldb.Batch), references: make(map[string]int), writeTimer: db.writeTimer}
 }
 
 type ldbBatch struct {
@@ -477,6 +485,7 @@ type ldbBatch struct {
 	b          *leveldb.Batch
 	references map[string]int
 	size       int
+	writeTimer metrics.Timer
 }
 
 func (b *ldbBatch) Put(key, value []byte) error {
@@ -505,6 +514,7 @@ func (b *ldbBatch) Dereference(key []byte) error {
 }
 
 func (b *ldbBatch) Write() error {
+	defer b.writeTimer.UpdateSince(time.Now())
 	err := b.db.Write(b.b, nil)
 	if err != nil {
 		return err
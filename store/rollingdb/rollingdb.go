@@ -13,11 +13,16 @@ import (
 	"github.com/dnerochain/dnero/blockchain"
 	"github.com/dnerochain/dnero/common"
 	"github.com/dnerochain/dnero/common/util"
+	"github.com/dnerochain/dnero/rlp"
 	"github.com/dnerochain/dnero/store/database"
 )
 
 var logger = util.GetLoggerForModule("rollingdb")
 
+// compactedHeightKey records the height of the oldest state retained by the compaction, the state
+// of the earlier heights is removed with the layers cut off
+var compactedHeightKey = []byte("/rollingdb/compacted_height")
+
 type RollingDB struct {
 	mu sync.RWMutex
 
@@ -80,7 +85,7 @@ func (rdb *RollingDB) loadLayers(rollingPath string) (*DBLayer, []*DBLayer) {
 	}
 
 	if len(names) == 0 {
-		if !viper.GetBool(common.CfgStorageRollingEnabled) {
+		if !rollingEnabled() {
 			return rdb.rootLayer, nil
 		}
 		return NewDBLayer(rollingPath, 1), nil
@@ -97,7 +102,7 @@ func (rdb *RollingDB) loadLayers(rollingPath string) (*DBLayer, []*DBLayer) {
 }
 
 func (rdb *RollingDB) Tag(height uint64, stateRoot common.Hash) {
-	if !viper.GetBool(common.CfgStorageRollingEnabled) {
+	if !rollingEnabled() {
 		return
 	}
 
@@ -126,7 +131,7 @@ func (rdb *RollingDB) addLayer() {
 }
 
 func (rdb *RollingDB) compact(height uint64) {
-	if !viper.GetBool(common.CfgStorageStatePruningEnabled) {
+	if !compactionEnabled() {
 		return
 	}
 
@@ -191,6 +196,9 @@ func (rdb *RollingDB) compact(height uint64) {
 						rdb.mu.Lock()
 						defer rdb.mu.Unlock()
 
+						// Recorded before the layers are removed, in case the removal is interrupted
+						rdb.saveCompactedHeight(sourceLayer.tag.Height)
+
 						remainingLayers := []*DBLayer{}
 						for _, layer := range rdb.layers {
 							// New layers might have been added after `targetLayer`
@@ -214,6 +222,51 @@ func (rdb *RollingDB) compact(height uint64) {
 
 }
 
+// CompactedHeight returns the height of the oldest state retained by the compaction, and whether
+// any layer has been cut off. The state of the heights below is no longer complete.
+func (rdb *RollingDB) CompactedHeight() (uint64, bool) {
+	rdb.mu.RLock()
+	defer rdb.mu.RUnlock()
+
+	if raw, err := rdb.root.Get(compactedHeightKey); err == nil {
+		var height uint64
+		if err := rlp.DecodeBytes(raw, &height); err == nil {
+			return height, true
+		}
+	}
+
+	// The DBs compacted before the height is recorded: the layers are named from 1 onwards, a gap
+	// means the earlier layers have been cut off. The height of the oldest layer left is a safe bound.
+	oldestLayer := rdb.activeLayer
+	if len(rdb.layers) > 0 {
+		oldestLayer = rdb.layers[0]
+	}
+	if oldestLayer != rdb.rootLayer && oldestLayer.name > 1 {
+		return oldestLayer.tag.Height, true
+	}
+	return 0, false
+}
+
+func (rdb *RollingDB) saveCompactedHeight(height uint64) {
+	raw, err := rlp.EncodeToBytes(height)
+	if err != nil {
+		logger.Panicf("Failed to encode the compacted height %v: %v", height, err)
+	}
+	if err := rdb.root.Put(compactedHeightKey, raw); err != nil {
+		logger.Panicf("Failed to save the compacted height %v: %v", height, err)
+	}
+}
+
+// rollingEnabled returns whether new layers are rolled, which is never the case in the archive mode
+func rollingEnabled() bool {
+	return viper.GetBool(common.CfgStorageRollingEnabled) && !viper.GetBool(common.CfgStorageArchiveMode)
+}
+
+// compactionEnabled returns whether the old layers are cut off, which is never the case in the archive mode
+func compactionEnabled() bool {
+	return viper.GetBool(common.CfgStorageStatePruningEnabled) && !viper.GetBool(common.CfgStorageArchiveMode)
+}
+
 func isRollingHeight(height uint64) bool {
 	return int(height)%viper.GetInt(common.CfgStorageRollingInterval) == 50
 }